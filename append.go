@@ -0,0 +1,379 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AppendOptions controls an AppendWriter
+type AppendOptions struct {
+	Fields    []FieldHeader // the fields, like for NewWriter, used to create the table when the file does not exist
+	BatchSize int           // number of records buffered before they are written, 1000 when 0
+	Sync      bool          // sync the files to disk after every batch, so written batches survive a power failure
+
+	// Lock places the Visual FoxPro header lock while a batch is written, for tables which other programs
+	// change at the same time. The record count and the next free memo block are read again under the lock,
+	// so records appended by others are not overwritten. See Locker for the lock retries.
+	Lock        bool
+	LockRetries int           // number of retries of the header lock, see Locker.Retries
+	LockDelay   time.Duration // time between the retries, see Locker.Delay
+	LockTimeout time.Duration // time the header lock is retried, see Locker.Timeout
+}
+
+// AppendWriter appends records to a table at a high rate, for tables which are used to hand over data
+// to a program which polls them. Records are buffered and written in batches, the record count in the header
+// is updated once per batch after the records and memos of the batch have been written. A reader which uses
+// the record count never sees a partial batch. When the program stops in the middle of a batch, the complete
+// records after the record count are recovered by the next OpenAppendWriter and a partial record is removed.
+// Memos are buffered with the records, they are written to the memo file before the records of their batch.
+type AppendWriter struct {
+	f      *os.File
+	fptf   *os.File
+	wr     *Writer // encodes the records and writes the memos to memobuf
+	locker *Locker // nil when Lock is not set
+
+	memobuf *generateBuffer // buffered memos, starting at block 1
+	memoPos []int           // position of the memo fields in the record data
+
+	opts      AppendOptions
+	batch     []byte // buffered records
+	pending   uint32 // number of buffered records
+	recovered uint32
+	closed    bool
+}
+
+// OpenAppendWriter opens the table filename for appending. When the file does not exist and opts.Fields is set,
+// an empty table is created. Only tables which the Writer can create are supported: the fields must be of
+// a type the Writer supports, and the table can not have null values or an SMT memo file.
+// The Encoder is used to convert UTF8 strings to the charset of the table, see encoder.go.
+// The caller is responsible for calling Close, which writes the buffered records.
+func OpenAppendWriter(filename string, enc Encoder, opts AppendOptions) (*AppendWriter, error) {
+	filename = filepath.Clean(filename)
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	if _, err := os.Stat(filename); os.IsNotExist(err) && opts.Fields != nil {
+		if err := createTable(filename, opts.Fields, enc); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	aw := &AppendWriter{f: f, opts: opts}
+	if err := aw.open(filename, enc); err != nil {
+		aw.closeFiles()
+		return nil, err
+	}
+	return aw, nil
+}
+
+// createTable creates an empty table with the fields, and its memo file when there are memo fields
+func createTable(filename string, fields []FieldHeader, enc Encoder) error {
+	wr, err := Create(filename, fields, enc)
+	if err != nil {
+		return err
+	}
+	return wr.Close()
+}
+
+// open reads the header and fields of the table, opens the memo file and recovers an interrupted batch
+func (aw *AppendWriter) open(filename string, enc Encoder) error {
+	dbf, err := prepareDBF(aw.f, nil, nil)
+	if err != nil {
+		return err
+	}
+	if dbf.nullflags >= 0 {
+		return errors.New("tables with null values can not be appended to")
+	}
+	fields := make([]FieldHeader, len(dbf.fields))
+	for i, f := range dbf.fields {
+		if err := prepareField(&f); err != nil {
+			return err
+		}
+		if f.Len != dbf.fields[i].Len {
+			return fmt.Errorf("field %s: invalid length %d for a %s field", f.FieldName(), dbf.fields[i].Len, f.FieldType())
+		}
+		fields[i] = f
+	}
+	aw.wr = &Writer{header: dbf.header, fields: fields, enc: enc, buf: make([]byte, dbf.header.RecLen)}
+	fillSpaces(aw.wr.buf)
+
+	if dbf.header.HasMemo() {
+		if dbf.smt {
+			return errors.New("tables with an SMT memo file can not be appended to")
+		}
+		if aw.fptf, err = os.OpenFile(memoFileName(filename), os.O_RDWR, 0); err != nil {
+			return err
+		}
+		h, err := readFPTHeader(aw.fptf)
+		if err != nil {
+			return err
+		}
+		if h.BlockSize == 0 {
+			return errors.New("invalid memo block size 0")
+		}
+		aw.memobuf = new(generateBuffer)
+		aw.wr.memo = &memoWriter{w: aw.memobuf, blockSize: uint32(h.BlockSize), next: 1}
+		offset := 1
+		for _, f := range fields {
+			if f.isMemo() {
+				aw.memoPos = append(aw.memoPos, offset)
+			}
+			offset += int(f.Len)
+		}
+	}
+
+	if aw.opts.Lock {
+		aw.locker = &Locker{f: aw.f, Retries: aw.opts.LockRetries, Delay: aw.opts.LockDelay, Timeout: aw.opts.LockTimeout}
+		if err := aw.locker.LockHeader(); err != nil {
+			return err
+		}
+		defer aw.locker.UnlockHeader()
+	}
+	return aw.recover()
+}
+
+// memoFileEnd returns the next free block of the memo file. Blocks after the next free block of the header
+// can be used by recovered records, so the end of the file is used when it is beyond the next free block.
+func memoFileEnd(fptfile *os.File) (uint32, error) {
+	h, err := readFPTHeader(fptfile)
+	if err != nil {
+		return 0, err
+	}
+	if h.BlockSize == 0 {
+		return 0, errors.New("invalid memo block size 0")
+	}
+	stat, err := fptfile.Stat()
+	if err != nil {
+		return 0, err
+	}
+	next := h.NextFree
+	if end := (stat.Size() + int64(h.BlockSize) - 1) / int64(h.BlockSize); end > int64(next) && end <= MaxMemoBlocks {
+		next = uint32(end)
+	}
+	return next, nil
+}
+
+// recover adds the complete records after the record count of the header to the table, removes a partial
+// record at the end and writes the EOF marker
+func (aw *AppendWriter) recover() error {
+	if err := aw.readCount(); err != nil {
+		return err
+	}
+	h := aw.wr.header
+	stat, err := aw.f.Stat()
+	if err != nil {
+		return err
+	}
+	first, reclen, count := int64(h.FirstRec), int64(h.RecLen), int64(h.NumRec)
+	if end := first + count*reclen; end > stat.Size() {
+		return fmt.Errorf("%d records need %d bytes, the file has %d bytes", count, end, stat.Size())
+	}
+	flag := make([]byte, 1)
+	for count < MaxRecords && first+(count+1)*reclen <= stat.Size() {
+		if _, err := aw.f.ReadAt(flag, first+count*reclen); err != nil {
+			return err
+		}
+		// the EOF marker or data of an incomplete write ends the records
+		if flag[0] != 0x20 && flag[0] != 0x2A {
+			break
+		}
+		count++
+	}
+	end := first + count*reclen
+	if err := aw.f.Truncate(end); err != nil {
+		return err
+	}
+	if _, err := aw.f.WriteAt([]byte{0x1A}, end); err != nil {
+		return err
+	}
+	if recovered := uint32(count) - h.NumRec; recovered > 0 {
+		aw.recovered = recovered
+		h.NumRec = uint32(count)
+		return aw.writeHeader()
+	}
+	return nil
+}
+
+// readCount reads the record count from the header in the file
+func (aw *AppendWriter) readCount() error {
+	h, err := readHeaderAt(aw.f)
+	if err != nil {
+		return err
+	}
+	aw.wr.header.NumRec = h.NumRec
+	return nil
+}
+
+// Recovered returns the number of records of an interrupted batch which were added to the record count when
+// the table was opened
+func (aw *AppendWriter) Recovered() uint32 {
+	return aw.recovered
+}
+
+// Fields returns the fields of the table
+func (aw *AppendWriter) Fields() []FieldHeader {
+	return aw.wr.fields
+}
+
+// NumRecords returns the number of records of the table, including the buffered records
+func (aw *AppendWriter) NumRecords() uint32 {
+	return aw.wr.header.NumRec + aw.pending
+}
+
+// Append buffers a record with the values in field order, the values are the same as for Writer.Append.
+// The batch is written when it has BatchSize records.
+func (aw *AppendWriter) Append(values ...interface{}) error {
+	if aw.closed {
+		return ErrWriterClosed
+	}
+	if len(values) != len(aw.wr.fields) {
+		return ErrNumFields
+	}
+	if uint64(aw.NumRecords()) >= MaxRecords {
+		return &LimitError{Limit: "number of records", Value: uint64(MaxRecords) + 1, Max: MaxRecords}
+	}
+	if err := aw.wr.encodeRecord(values); err != nil {
+		return err
+	}
+	aw.batch = append(aw.batch, aw.wr.buf...)
+	aw.pending++
+	if aw.pending >= uint32(aw.opts.BatchSize) {
+		return aw.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered records and updates the record count in the header.
+// The memos are written first, then the records with a new EOF marker and last the record count.
+// When the header can not be locked ErrLocked is returned, the records stay buffered and Flush can be retried.
+func (aw *AppendWriter) Flush() error {
+	if aw.closed {
+		return ErrWriterClosed
+	}
+	if aw.pending == 0 {
+		return nil
+	}
+	if aw.locker != nil {
+		if err := aw.locker.LockHeader(); err != nil {
+			return err
+		}
+		defer aw.locker.UnlockHeader()
+		// other programs can have appended records since the last batch
+		if err := aw.readCount(); err != nil {
+			return err
+		}
+	}
+	if aw.memobuf != nil {
+		if err := aw.flushMemos(); err != nil {
+			return err
+		}
+	}
+
+	h := aw.wr.header
+	pos := int64(h.FirstRec) + int64(h.NumRec)*int64(h.RecLen)
+	if _, err := aw.f.WriteAt(append(aw.batch, 0x1A), pos); err != nil {
+		return err
+	}
+	if err := aw.sync(aw.f); err != nil {
+		return err
+	}
+	h.NumRec += aw.pending
+	aw.batch, aw.pending = aw.batch[:0], 0
+	if err := aw.writeHeader(); err != nil {
+		return err
+	}
+	return aw.sync(aw.f)
+}
+
+// flushMemos writes the buffered memos after the last block of the memo file and updates the memo file header.
+// The block numbers in the buffered records are changed to the blocks in the file.
+func (aw *AppendWriter) flushMemos() error {
+	mw := aw.wr.memo
+	if mw.next == 1 {
+		// no memos in this batch
+		return nil
+	}
+	next, err := memoFileEnd(aw.fptf)
+	if err != nil {
+		return err
+	}
+	if end := uint64(next) + uint64(mw.next) - 1; end > MaxMemoBlocks {
+		return &LimitError{Limit: "number of memo blocks", Value: end, Max: MaxMemoBlocks}
+	}
+	if _, err := aw.fptf.WriteAt(aw.memobuf.buf[mw.blockSize:], int64(next)*int64(mw.blockSize)); err != nil {
+		return err
+	}
+	if err := aw.sync(aw.fptf); err != nil {
+		return err
+	}
+	header := &memoWriter{w: aw.fptf, blockSize: mw.blockSize, next: next + mw.next - 1}
+	if err := header.close(); err != nil {
+		return err
+	}
+	if err := aw.sync(aw.fptf); err != nil {
+		return err
+	}
+
+	reclen := int(aw.wr.header.RecLen)
+	for rec := aw.batch; len(rec) >= reclen; rec = rec[reclen:] {
+		for _, pos := range aw.memoPos {
+			// empty memos are stored as block 0
+			if block := binary.LittleEndian.Uint32(rec[pos:]); block != 0 {
+				binary.LittleEndian.PutUint32(rec[pos:], block+next-1)
+			}
+		}
+	}
+	aw.memobuf.buf, aw.memobuf.pos = aw.memobuf.buf[:0], 0
+	mw.next = 1
+	return nil
+}
+
+// writeHeader writes the last update date and the record count to the header
+func (aw *AppendWriter) writeHeader() error {
+	h := aw.wr.header
+	setHeaderModified(h, time.Now())
+	buf := []byte{h.ModYear, h.ModMonth, h.ModDay, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(buf[3:], h.NumRec)
+	_, err := aw.f.WriteAt(buf, 1)
+	return err
+}
+
+// sync syncs f to disk when Sync is set
+func (aw *AppendWriter) sync(f *os.File) error {
+	if !aw.opts.Sync {
+		return nil
+	}
+	return f.Sync()
+}
+
+// Close writes the buffered records and closes the files
+func (aw *AppendWriter) Close() error {
+	if aw.closed {
+		return ErrWriterClosed
+	}
+	err := aw.Flush()
+	aw.closed = true
+	if cerr := aw.closeFiles(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// closeFiles closes the table and memo file
+func (aw *AppendWriter) closeFiles() error {
+	err := aw.f.Close()
+	if aw.fptf != nil {
+		if ferr := aw.fptf.Close(); err == nil {
+			err = ferr
+		}
+	}
+	return err
+}
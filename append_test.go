@@ -0,0 +1,194 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAppendWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfappend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "LOG.DBF")
+
+	fields := []FieldHeader{newField("SEQ", 'I', 0, 0), newField("MSG", 'C', 20, 0), newField("DETAIL", 'M', 0, 0)}
+	aw, err := OpenAppendWriter(filename, new(UTF8Encoder), AppendOptions{Fields: fields, BatchSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		if err := aw.Append(i, "message", "detail"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if aw.NumRecords() != 7 {
+		t.Errorf("Want 7 records, have %d", aw.NumRecords())
+	}
+	// two batches are written, the last record is buffered
+	if n := appendedRecords(t, filename); n != 6 {
+		t.Errorf("Want 6 written records, have %d", n)
+	}
+	if err := aw.Append(1); err != ErrNumFields {
+		t.Errorf("Want ErrNumFields, have %v", err)
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := aw.Append(1, "", ""); err != ErrWriterClosed {
+		t.Errorf("Want ErrWriterClosed, have %v", err)
+	}
+	if n := appendedRecords(t, filename); n != 7 {
+		t.Errorf("Want 7 written records, have %d", n)
+	}
+
+	// records written after the record count and a partial record, like after a crash in the middle of a batch
+	f, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := make([]byte, 4)
+	binary.LittleEndian.PutUint32(count, 5)
+	if _, err := f.WriteAt(count, 4); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte(" partial"), stat.Size()-1); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	aw, err = OpenAppendWriter(filename, new(UTF8Encoder), AppendOptions{Sync: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aw.Recovered() != 2 || aw.NumRecords() != 7 {
+		t.Errorf("Want 2 recovered records of 7, have %d of %d", aw.Recovered(), aw.NumRecords())
+	}
+	if err := aw.Append(7, "last", "the last detail"); err != nil {
+		t.Fatal(err)
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dbf, err := OpenFile(filename, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	if dbf.NumRecords() != 8 {
+		t.Fatalf("Want 8 records, have %d", dbf.NumRecords())
+	}
+	for recno := uint32(0); recno < 8; recno++ {
+		rec, err := dbf.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq := rec.FieldSlice()[0]; seq != int32(recno) {
+			t.Errorf("Record %d: want SEQ %d, have %v", recno, recno, seq)
+		}
+	}
+	rec, err := dbf.RecordAt(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail := rec.FieldSlice()[2]; detail != "the last detail" {
+		t.Errorf("Want the memo of the last record, have %q", detail)
+	}
+	rec, err = dbf.RecordAt(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail := rec.FieldSlice()[2]; detail != "detail" {
+		t.Errorf("Want the memo of record 2, have %q", detail)
+	}
+}
+
+func TestAppendWriterLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfappend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "LOG.DBF")
+
+	fields := []FieldHeader{newField("MSG", 'C', 20, 0), newField("DETAIL", 'M', 0, 0)}
+	opts := AppendOptions{Fields: fields, Lock: true, LockTimeout: 30 * time.Millisecond}
+	aw, err := OpenAppendWriter(filename, new(UTF8Encoder), opts)
+	if err == ErrLockingNotSupported {
+		t.Skip(err)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	defer aw.Close()
+	if err := aw.Append("mine", "my detail"); err != nil {
+		t.Fatal(err)
+	}
+
+	release := lockInOtherProcess(t, filename)
+	if err := aw.Flush(); err != ErrLocked {
+		t.Errorf("Want ErrLocked while another process holds the header lock, have %v", err)
+	}
+	if _, err := OpenAppendWriter(filename, new(UTF8Encoder), opts); err != ErrLocked {
+		t.Errorf("Want ErrLocked when opening a locked table, have %v", err)
+	}
+	release()
+
+	// another program appends records while the first batch is buffered
+	other, err := OpenAppendWriter(filename, new(UTF8Encoder), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"first", "second"} {
+		if err := other.Append(msg, msg+" detail"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := other.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := aw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if aw.NumRecords() != 3 {
+		t.Errorf("Want 3 records, have %d", aw.NumRecords())
+	}
+
+	dbf, err := OpenFile(filename, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	want := [][]interface{}{{"first", "first detail"}, {"second", "second detail"}, {"mine", "my detail"}}
+	for recno, values := range want {
+		rec, err := dbf.RecordAt(uint32(recno))
+		if err != nil {
+			t.Fatal(err)
+		}
+		values[0] = fmt.Sprintf("%-20s", values[0])
+		if !reflect.DeepEqual(rec.FieldSlice(), values) {
+			t.Errorf("Record %d: want %q, have %q", recno, values, rec.FieldSlice())
+		}
+	}
+}
+
+// appendedRecords returns the record count in the header of a table
+func appendedRecords(t *testing.T, filename string) uint32 {
+	dbf, err := OpenFile(filename, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	return dbf.NumRecords()
+}
@@ -0,0 +1,368 @@
+package dbf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// ArrowOptions controls how a table is written by WriteArrow
+type ArrowOptions struct {
+	// BatchSize is the number of records per record batch, 0 uses DefaultArrowBatchSize
+	BatchSize int
+
+	// IncludeDeleted also writes deleted records, by default they are skipped
+	IncludeDeleted bool
+}
+
+// DefaultArrowBatchSize is the number of records per record batch used when ArrowOptions.BatchSize is 0
+const DefaultArrowBatchSize = 65536
+
+// Arrow column types as used by WriteArrow, the values are the Arrow flatbuffers Type union ids
+const (
+	arrowInt       = 2
+	arrowFloat     = 3
+	arrowBinary    = 4
+	arrowUtf8      = 5
+	arrowBool      = 6
+	arrowDate      = 8
+	arrowTimestamp = 10
+)
+
+// Arrow flatbuffers MessageHeader union ids and the metadata version (V5)
+const (
+	arrowMessageSchema      = 1
+	arrowMessageRecordBatch = 3
+	arrowMetadataVersion    = 4
+)
+
+var arrowMagic = []byte("ARROW1")
+
+// WriteArrow writes the table to w in the Arrow IPC file format, also known as Feather version 2,
+// which can be opened directly by Arrow based tools like DuckDB, Pandas and Polars.
+// Records are read and written in batches, so the table is never completely in memory.
+// The field types are mapped as follows:
+//
+//	C, M        utf8 (trailing spaces of C fields are trimmed)
+//	I           int32
+//	N           int64 without decimals, float64 with decimals
+//	F, B, Y     float64
+//	L           bool
+//	D           date32 (days)
+//	T           timestamp (milliseconds, without time zone)
+//	G, P, W, V  binary
+//
+// Empty D and T fields are written as null. WriteArrow is Export with an ArrowWriter.
+func (dbf *DBF) WriteArrow(w io.Writer, opts ArrowOptions) error {
+	_, err := dbf.Export(NewArrowWriter(w, opts.BatchSize), ExportOptions{IncludeDeleted: opts.IncludeDeleted})
+	return err
+}
+
+// ArrowWriter is a RecordWriter which writes the Arrow IPC file format, see WriteArrow
+type ArrowWriter struct {
+	cw        *countingWriter
+	batchSize int
+	schema    fbTable
+	columns   []*arrowColumn
+	rows      int
+	blocks    []byte
+}
+
+var _ RecordWriter = (*ArrowWriter)(nil)
+
+// NewArrowWriter returns an ArrowWriter which writes record batches of batchSize records to w,
+// 0 uses DefaultArrowBatchSize
+func NewArrowWriter(w io.Writer, batchSize int) *ArrowWriter {
+	if batchSize <= 0 {
+		batchSize = DefaultArrowBatchSize
+	}
+	return &ArrowWriter{cw: &countingWriter{w: w}, batchSize: batchSize}
+}
+
+// WriteSchema writes the file header and the schema
+func (aw *ArrowWriter) WriteSchema(fields []FieldHeader) error {
+	aw.schema = arrowSchema(fields)
+	aw.columns = make([]*arrowColumn, len(fields))
+	for i := range fields {
+		aw.columns[i] = newArrowColumn(&fields[i])
+	}
+	if _, err := aw.cw.Write(append(append([]byte{}, arrowMagic...), 0, 0)); err != nil {
+		return err
+	}
+	_, err := writeArrowMessage(aw.cw, arrowMessageSchema, aw.schema, nil)
+	return err
+}
+
+// WriteRecord adds a record to the current batch, the batch is written when it is full
+func (aw *ArrowWriter) WriteRecord(rec *Record) error {
+	if len(rec.data) != len(aw.columns) {
+		return ErrNumFields
+	}
+	for pos, c := range aw.columns {
+		c.append(rec.data[pos])
+	}
+	aw.rows++
+	if aw.rows == aw.batchSize {
+		return aw.flush()
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a record batch
+func (aw *ArrowWriter) flush() error {
+	if aw.rows == 0 {
+		return nil
+	}
+	offset := aw.cw.n
+	meta, body := arrowRecordBatch(aw.columns, aw.rows)
+	metaLen, err := writeArrowMessage(aw.cw, arrowMessageRecordBatch, meta, body)
+	if err != nil {
+		return err
+	}
+	// Block struct: offset, metaDataLength (+4 padding), bodyLength
+	aw.blocks = appendUint64(aw.blocks, uint64(offset))
+	aw.blocks = appendUint64(aw.blocks, uint64(metaLen))
+	aw.blocks = appendUint64(aw.blocks, uint64(len(body)))
+	for _, c := range aw.columns {
+		c.reset()
+	}
+	aw.rows = 0
+	return nil
+}
+
+// Close writes the last batch and the footer, it does not close the underlying writer
+func (aw *ArrowWriter) Close() error {
+	if aw.schema == nil {
+		return errors.New("no schema written")
+	}
+	if err := aw.flush(); err != nil {
+		return err
+	}
+
+	// end of stream marker
+	if _, err := aw.cw.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0}); err != nil {
+		return err
+	}
+
+	footer := fbFinish(fbTable{
+		fbScalar(2, arrowMetadataVersion),
+		fbChild(aw.schema),
+		fbChild(fbStructs{align: 8}),
+		fbChild(fbStructs{align: 8, count: len(aw.blocks) / 24, data: aw.blocks}),
+	})
+	if _, err := aw.cw.Write(footer); err != nil {
+		return err
+	}
+	if _, err := aw.cw.Write(appendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	_, err := aw.cw.Write(arrowMagic)
+	return err
+}
+
+// arrowSchema returns the Schema flatbuffers table of the fields
+func arrowSchema(fields []FieldHeader) fbTable {
+	schema := make(fbTables, len(fields))
+	for i := range fields {
+		f := &fields[i]
+		typeID, typ := arrowType(f)
+		schema[i] = fbTable{
+			fbChild(fbString(f.FieldName())),
+			fbBool(true), // nullable
+			fbScalar(1, typeID),
+			fbChild(typ),
+			{},                     // dictionary
+			fbChild(fbTables(nil)), // children
+		}
+	}
+	return fbTable{
+		fbScalar(2, 0), // little endian
+		fbChild(schema),
+	}
+}
+
+// arrowType returns the Type union id and table for a field
+func arrowType(f *FieldHeader) (uint64, fbTable) {
+	switch f.Type {
+	case 'C', 'M':
+		return arrowUtf8, fbTable{}
+	case 'I':
+		return arrowInt, fbTable{fbScalar(4, 32), fbBool(true)}
+	case 'N':
+		if f.Decimals == 0 {
+			return arrowInt, fbTable{fbScalar(4, 64), fbBool(true)}
+		}
+		return arrowFloat, fbTable{fbScalar(2, 2)} // double
+	case 'F', 'B', 'Y':
+		return arrowFloat, fbTable{fbScalar(2, 2)}
+	case 'L':
+		return arrowBool, fbTable{}
+	case 'D':
+		return arrowDate, fbTable{fbScalar(2, 0)} // days
+	case 'T':
+		return arrowTimestamp, fbTable{fbScalar(2, 1)} // milliseconds
+	}
+	return arrowBinary, fbTable{}
+}
+
+// arrowRecordBatch returns the RecordBatch flatbuffers table and the message body for the buffered rows
+func arrowRecordBatch(columns []*arrowColumn, rows int) (fbTable, []byte) {
+	var nodes, buffers, body []byte
+	addBuffer := func(data []byte) {
+		buffers = appendUint64(buffers, uint64(len(body)))
+		buffers = appendUint64(buffers, uint64(len(data)))
+		body = append(body, data...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+	}
+	numBuffers := 0
+	for _, c := range columns {
+		nodes = appendUint64(nodes, uint64(rows))
+		nodes = appendUint64(nodes, uint64(c.nulls))
+		if c.nulls > 0 {
+			addBuffer(c.validity)
+		} else {
+			addBuffer(nil)
+		}
+		if c.typeID == arrowUtf8 || c.typeID == arrowBinary {
+			addBuffer(c.offsets)
+			numBuffers++
+		}
+		addBuffer(c.values)
+		numBuffers += 2
+	}
+	return fbTable{
+		fbScalar(8, uint64(rows)),
+		fbChild(fbStructs{align: 8, count: len(columns), data: nodes}),
+		fbChild(fbStructs{align: 8, count: numBuffers, data: buffers}),
+	}, body
+}
+
+// writeArrowMessage writes an encapsulated IPC message and returns the length of the metadata including its prefix
+func writeArrowMessage(w io.Writer, headerType uint64, header fbTable, body []byte) (int, error) {
+	meta := fbFinish(fbTable{
+		fbScalar(2, arrowMetadataVersion),
+		fbScalar(1, headerType),
+		fbChild(header),
+		fbScalar(8, uint64(len(body))),
+	})
+	prefix := appendUint32([]byte{0xFF, 0xFF, 0xFF, 0xFF}, uint32(len(meta)))
+	for _, b := range [][]byte{prefix, meta, body} {
+		if _, err := w.Write(b); err != nil {
+			return 0, err
+		}
+	}
+	return len(prefix) + len(meta), nil
+}
+
+// arrowColumn buffers the values of one field for the current record batch
+type arrowColumn struct {
+	field    *FieldHeader
+	typeID   uint64
+	rows     int
+	nulls    int
+	validity []byte
+	offsets  []byte // utf8 and binary only
+	values   []byte
+}
+
+func newArrowColumn(f *FieldHeader) *arrowColumn {
+	typeID, _ := arrowType(f)
+	c := &arrowColumn{field: f, typeID: typeID}
+	c.reset()
+	return c
+}
+
+func (c *arrowColumn) reset() {
+	c.rows, c.nulls = 0, 0
+	c.validity = c.validity[:0]
+	c.values = c.values[:0]
+	c.offsets = c.offsets[:0]
+	if c.typeID == arrowUtf8 || c.typeID == arrowBinary {
+		c.offsets = appendUint32(c.offsets, 0)
+	}
+}
+
+// append adds a field value to the column
+func (c *arrowColumn) append(val interface{}) {
+	if c.rows%8 == 0 {
+		c.validity = append(c.validity, 0)
+		if c.typeID == arrowBool {
+			c.values = append(c.values, 0)
+		}
+	}
+	valid := true
+	switch c.typeID {
+	case arrowUtf8, arrowBinary:
+		var data string
+		switch v := val.(type) {
+		case string:
+			data = v
+			if c.field.Type == 'C' {
+				data = strings.TrimRight(v, " ")
+			}
+		case []byte:
+			data = string(v)
+		case nil:
+			valid = false
+		default:
+			data = fmt.Sprint(v)
+		}
+		c.values = append(c.values, data...)
+		c.offsets = appendUint32(c.offsets, uint32(len(c.values)))
+	case arrowInt:
+		if c.field.Type == 'I' {
+			i, _ := val.(int32)
+			c.values = appendUint32(c.values, uint32(i))
+		} else {
+			c.values = appendUint64(c.values, uint64(ToInt64(val)))
+		}
+	case arrowFloat:
+		c.values = appendUint64(c.values, math.Float64bits(ToFloat64(val)))
+	case arrowBool:
+		if ToBool(val) {
+			c.values[c.rows/8] |= 1 << uint(c.rows%8)
+		}
+	case arrowDate:
+		t := ToTime(val)
+		valid = !t.IsZero()
+		days := int64(0)
+		if valid {
+			days = t.Unix() / 86400
+			if t.Unix()%86400 < 0 {
+				days--
+			}
+		}
+		c.values = appendUint32(c.values, uint32(int32(days)))
+	case arrowTimestamp:
+		t := ToTime(val)
+		valid = !t.IsZero()
+		ms := int64(0)
+		if valid {
+			ms = t.Unix()*1000 + int64(t.Nanosecond()/int(time.Millisecond))
+		}
+		c.values = appendUint64(c.values, uint64(ms))
+	}
+	if valid {
+		c.validity[c.rows/8] |= 1 << uint(c.rows%8)
+	} else {
+		c.nulls++
+	}
+	c.rows++
+}
+
+// countingWriter counts the number of bytes written
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
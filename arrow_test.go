@@ -0,0 +1,97 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteArrow(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	tests := []struct {
+		opts    ArrowOptions
+		batches int
+		rows    []uint64
+	}{
+		{ArrowOptions{}, 1, []uint64{3}},
+		{ArrowOptions{BatchSize: 2}, 2, []uint64{2, 1}},
+		{ArrowOptions{BatchSize: 2, IncludeDeleted: true}, 2, []uint64{2, 2}},
+	}
+	for i, test := range tests {
+		buf := new(bytes.Buffer)
+		if err := dbf.WriteArrow(buf, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		if !bytes.HasPrefix(data, []byte("ARROW1\x00\x00")) || !bytes.HasSuffix(data, []byte("ARROW1")) {
+			t.Fatalf("Test %d: missing Arrow magic", i)
+		}
+		footerLen := int(binary.LittleEndian.Uint32(data[len(data)-10:]))
+		footer := data[len(data)-10-footerLen : len(data)-10]
+
+		// Footer: 0 version, 1 schema, 2 dictionaries, 3 record batches
+		root := fbTestRoot(footer)
+		if v := binary.LittleEndian.Uint16(footer[fbTestField(footer, root, 0):]); v != arrowMetadataVersion {
+			t.Errorf("Test %d: want metadata version %d, have %d", i, arrowMetadataVersion, v)
+		}
+		schema := fbTestChild(footer, fbTestField(footer, root, 1))
+		fields := fbTestChild(footer, fbTestField(footer, schema, 1))
+		if n := binary.LittleEndian.Uint32(footer[fields:]); n != 13 {
+			t.Errorf("Test %d: want 13 fields, have %d", i, n)
+		}
+		blocks := fbTestChild(footer, fbTestField(footer, root, 3))
+		if n := int(binary.LittleEndian.Uint32(footer[blocks:])); n != test.batches {
+			t.Fatalf("Test %d: want %d record batches, have %d", i, test.batches, n)
+		}
+
+		for b := 0; b < test.batches; b++ {
+			block := footer[blocks+4+24*b:]
+			offset := binary.LittleEndian.Uint64(block)
+			if offset%8 != 0 {
+				t.Errorf("Test %d: record batch %d is not aligned", i, b)
+			}
+			if binary.LittleEndian.Uint32(data[offset:]) != 0xFFFFFFFF {
+				t.Errorf("Test %d: record batch %d has no continuation marker", i, b)
+			}
+			// Message: 0 version, 1 header type, 2 header, 3 body length
+			msg := data[offset+8:]
+			root := fbTestRoot(msg)
+			if typ := msg[fbTestField(msg, root, 1)]; typ != arrowMessageRecordBatch {
+				t.Errorf("Test %d: want record batch message, have type %d", i, typ)
+			}
+			batch := fbTestChild(msg, fbTestField(msg, root, 2))
+			if rows := binary.LittleEndian.Uint64(msg[fbTestField(msg, batch, 0):]); rows != test.rows[b] {
+				t.Errorf("Test %d: want %d rows in batch %d, have %d", i, test.rows[b], b, rows)
+			}
+		}
+	}
+}
+
+// fbTestRoot returns the position of the root table of a flatbuffer
+func fbTestRoot(buf []byte) int {
+	return int(binary.LittleEndian.Uint32(buf))
+}
+
+// fbTestField returns the position of field id in the table at pos
+func fbTestField(buf []byte, pos, id int) int {
+	vtable := pos - int(int32(binary.LittleEndian.Uint32(buf[pos:])))
+	if 4+2*id >= int(binary.LittleEndian.Uint16(buf[vtable:])) {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(buf[vtable+4+2*id:]))
+	if off == 0 {
+		return 0
+	}
+	return pos + off
+}
+
+// fbTestChild follows the offset at pos
+func fbTestChild(buf []byte, pos int) int {
+	return pos + int(binary.LittleEndian.Uint32(buf[pos:]))
+}
@@ -0,0 +1,244 @@
+package dbf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrTableChanged is returned by BackupTable when the table kept changing while it was copied
+var ErrTableChanged = errors.New("the table changed while it was copied")
+
+// tableExtensions are the extensions of the files which belong to a table: the table, memo files and indexes.
+// Database containers (DBC) have a memo file (DCT) and an index (DCX) as well.
+var tableExtensions = map[string]bool{
+	".dbf": true, ".fpt": true, ".dbt": true, ".smt": true, ".cdx": true, ".idx": true,
+	".dbc": true, ".dct": true, ".dcx": true,
+}
+
+// backupAttempts is the number of times BackupTable copies a table which changes while it is copied
+const backupAttempts = 3
+
+// BackupReport describes the files copied by BackupTable or RestoreTable
+type BackupReport struct {
+	Files   []string // the files written
+	Records uint32   // number of records in the header of the table
+}
+
+// BackupTable copies a table with its memo file and index files (CDX, IDX) to destDir. The files belong to the
+// table when they have the same name as the table, ignoring case, and one of the extensions of these files.
+// The copies are written to temporary files and renamed into place together, see Rewrite, so destDir never
+// contains a partial backup. When the table is changed while it is copied, the copy is made again.
+// Files of an earlier backup of the table in destDir are replaced, or removed when the table no longer has them.
+func BackupTable(path, destDir string) (*BackupReport, error) {
+	files, err := tableFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; attempt < backupAttempts; attempt++ {
+		before, err := readTableState(files)
+		if err != nil {
+			return nil, err
+		}
+		rw, report, err := copyTableFiles(files, path, destDir)
+		if err != nil {
+			return nil, err
+		}
+		after, err := readTableState(files)
+		if err != nil {
+			rw.Abort()
+			return nil, err
+		}
+		if !before.equal(after) {
+			rw.Abort()
+			continue
+		}
+		return report, rw.Commit()
+	}
+	return nil, ErrTableChanged
+}
+
+// BackupTableLocked is BackupTable while the table is locked like FLOCK in FoxPro, so applications which lock
+// before they change the table wait until the copy is made. The lock is retried for the timeout.
+func BackupTableLocked(path, destDir string, timeout time.Duration) (*BackupReport, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	l := NewLocker(f)
+	l.Timeout = timeout
+	if err := l.LockTable(); err != nil {
+		return nil, err
+	}
+	defer l.UnlockTable()
+	return BackupTable(path, destDir)
+}
+
+// RestoreTable copies a table which was copied by BackupTable back to destDir. The backup is opened first,
+// a backup which can not be opened is not restored. The files of the table in destDir are replaced together,
+// files which are not in the backup, like an index which was created later, are removed.
+// The table must not be in use while it is restored.
+func RestoreTable(backupPath, destDir string) (*BackupReport, error) {
+	d, err := OpenFile(backupPath, new(UTF8Decoder))
+	if err != nil {
+		return nil, err
+	}
+	d.Close()
+	files, err := tableFiles(backupPath)
+	if err != nil {
+		return nil, err
+	}
+	rw, report, err := copyTableFiles(files, backupPath, destDir)
+	if err != nil {
+		return nil, err
+	}
+	return report, rw.Commit()
+}
+
+// tableFiles returns the files of the table at path, the table itself first
+func tableFiles(path string) ([]string, error) {
+	path = filepath.Clean(path)
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	for _, info := range infos {
+		name := filepath.Join(filepath.Dir(path), info.Name())
+		if info.IsDir() || name == path || !sameTable(name, path) {
+			continue
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// sameTable returns if name is a file of the table at path
+func sameTable(name, path string) bool {
+	ext := filepath.Ext(name)
+	return tableExtensions[strings.ToLower(ext)] &&
+		strings.EqualFold(strings.TrimSuffix(filepath.Base(name), ext), strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+}
+
+// tableState is the header of a table and the size and modification time of its files,
+// to find changes made while the files are copied
+type tableState struct {
+	header   []byte
+	sizes    []int64
+	modTimes []time.Time
+}
+
+// readTableState returns the state of the table with the files
+func readTableState(files []string) (*tableState, error) {
+	f, err := os.Open(files[0])
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	state := &tableState{header: make([]byte, 32)}
+	if _, err := io.ReadFull(f, state.header); err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		state.sizes = append(state.sizes, info.Size())
+		state.modTimes = append(state.modTimes, info.ModTime())
+	}
+	return state, nil
+}
+
+// equal returns if nothing was changed between two states
+func (s *tableState) equal(other *tableState) bool {
+	if !bytes.Equal(s.header, other.header) {
+		return false
+	}
+	for i := range s.sizes {
+		if s.sizes[i] != other.sizes[i] || !s.modTimes[i].Equal(other.modTimes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// copyTableFiles copies the files of the table at path to destDir with a Rewrite, which also removes the files
+// of the table in destDir that are not copied. The Rewrite is not committed yet.
+func copyTableFiles(files []string, path, destDir string) (*Rewrite, *BackupReport, error) {
+	src, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err := os.Stat(destDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if os.SameFile(src, dst) {
+		return nil, nil, errors.New("the table can not be copied to its own directory")
+	}
+	existing, err := tableFiles(filepath.Join(destDir, filepath.Base(path)))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	rw := NewRewrite()
+	report := new(BackupReport)
+	copied := make(map[string]bool)
+	for _, name := range files {
+		dst := filepath.Join(destDir, filepath.Base(name))
+		if err := copyToRewrite(rw, name, dst); err != nil {
+			rw.Abort()
+			return nil, nil, err
+		}
+		report.Files = append(report.Files, dst)
+		copied[strings.ToLower(filepath.Base(name))] = true
+	}
+	for _, name := range existing {
+		if !copied[strings.ToLower(filepath.Base(name))] {
+			rw.Remove(name)
+		}
+	}
+
+	h, err := readHeaderFile(files[0])
+	if err != nil {
+		rw.Abort()
+		return nil, nil, err
+	}
+	report.Records = h.NumRec
+	return rw, report, nil
+}
+
+// copyToRewrite copies the file src to a new file of the Rewrite which replaces dst
+func copyToRewrite(rw *Rewrite, src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := rw.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// readHeaderFile reads the DBF header of the file filename
+func readHeaderFile(filename string) (*DBFHeader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readHeaderAt(f)
+}
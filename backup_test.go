@@ -0,0 +1,99 @@
+package dbf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupTable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfbackup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	srcDir, backupDir := filepath.Join(dir, "data"), filepath.Join(dir, "backup")
+	for _, d := range []string{srcDir, backupDir} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"TEST.DBF", "TEST.FPT"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(srcDir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// an index and a file of another table
+	for _, name := range []string{"test.cdx", "TESTS.DBF"} {
+		if err := ioutil.WriteFile(filepath.Join(srcDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	table := filepath.Join(srcDir, "TEST.DBF")
+
+	report, err := BackupTable(table, backupDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 3 || report.Records != 4 {
+		t.Errorf("Want 3 files with 4 records, have %v with %d records", report.Files, report.Records)
+	}
+	sameContents(t, filepath.Join(srcDir, "TEST.FPT"), filepath.Join(backupDir, "TEST.FPT"))
+	sameContents(t, filepath.Join(srcDir, "test.cdx"), filepath.Join(backupDir, "test.cdx"))
+
+	// the index was removed from the table, so it is removed from the backup
+	if err := os.Remove(filepath.Join(srcDir, "test.cdx")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BackupTableLocked(table, backupDir, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, "test.cdx")); !os.IsNotExist(err) {
+		t.Errorf("Want the index removed from the backup, have %v", err)
+	}
+	if _, err := BackupTable(table, srcDir); err == nil {
+		t.Error("Want an error for a backup in the directory of the table")
+	}
+
+	// restore the backup over a damaged table
+	if err := ioutil.WriteFile(filepath.Join(srcDir, "TEST.FPT"), []byte("damaged"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreTable(filepath.Join(backupDir, "TEST.DBF"), srcDir); err != nil {
+		t.Fatal(err)
+	}
+	sameContents(t, filepath.Join("testdata", "TEST.FPT"), filepath.Join(srcDir, "TEST.FPT"))
+	sameContents(t, filepath.Join("testdata", "TEST.DBF"), table)
+
+	// a backup which can not be opened is not restored
+	if err := ioutil.WriteFile(filepath.Join(backupDir, "TEST.DBF"), []byte("damaged"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreTable(filepath.Join(backupDir, "TEST.DBF"), srcDir); err == nil {
+		t.Error("Want an error for a damaged backup")
+	}
+	sameContents(t, filepath.Join("testdata", "TEST.DBF"), table)
+}
+
+// sameContents checks that the files a and b have the same contents
+func sameContents(t *testing.T, a, b string) {
+	t.Helper()
+	dataA, err := ioutil.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataB, err := ioutil.ReadFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dataA, dataB) {
+		t.Errorf("Want %s and %s to be equal", a, b)
+	}
+}
@@ -1,11 +1,27 @@
 package dbf
 
 import (
+	"fmt"
+	"math"
 	"strings"
 	"time"
 )
 
 // This file contains some helper casting functions for the interface values returned from the field methods.
+// The To functions return the zero value when the value has another type, the ToE variants return a *CastError.
+
+// CastError is returned by the ToE functions when a value can not be converted
+type CastError struct {
+	Value interface{} // the value which could not be converted
+	Type  string      // the requested type, like "int64"
+}
+
+func (e *CastError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("cannot convert nil to %s", e.Type)
+	}
+	return fmt.Sprintf("cannot convert %T value %v to %s", e.Value, e.Value, e.Type)
+}
 
 // ToString always returns a string
 func ToString(in interface{}) string {
@@ -54,3 +70,51 @@ func ToBool(in interface{}) bool {
 	}
 	return false
 }
+
+// ToInt64E returns an int64 from an int64, int32 (I fields) or a float64 without fraction,
+// other values return a *CastError
+func ToInt64E(in interface{}) (int64, error) {
+	switch v := in.(type) {
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return int64(v), nil
+		}
+	}
+	return 0, &CastError{Value: in, Type: "int64"}
+}
+
+// ToFloat64E returns a float64 from a float64, int64 or int32, other values and NaN return a *CastError
+func ToFloat64E(in interface{}) (float64, error) {
+	switch v := in.(type) {
+	case float64:
+		if !math.IsNaN(v) {
+			return v, nil
+		}
+	case int64:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	}
+	return 0, &CastError{Value: in, Type: "float64"}
+}
+
+// ToTimeE returns a time.Time, other values return a *CastError.
+// Empty D and T fields are returned as the zero time without an error.
+func ToTimeE(in interface{}) (time.Time, error) {
+	if t, ok := in.(time.Time); ok {
+		return t, nil
+	}
+	return time.Time{}, &CastError{Value: in, Type: "time.Time"}
+}
+
+// ToBoolE returns a boolean, other values return a *CastError
+func ToBoolE(in interface{}) (bool, error) {
+	if b, ok := in.(bool); ok {
+		return b, nil
+	}
+	return false, &CastError{Value: in, Type: "bool"}
+}
@@ -1,6 +1,7 @@
 package dbf
 
 import (
+	"math"
 	"testing"
 	"time"
 )
@@ -59,3 +60,48 @@ func TestToBool(t *testing.T) {
 		t.Error("Want false")
 	}
 }
+
+func TestToE(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		conv func(interface{}) (interface{}, error)
+		in   interface{}
+		want interface{}
+	}{
+		{toInt64E, int64(12), int64(12)},
+		{toInt64E, int32(-7), int64(-7)},
+		{toInt64E, float64(3), int64(3)},
+		{toInt64E, 3.5, nil},
+		{toInt64E, math.NaN(), nil},
+		{toInt64E, math.Inf(1), nil},
+		{toInt64E, "12", nil},
+		{toInt64E, nil, nil},
+		{toFloat64E, 1.25, 1.25},
+		{toFloat64E, int64(2), float64(2)},
+		{toFloat64E, int32(2), float64(2)},
+		{toFloat64E, math.NaN(), nil},
+		{toFloat64E, "***.**", nil},
+		{toTimeE, now, now},
+		{toTimeE, time.Time{}, time.Time{}},
+		{toTimeE, "2020-01-01", nil},
+		{toBoolE, true, true},
+		{toBoolE, "T", nil},
+	}
+	for _, test := range tests {
+		have, err := test.conv(test.in)
+		if test.want == nil {
+			if _, ok := err.(*CastError); !ok {
+				t.Errorf("%#v: want *CastError, have %v %v", test.in, have, err)
+			}
+			continue
+		}
+		if err != nil || have != test.want {
+			t.Errorf("%#v: want %v, have %v %v", test.in, test.want, have, err)
+		}
+	}
+}
+
+func toInt64E(in interface{}) (interface{}, error)   { return ToInt64E(in) }
+func toFloat64E(in interface{}) (interface{}, error) { return ToFloat64E(in) }
+func toTimeE(in interface{}) (interface{}, error)    { return ToTimeE(in) }
+func toBoolE(in interface{}) (interface{}, error)    { return ToBoolE(in) }
@@ -0,0 +1,206 @@
+package dbf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChangeType is the type of a Change
+type ChangeType int
+
+const (
+	// ChangeInsert is a record with a new key
+	ChangeInsert ChangeType = iota
+	// ChangeUpdate is a record with a known key of which the contents changed
+	ChangeUpdate
+	// ChangeDelete is a key which no longer exists, or of which the record was deleted
+	ChangeDelete
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeInsert:
+		return "insert"
+	case ChangeUpdate:
+		return "update"
+	case ChangeDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("ChangeType(%d)", int(t))
+}
+
+// Change is a change of one record found by CDC
+type Change struct {
+	Type   ChangeType
+	Key    string  // Key values, trimmed and joined with a tab
+	RecNo  uint32  // Record number, for deletes the last known record number
+	Record *Record // The new record, nil for deletes
+}
+
+// CDCOptions configures a CDC
+type CDCOptions struct {
+	// KeyFields are the fields which uniquely identify a record (required)
+	KeyFields []string
+
+	// Interval is the time between polls in Run, 0 uses 10 seconds
+	Interval time.Duration
+}
+
+// CDC (change data capture) detects inserted, updated and deleted records by comparing a snapshot of the key and
+// a hash of the contents (including memos) of every record between polls.
+// Only the keys and hashes are kept in memory. Deleted records are treated as not existing.
+// When multiple records have the same key, the last record is used.
+type CDC struct {
+	filename string
+	dec      Decoder
+	opts     CDCOptions
+
+	snapshot map[string]cdcEntry
+}
+
+type cdcEntry struct {
+	recno uint32
+	hash  [sha256.Size]byte
+}
+
+// NewCDC returns a CDC for the table in filename, which is opened for every poll
+func NewCDC(filename string, dec Decoder, opts CDCOptions) (*CDC, error) {
+	if len(opts.KeyFields) == 0 {
+		return nil, errors.New("at least one key field is required")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	return &CDC{filename: filename, dec: dec, opts: opts}, nil
+}
+
+// Poll reads the table and returns the changes since the previous poll.
+// The first poll returns all records as inserts, so it can be used for the initial load.
+func (c *CDC) Poll() ([]Change, error) {
+	dbf, err := OpenFile(c.filename, c.dec)
+	if err != nil {
+		return nil, err
+	}
+	defer dbf.Close()
+
+	keyFields := make([]int, len(c.opts.KeyFields))
+	for i, name := range c.opts.KeyFields {
+		keyFields[i] = dbf.FieldPos(name)
+		if keyFields[i] < 0 {
+			return nil, fmt.Errorf("key field %s not found", name)
+		}
+	}
+
+	var changes []Change
+	snapshot := make(map[string]cdcEntry, len(c.snapshot))
+	format := new(CSVReader)
+	for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+		data, err := dbf.readRecord(recno)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", recno, err)
+		}
+		if data[0] == 0x2A {
+			continue
+		}
+		hash, err := dbf.contentHash(data)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", recno, err)
+		}
+		rec, err := dbf.bytesToRecord(data)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", recno, err)
+		}
+		keys := make([]string, len(keyFields))
+		for i, pos := range keyFields {
+			keys[i] = format.format(rec.data[pos], &dbf.fields[pos])
+		}
+		key := strings.Join(keys, "\t")
+
+		if prev, ok := snapshot[key]; ok {
+			// duplicate key in this poll, the last record wins
+			for i := range changes {
+				if changes[i].Key == key && changes[i].RecNo == prev.recno {
+					changes = append(changes[:i], changes[i+1:]...)
+					break
+				}
+			}
+		}
+		snapshot[key] = cdcEntry{recno: recno, hash: hash}
+		old, existed := c.snapshot[key]
+		switch {
+		case !existed:
+			changes = append(changes, Change{Type: ChangeInsert, Key: key, RecNo: recno, Record: rec})
+		case old.hash != hash:
+			changes = append(changes, Change{Type: ChangeUpdate, Key: key, RecNo: recno, Record: rec})
+		}
+	}
+	var deletes []Change
+	for key, old := range c.snapshot {
+		if _, ok := snapshot[key]; !ok {
+			deletes = append(deletes, Change{Type: ChangeDelete, Key: key, RecNo: old.recno})
+		}
+	}
+	sort.Slice(deletes, func(i, j int) bool {
+		return deletes[i].RecNo < deletes[j].RecNo
+	})
+	changes = append(changes, deletes...)
+	c.snapshot = snapshot
+	return changes, nil
+}
+
+// Run polls the table every interval until ctx is done and calls fn for every change.
+// Run stops with the error of a failed poll or the first error returned by fn.
+func (c *CDC) Run(ctx context.Context, fn func(Change) error) error {
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	for {
+		changes, err := c.Poll()
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if err := fn(change); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// contentHash returns a hash of the record without the deleted flag, with the memo pointers replaced by the
+// memo contents, so moving a memo to another block is not seen as a change
+func (dbf *DBF) contentHash(data []byte) ([sha256.Size]byte, error) {
+	h := sha256.New()
+	for i, f := range dbf.fields {
+		raw := dbf.layout[i].data(data)
+		if !f.isMemo() || dbf.fptr == nil {
+			h.Write(raw)
+			continue
+		}
+		var memo []byte
+		if block, _ := dbf.memoPointer(raw); block != 0 {
+			var err error
+			if memo, _, err = dbf.readFPT(raw); err != nil {
+				return [sha256.Size]byte{}, err
+			}
+		}
+		// length prefix so the memo content cannot be confused with the next field
+		var size [4]byte
+		binary.LittleEndian.PutUint32(size[:], uint32(len(memo)))
+		h.Write(size[:])
+		h.Write(memo)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
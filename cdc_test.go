@@ -0,0 +1,117 @@
+package dbf
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCDC(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfcdc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"TEST.DBF", "TEST.FPT"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filename := filepath.Join(dir, "TEST.DBF")
+
+	if _, err := NewCDC(filename, new(Win1250Decoder), CDCOptions{}); err == nil {
+		t.Error("Want an error without key fields")
+	}
+	cdc, err := NewCDC(filename, new(Win1250Decoder), CDCOptions{KeyFields: []string{"ID"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// initial load
+	changes, err := cdc.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("Want 3 inserts, have %d changes", len(changes))
+	}
+	for _, c := range changes {
+		if c.Type != ChangeInsert || c.Record == nil {
+			t.Errorf("Want insert with record, have %v %+v", c.Type, c)
+		}
+	}
+
+	// no changes
+	changes, err = cdc.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Want no changes, have %d", len(changes))
+	}
+
+	// update record 0, recall deleted record 1 and delete record 2
+	dbf, err := OpenFile(filename, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := dbf.Header()
+	name := int64(h.FirstRec) + int64(dbf.fields[dbf.FieldPos("COMP_NAME")].Pos)
+	dbf.Close()
+	f, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writes := map[int64][]byte{
+		name:                                  []byte("CHANGED"),
+		int64(h.FirstRec) + int64(h.RecLen):   {0x20},
+		int64(h.FirstRec) + 2*int64(h.RecLen): {0x2A},
+	}
+	for pos, data := range writes {
+		if _, err := f.WriteAt(data, pos); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+
+	changes, err = cdc.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		typ ChangeType
+		key string
+	}{
+		{ChangeUpdate, "1"},
+		{ChangeInsert, "2"},
+		{ChangeDelete, "3"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Want %d changes, have %d", len(want), len(changes))
+	}
+	for i, w := range want {
+		if changes[i].Type != w.typ || changes[i].Key != w.key {
+			t.Errorf("Change %d: want %s %s, have %s %s", i, w.typ, w.key, changes[i].Type, changes[i].Key)
+		}
+	}
+	if changes[2].Record != nil {
+		t.Error("Want no record for a delete")
+	}
+
+	// Run stops with the error of the callback
+	stop := errors.New("stop")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	cdc, _ = NewCDC(filename, new(Win1250Decoder), CDCOptions{KeyFields: []string{"ID", "COMP_NAME"}, Interval: time.Millisecond})
+	if err := cdc.Run(ctx, func(c Change) error { return stop }); err != stop {
+		t.Errorf("Want callback error, have %v", err)
+	}
+}
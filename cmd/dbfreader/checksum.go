@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// hashFuncs contains the supported checksum algorithms
+var hashFuncs = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// runChecksum prints checksums of a DBF file (and its memo file) and optionally of every record.
+// The output format of the file checksums is the same as sha256sum and friends.
+func runChecksum(args []string) error {
+	fs := flag.NewFlagSet("checksum", flag.ExitOnError)
+	perRecord := fs.Bool("per-record", false, "also print a checksum of every record")
+	algo := fs.String("algo", "sha256", "hash algorithm: sha256, sha1 or md5")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+	newHash, ok := hashFuncs[*algo]
+	if !ok {
+		return fmt.Errorf("unsupported hash algorithm %s", *algo)
+	}
+
+	d, err := openTable(files[0], *encoding)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	// Table level checksums over the complete files
+	sum, err := fileChecksum(files[0], newHash)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s  %s\n", sum, files[0])
+	if d.Header().HasMemo() {
+		fptfile, err := findMemoFile(files[0])
+		if err != nil {
+			return err
+		}
+		sum, err := fileChecksum(fptfile, newHash)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s  %s\n", sum, fptfile)
+	}
+
+	if !*perRecord {
+		return nil
+	}
+
+	// Record level checksums over the raw record bytes (including the deleted flag)
+	f, err := os.Open(files[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := d.Header()
+	buf := make([]byte, header.RecLen)
+	h := newHash()
+	for i := uint32(0); i < header.NumRec; i++ {
+		pos := int64(header.FirstRec) + int64(i)*int64(header.RecLen)
+		if _, err := f.ReadAt(buf, pos); err != nil {
+			return fmt.Errorf("record %d: %v", i, err)
+		}
+		h.Reset()
+		h.Write(buf)
+		fmt.Printf("%d\t%s\n", i, hex.EncodeToString(h.Sum(nil)))
+	}
+	return nil
+}
+
+// fileChecksum returns the hex encoded checksum of the complete file
+func fileChecksum(filename string, newHash func() hash.Hash) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// command is a dbfreader subcommand, invoked as: dbfreader <name> [ARGS] [OPTIONS]
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+// commands contains all subcommands, if the first argument is not a command name
+// it is treated as a DBF file for the default display/export mode
+var commands = []*command{
+	{name: "memos", usage: "memos FILE [--field NOTES] [--key CUSTNO] --out-dir DIR", run: runMemos},
+	{name: "debug", usage: "debug FILE [--records N]", run: runDebug},
+	{name: "serve", usage: "serve DIR [--port 8080] [--host ADDR] [--metrics] [--cache-size 100]", run: runServe},
+	{name: "checksum", usage: "checksum FILE [--per-record] [--algo sha256]", run: runChecksum},
+	{name: "dedupe", usage: "dedupe FILE --key CUSTNO [--keep first|last] --csv out.csv [--locale de]", run: runDedupe},
+	{name: "materialize", usage: "materialize DIR cache.db [--force] [--sqlite3 PATH] (needs the sqlite3 shell)", run: runMaterialize},
+	{name: "merge", usage: "merge FILE1 FILE2 ... --out combined.csv [--source-column SOURCE] [--workers N] [--locale de]", run: runMerge},
+	{name: "schema-diff", usage: "schema-diff OLD.DBF NEW.DBF [--json] [--ignore-order]", run: runSchemaDiff},
+	{name: "repair", usage: "repair FILE [--fix-count] [--strip-garbage] [--out fixed.dbf]", run: runRepair},
+	{name: "stats", usage: "stats FILE [--json] [--cache] [--top N] [--include-deleted]", run: runStats},
+	{name: "memo-audit", usage: "memo-audit FILE [--json]", run: runMemoAudit},
+	{name: "compact-memo", usage: "compact-memo FILE --out NEW.DBF [--block-size N]", run: runCompactMemo},
+	{name: "pack", usage: "pack FILE [--out NEW.DBF] [--no-backup] [--drop-index]", run: runPack},
+	{name: "import", usage: "import FILE.csv --schema schema.json --out NEW.DBF [--encoding win1250]", run: runImport},
+	{name: "template", usage: "template FILE --template letter.tmpl [--out FILE | --out-dir DIR --name NAME] [--html] [--locale de]", run: runTemplate},
+	{name: "gen-test", usage: "gen-test --schema schema.json --out TEST.DBF [--records N] [--seed N] [--deleted 0.1] [--corrupt truncate,memo-refs,numbers]", run: runGenTest},
+}
+
+// findCommand returns the subcommand with the given name, or nil
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// printCommandUsage prints the usage lines of all subcommands
+func printCommandUsage() {
+	fmt.Println("Commands:")
+	for _, cmd := range commands {
+		fmt.Printf("  %s\n", cmd.usage)
+	}
+}
+
+// parseFlags parses args with fs, allowing positional arguments and flags to be mixed
+// (the flag package stops at the first positional argument).
+// It returns the positional arguments in order.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// newDecoder returns the decoder for an encoding name as used on the command line
+func newDecoder(encoding string) (dbf.Decoder, error) {
+	switch strings.ToLower(encoding) {
+	case "big5":
+		return new(dbf.Big5Decoder), nil
+	case "utf8":
+		return new(dbf.UTF8Decoder), nil
+	case "win1250", "":
+		return new(dbf.Win1250Decoder), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+// newEncoder returns the encoder and code page mark for an encoding name as used on the command line
+func newEncoder(encoding string) (dbf.Encoder, byte, error) {
+	switch strings.ToLower(encoding) {
+	case "big5":
+		return new(dbf.Big5Encoder), 0x78, nil
+	case "utf8":
+		return new(dbf.UTF8Encoder), 0x00, nil
+	case "win1250", "":
+		return new(dbf.Win1250Encoder), 0xC8, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+// openTable opens a DBF file from disk using the named encoding
+func openTable(filename, encoding string) (*dbf.DBF, error) {
+	dec, err := newDecoder(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return dbf.OpenFile(filename, dec)
+}
+
+// exitOnError prints err and exits with status 1 if err is not nil
+func exitOnError(cmd *command, err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+	os.Exit(1)
+}
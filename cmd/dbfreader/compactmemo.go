@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runCompactMemo writes a copy of a table with a memo file which only contains the used memo blocks
+func runCompactMemo(args []string) error {
+	fs := flag.NewFlagSet("compact-memo", flag.ExitOnError)
+	out := fs.String("out", "", "table to write, the memo file is written next to it")
+	blockSize := fs.Uint("block-size", 0, "block size of the new memo file, 0 keeps the block size of the table")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+	if *out == "" {
+		return errors.New("--out is required")
+	}
+	if *blockSize > 0xFFFF {
+		return fmt.Errorf("invalid block size %d", *blockSize)
+	}
+
+	d, err := openTable(files[0], *encoding)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	before, err := d.AuditMemos()
+	if err != nil {
+		return err
+	}
+	report, err := dbf.CompactMemo(d, *out, uint16(*blockSize))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Compacted %s: %d records, memo file reduced from %d to %d blocks\n",
+		*out, report.Kept, before.NextFree, report.MemoBlocks)
+	return nil
+}
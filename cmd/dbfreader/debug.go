@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runDebug prints the raw structure of a DBF file without using the library's open functions,
+// so files which are rejected by OpenFile can still be inspected
+func runDebug(args []string) error {
+	fs := flag.NewFlagSet("debug", flag.ExitOnError)
+	numRecords := fs.Int("records", 1, "number of records to hexdump")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("File: %s (%d bytes)\n", files[0], stat.Size())
+
+	// Raw header
+	raw := make([]byte, 32)
+	if _, err := f.ReadAt(raw, 0); err != nil {
+		return fmt.Errorf("cannot read 32 byte header: %v", err)
+	}
+	fmt.Println("\nHeader bytes:")
+	fmt.Print(hex.Dump(raw))
+
+	header := new(dbf.DBFHeader)
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, header); err != nil {
+		return err
+	}
+	fmt.Println("\nHeader:")
+	fmt.Printf("  File version:  0x%02X (%s)\n", header.FileVersion, dbf.LookupFileVersion(header.FileVersion).Description)
+	fmt.Printf("  Modified:      %02d-%02d-%02d (YY-MM-DD)\n", header.ModYear, header.ModMonth, header.ModDay)
+	fmt.Printf("  Records:       %d\n", header.NumRec)
+	fmt.Printf("  First record:  %d\n", header.FirstRec)
+	fmt.Printf("  Record length: %d\n", header.RecLen)
+	fmt.Printf("  Table flags:   0x%02X%s\n", header.TableFlags, describeTableFlags(header.TableFlags))
+	fmt.Printf("  Code page:     0x%02X (%s)\n", header.CodePage, header.CodePageInfo())
+
+	// Field descriptors, read until the terminator, the first record or the end of the file
+	fmt.Println("\nField descriptors:")
+	fmt.Printf("  %-8s %-11s %-4s %-6s %-6s %-4s %-4s %-6s %-10s %-5s\n",
+		"Offset", "Name", "Type", "Pos", "Calc", "Len", "Dec", "Flags", "Next", "Step")
+	recLen := uint32(1) // deletion flag
+	offset := int64(32)
+	var datetimes []debugField // T fields, decoded below the hexdump of the records
+	terminated := false
+	for offset+32 <= stat.Size() {
+		// dbf.FieldHeader is one byte larger than a descriptor (Step is read as uint16)
+		// so read one byte from the next descriptor, this is the same as the library does
+		desc := make([]byte, binary.Size(dbf.FieldHeader{}))
+		if _, err := f.ReadAt(desc, offset); err != nil && err != io.EOF {
+			return err
+		}
+		if desc[0] == 0x0D {
+			terminated = true
+			break
+		}
+		field := new(dbf.FieldHeader)
+		if err := binary.Read(bytes.NewReader(desc), binary.LittleEndian, field); err != nil {
+			return err
+		}
+		warn := ""
+		if field.Pos != recLen {
+			warn = " <- displacement differs from calculated offset"
+		}
+		fmt.Printf("  %-8d %-11s %-4s %-6d %-6d %-4d %-4d 0x%02X%s %-10d %-5d%s\n",
+			offset, field.FieldName(), field.FieldType(), field.Pos, recLen, field.Len, field.Decimals,
+			field.Flags, describeFieldFlags(field.Flags), field.Next, field.Step, warn)
+		if field.Type == 'T' && field.Len == 8 {
+			datetimes = append(datetimes, debugField{name: field.FieldName(), offset: recLen})
+		}
+		recLen += uint32(field.Len)
+		offset += 32
+		if header.FirstRec > 0 && offset >= int64(header.FirstRec) {
+			break
+		}
+	}
+	if !terminated {
+		fmt.Println("  WARNING: no header terminator (0x0D) found")
+	} else {
+		fmt.Printf("  Terminator at offset %d\n", offset)
+		// Visual FoxPro tables have a 263 byte backlink area after the terminator
+		if header.FileVersion == 0x30 || header.FileVersion == 0x31 || header.FileVersion == 0x32 {
+			backlink := make([]byte, 263)
+			if _, err := f.ReadAt(backlink, offset+1); err == nil {
+				if link := string(bytes.TrimRight(backlink, "\x00")); link != "" {
+					fmt.Printf("  Database container backlink: %s\n", link)
+				}
+			}
+		}
+	}
+
+	// Consistency checks
+	fmt.Println("\nChecks:")
+	if recLen != uint32(header.RecLen) {
+		fmt.Printf("  Record length in header (%d) differs from sum of field lengths (%d)\n", header.RecLen, recLen)
+	} else {
+		fmt.Println("  Record length matches field lengths")
+	}
+	dataSize := stat.Size() - int64(header.FirstRec)
+	if header.RecLen > 0 && dataSize >= 0 {
+		actual := dataSize / int64(header.RecLen)
+		fmt.Printf("  Records according to file size: %d (header says %d), %d trailing bytes\n",
+			actual, header.NumRec, dataSize%int64(header.RecLen))
+	} else {
+		fmt.Println("  First record position is beyond the end of the file")
+	}
+	expectedEnd := int64(header.FirstRec) + int64(header.NumRec)*int64(header.RecLen)
+	eof := make([]byte, 1)
+	if _, err := f.ReadAt(eof, expectedEnd); err == nil && eof[0] == 0x1A {
+		fmt.Printf("  EOF marker (0x1A) found at offset %d\n", expectedEnd)
+	} else {
+		fmt.Printf("  No EOF marker (0x1A) at offset %d\n", expectedEnd)
+	}
+
+	// Memo file
+	if header.HasMemo() {
+		debugMemoFile(files[0])
+	}
+
+	// Record data
+	for i := 0; i < *numRecords && uint32(i) < header.NumRec; i++ {
+		rec := make([]byte, header.RecLen)
+		pos := int64(header.FirstRec) + int64(i)*int64(header.RecLen)
+		n, err := f.ReadAt(rec, pos)
+		fmt.Printf("\nRecord %d at offset %d:\n", i, pos)
+		fmt.Print(hex.Dump(rec[:n]))
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n < len(rec) {
+			fmt.Printf("  WARNING: record is truncated, read %d of %d bytes\n", n, len(rec))
+			break
+		}
+		for _, f := range datetimes {
+			if int(f.offset)+8 > len(rec) {
+				continue
+			}
+			day := binary.LittleEndian.Uint32(rec[f.offset:])
+			msec := binary.LittleEndian.Uint32(rec[f.offset+4:])
+			desc := "empty"
+			if t := dbf.JulianToTime(day, msec); !t.IsZero() {
+				desc = t.Format("2006-01-02 15:04:05.000")
+			} else if day != 0 || msec != 0 {
+				desc = "INVALID, read as empty"
+			}
+			if msec >= 86400000 {
+				desc += ", WARNING: more than a day of milliseconds"
+			}
+			fmt.Printf("  %-10s T: Julian day %d, %d ms: %s\n", f.name, day, msec, desc)
+		}
+	}
+	return nil
+}
+
+// debugField is a field whose value is decoded by debug
+type debugField struct {
+	name   string
+	offset uint32 // calculated offset in the record
+}
+
+// debugMemoFile prints the header of the memo file belonging to dbffile
+func debugMemoFile(dbffile string) {
+	fmt.Println("\nMemo file:")
+	fptfile, err := findMemoFile(dbffile)
+	if err != nil {
+		fmt.Printf("  %v\n", err)
+		return
+	}
+	f, err := os.Open(fptfile)
+	if err != nil {
+		fmt.Printf("  %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	raw := make([]byte, 8)
+	if _, err := f.ReadAt(raw, 0); err != nil {
+		fmt.Printf("  cannot read memo header: %v\n", err)
+		return
+	}
+	header := new(dbf.FPTHeader)
+	if err := binary.Read(bytes.NewReader(raw), binary.BigEndian, header); err != nil {
+		fmt.Printf("  cannot parse memo header: %v\n", err)
+		return
+	}
+	fmt.Printf("  File:            %s\n", fptfile)
+	fmt.Print(indent(hex.Dump(raw), "  "))
+	fmt.Printf("  Next free block: %d\n", header.NextFree)
+	fmt.Printf("  Block size:      %d\n", header.BlockSize)
+	if stat, err := f.Stat(); err == nil {
+		fmt.Printf("  File size:       %d\n", stat.Size())
+		if header.BlockSize > 0 && int64(header.NextFree)*int64(header.BlockSize) > stat.Size() {
+			fmt.Println("  WARNING: next free block lies beyond the end of the file")
+		}
+	}
+}
+
+// findMemoFile returns the path of the memo file of dbffile, trying .fpt and .FPT, and .smt for SIx tables
+func findMemoFile(dbffile string) (string, error) {
+	base := strings.TrimSuffix(dbffile, filepath.Ext(dbffile))
+	for _, ext := range []string{".fpt", ".FPT", ".Fpt", ".smt", ".SMT"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext, nil
+		}
+	}
+	return "", fmt.Errorf("memo file %s.fpt not found", base)
+}
+
+// describeTableFlags returns a readable description of the table flags byte
+func describeTableFlags(flags byte) string {
+	var desc []string
+	if flags&0x01 != 0 {
+		desc = append(desc, "has CDX")
+	}
+	if flags&0x02 != 0 {
+		desc = append(desc, "has memo")
+	}
+	if flags&0x04 != 0 {
+		desc = append(desc, "is database")
+	}
+	if len(desc) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(desc, ", ") + ")"
+}
+
+// describeFieldFlags returns a short code for the field flags byte
+func describeFieldFlags(flags byte) string {
+	desc := ""
+	if flags&0x01 != 0 {
+		desc += "S" // system column
+	}
+	if flags&0x02 != 0 {
+		desc += "N" // nullable
+	}
+	if flags&0x04 != 0 {
+		desc += "B" // binary
+	}
+	if flags&0x0C == 0x0C {
+		desc += "A" // autoincrement
+	}
+	return fmt.Sprintf("%-3s", desc)
+}
+
+// indent prefixes every line in s with prefix
+func indent(s, prefix string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
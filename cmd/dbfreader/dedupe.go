@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runDedupe exports a table to CSV keeping only one record per key
+func runDedupe(args []string) error {
+	fs := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	keyList := fs.String("key", "", "comma separated key field(s)")
+	keep := fs.String("keep", "first", "which record to keep per key: first or last")
+	csvOutput := fs.String("csv", "", "CSV file to write")
+	ignoreCase := fs.Bool("ignore-case", false, "compare character keys case-insensitive")
+	mapFile := fs.String("map", "", "column mapping file")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+	locale := fs.String("locale", "", "render logicals and numbers and choose the CSV delimiter for a locale, like de")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+	if *keyList == "" {
+		return errors.New("--key is required")
+	}
+	if *csvOutput == "" {
+		return errors.New("--csv is required")
+	}
+	if *keep != "first" && *keep != "last" {
+		return fmt.Errorf("invalid --keep %q, use first or last", *keep)
+	}
+	format, err := parseFormatOptions(*locale, "", "", "", "", "")
+	if err != nil {
+		return err
+	}
+
+	d, err := openTable(files[0], *encoding)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	var keyFields []int
+	for _, name := range strings.Split(*keyList, ",") {
+		pos := d.FieldPos(strings.ToUpper(strings.TrimSpace(name)))
+		if pos < 0 {
+			return fmt.Errorf("key field %s does not exist in table", name)
+		}
+		keyFields = append(keyFields, pos)
+	}
+	keyOf := func(rec *dbf.Record) string {
+		values := rec.FieldSlice()
+		parts := make([]string, len(keyFields))
+		for i, pos := range keyFields {
+			parts[i] = formatValueForCSV(values[pos], d.Fields()[pos])
+			if *ignoreCase {
+				parts[i] = strings.ToUpper(parts[i])
+			}
+		}
+		return strings.Join(parts, "\x00")
+	}
+
+	// First pass: determine which record to keep for every key
+	keepRec := make(map[string]uint32)
+	total := 0
+	for i := uint32(0); i < d.NumRecords(); i++ {
+		rec, err := d.RecordAt(i)
+		if err != nil {
+			return fmt.Errorf("record %d: %v", i, err)
+		}
+		if rec.Deleted {
+			continue
+		}
+		total++
+		key := keyOf(rec)
+		if _, seen := keepRec[key]; !seen || *keep == "last" {
+			keepRec[key] = i
+		}
+	}
+
+	var mapping *columnMapping
+	if *mapFile != "" {
+		mapping, err = loadColumnMapping(*mapFile)
+		if err != nil {
+			return err
+		}
+	}
+	columns, err := mapping.columns(d)
+	if err != nil {
+		return err
+	}
+
+	// Second pass: export the kept records in table order
+	err = exportToCSV(d, *csvOutput, exportOptions{
+		columns: columns,
+		format:  format,
+		comma:   csvComma(*locale, format),
+		filter: func(recno uint32, rec *dbf.Record) bool {
+			return keepRec[keyOf(rec)] == recno
+		},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d unique records to %s, %d duplicates removed\n", len(keepRec), *csvOutput, total-len(keepRec))
+	return nil
+}
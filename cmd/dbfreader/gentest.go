@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runGenTest creates a DBF (and FPT) file with random values for the fields of a schema file like the one of import
+func runGenTest(args []string) error {
+	fs := flag.NewFlagSet("gen-test", flag.ExitOnError)
+	schemaFile := fs.String("schema", "", "JSON file with the field definitions, like for import (required)")
+	out := fs.String("out", "", "DBF file to create (required)")
+	records := fs.Int("records", 100, "number of records")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+	seed := fs.Int64("seed", 1, "seed of the random values, the same seed gives the same file")
+	deleted := fs.Float64("deleted", 0, "fraction of the records marked as deleted, 0 to 1")
+	corrupt := fs.String("corrupt", "", "comma separated damage to the file: truncate, memo-refs, numbers")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 0 {
+		return errors.New("gen-test takes no files, use --schema and --out")
+	}
+	if *schemaFile == "" || *out == "" {
+		return errors.New("--schema and --out are required")
+	}
+	corruption, err := parseCorruption(*corrupt)
+	if err != nil {
+		return err
+	}
+	enc, codePage, err := newEncoder(*encoding)
+	if err != nil {
+		return err
+	}
+	schema, err := loadImportSchema(*schemaFile)
+	if err != nil {
+		return err
+	}
+	fields, err := schema.fieldHeaders()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(*out); err == nil {
+		return fmt.Errorf("%s already exists", *out)
+	}
+	dbffile, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer dbffile.Close()
+	var fptout io.Writer
+	for _, f := range fields {
+		if isMemoType(string(f.Type)) {
+			fptfile, err := os.Create(strings.TrimSuffix(*out, filepath.Ext(*out)) + memoExtension(*out))
+			if err != nil {
+				return err
+			}
+			defer fptfile.Close()
+			fptout = fptfile
+			break
+		}
+	}
+
+	opts := dbf.GenerateOptions{
+		Fields:   fields,
+		Records:  *records,
+		CodePage: codePage,
+		Seed:     *seed,
+		Deleted:  *deleted,
+		Corrupt:  corruption,
+	}
+	if err := dbf.Generate(dbffile, fptout, enc, opts); err != nil {
+		return err
+	}
+	fmt.Printf("%d records generated in %s\n", *records, *out)
+	return nil
+}
+
+// parseCorruption parses the comma separated corruption modes of --corrupt
+func parseCorruption(s string) (dbf.Corruption, error) {
+	var c dbf.Corruption
+	for _, mode := range strings.Split(s, ",") {
+		switch strings.TrimSpace(mode) {
+		case "":
+		case "truncate":
+			c |= dbf.CorruptTruncate
+		case "memo-refs":
+			c |= dbf.CorruptMemoRefs
+		case "numbers":
+			c |= dbf.CorruptNumbers
+		default:
+			return 0, fmt.Errorf("unknown corruption %q, use truncate, memo-refs or numbers", mode)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// importSchema is the JSON schema file describing the table created by import
+type importSchema struct {
+	Fields []importField `json:"fields"`
+}
+
+// importField is one field in the schema file.
+// Column is the CSV header of the input column, it defaults to the field name.
+type importField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Len      uint8  `json:"len"`
+	Decimals uint8  `json:"dec"`
+	Column   string `json:"column"`
+}
+
+// runImport creates a new DBF (and FPT) file from a CSV file, the inverse of the CSV export
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	schemaFile := fs.String("schema", "", "JSON file with the field definitions (required)")
+	out := fs.String("out", "", "DBF file to create (required)")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+	delimiter := fs.String("delimiter", ",", "CSV field delimiter")
+	dateLayout := fs.String("date-layout", "", "Go time layout of the dates of D fields, like 01/02/2006")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one CSV file")
+	}
+	if *schemaFile == "" || *out == "" {
+		return errors.New("--schema and --out are required")
+	}
+	delim, size := utf8.DecodeRuneInString(*delimiter)
+	if size != len(*delimiter) || delim == utf8.RuneError {
+		return fmt.Errorf("invalid delimiter %q", *delimiter)
+	}
+	enc, codePage, err := newEncoder(*encoding)
+	if err != nil {
+		return err
+	}
+	schema, err := loadImportSchema(*schemaFile)
+	if err != nil {
+		return err
+	}
+
+	fields, err := schema.fieldHeaders()
+	if err != nil {
+		return err
+	}
+	opts := dbf.CSVImportOptions{Comma: delim}
+	for _, f := range schema.Fields {
+		opts.Columns = append(opts.Columns, f.Column)
+	}
+	if *dateLayout != "" {
+		opts.DateLayouts = []string{*dateLayout}
+	}
+
+	in, err := os.Open(files[0])
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if _, err := os.Stat(*out); err == nil {
+		return fmt.Errorf("%s already exists", *out)
+	}
+	w, err := dbf.Create(*out, fields, enc)
+	if err != nil {
+		return err
+	}
+	w.Header().CodePage = codePage
+	n, err := dbf.ImportCSV(in, w, opts)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// no half-imported table is left behind
+		os.Remove(*out)
+		if w.Header().HasMemo() {
+			os.Remove(strings.TrimSuffix(*out, filepath.Ext(*out)) + memoExtension(*out))
+		}
+		return err
+	}
+	fmt.Printf("%d records written to %s\n", n, *out)
+	return nil
+}
+
+// loadImportSchema reads the JSON schema file
+func loadImportSchema(filename string) (*importSchema, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	schema := new(importSchema)
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if len(schema.Fields) == 0 {
+		return nil, fmt.Errorf("%s: no fields defined", filename)
+	}
+	return schema, nil
+}
+
+// fieldHeaders converts the schema to field headers for the writer
+func (s *importSchema) fieldHeaders() ([]dbf.FieldHeader, error) {
+	fields := make([]dbf.FieldHeader, len(s.Fields))
+	for i, f := range s.Fields {
+		if len(f.Type) != 1 {
+			return nil, fmt.Errorf("field %s: invalid type %q", f.Name, f.Type)
+		}
+		if len(f.Name) > 10 {
+			return nil, fmt.Errorf("field name %s is longer than 10 characters", f.Name)
+		}
+		copy(fields[i].Name[:], strings.ToUpper(f.Name))
+		fields[i].Type = strings.ToUpper(f.Type)[0]
+		fields[i].Len = f.Len
+		fields[i].Decimals = f.Decimals
+	}
+	return fields, nil
+}
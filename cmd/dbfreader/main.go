@@ -2,17 +2,28 @@ package main
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+	_ "time/tzdata" // time zones for --source-tz and --target-tz on systems without a time zone database
 
 	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
 )
 
 func main() {
+	// Run a subcommand if the first argument is a command name
+	if len(os.Args) > 1 {
+		if cmd := findCommand(os.Args[1]); cmd != nil {
+			exitOnError(cmd, cmd.run(os.Args[2:]))
+			return
+		}
+	}
+
 	// Check if DBF file is provided as argument
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: go run main.go <DBF_FILE> [ENCODING] [OPTIONS]")
@@ -24,13 +35,42 @@ func main() {
 		fmt.Println("  --csv          Export to CSV file (same name as DBF)")
 		fmt.Println("  --csv=file.csv Export to specified CSV file")
 		fmt.Println("  --no-display   Skip console display (useful with --csv)")
+		fmt.Println("  --map=file     Rename, reorder or drop exported columns using a mapping file")
+		fmt.Println("  --mask=F1,F2   Anonymize the listed fields in the export and display")
+		fmt.Println("  --mask-mode=m  Masking mode: redact (default), hash or fake")
+		fmt.Println("  --mask-salt=s  Secret used for the hash and fake masking modes, required for these modes")
+		fmt.Println("  --workers=N    Number of CSV conversion workers (default: one per CPU)")
+		fmt.Println("  --locale=l     Logical words, separators and CSV delimiter of a locale, like de or fr-CH")
+		fmt.Println("  --bool=T/F     Text of logical values in the export, like 1/0 or TRUE/FALSE")
+		fmt.Println("  --decimal-sep=s Decimal separator of numbers in the export (default: .)")
+		fmt.Println("  --group-sep=s  Digit grouping separator of numbers in the export (default: none)")
+		fmt.Println("  --precision=F:N Decimals of a numeric field in the export, comma separated for more fields")
+		fmt.Println("  --rounding=m   Rounding of numbers to their decimals: binary (default), half-up or half-even")
+		fmt.Println("  --source-tz=z  Time zone of the stored datetimes, like Europe/Berlin (default: none, kept as stored)")
+		fmt.Println("  --target-tz=z  Time zone to convert the datetimes to, like UTC (default: the source zone)")
+		fmt.Println("  --invalid-numbers=p  Value of numeric fields with overflow or garbage: error (default), zero, nan or raw")
+		printCommandUsage()
 		os.Exit(1)
 	}
 
 	dbfFile := os.Args[1]
 	encoding := "big5" // default
 	csvOutput := ""
+	mapFile := ""
+	maskFields := ""
+	maskMode := ""
+	maskSalt := ""
 	noDisplay := false
+	workers := 0
+	locale := ""
+	boolStyle := ""
+	decimalSep := ""
+	groupSep := ""
+	precision := ""
+	rounding := ""
+	invalidNumbers := ""
+	sourceTZ := ""
+	targetTZ := ""
 
 	// Parse arguments
 	for i := 2; i < len(os.Args); i++ {
@@ -42,6 +82,50 @@ func main() {
 			} else if strings.HasPrefix(arg, "--csv=") {
 				csvOutput = strings.TrimPrefix(arg, "--csv=")
 			}
+		} else if strings.HasPrefix(arg, "--map=") {
+			mapFile = strings.TrimPrefix(arg, "--map=")
+		} else if arg == "--map" && i+1 < len(os.Args) {
+			i++
+			mapFile = os.Args[i]
+		} else if strings.HasPrefix(arg, "--mask=") {
+			maskFields = strings.TrimPrefix(arg, "--mask=")
+		} else if arg == "--mask" && i+1 < len(os.Args) {
+			i++
+			maskFields = os.Args[i]
+		} else if strings.HasPrefix(arg, "--mask-mode=") {
+			maskMode = strings.TrimPrefix(arg, "--mask-mode=")
+		} else if arg == "--mask-mode" && i+1 < len(os.Args) {
+			i++
+			maskMode = os.Args[i]
+		} else if strings.HasPrefix(arg, "--mask-salt=") {
+			maskSalt = strings.TrimPrefix(arg, "--mask-salt=")
+		} else if arg == "--mask-salt" && i+1 < len(os.Args) {
+			i++
+			maskSalt = os.Args[i]
+		} else if strings.HasPrefix(arg, "--workers=") {
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--workers="))
+			if err != nil || n < 1 {
+				log.Fatalf("Invalid number of workers: %s", arg)
+			}
+			workers = n
+		} else if strings.HasPrefix(arg, "--locale=") {
+			locale = strings.TrimPrefix(arg, "--locale=")
+		} else if strings.HasPrefix(arg, "--bool=") {
+			boolStyle = strings.TrimPrefix(arg, "--bool=")
+		} else if strings.HasPrefix(arg, "--decimal-sep=") {
+			decimalSep = strings.TrimPrefix(arg, "--decimal-sep=")
+		} else if strings.HasPrefix(arg, "--group-sep=") {
+			groupSep = strings.TrimPrefix(arg, "--group-sep=")
+		} else if strings.HasPrefix(arg, "--precision=") {
+			precision = strings.TrimPrefix(arg, "--precision=")
+		} else if strings.HasPrefix(arg, "--rounding=") {
+			rounding = strings.TrimPrefix(arg, "--rounding=")
+		} else if strings.HasPrefix(arg, "--source-tz=") {
+			sourceTZ = strings.TrimPrefix(arg, "--source-tz=")
+		} else if strings.HasPrefix(arg, "--target-tz=") {
+			targetTZ = strings.TrimPrefix(arg, "--target-tz=")
+		} else if strings.HasPrefix(arg, "--invalid-numbers=") {
+			invalidNumbers = strings.TrimPrefix(arg, "--invalid-numbers=")
 		} else if arg == "--no-display" {
 			noDisplay = true
 		} else if i == 2 && !strings.HasPrefix(arg, "--") {
@@ -78,6 +162,19 @@ func main() {
 	}
 	defer d.Close()
 
+	if invalidNumbers != "" {
+		policy, err := parseInvalidNumberPolicy(invalidNumbers)
+		if err != nil {
+			log.Fatal(err)
+		}
+		d.SetInvalidNumberPolicy(policy)
+	}
+	if sourceTZ != "" || targetTZ != "" {
+		if err := setTimeZone(d, sourceTZ, targetTZ); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// Print basic file information
 	if !noDisplay {
 		fmt.Printf("Total records: %d\n", d.NumRecords())
@@ -85,9 +182,41 @@ func main() {
 		fmt.Println("Field names:", d.FieldNames())
 	}
 
+	// Load the column mapping if provided
+	var mapping *columnMapping
+	if mapFile != "" {
+		mapping, err = loadColumnMapping(mapFile)
+		if err != nil {
+			log.Fatalf("Error reading mapping file: %v", err)
+		}
+	}
+
+	// Prepare masking of sensitive fields
+	mask, err := newMasker(d, maskFields, maskMode, maskSalt)
+	if err != nil {
+		log.Fatalf("Error preparing masking: %v", err)
+	}
+
+	// Rendering of logicals and numbers in the export
+	format, err := parseFormatOptions(locale, boolStyle, decimalSep, groupSep, precision, rounding)
+	if err != nil {
+		log.Fatalf("Error in format options: %v", err)
+	}
+
 	// Export to CSV if requested
 	if csvOutput != "" {
-		err = exportToCSV(d, csvOutput, noDisplay)
+		columns, err := mapping.columns(d)
+		if err != nil {
+			log.Fatalf("Error applying mapping file: %v", err)
+		}
+		err = exportToCSV(d, csvOutput, exportOptions{
+			columns: columns,
+			mask:    mask,
+			format:  format,
+			comma:   csvComma(locale, format),
+			silent:  noDisplay,
+			workers: workers,
+		})
 		if err != nil {
 			log.Fatalf("Error exporting to CSV: %v", err)
 		}
@@ -129,29 +258,12 @@ func main() {
 
 			// Print record with field names and values
 			fmt.Printf("Record %d:\n", i)
-			fieldNames := d.FieldNames()
-			fieldSlice := record.FieldSlice()
-
-			for j, value := range fieldSlice {
-				if j < len(fieldNames) {
-					// Use cast helpers for cleaner display
-					switch d.Fields()[j].FieldType() {
-					case "C": // Character
-						fmt.Printf("  %s: %q\n", fieldNames[j], dbf.ToTrimmedString(value))
-					case "N", "F": // Numeric, Float
-						if d.Fields()[j].Decimals == 0 {
-							fmt.Printf("  %s: %d\n", fieldNames[j], dbf.ToInt64(value))
-						} else {
-							fmt.Printf("  %s: %.2f\n", fieldNames[j], dbf.ToFloat64(value))
-						}
-					case "L": // Logical
-						fmt.Printf("  %s: %t\n", fieldNames[j], dbf.ToBool(value))
-					case "D", "T": // Date, DateTime
-						fmt.Printf("  %s: %v\n", fieldNames[j], dbf.ToTime(value))
-					default:
-						fmt.Printf("  %s: %v\n", fieldNames[j], value)
-					}
-				}
+			printOpts := &dbf.PrintOptions{Indent: "  ", Mask: mask.apply}
+			if format != nil {
+				printOpts.Format = *format
+			}
+			if err := record.Print(os.Stdout, printOpts); err != nil {
+				log.Printf("Error printing record %d: %v", i, err)
 			}
 			fmt.Println()
 		}
@@ -174,7 +286,7 @@ func main() {
 				if err != nil {
 					log.Printf("Error reading field 0: %v", err)
 				} else {
-					fmt.Printf("Field 0 value: %v\n", dbf.ToTrimmedString(field0))
+					fmt.Printf("Field 0 value: %v\n", mask.apply(0, dbf.ToTrimmedString(field0)))
 				}
 			}
 
@@ -184,8 +296,8 @@ func main() {
 		}
 	}
 
-	// Convert a record to JSON (if records exist)
-	if !noDisplay && d.NumRecords() > 0 {
+	// Convert a record to JSON (if records exist), this is skipped when masking because the JSON contains all raw values
+	if !noDisplay && d.NumRecords() > 0 && mask == nil {
 		fmt.Println("\nRecord 0 as JSON:")
 		jsonData, err := d.RecordToJSON(0, true) // trim spaces
 		if err != nil {
@@ -200,8 +312,24 @@ func main() {
 	}
 }
 
-// exportToCSV exports all records from the DBF to a CSV file
-func exportToCSV(d *dbf.DBF, filename string, silent bool) error {
+// exportOptions controls what is written by the exporters
+type exportOptions struct {
+	columns []exportColumn     // columns to export, in output order
+	mask    *masker            // optional masking of sensitive fields
+	format  *dbf.FormatOptions // optional rendering of logicals and numbers, nil uses formatValueForCSV
+	comma   rune               // CSV field delimiter, 0 uses a comma
+	silent  bool               // suppress progress output
+	workers int                // number of conversion workers, 0 uses one worker per CPU
+
+	// filter is called for every record which is not deleted, if it returns false the record is not exported
+	filter func(recno uint32, record *dbf.Record) bool
+}
+
+// exportToCSV exports all records from the DBF to a CSV file, writing only the configured columns
+func exportToCSV(d *dbf.DBF, filename string, opts exportOptions) error {
+	silent := opts.silent
+	columns := opts.columns
+
 	if !silent {
 		fmt.Printf("Exporting to CSV: %s...\n", filename)
 	}
@@ -214,61 +342,138 @@ func exportToCSV(d *dbf.DBF, filename string, silent bool) error {
 	defer file.Close()
 
 	writer := csv.NewWriter(file)
+	if opts.comma != 0 {
+		writer.Comma = opts.comma
+	}
 	defer writer.Flush()
 
-	// Write header row (field names)
-	fieldNames := d.FieldNames()
-	if err := writer.Write(fieldNames); err != nil {
+	// Write header row (field names or mapped headers)
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.header
+	}
+	if err := writer.Write(headers); err != nil {
 		return fmt.Errorf("failed to write CSV header: %v", err)
 	}
 
-	// Write data rows
-	totalRecords := d.NumRecords()
-	processedRecords := uint32(0)
+	_, err = writeCSVRows(writer, d, opts)
+	return err
+}
 
-	for i := uint32(0); i < totalRecords; i++ {
-		record, err := d.RecordAt(i)
-		if err != nil {
-			if !silent {
-				log.Printf("Error reading record %d: %v", i, err)
-			}
-			continue
-		}
+// formatValue formats a field value for the export using the format options
+func (opts *exportOptions) formatValue(value interface{}, field dbf.FieldHeader) string {
+	if opts.format == nil {
+		return formatValueForCSV(value, field)
+	}
+	return opts.format.FormatValue(value, &field)
+}
 
-		// Skip deleted records
-		if record.Deleted {
-			continue
+// parseFormatOptions parses the --locale, --bool, --decimal-sep, --group-sep, --precision and --rounding options,
+// it returns nil if none of them is set. The other options override the settings of the locale.
+func parseFormatOptions(locale, boolStyle, decimalSep, groupSep, precision, rounding string) (*dbf.FormatOptions, error) {
+	if locale == "" && boolStyle == "" && decimalSep == "" && groupSep == "" && precision == "" && rounding == "" {
+		return nil, nil
+	}
+	format := new(dbf.FormatOptions)
+	if locale != "" {
+		var err error
+		if *format, err = dbf.LocaleFormat(locale); err != nil {
+			return nil, err
 		}
-
-		// Convert record to string slice
-		fieldSlice := record.FieldSlice()
-		csvRow := make([]string, len(fieldSlice))
-
-		for j, value := range fieldSlice {
-			csvRow[j] = formatValueForCSV(value, d.Fields()[j])
+	}
+	if decimalSep != "" {
+		format.DecimalSeparator = decimalSep
+	}
+	if groupSep != "" {
+		format.GroupSeparator = groupSep
+	}
+	if boolStyle != "" {
+		parts := strings.SplitN(boolStyle, "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid --bool %q, use TRUE/FALSE text like 1/0", boolStyle)
 		}
-
-		if err := writer.Write(csvRow); err != nil {
-			return fmt.Errorf("failed to write CSV row %d: %v", i, err)
+		format.True, format.False = parts[0], parts[1]
+	}
+	switch strings.ToLower(rounding) {
+	case "", "binary":
+	case "half-up":
+		format.Rounding = dbf.RoundHalfUp
+	case "half-even":
+		format.Rounding = dbf.RoundHalfEven
+	default:
+		return nil, fmt.Errorf("invalid --rounding %q, use binary, half-up or half-even", rounding)
+	}
+	if precision != "" {
+		format.Precision = make(map[string]int)
+		for _, item := range strings.Split(precision, ",") {
+			parts := strings.SplitN(strings.TrimSpace(item), ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid --precision %q, use FIELD:DECIMALS", item)
+			}
+			n, err := strconv.Atoi(parts[1])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid number of decimals for field %s: %s", parts[0], parts[1])
+			}
+			format.Precision[strings.ToUpper(parts[0])] = n
 		}
+	}
+	return format, nil
+}
 
-		processedRecords++
+// csvComma returns the CSV delimiter for the --locale option: the delimiter spreadsheets in the locale expect,
+// or 0 for the default comma when no locale is set
+func csvComma(locale string, format *dbf.FormatOptions) rune {
+	if locale == "" || format == nil {
+		return 0
+	}
+	return format.CSVDelimiter()
+}
 
-		// Show progress for large files
-		if !silent && totalRecords > 1000 && processedRecords%1000 == 0 {
-			fmt.Printf("Processed %d/%d records...\n", processedRecords, totalRecords)
+// setTimeZone sets the --source-tz and --target-tz options of the table
+func setTimeZone(d *dbf.DBF, sourceTZ, targetTZ string) error {
+	if sourceTZ == "" {
+		return errors.New("--target-tz requires --source-tz, the zone of the stored datetimes")
+	}
+	source, err := time.LoadLocation(sourceTZ)
+	if err != nil {
+		return fmt.Errorf("invalid --source-tz: %v", err)
+	}
+	var target *time.Location
+	if targetTZ != "" {
+		if target, err = time.LoadLocation(targetTZ); err != nil {
+			return fmt.Errorf("invalid --target-tz: %v", err)
 		}
 	}
-
+	d.SetTimeZone(source, target)
 	return nil
 }
 
+// parseInvalidNumberPolicy parses the --invalid-numbers option
+func parseInvalidNumberPolicy(s string) (dbf.InvalidNumberPolicy, error) {
+	switch strings.ToLower(s) {
+	case "error":
+		return dbf.InvalidNumbersError, nil
+	case "zero":
+		return dbf.InvalidNumbersZero, nil
+	case "nan":
+		return dbf.InvalidNumbersNaN, nil
+	case "raw":
+		return dbf.InvalidNumbersRaw, nil
+	}
+	return 0, fmt.Errorf("invalid --invalid-numbers %q, use error, zero, nan or raw", s)
+}
+
 // formatValueForCSV formats a field value for CSV output
 func formatValueForCSV(value interface{}, field dbf.FieldHeader) string {
 	if value == nil {
 		return ""
 	}
 
+	// invalid numbers are passed as string with the raw policy
+	if s, ok := value.(string); ok && (field.Type == 'N' || field.Type == 'F') {
+		return s
+	}
+
 	switch field.FieldType() {
 	case "C": // Character
 		return dbf.ToTrimmedString(value)
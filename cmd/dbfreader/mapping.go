@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// exportColumn is a single output column of an export, pointing to a field in the table
+type exportColumn struct {
+	pos    int    // zero-based field position in the DBF
+	header string // column header written to the export
+}
+
+// mapEntry is one line from a mapping file
+type mapEntry struct {
+	field  string
+	header string
+	drop   bool
+}
+
+// columnMapping renames, reorders and drops columns in exports.
+// Fields are exported in the order they appear in the mapping file, fields which are
+// not mentioned in the file follow in table order using their original names.
+type columnMapping struct {
+	entries []mapEntry
+}
+
+// loadColumnMapping reads a mapping file in a simple YAML subset:
+//
+//	# comments are allowed
+//	columns:
+//	  CUSTNO: Customer number
+//	  NM_1: "Name"
+//	  FILLER: ~
+//
+// Each entry maps a field name to a new header, an empty value, ~ or null drops the column.
+// The top level "columns:" key is optional.
+func loadColumnMapping(filename string) (*columnMapping, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	m := new(columnMapping)
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" || line == "columns:" || line == "---" {
+			continue
+		}
+		sep := strings.Index(line, ":")
+		if sep < 1 {
+			return nil, fmt.Errorf("%s line %d: expected FIELD: header", filename, lineno)
+		}
+		field := strings.ToUpper(unquote(strings.TrimSpace(line[:sep])))
+		value := strings.TrimSpace(line[sep+1:])
+		if seen[field] {
+			return nil, fmt.Errorf("%s line %d: field %s is mapped more than once", filename, lineno, field)
+		}
+		seen[field] = true
+
+		entry := mapEntry{field: field}
+		switch value {
+		case "", "~", "null", "Null", "NULL":
+			entry.drop = true
+		default:
+			entry.header = unquote(value)
+		}
+		m.entries = append(m.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// columns resolves the mapping against the fields in d.
+// A nil mapping returns all fields in table order with their original names.
+func (m *columnMapping) columns(d *dbf.DBF) ([]exportColumn, error) {
+	names := d.FieldNames()
+	if m == nil {
+		cols := make([]exportColumn, len(names))
+		for i, name := range names {
+			cols[i] = exportColumn{pos: i, header: name}
+		}
+		return cols, nil
+	}
+
+	used := make([]bool, len(names))
+	cols := make([]exportColumn, 0, len(names))
+	for _, e := range m.entries {
+		pos := d.FieldPos(e.field)
+		if pos < 0 {
+			return nil, fmt.Errorf("mapped field %s does not exist in table", e.field)
+		}
+		used[pos] = true
+		if !e.drop {
+			cols = append(cols, exportColumn{pos: pos, header: e.header})
+		}
+	}
+	for i, name := range names {
+		if !used[i] {
+			cols = append(cols, exportColumn{pos: i, header: name})
+		}
+	}
+	return cols, nil
+}
+
+// stripComment removes a # comment from a line, ignoring # inside quotes
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote removes matching single or double quotes around s
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
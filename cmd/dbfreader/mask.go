@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// Supported masking modes
+const (
+	maskHash   = "hash"   // replace the value with a (salted) hash, equal values stay equal
+	maskRedact = "redact" // replace the value with a fixed marker
+	maskFake   = "fake"   // replace the value with fake data of the same shape, equal values stay equal
+)
+
+const redactedValue = "[REDACTED]"
+
+// masker anonymizes values of selected fields before they are written or displayed
+type masker struct {
+	mode   string
+	salt   []byte
+	fields map[int]bool
+}
+
+// newMasker creates a masker for the comma separated list of fieldnames.
+// An empty list returns a nil masker which leaves all values untouched.
+func newMasker(d *dbf.DBF, fieldList, mode, salt string) (*masker, error) {
+	if fieldList == "" {
+		return nil, nil
+	}
+	switch mode {
+	case "":
+		mode = maskRedact
+	case maskHash, maskRedact, maskFake:
+	default:
+		return nil, fmt.Errorf("unsupported mask mode %q, use hash, redact or fake", mode)
+	}
+	// without a secret salt the original values can be found by hashing guesses
+	if salt == "" && mode != maskRedact {
+		return nil, fmt.Errorf("mask mode %s needs a secret --mask-salt", mode)
+	}
+	m := &masker{
+		mode:   mode,
+		salt:   []byte(salt),
+		fields: make(map[int]bool),
+	}
+	for _, name := range strings.Split(fieldList, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		pos := d.FieldPos(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("mask field %s does not exist in table", name)
+		}
+		m.fields[pos] = true
+	}
+	return m, nil
+}
+
+// masked returns if field pos will be masked, a nil masker never masks
+func (m *masker) masked(pos int) bool {
+	return m != nil && m.fields[pos]
+}
+
+// apply masks the formatted value of field pos if needed.
+// Empty values are never masked so empty and filled columns can still be told apart.
+func (m *masker) apply(pos int, value string) string {
+	if !m.masked(pos) || value == "" {
+		return value
+	}
+	switch m.mode {
+	case maskHash:
+		return hex.EncodeToString(m.digest(value))[:16]
+	case maskFake:
+		return m.fake(value)
+	default:
+		return redactedValue
+	}
+}
+
+// digest returns the HMAC-SHA256 of value using the salt as key
+func (m *masker) digest(value string) []byte {
+	mac := hmac.New(sha256.New, m.salt)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// fake replaces letters and digits in value with pseudo-random letters and digits.
+// The replacement is seeded from the value itself so the same input always gives the same output,
+// the shape of the value (length, case, punctuation) is preserved.
+func (m *masker) fake(value string) string {
+	seed := binary.LittleEndian.Uint64(m.digest(value))
+	next := func(n uint64) uint64 {
+		// xorshift64
+		seed ^= seed << 13
+		seed ^= seed >> 7
+		seed ^= seed << 17
+		return seed % n
+	}
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteByte(byte('0' + next(10)))
+		case unicode.IsUpper(r):
+			b.WriteByte(byte('A' + next(26)))
+		case unicode.IsLetter(r):
+			b.WriteByte(byte('a' + next(26)))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// materializeTable is the table in the SQLite file which records the state of the mirrored DBF files
+const materializeTable = "_dbf_tables"
+
+// runMaterialize mirrors all tables in a directory into a SQLite file. Only tables which changed since the
+// previous run are copied again, tables which were removed from the directory are dropped.
+// The SQLite file is written with the sqlite3 command line shell.
+func runMaterialize(args []string) error {
+	fs := flag.NewFlagSet("materialize", flag.ExitOnError)
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+	shell := fs.String("sqlite3", "sqlite3", "path of the sqlite3 command line shell, which must be installed")
+	force := fs.Bool("force", false, "copy all tables, also the unchanged tables")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return errors.New("expected a directory and a SQLite file")
+	}
+	dir, dbfile := positional[0], positional[1]
+	if _, err := newDecoder(*encoding); err != nil {
+		return err
+	}
+	// the shell is not part of this program, fail before the directory is read
+	path, err := exec.LookPath(*shell)
+	if err != nil {
+		return fmt.Errorf("the sqlite3 command line shell is needed, install it from https://sqlite.org/download.html or set its path with --sqlite3: %v", err)
+	}
+	db := &sqliteShell{path: path, file: dbfile}
+
+	files, err := dbfFiles(dir)
+	if err != nil {
+		return err
+	}
+	mirrored, err := db.mirroredTables()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// the state before reading, so a table which changes while it is copied is copied again next time
+		state, err := readTableState(files[name])
+		if err != nil {
+			return err
+		}
+		if prev, ok := mirrored[name]; ok && prev.equal(state) && !*force {
+			fmt.Printf("Unchanged %s\n", name)
+			continue
+		}
+		n, err := db.copyTable(name, state, *encoding)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		fmt.Printf("Copied %s: %d records\n", name, n)
+	}
+
+	var removed []string
+	for name := range mirrored {
+		if _, ok := files[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		if err := db.exec(fmt.Sprintf("DROP TABLE IF EXISTS %s;\nDELETE FROM %s WHERE name = %s;\n",
+			sqlIdent(name), materializeTable, sqlString(name))); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", name)
+	}
+	return nil
+}
+
+// sqliteShell runs SQL on a SQLite file with the sqlite3 command line shell
+type sqliteShell struct {
+	path string // the sqlite3 executable
+	file string // the SQLite file
+}
+
+// command returns the sqlite3 command which reads SQL from stdin
+func (db *sqliteShell) command(stdout io.Writer) (*exec.Cmd, *bytes.Buffer) {
+	cmd := exec.Command(db.path, "-batch", "-bail", "-noheader", "-separator", "\t", db.file)
+	stderr := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd, stderr
+}
+
+// exec runs the SQL statements
+func (db *sqliteShell) exec(sql string) error {
+	cmd, stderr := db.command(nil)
+	cmd.Stdin = strings.NewReader(sql)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sqlite3: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// query runs the SQL statements and returns the rows of the output, with the columns separated by tabs
+func (db *sqliteShell) query(sql string) ([][]string, error) {
+	stdout := new(bytes.Buffer)
+	cmd, stderr := db.command(stdout)
+	cmd.Stdin = strings.NewReader(sql)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("sqlite3: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var rows [][]string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			rows = append(rows, strings.Split(line, "\t"))
+		}
+	}
+	return rows, nil
+}
+
+// mirroredTables returns the state of the DBF files when they were copied by table name,
+// the state table is created when the SQLite file does not have it yet
+func (db *sqliteShell) mirroredTables() (map[string]tableState, error) {
+	rows, err := db.query(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name TEXT PRIMARY KEY, file TEXT, "+
+		"size INTEGER, modified INTEGER, memo_size INTEGER, memo_modified INTEGER, records INTEGER, copied TEXT);\n"+
+		"SELECT name, file, size, modified, memo_size, memo_modified FROM %[1]s;\n", materializeTable))
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]tableState, len(rows))
+	for _, row := range rows {
+		if len(row) != 6 {
+			return nil, fmt.Errorf("unexpected row in %s: %q", materializeTable, row)
+		}
+		var nums [4]int64
+		for i := range nums {
+			if nums[i], err = strconv.ParseInt(row[2+i], 10, 64); err != nil {
+				return nil, fmt.Errorf("unexpected row in %s: %q", materializeTable, row)
+			}
+		}
+		tables[row[0]] = tableState{
+			filename:    row[1],
+			size:        nums[0],
+			modTime:     time.Unix(0, nums[1]),
+			memoSize:    nums[2],
+			memoModTime: time.Unix(0, nums[3]),
+		}
+	}
+	return tables, nil
+}
+
+// copyTable replaces the SQLite table name with the records of the DBF file of state, deleted records are skipped.
+// The table and its state are replaced in one transaction, so the SQLite file keeps the previous copy when copying
+// fails. It returns the number of copied records.
+func (db *sqliteShell) copyTable(name string, state tableState, encoding string) (int, error) {
+	d, err := openTable(state.filename, encoding)
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+
+	cmd, stderr := db.command(nil)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	n, writeErr := writeTableSQL(stdin, d, name, state)
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return 0, fmt.Errorf("sqlite3: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return n, writeErr
+}
+
+// writeTableSQL writes the SQL statements which replace the SQLite table name with the records of d.
+// When a record can not be read the transaction is rolled back.
+func writeTableSQL(w io.Writer, d *dbf.DBF, name string, state tableState) (int, error) {
+	bw := bufio.NewWriter(w)
+	fields := d.Fields()
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = sqlIdent(f.FieldName()) + " " + sqliteType(f)
+	}
+	fmt.Fprintf(bw, "BEGIN;\nDROP TABLE IF EXISTS %s;\nCREATE TABLE %[1]s (%s);\n", sqlIdent(name), strings.Join(columns, ", "))
+
+	n := 0
+	values := make([]string, len(fields))
+	for i := uint32(0); i < d.NumRecords(); i++ {
+		rec, err := d.RecordAt(i)
+		if err != nil {
+			fmt.Fprintln(bw, "ROLLBACK;")
+			bw.Flush()
+			return 0, fmt.Errorf("record %d: %v", i, err)
+		}
+		if rec.Deleted {
+			continue
+		}
+		for j, val := range rec.FieldSlice() {
+			values[j] = sqlValue(val, fields[j])
+		}
+		fmt.Fprintf(bw, "INSERT INTO %s VALUES (%s);\n", sqlIdent(name), strings.Join(values, ", "))
+		n++
+	}
+
+	fmt.Fprintf(bw, "INSERT OR REPLACE INTO %s VALUES (%s, %s, %d, %d, %d, %d, %d, %s);\nCOMMIT;\n",
+		materializeTable, sqlString(name), sqlString(state.filename), state.size, state.modTime.UnixNano(),
+		state.memoSize, state.memoModTime.UnixNano(), n, sqlString(time.Now().UTC().Format(time.RFC3339)))
+	return n, bw.Flush()
+}
+
+// sqliteType returns the column type of a field
+func sqliteType(f dbf.FieldHeader) string {
+	switch f.Type {
+	case 'C', 'M', 'V', 'D', 'T':
+		return "TEXT"
+	case 'I', 'L', '+':
+		return "INTEGER"
+	case 'N':
+		if f.Decimals == 0 {
+			return "INTEGER"
+		}
+		return "REAL"
+	case 'F', 'B', 'Y':
+		return "REAL"
+	}
+	return "BLOB"
+}
+
+// sqlValue returns a value as SQL literal
+func sqlValue(val interface{}, f dbf.FieldHeader) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return "X'" + hex.EncodeToString(v) + "'"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		if v.IsZero() {
+			return "NULL"
+		}
+		if f.Type == 'D' {
+			return sqlString(v.Format("2006-01-02"))
+		}
+		return sqlString(v.Format("2006-01-02 15:04:05"))
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "NULL"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		if f.Type == 'C' {
+			v = strings.TrimRight(v, " ")
+		}
+		return sqlString(v)
+	}
+	return sqlString(fmt.Sprint(val))
+}
+
+// sqlString returns s as SQL string literal. Strings with NUL characters are written as a blob cast to text,
+// the sqlite3 shell would cut them off.
+func sqlString(s string) string {
+	if strings.IndexByte(s, 0) >= 0 {
+		return "CAST(X'" + hex.EncodeToString([]byte(s)) + "' AS TEXT)"
+	}
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// sqlIdent returns name as quoted SQL identifier
+func sqlIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
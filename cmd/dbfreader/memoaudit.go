@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// errMemoProblems is returned by memo-audit when problems are found, so the exit status is 1
+var errMemoProblems = errors.New("the memo file has problems")
+
+// runMemoAudit checks the memo file of a table against the memo pointers of the records
+func runMemoAudit(args []string) error {
+	fs := flag.NewFlagSet("memo-audit", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "write the audit as JSON")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+
+	d, err := openTable(files[0], *encoding)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	audit, err := d.AuditMemos()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(audit); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Block size:     %d\n", audit.BlockSize)
+		fmt.Printf("Next free:      %d\n", audit.NextFree)
+		fmt.Printf("File blocks:    %d\n", audit.FileBlocks)
+		fmt.Printf("Pointers:       %d\n", audit.Pointers)
+		fmt.Printf("Used blocks:    %d\n", audit.UsedBlocks)
+		fmt.Printf("Orphan blocks:  %d\n", audit.OrphanBlocks())
+		for _, r := range audit.Orphans {
+			fmt.Printf("  blocks %d to %d\n", r.Block, r.Block+r.Blocks-1)
+		}
+		fmt.Printf("Problems:       %d\n", len(audit.Problems))
+		for _, p := range audit.Problems {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+	if len(audit.Problems) > 0 {
+		return errMemoProblems
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runMemos writes the content of memo, general, picture and blob fields to separate files,
+// one file per record and field
+func runMemos(args []string) error {
+	fs := flag.NewFlagSet("memos", flag.ExitOnError)
+	fieldList := fs.String("field", "", "comma separated memo fields to extract (default all memo fields)")
+	keyField := fs.String("key", "", "field used to name the files (default the record number)")
+	outDir := fs.String("out-dir", "", "directory to write the files to")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+	withDeleted := fs.Bool("deleted", false, "also extract memos of deleted records")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+	if *outDir == "" {
+		return errors.New("--out-dir is required")
+	}
+
+	d, err := openTable(files[0], *encoding)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	fields, err := memoFields(d, *fieldList)
+	if err != nil {
+		return err
+	}
+	keypos := -1
+	if *keyField != "" {
+		keypos = d.FieldPos(strings.ToUpper(*keyField))
+		if keypos < 0 {
+			return fmt.Errorf("key field %s does not exist in table", *keyField)
+		}
+	}
+
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return err
+	}
+
+	written := 0
+	usedNames := make(map[string]bool)
+	for i := uint32(0); i < d.NumRecords(); i++ {
+		rec, err := d.RecordAt(i)
+		if err != nil {
+			return fmt.Errorf("record %d: %v", i, err)
+		}
+		if rec.Deleted && !*withDeleted {
+			continue
+		}
+		name := fmt.Sprintf("%d", i)
+		if keypos >= 0 {
+			key, _ := rec.Field(keypos)
+			name = sanitizeFilename(keyText(key))
+			if usedNames[name] {
+				// duplicate keys are made unique using the record number
+				name = fmt.Sprintf("%s-%d", name, i)
+			}
+			usedNames[name] = true
+		}
+		for _, pos := range fields {
+			val, _ := rec.Field(pos)
+			var data []byte
+			ext := ".txt"
+			switch v := val.(type) {
+			case string:
+				data = []byte(v)
+			case []byte:
+				data = v
+				ext = blobExtension(v)
+			}
+			// empty memos point to block 0 which reads as zero bytes
+			if len(bytes.Trim(data, " \t\r\n\x00")) == 0 {
+				continue
+			}
+			filename := filepath.Join(*outDir, name+"_"+d.Fields()[pos].FieldName()+ext)
+			if err := os.WriteFile(filename, data, 0644); err != nil {
+				return err
+			}
+			written++
+		}
+	}
+	fmt.Printf("Extracted %d memos to %s\n", written, *outDir)
+	return nil
+}
+
+// memoFields returns the positions of the requested memo-like fields,
+// or all memo-like fields if fieldList is empty
+func memoFields(d *dbf.DBF, fieldList string) ([]int, error) {
+	var fields []int
+	if fieldList == "" {
+		for i, f := range d.Fields() {
+			if isMemoType(f.FieldType()) {
+				fields = append(fields, i)
+			}
+		}
+		if len(fields) == 0 {
+			return nil, errors.New("table has no memo fields")
+		}
+		return fields, nil
+	}
+	for _, name := range strings.Split(fieldList, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		pos := d.FieldPos(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("field %s does not exist in table", name)
+		}
+		if !isMemoType(d.Fields()[pos].FieldType()) {
+			return nil, fmt.Errorf("field %s is not a memo field (type %s)", name, d.Fields()[pos].FieldType())
+		}
+		fields = append(fields, pos)
+	}
+	return fields, nil
+}
+
+// isMemoType returns if the field type stores its data in the memo file
+func isMemoType(fieldType string) bool {
+	switch fieldType {
+	case "M", "G", "P", "W":
+		return true
+	}
+	return false
+}
+
+// blobExtension guesses a file extension from the content of binary memo data
+func blobExtension(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG")):
+		return ".png"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return ".jpg"
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return ".gif"
+	case bytes.HasPrefix(data, []byte("BM")):
+		return ".bmp"
+	case bytes.HasPrefix(data, []byte("%PDF")):
+		return ".pdf"
+	default:
+		return ".bin"
+	}
+}
+
+// keyText returns a trimmed text representation of a key value for use in filenames
+func keyText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// sanitizeFilename replaces characters which are not safe in filenames
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', 0:
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		return "_"
+	}
+	return name
+}
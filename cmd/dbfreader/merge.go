@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runMerge exports multiple tables with the same structure to one CSV file,
+// adding a column with the source filename to every row
+func runMerge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	out := fs.String("out", "", "CSV file to write")
+	sourceColumn := fs.String("source-column", "SOURCE", "header of the added source file column, empty to omit the column")
+	mapFile := fs.String("map", "", "column mapping file")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+	workers := fs.Int("workers", 0, "number of CSV conversion workers, 0 uses one per CPU")
+	locale := fs.String("locale", "", "render logicals and numbers and choose the CSV delimiter for a locale, like de")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) < 2 {
+		return errors.New("expected at least two DBF files")
+	}
+	if *out == "" {
+		return errors.New("--out is required")
+	}
+	format, err := parseFormatOptions(*locale, "", "", "", "", "")
+	if err != nil {
+		return err
+	}
+
+	// Validate all schemas against the first file before writing anything
+	first, err := openTable(files[0], *encoding)
+	if err != nil {
+		return err
+	}
+	defer first.Close()
+	for _, filename := range files[1:] {
+		d, err := openTable(filename, *encoding)
+		if err != nil {
+			return err
+		}
+		diffs := schemaMismatches(first.Fields(), d.Fields())
+		d.Close()
+		if len(diffs) > 0 {
+			return fmt.Errorf("schema of %s does not match %s: %s", filename, files[0], strings.Join(diffs, "; "))
+		}
+	}
+
+	var mapping *columnMapping
+	if *mapFile != "" {
+		mapping, err = loadColumnMapping(*mapFile)
+		if err != nil {
+			return err
+		}
+	}
+	columns, err := mapping.columns(first)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	if comma := csvComma(*locale, format); comma != 0 {
+		writer.Comma = comma
+	}
+
+	headers := make([]string, 0, len(columns)+1)
+	for _, col := range columns {
+		headers = append(headers, col.header)
+	}
+	if *sourceColumn != "" {
+		headers = append(headers, *sourceColumn)
+	}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	total := uint32(0)
+	for _, filename := range files {
+		var extra []string
+		if *sourceColumn != "" {
+			extra = append(extra, filepath.Base(filename))
+		}
+		n, err := mergeTable(writer, filename, *encoding, exportOptions{columns: columns, format: format, silent: true, workers: *workers}, extra)
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+		fmt.Printf("%s: %d records\n", filename, n)
+		total += n
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	fmt.Printf("Merged %d records from %d files into %s\n", total, len(files), *out)
+	return nil
+}
+
+// mergeTable writes the rows of one table to writer
+func mergeTable(writer *csv.Writer, filename, encoding string, opts exportOptions, extra []string) (uint32, error) {
+	d, err := openTable(filename, encoding)
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+	return writeCSVRows(writer, d, opts, extra...)
+}
+
+// schemaMismatches returns a description of every field which differs between a and b in
+// name, type, length or decimals, fields must also be in the same order
+func schemaMismatches(a, b []dbf.FieldHeader) []string {
+	var diffs []string
+	if len(a) != len(b) {
+		diffs = append(diffs, fmt.Sprintf("%d fields instead of %d", len(b), len(a)))
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		fa, fb := a[i], b[i]
+		if fa.FieldName() != fb.FieldName() || fa.Type != fb.Type || fa.Len != fb.Len || fa.Decimals != fb.Decimals {
+			diffs = append(diffs, fmt.Sprintf("field %d is %s instead of %s", i+1, describeField(fb), describeField(fa)))
+		}
+	}
+	return diffs
+}
+
+// describeField returns a field definition in FoxPro notation, for example NAME C(40) or AMOUNT N(12,2)
+func describeField(f dbf.FieldHeader) string {
+	if f.Decimals > 0 {
+		return fmt.Sprintf("%s %s(%d,%d)", f.FieldName(), f.FieldType(), f.Len, f.Decimals)
+	}
+	return fmt.Sprintf("%s %s(%d)", f.FieldName(), f.FieldType(), f.Len)
+}
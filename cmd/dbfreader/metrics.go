@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scanBuckets are the upper bounds in seconds of the scan duration histogram
+var scanBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// serveMetrics are the metrics of the serve command, written in the Prometheus text format by /metrics.
+// A nil *serveMetrics records nothing, so the handlers do not have to check if metrics are enabled.
+type serveMetrics struct {
+	mu         sync.Mutex
+	requests   map[requestKey]uint64
+	rows       map[string]uint64 // rows served by table
+	openTables int
+	scans      map[string]*histogram // scan durations by table
+	cache      map[string]uint64     // query cache lookups by result, hit or miss
+}
+
+// requestKey are the labels of the request counter
+type requestKey struct {
+	endpoint string
+	code     int
+}
+
+// histogram is a Prometheus histogram with the scanBuckets
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func newServeMetrics() *serveMetrics {
+	return &serveMetrics{
+		requests: make(map[requestKey]uint64),
+		rows:     make(map[string]uint64),
+		scans:    make(map[string]*histogram),
+		cache:    make(map[string]uint64),
+	}
+}
+
+func (m *serveMetrics) request(endpoint string, code int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.requests[requestKey{endpoint, code}]++
+	m.mu.Unlock()
+}
+
+func (m *serveMetrics) rowsServed(table string, n int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.rows[table] += uint64(n)
+	m.mu.Unlock()
+}
+
+func (m *serveMetrics) tableOpened(delta int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.openTables += delta
+	m.mu.Unlock()
+}
+
+func (m *serveMetrics) scanned(table string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.scans[table]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(scanBuckets))}
+		m.scans[table] = h
+	}
+	seconds := d.Seconds()
+	for i, le := range scanBuckets {
+		if seconds <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (m *serveMetrics) cacheLookup(hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.mu.Lock()
+	m.cache[result]++
+	m.mu.Unlock()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *serveMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	fmt.Fprintln(bw, "# HELP dbfreader_http_requests_total HTTP requests by endpoint and status code.")
+	fmt.Fprintln(bw, "# TYPE dbfreader_http_requests_total counter")
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint < keys[j].endpoint
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, k := range keys {
+		fmt.Fprintf(bw, "dbfreader_http_requests_total{endpoint=%q,code=\"%d\"} %d\n", k.endpoint, k.code, m.requests[k])
+	}
+
+	fmt.Fprintln(bw, "# HELP dbfreader_rows_served_total Records returned by the records and tail endpoints.")
+	fmt.Fprintln(bw, "# TYPE dbfreader_rows_served_total counter")
+	for _, table := range sortedKeys(m.rows) {
+		fmt.Fprintf(bw, "dbfreader_rows_served_total{table=%q} %d\n", table, m.rows[table])
+	}
+
+	fmt.Fprintln(bw, "# HELP dbfreader_open_tables Tables which are currently open.")
+	fmt.Fprintln(bw, "# TYPE dbfreader_open_tables gauge")
+	fmt.Fprintf(bw, "dbfreader_open_tables %d\n", m.openTables)
+
+	fmt.Fprintln(bw, "# HELP dbfreader_scan_duration_seconds Duration of the table scans of the records endpoint.")
+	fmt.Fprintln(bw, "# TYPE dbfreader_scan_duration_seconds histogram")
+	tables := make([]string, 0, len(m.scans))
+	for table := range m.scans {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		h := m.scans[table]
+		cumulative := uint64(0)
+		for i, le := range scanBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(bw, "dbfreader_scan_duration_seconds_bucket{table=%q,le=\"%g\"} %d\n", table, le, cumulative)
+		}
+		fmt.Fprintf(bw, "dbfreader_scan_duration_seconds_bucket{table=%q,le=\"+Inf\"} %d\n", table, h.count)
+		fmt.Fprintf(bw, "dbfreader_scan_duration_seconds_sum{table=%q} %g\n", table, h.sum)
+		fmt.Fprintf(bw, "dbfreader_scan_duration_seconds_count{table=%q} %d\n", table, h.count)
+	}
+
+	fmt.Fprintln(bw, "# HELP dbfreader_query_cache_total Lookups in the query cache of the records endpoint by result.")
+	fmt.Fprintln(bw, "# TYPE dbfreader_query_cache_total counter")
+	for _, result := range sortedKeys(m.cache) {
+		fmt.Fprintf(bw, "dbfreader_query_cache_total{result=%q} %d\n", result, m.cache[result])
+	}
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// endpointName returns the endpoint label of a request path
+func endpointName(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) == 1 && (parts[0] == "" || parts[0] == "tables"):
+		return "tables"
+	case len(parts) == 1 && parts[0] == "metrics":
+		return "metrics"
+	case len(parts) == 2 && parts[0] == "tables":
+		return "schema"
+	case len(parts) == 3 && parts[0] == "tables" && (parts[2] == "records" || parts[2] == "tail"):
+		return parts[2]
+	}
+	return "other"
+}
+
+// statusRecorder remembers the status code written by a handler.
+// It implements http.Hijacker for the WebSocket upgrade of the tail endpoint.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("the connection cannot be hijacked")
+	}
+	r.code = http.StatusSwitchingProtocols
+	return hj.Hijack()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runPack removes deleted records from a table and compacts its memo file.
+// Without --out the table is replaced, the original files are kept as .bak files.
+// Tables with a structural index (CDX) are only packed with --drop-index, the packed table no longer refers to the index.
+func runPack(args []string) error {
+	fs := flag.NewFlagSet("pack", flag.ExitOnError)
+	out := fs.String("out", "", "write the packed table to this file instead of replacing the original")
+	noBackup := fs.Bool("no-backup", false, "remove the original files instead of keeping .bak copies")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+	dropIndex := fs.Bool("drop-index", false, "pack a table with a structural index (CDX), the index must be rebuilt afterwards")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+	src := files[0]
+
+	d, err := openTable(src, *encoding)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if d.Header().HasCDX() {
+		if !*dropIndex {
+			return errors.New("the table has a structural index (CDX) which is not updated by pack, use --drop-index to pack it anyway")
+		}
+		fmt.Println("WARNING: the structural index (CDX) is dropped from the packed table, it must be rebuilt in FoxPro")
+	}
+	if set, err := dbf.DiscoverSet(src); err == nil {
+		for _, problem := range set.Problems() {
+			fmt.Printf("WARNING: %s\n", problem)
+		}
+	}
+
+	// write the packed files next to the destination so they can be renamed into place
+	dst := *out
+	if dst == "" {
+		dst = src
+	}
+	if *out != "" && sameFile(src, *out) {
+		return errors.New("--out must be a different file, omit --out to pack in place")
+	}
+	dstFPT := ""
+	srcFPT := ""
+	if d.Header().HasMemo() {
+		srcFPT, err = findMemoFile(src)
+		if err != nil {
+			return err
+		}
+		dstFPT = srcFPT
+		if *out != "" {
+			dstFPT = strings.TrimSuffix(*out, filepath.Ext(*out)) + memoExtension(*out)
+		}
+	}
+
+	// the packed files replace the destination files together, or not at all when packing fails
+	rw := dbf.NewRewrite()
+	rw.Backup = *out == "" && !*noBackup
+	tmpDBF, err := rw.Create(dst)
+	if err != nil {
+		return err
+	}
+	var fptout io.WriteSeeker
+	if dstFPT != "" {
+		tmpFPT, err := rw.Create(dstFPT)
+		if err != nil {
+			rw.Abort()
+			return err
+		}
+		fptout = tmpFPT
+	}
+	report, err := d.PackTo(tmpDBF, fptout)
+	if err != nil {
+		rw.Abort()
+		return err
+	}
+	// close the table before its files are replaced
+	d.Close()
+	if err := rw.Commit(); err != nil {
+		return err
+	}
+	if rw.Backup {
+		for _, f := range []string{src, srcFPT} {
+			if f != "" {
+				fmt.Printf("Backup: %s\n", f+".bak")
+			}
+		}
+	}
+
+	fmt.Printf("Packed %s: %d records kept, %d deleted records removed\n", dst, report.Kept, report.Removed)
+	if dstFPT != "" {
+		fmt.Printf("Memo file %s compacted to %d blocks\n", dstFPT, report.MemoBlocks)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// exportBatchSize is the number of records a worker reads and converts at once
+const exportBatchSize = 1000
+
+// exportBatch is a range of records which is converted by a worker and written in order by the writer
+type exportBatch struct {
+	seq        int    // sequence number of the batch, the writer writes the batches in this order
+	start, end uint32 // record numbers in the batch
+	rows       []exportRow
+}
+
+// exportRow is a converted record which is not deleted, or the error reading it
+type exportRow struct {
+	recno  uint32
+	record *dbf.Record
+	values []string
+	err    error
+}
+
+// writeCSVRows writes all records which are not deleted or filtered to writer and returns the number of written rows.
+// The extra values are appended to every row.
+//
+// The records are exported by a pipeline: a reader goroutine divides the table in batches, a pool of workers reads
+// and converts the batches in parallel and the writer (the calling goroutine) writes them in table order.
+// The filter is called by the writer, in table order.
+func writeCSVRows(writer *csv.Writer, d *dbf.DBF, opts exportOptions, extra ...string) (uint32, error) {
+	silent := opts.silent
+	workers := opts.workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	totalRecords := d.NumRecords()
+	processedRecords := uint32(0)
+
+	// closing done stops the reader and the workers when the writer returns early
+	done := make(chan struct{})
+	defer close(done)
+
+	// tokens limits the number of batches in the pipeline, so the memory use does not depend on the table size
+	tokens := make(chan struct{}, 2*workers)
+	batches := make(chan *exportBatch)
+	converted := make(chan *exportBatch, workers)
+
+	// Reader
+	go func() {
+		defer close(batches)
+		for seq, start := 0, uint32(0); start < totalRecords; seq++ {
+			end := start + exportBatchSize
+			if end > totalRecords || end < start {
+				end = totalRecords
+			}
+			select {
+			case tokens <- struct{}{}:
+			case <-done:
+				return
+			}
+			select {
+			case batches <- &exportBatch{seq: seq, start: start, end: end}:
+			case <-done:
+				return
+			}
+			start = end
+		}
+	}()
+
+	// Workers
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				convertBatch(d, opts, b, extra)
+				select {
+				case converted <- b:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(converted)
+	}()
+
+	// Writer, batches which are converted before their predecessors wait in pending
+	pending := make(map[int]*exportBatch)
+	next := 0
+	for b := range converted {
+		pending[b.seq] = b
+		for ready, ok := pending[next]; ok; ready, ok = pending[next] {
+			delete(pending, next)
+			next++
+			for _, row := range ready.rows {
+				if row.err != nil {
+					if !silent {
+						log.Printf("Error reading record %d: %v", row.recno, row.err)
+					}
+					continue
+				}
+				if opts.filter != nil && !opts.filter(row.recno, row.record) {
+					continue
+				}
+				if names := row.record.InvalidFields(); names != nil && !silent {
+					log.Printf("Record %d has invalid numbers in %s", row.recno, strings.Join(names, ", "))
+				}
+				if err := writer.Write(row.values); err != nil {
+					return processedRecords, fmt.Errorf("failed to write CSV row %d: %v", row.recno, err)
+				}
+
+				processedRecords++
+
+				// Show progress for large files
+				if !silent && totalRecords > 1000 && processedRecords%1000 == 0 {
+					fmt.Printf("Processed %d/%d records...\n", processedRecords, totalRecords)
+				}
+			}
+			<-tokens
+		}
+	}
+
+	return processedRecords, nil
+}
+
+// convertBatch reads the records of a batch and converts the records which are not deleted to CSV rows
+func convertBatch(d *dbf.DBF, opts exportOptions, b *exportBatch, extra []string) {
+	fields := d.Fields()
+	for i := b.start; i < b.end; i++ {
+		record, err := d.RecordAt(i)
+		if err != nil {
+			b.rows = append(b.rows, exportRow{recno: i, err: err})
+			continue
+		}
+
+		// Skip deleted records
+		if record.Deleted {
+			continue
+		}
+
+		// Convert record to string slice
+		fieldSlice := record.FieldSlice()
+		csvRow := make([]string, len(opts.columns), len(opts.columns)+len(extra))
+		for j, col := range opts.columns {
+			csvRow[j] = opts.mask.apply(col.pos, opts.formatValue(fieldSlice[col.pos], fields[col.pos]))
+		}
+		csvRow = append(csvRow, extra...)
+		b.rows = append(b.rows, exportRow{recno: i, record: record, values: csvRow})
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// recordQuery are the query options of the records endpoint of the serve command
+type recordQuery struct {
+	offset      int
+	limit       int
+	withDeleted bool
+	selected    []int // positions of the returned fields, in output order
+	filters     []fieldFilter
+	sort        []sortKey
+}
+
+// fieldFilter is a filter on one field, FIELD=value or FIELD.op=value
+type fieldFilter struct {
+	pos   int
+	op    string      // eq, ne, gt, gte, lt or lte
+	text  string      // value as given, eq and ne compare it with the value formatted like CSV
+	value interface{} // value converted to the type of the field, for the range operators
+}
+
+// sortKey is a field to sort on, -FIELD sorts descending
+type sortKey struct {
+	pos  int
+	desc bool
+}
+
+// queryParams are the parameters of the records endpoint which are not filters
+var queryParams = map[string]bool{
+	"offset": true, "limit": true, "format": true, "deleted": true, "select": true, "sort": true, "page_token": true,
+}
+
+// dateLayouts are the accepted formats of D and T values in filters
+var dateLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339}
+
+// parseRecordQuery parses the query parameters of the records endpoint for table d
+func parseRecordQuery(query url.Values, d *dbf.DBF) (*recordQuery, error) {
+	q := &recordQuery{withDeleted: query.Get("deleted") == "true"}
+	var err error
+	if q.offset, err = queryInt(query.Get("offset"), 0); err != nil || q.offset < 0 {
+		return nil, errors.New("invalid offset")
+	}
+	if token := query.Get("page_token"); token != "" {
+		if q.offset, err = decodePageToken(token); err != nil {
+			return nil, errors.New("invalid page_token")
+		}
+	}
+	if q.limit, err = queryInt(query.Get("limit"), defaultPageSize); err != nil || q.limit < 1 || q.limit > maxPageSize {
+		return nil, fmt.Errorf("invalid limit, use 1 to %d", maxPageSize)
+	}
+
+	fields := d.Fields()
+	fieldPos := func(name string) (int, error) {
+		pos := d.FieldPos(strings.ToUpper(strings.TrimSpace(name)))
+		if pos < 0 {
+			return 0, fmt.Errorf("unknown field %s", name)
+		}
+		return pos, nil
+	}
+	if sel := query.Get("select"); sel != "" {
+		for _, name := range strings.Split(sel, ",") {
+			pos, err := fieldPos(name)
+			if err != nil {
+				return nil, err
+			}
+			q.selected = append(q.selected, pos)
+		}
+	} else {
+		for pos := range fields {
+			q.selected = append(q.selected, pos)
+		}
+	}
+	if s := query.Get("sort"); s != "" {
+		for _, name := range strings.Split(s, ",") {
+			key := sortKey{desc: strings.HasPrefix(name, "-")}
+			if key.pos, err = fieldPos(strings.TrimPrefix(name, "-")); err != nil {
+				return nil, err
+			}
+			q.sort = append(q.sort, key)
+		}
+	}
+
+	// all other parameters are filters
+	for key, values := range query {
+		if queryParams[key] {
+			continue
+		}
+		name, op := key, "eq"
+		if i := strings.LastIndex(key, "."); i > 0 {
+			name, op = key[:i], key[i+1:]
+		}
+		pos, err := fieldPos(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, text := range values {
+			f := fieldFilter{pos: pos, op: op, text: text}
+			switch op {
+			case "eq", "ne":
+			case "gt", "gte", "lt", "lte":
+				if f.value, err = filterValue(text, fields[pos]); err != nil {
+					return nil, fmt.Errorf("invalid value for %s: %v", key, err)
+				}
+			default:
+				return nil, fmt.Errorf("unknown operator %s, use eq, ne, gt, gte, lt or lte", op)
+			}
+			q.filters = append(q.filters, f)
+		}
+	}
+	return q, nil
+}
+
+// filterValue converts the value of a range filter to the type used by compareValues for the field
+func filterValue(text string, field dbf.FieldHeader) (interface{}, error) {
+	switch field.Type {
+	case 'N', 'F', 'I', 'B', 'Y':
+		return strconv.ParseFloat(text, 64)
+	case 'D', 'T':
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, text); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date %q, use YYYY-MM-DD", text)
+	case 'L':
+		return strconv.ParseBool(text)
+	}
+	return text, nil
+}
+
+// match returns if a record matches all filters
+func (q *recordQuery) match(values []interface{}, fields []dbf.FieldHeader) bool {
+	for _, f := range q.filters {
+		val, field := values[f.pos], fields[f.pos]
+		switch f.op {
+		case "eq":
+			if formatValueForCSV(val, field) != f.text {
+				return false
+			}
+		case "ne":
+			if formatValueForCSV(val, field) == f.text {
+				return false
+			}
+		default:
+			c := compareValues(val, f.value)
+			if (f.op == "gt" && c <= 0) || (f.op == "gte" && c < 0) || (f.op == "lt" && c >= 0) || (f.op == "lte" && c > 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sortRecords sorts matching records by the sort keys, records with equal keys stay in table order
+func (q *recordQuery) sortRecords(records [][]interface{}) {
+	if len(q.sort) == 0 {
+		return
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		for _, key := range q.sort {
+			c := compareValues(records[i][key.pos], records[j][key.pos])
+			if c == 0 {
+				continue
+			}
+			if key.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// compareValues compares two field values, numbers as numbers, dates as dates and text without surrounding spaces
+func compareValues(a, b interface{}) int {
+	if x, ok := numericValue(a); ok {
+		if y, ok := numericValue(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	switch x := a.(type) {
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			switch {
+			case x.Before(y):
+				return -1
+			case x.After(y):
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(strings.TrimSpace(dbf.ToString(a)), strings.TrimSpace(dbf.ToString(b)))
+}
+
+// numericValue returns the value of the numeric types returned for N, F, I, B and Y fields
+func numericValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// encodePageToken returns the opaque token for the page starting at offset
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodePageToken returns the offset of a page token
+func decodePageToken(token string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.HasPrefix(string(data), "offset:") {
+		return 0, errors.New("invalid page token")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), "offset:"))
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid page token")
+	}
+	return offset, nil
+}
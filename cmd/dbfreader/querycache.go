@@ -0,0 +1,105 @@
+package main
+
+import (
+	"container/list"
+	"os"
+	"sync"
+	"time"
+)
+
+// queryCache keeps the results of the records endpoint of the serve command, so dashboards which repeat the same
+// queries on unchanged tables do not scan the table every time. A result is keyed by the request and is only used
+// while the table file and its memo file have the same size and modification time as when the result was computed.
+// The least recently used results are removed when the cache is full.
+// A nil *queryCache caches nothing.
+type queryCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List // most recently used first
+}
+
+// cachedQuery is an entry of the queryCache
+type cachedQuery struct {
+	key    string
+	state  tableState
+	result *recordResult
+}
+
+// tableState is the size and modification time of a table file and its memo file
+type tableState struct {
+	filename    string
+	size        int64
+	modTime     time.Time
+	memoSize    int64
+	memoModTime time.Time
+}
+
+// newQueryCache returns a cache of size results, or nil when size is 0
+func newQueryCache(size int) *queryCache {
+	if size <= 0 {
+		return nil
+	}
+	return &queryCache{size: size, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// readTableState returns the state of the table file and its memo file, if any
+func readTableState(filename string) (tableState, error) {
+	state := tableState{filename: filename}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return state, err
+	}
+	state.size, state.modTime = info.Size(), info.ModTime()
+	if memo, err := findMemoFile(filename); err == nil {
+		if info, err := os.Stat(memo); err == nil {
+			state.memoSize, state.memoModTime = info.Size(), info.ModTime()
+		}
+	}
+	return state, nil
+}
+
+// equal returns if the files did not change between both states
+func (s tableState) equal(other tableState) bool {
+	return s.filename == other.filename && s.size == other.size && s.modTime.Equal(other.modTime) &&
+		s.memoSize == other.memoSize && s.memoModTime.Equal(other.memoModTime)
+}
+
+// get returns the result for key, or nil when there is none or the table changed
+func (c *queryCache) get(key string, state tableState) *recordResult {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := elem.Value.(*cachedQuery)
+	if !entry.state.equal(state) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return entry.result
+}
+
+// put adds the result for key, computed from the table in state
+func (c *queryCache) put(key string, state tableState, result *recordResult) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(&cachedQuery{key: key, state: state, result: result})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedQuery).key)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runRepair checks a DBF file for structural problems and writes a repaired copy.
+// Without --out only the problems found are reported.
+func runRepair(args []string) error {
+	fs := flag.NewFlagSet("repair", flag.ExitOnError)
+	fixCount := fs.Bool("fix-count", false, "set the header record count to the number of complete records")
+	stripGarbage := fs.Bool("strip-garbage", false, "remove data after the last record and add an EOF marker")
+	out := fs.String("out", "", "repaired DBF file to write, the memo file is copied next to it")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+	if *out != "" && sameFile(files[0], *out) {
+		return errors.New("--out must be a different file")
+	}
+
+	src, err := os.Open(files[0])
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	stat, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst := io.Discard
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		dst = f
+	}
+
+	report, err := dbf.Repair(dst, src, stat.Size(), dbf.RepairOptions{
+		FixCount:     *fixCount,
+		StripGarbage: *stripGarbage,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Records in header:  %d\n", report.HeaderRecords)
+	fmt.Printf("Records in file:    %d\n", report.FileRecords)
+	fmt.Printf("Trailing bytes:     %d\n", report.TrailingBytes)
+	fmt.Printf("Missing EOF marker: %t\n", report.MissingEOF)
+	fmt.Printf("Truncated:          %t\n", report.Truncated)
+
+	if *out == "" {
+		return nil
+	}
+	if len(report.Fixes) == 0 {
+		fmt.Println("No fixes applied, file copied as is")
+	}
+	for _, fix := range report.Fixes {
+		fmt.Printf("Fixed: %s\n", fix)
+	}
+
+	// copy the memo file so the repaired table can be opened
+	if fptfile, err := findMemoFile(files[0]); err == nil {
+		fptout := strings.TrimSuffix(*out, filepath.Ext(*out)) + memoExtension(*out)
+		if err := copyFile(fptfile, fptout); err != nil {
+			return err
+		}
+		fmt.Printf("Copied memo file to %s\n", fptout)
+	}
+	fmt.Printf("Wrote %s\n", *out)
+	return nil
+}
+
+// memoExtension returns the memo file extension matching the case of the DBF file extension,
+// the same rule as OpenFile uses to find the memo file
+func memoExtension(dbffile string) string {
+	ext := filepath.Ext(dbffile)
+	if strings.ToUpper(ext) == ext {
+		return ".FPT"
+	}
+	return ".fpt"
+}
+
+// sameFile returns if a and b point to the same existing file
+func sameFile(a, b string) bool {
+	sa, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	sb, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(sa, sb)
+}
+
+// copyFile copies src to dst, dst is created or truncated
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// Kinds of schema changes
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeRetyped = "retyped"
+	changeResized = "resized"
+	changeMoved   = "moved"
+)
+
+// errSchemaChanged is returned by schema-diff when the schemas differ, so the exit status is 1 like diff
+var errSchemaChanged = errors.New("schemas differ")
+
+// schemaChange is one difference between two table schemas
+type schemaChange struct {
+	Kind  string `json:"kind"`
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// runSchemaDiff compares the fields of two tables by name
+func runSchemaDiff(args []string) error {
+	fs := flag.NewFlagSet("schema-diff", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "write the differences as JSON")
+	ignoreOrder := fs.Bool("ignore-order", false, "do not report fields which moved to another position")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 2 {
+		return errors.New("expected two DBF files")
+	}
+
+	oldFields, err := tableFields(files[0], *encoding)
+	if err != nil {
+		return err
+	}
+	newFields, err := tableFields(files[1], *encoding)
+	if err != nil {
+		return err
+	}
+
+	changes := diffSchemas(oldFields, newFields)
+	if *ignoreOrder {
+		filtered := changes[:0]
+		for _, c := range changes {
+			if c.Kind != changeMoved {
+				filtered = append(filtered, c)
+			}
+		}
+		changes = filtered
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if changes == nil {
+			changes = []schemaChange{}
+		}
+		if err := enc.Encode(changes); err != nil {
+			return err
+		}
+	} else {
+		for _, c := range changes {
+			switch c.Kind {
+			case changeAdded:
+				fmt.Printf("+ %-8s %s\n", c.Kind, c.New)
+			case changeRemoved:
+				fmt.Printf("- %-8s %s\n", c.Kind, c.Old)
+			default:
+				fmt.Printf("~ %-8s %s -> %s\n", c.Kind, c.Old, c.New)
+			}
+		}
+	}
+	if len(changes) > 0 {
+		return errSchemaChanged
+	}
+	return nil
+}
+
+// tableFields opens a table and returns its fields
+func tableFields(filename, encoding string) ([]dbf.FieldHeader, error) {
+	d, err := openTable(filename, encoding)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+	return d.Fields(), nil
+}
+
+// diffSchemas compares fields by name and returns all changes, removed fields first in old order,
+// then changed and added fields in new order
+func diffSchemas(oldFields, newFields []dbf.FieldHeader) []schemaChange {
+	oldPos := make(map[string]int, len(oldFields))
+	for i, f := range oldFields {
+		oldPos[f.FieldName()] = i
+	}
+	newPos := make(map[string]int, len(newFields))
+	for i, f := range newFields {
+		newPos[f.FieldName()] = i
+	}
+
+	var changes []schemaChange
+	for _, f := range oldFields {
+		if _, ok := newPos[f.FieldName()]; !ok {
+			changes = append(changes, schemaChange{Kind: changeRemoved, Field: f.FieldName(), Old: describeField(f)})
+		}
+	}
+
+	// position of the fields relative to the fields both schemas have in common
+	common := 0
+	commonOld := make(map[string]int)
+	for _, f := range oldFields {
+		if _, ok := newPos[f.FieldName()]; ok {
+			commonOld[f.FieldName()] = common
+			common++
+		}
+	}
+
+	common = 0
+	for i, f := range newFields {
+		j, ok := oldPos[f.FieldName()]
+		if !ok {
+			changes = append(changes, schemaChange{Kind: changeAdded, Field: f.FieldName(), New: describeField(f)})
+			continue
+		}
+		old := oldFields[j]
+		switch {
+		case old.Type != f.Type:
+			changes = append(changes, schemaChange{Kind: changeRetyped, Field: f.FieldName(), Old: describeField(old), New: describeField(f)})
+		case old.Len != f.Len || old.Decimals != f.Decimals:
+			changes = append(changes, schemaChange{Kind: changeResized, Field: f.FieldName(), Old: describeField(old), New: describeField(f)})
+		}
+		if commonOld[f.FieldName()] != common {
+			changes = append(changes, schemaChange{
+				Kind:  changeMoved,
+				Field: f.FieldName(),
+				Old:   fmt.Sprintf("%s at position %d", f.FieldName(), j+1),
+				New:   fmt.Sprintf("%s at position %d", f.FieldName(), i+1),
+			})
+		}
+		common++
+	}
+	return changes
+}
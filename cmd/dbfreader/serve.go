@@ -0,0 +1,459 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 10000
+)
+
+// runServe serves all DBF files in a directory as a read-only HTTP API
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	port := fs.Int("port", 8080, "port to listen on")
+	host := fs.String("host", "", "host or address to listen on (default all interfaces)")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+	metrics := fs.Bool("metrics", false, "expose Prometheus metrics on /metrics")
+	cacheSize := fs.Int("cache-size", 100, "number of records endpoint results to cache while the table is unchanged, 0 disables the cache")
+
+	dirs, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(dirs) != 1 {
+		return errors.New("expected exactly one directory")
+	}
+	if _, err := newDecoder(*encoding); err != nil {
+		return err
+	}
+	stat, err := os.Stat(dirs[0])
+	if err != nil {
+		return err
+	}
+	if !stat.IsDir() {
+		return fmt.Errorf("%s is not a directory", dirs[0])
+	}
+
+	srv := &tableServer{
+		dir:      dirs[0],
+		encoding: *encoding,
+		cache:    newQueryCache(*cacheSize),
+	}
+	if *metrics {
+		srv.metrics = newServeMetrics()
+	}
+	addr := fmt.Sprintf("%s:%d", *host, *port)
+	log.Printf("Serving DBF files from %s on http://%s", dirs[0], addr)
+	return http.ListenAndServe(addr, srv)
+}
+
+// tableServer is an http.Handler exposing the DBF files in dir:
+//
+//	GET /tables                  list of tables
+//	GET /tables/{name}           table schema
+//	GET /tables/{name}/records   records, with pagination and filters
+//	GET /tables/{name}/tail      WebSocket streaming the records appended to the table
+//	GET /metrics                 Prometheus metrics, when enabled
+//
+// Tables are opened per request so changes to the files are picked up immediately.
+// Results of the records endpoint are cached until the table changes.
+type tableServer struct {
+	dir      string
+	encoding string
+	metrics  *serveMetrics // nil when metrics are disabled
+	cache    *queryCache   // nil when the cache is disabled
+}
+
+// tableInfo describes a table in the listing and schema responses
+type tableInfo struct {
+	Name       string      `json:"name"`
+	File       string      `json:"file"`
+	NumRecords uint32      `json:"num_records"`
+	Modified   time.Time   `json:"modified"`
+	Fields     []fieldInfo `json:"fields,omitempty"`
+}
+
+// fieldInfo describes a field in the schema response
+type fieldInfo struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Len      uint8  `json:"len"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// recordPage is the JSON response of the records endpoint
+type recordPage struct {
+	Table     string                   `json:"table"`
+	Offset    int                      `json:"offset"`
+	Limit     int                      `json:"limit"`
+	Total     int                      `json:"total"`
+	Next      string                   `json:"next,omitempty"`
+	NextToken string                   `json:"next_token,omitempty"`
+	Records   []map[string]interface{} `json:"records"`
+}
+
+// recordResult is a page of records in the JSON and CSV form, as kept by the queryCache
+type recordResult struct {
+	page  recordPage
+	names []string
+	rows  [][]string
+}
+
+func (s *tableServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.metrics == nil {
+		s.route(w, r)
+		return
+	}
+	if r.URL.Path == "/metrics" {
+		s.metrics.ServeHTTP(w, r)
+		s.metrics.request("metrics", http.StatusOK)
+		return
+	}
+	rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+	s.route(rec, r)
+	s.metrics.request(endpointName(r.URL.Path), rec.code)
+}
+
+// route passes a request to the handler of its endpoint
+func (s *tableServer) route(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		httpError(w, http.StatusMethodNotAllowed, "only GET is supported")
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && (parts[0] == "" || parts[0] == "tables"):
+		s.serveTables(w, r)
+	case len(parts) == 2 && parts[0] == "tables":
+		s.serveSchema(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "tables" && parts[2] == "records":
+		s.serveRecords(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "tables" && parts[2] == "tail":
+		s.serveTail(w, r, parts[1])
+	default:
+		httpError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// tableFiles returns all DBF files in the directory by lowercase table name
+func (s *tableServer) tableFiles() (map[string]string, error) {
+	return dbfFiles(s.dir)
+}
+
+// dbfFiles returns all DBF files in dir by lowercase table name
+func dbfFiles(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".dbf") {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+		files[name] = filepath.Join(dir, e.Name())
+	}
+	return files, nil
+}
+
+// tableFile returns the file of the table by name, names are matched case-insensitive.
+// Only files in the served directory are returned.
+func (s *tableServer) tableFile(name string) (string, error) {
+	files, err := s.tableFiles()
+	if err != nil {
+		return "", err
+	}
+	filename, ok := files[strings.ToLower(name)]
+	if !ok {
+		return "", errTableNotFound
+	}
+	return filename, nil
+}
+
+// openTable opens the table by name, see tableFile
+func (s *tableServer) openTable(name string) (*dbf.DBF, string, error) {
+	filename, err := s.tableFile(name)
+	if err != nil {
+		return nil, "", err
+	}
+	d, err := openTable(filename, s.encoding)
+	if err == nil {
+		s.metrics.tableOpened(1)
+	}
+	return d, filename, err
+}
+
+// closeTable closes a table opened by openTable
+func (s *tableServer) closeTable(d *dbf.DBF) {
+	d.Close()
+	s.metrics.tableOpened(-1)
+}
+
+var errTableNotFound = errors.New("table not found")
+
+func (s *tableServer) serveTables(w http.ResponseWriter, r *http.Request) {
+	files, err := s.tableFiles()
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tables := make([]tableInfo, 0, len(names))
+	for _, name := range names {
+		info := tableInfo{Name: name, File: filepath.Base(files[name])}
+		// tables which cannot be opened are listed without details
+		if d, err := openTable(files[name], s.encoding); err == nil {
+			s.metrics.tableOpened(1)
+			info.NumRecords = d.NumRecords()
+			info.Modified = d.Header().Modified()
+			s.closeTable(d)
+		}
+		tables = append(tables, info)
+	}
+	writeJSON(w, tables)
+}
+
+func (s *tableServer) serveSchema(w http.ResponseWriter, r *http.Request, name string) {
+	d, filename, err := s.openTable(name)
+	if err != nil {
+		tableError(w, err)
+		return
+	}
+	defer s.closeTable(d)
+
+	info := tableInfo{
+		Name:       strings.ToLower(name),
+		File:       filepath.Base(filename),
+		NumRecords: d.NumRecords(),
+		Modified:   d.Header().Modified(),
+	}
+	for _, f := range d.Fields() {
+		info.Fields = append(info.Fields, fieldInfo{
+			Name:     f.FieldName(),
+			Type:     f.FieldType(),
+			Len:      f.Len,
+			Decimals: f.Decimals,
+		})
+	}
+	writeJSON(w, info)
+}
+
+// serveRecords writes a page of records as JSON or CSV.
+// Query parameters:
+//
+//	offset, limit   pagination over the matching records
+//	page_token      continue with the page of the next_token of the previous response
+//	format          json or csv, overrides the Accept header
+//	deleted         include deleted records when true
+//	select          comma separated fields to return, default all fields
+//	sort            comma separated fields to sort on, -FIELD sorts descending, default table order
+//	FIELD=value     only records where the trimmed field value equals value
+//	FIELD.op=value  only records where the field compares to value with op: eq, ne, gt, gte, lt or lte,
+//	                the range operators compare numbers, dates (YYYY-MM-DD) and logicals by value
+func (s *tableServer) serveRecords(w http.ResponseWriter, r *http.Request, name string) {
+	filename, err := s.tableFile(name)
+	if err != nil {
+		tableError(w, err)
+		return
+	}
+	// the path is part of the key because the next link contains it
+	key := r.URL.Path + "?" + r.URL.Query().Encode()
+	state, err := readTableState(filename)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	res := s.cache.get(key, state)
+	if res != nil {
+		s.metrics.cacheLookup(true)
+	} else {
+		if s.cache != nil {
+			s.metrics.cacheLookup(false)
+		}
+		var status int
+		res, status, err = s.queryRecords(r, name)
+		if err != nil {
+			httpError(w, status, err.Error())
+			return
+		}
+		// the state from before the query, so a table which changed during the query is queried again
+		s.cache.put(key, state, res)
+	}
+	s.metrics.rowsServed(res.page.Table, len(res.rows))
+
+	if wantsCSV(r) {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("X-Total-Count", strconv.Itoa(res.page.Total))
+		if res.page.Next != "" {
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", res.page.Next))
+		}
+		cw := csv.NewWriter(w)
+		cw.Write(res.names)
+		cw.WriteAll(res.rows)
+		return
+	}
+	writeJSON(w, res.page)
+}
+
+// queryRecords scans the table for the page of records of a records request.
+// On failure it returns the HTTP status for the error.
+func (s *tableServer) queryRecords(r *http.Request, name string) (*recordResult, int, error) {
+	d, _, err := s.openTable(name)
+	if err != nil {
+		if err == errTableNotFound {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	defer s.closeTable(d)
+
+	q, err := parseRecordQuery(r.URL.Query(), d)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	fields := d.Fields()
+	allNames := d.FieldNames()
+	res := &recordResult{names: make([]string, len(q.selected))}
+	for i, pos := range q.selected {
+		res.names[i] = allNames[pos]
+	}
+	page := &res.page
+	*page = recordPage{
+		Table:   strings.ToLower(name),
+		Offset:  q.offset,
+		Limit:   q.limit,
+		Records: make([]map[string]interface{}, 0),
+	}
+
+	// without sorting only the records of the page are kept
+	var matches [][]interface{}
+	start := time.Now()
+	for i := uint32(0); i < d.NumRecords(); i++ {
+		rec, err := d.RecordAt(i)
+		if err != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("record %d: %v", i, err)
+		}
+		if rec.Deleted && !q.withDeleted {
+			continue
+		}
+		values := rec.FieldSlice()
+		if !q.match(values, fields) {
+			continue
+		}
+		page.Total++
+		if len(q.sort) == 0 && (page.Total <= q.offset || page.Total > q.offset+q.limit) {
+			continue
+		}
+		matches = append(matches, values)
+	}
+	if len(q.sort) > 0 {
+		q.sortRecords(matches)
+		from, to := q.offset, q.offset+q.limit
+		if from > len(matches) {
+			from = len(matches)
+		}
+		if to > len(matches) {
+			to = len(matches)
+		}
+		matches = matches[from:to]
+	}
+	s.metrics.scanned(page.Table, time.Since(start))
+
+	res.rows = make([][]string, len(matches))
+	for i, values := range matches {
+		selected := make([]interface{}, len(q.selected))
+		res.rows[i] = make([]string, len(q.selected))
+		for j, pos := range q.selected {
+			selected[j] = values[pos]
+			res.rows[i][j] = formatValueForCSV(values[pos], fields[pos])
+		}
+		page.Records = append(page.Records, jsonRow(res.names, selected))
+	}
+
+	if q.offset+q.limit < page.Total {
+		page.NextToken = encodePageToken(q.offset + q.limit)
+		next := r.URL.Query()
+		next.Del("offset")
+		next.Set("page_token", page.NextToken)
+		page.Next = r.URL.Path + "?" + next.Encode()
+	}
+	return res, http.StatusOK, nil
+}
+
+// jsonRow returns the values of a record by field name, with trimmed strings
+func jsonRow(names []string, values []interface{}) map[string]interface{} {
+	row := make(map[string]interface{}, len(values))
+	for j, val := range values {
+		if str, ok := val.(string); ok {
+			val = strings.TrimSpace(str)
+		}
+		row[names[j]] = val
+	}
+	return row
+}
+
+// wantsCSV returns if the client asked for CSV, using the format parameter or the Accept header
+func wantsCSV(r *http.Request) bool {
+	switch r.URL.Query().Get("format") {
+	case "csv":
+		return true
+	case "json":
+		return false
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "text/csv") && !strings.Contains(accept, "application/json")
+}
+
+func queryInt(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		log.Printf("error writing response: %v", err)
+	}
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+func tableError(w http.ResponseWriter, err error) {
+	if err == errTableNotFound {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	httpError(w, http.StatusInternalServerError, err.Error())
+}
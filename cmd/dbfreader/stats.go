@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runStats prints the record counts and column statistics of a table.
+// With --cache the statistics are kept in a .stats file next to the table and reused while the table is unchanged.
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "write the statistics as JSON")
+	cache := fs.Bool("cache", false, "reuse and update the statistics cache file next to the table")
+	top := fs.Int("top", 5, "number of most frequent values per field")
+	includeDeleted := fs.Bool("include-deleted", false, "also include deleted records in the column statistics")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+
+	opts := dbf.ProfileOptions{TopK: *top, IncludeDeleted: *includeDeleted}
+	var stats *dbf.TableStats
+	if *cache {
+		dec, err := newDecoder(*encoding)
+		if err != nil {
+			return err
+		}
+		stats, err = dbf.CachedStats(files[0], dec, opts)
+		if err != nil {
+			return err
+		}
+	} else {
+		d, err := openTable(files[0], *encoding)
+		if err != nil {
+			return err
+		}
+		defer d.Close()
+		stats, err = d.Stats(opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	fmt.Printf("Records:  %d\n", stats.Records)
+	fmt.Printf("Deleted:  %d\n", stats.Deleted)
+	if stats.Cached {
+		fmt.Println("(from cache)")
+	}
+	for _, c := range stats.Columns {
+		fmt.Printf("%-10s %s: %d values, %d empty, %d distinct", c.Field, c.Type, c.Count, c.Empty, c.Distinct)
+		if c.Buckets != nil {
+			fmt.Printf(", min %v, max %v", c.Min, c.Max)
+		}
+		fmt.Println()
+		for _, v := range c.TopValues {
+			fmt.Printf("  %6d  %q\n", v.Count, v.Value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+const (
+	defaultTailInterval = time.Second
+	minTailInterval     = 100 * time.Millisecond
+)
+
+// tailMessage is a WebSocket message of the tail endpoint, either a record or an error
+type tailMessage struct {
+	RecNo   uint32                 `json:"recno"`
+	Deleted bool                   `json:"deleted,omitempty"`
+	Record  map[string]interface{} `json:"record,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// serveTail streams the records appended to a table over a WebSocket, every record is one JSON message.
+// Query parameters:
+//
+//	from       record count to start after, the default is the current record count (only new records)
+//	interval   how often the table is checked for changes, like 500ms (default 1s)
+//	deleted    include deleted records when true
+//
+// When the table is packed or zapped an error message is sent and streaming continues after the new last record.
+// When the structure of the table changes an error message is sent and the connection is closed.
+func (s *tableServer) serveTail(w http.ResponseWriter, r *http.Request, name string) {
+	d, _, err := s.openTable(name)
+	if err != nil {
+		tableError(w, err)
+		return
+	}
+	defer s.closeTable(d)
+
+	query := r.URL.Query()
+	last := d.NumRecords()
+	if from := query.Get("from"); from != "" {
+		n, err := strconv.ParseUint(from, 10, 32)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, "invalid from")
+			return
+		}
+		last = uint32(n)
+	}
+	interval := defaultTailInterval
+	if value := query.Get("interval"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval < minTailInterval {
+			httpError(w, http.StatusBadRequest, fmt.Sprintf("invalid interval, use at least %s", minTailInterval))
+			return
+		}
+	}
+	withDeleted := query.Get("deleted") == "true"
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer ws.close()
+
+	// the request context is not cancelled for hijacked connections, the read loop ends when the client leaves
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		ws.readLoop()
+		cancel()
+	}()
+
+	events, err := d.Watch(ctx, interval)
+	if err != nil {
+		ws.writeJSON(tailMessage{Error: err.Error()})
+		return
+	}
+	names := d.FieldNames()
+	send := func() error {
+		records, next, err := d.ReadNewSince(last)
+		for i, rec := range records {
+			if rec.Deleted && !withDeleted {
+				continue
+			}
+			msg := tailMessage{RecNo: last + uint32(i), Deleted: rec.Deleted, Record: jsonRow(names, rec.FieldSlice())}
+			if err := ws.writeJSON(msg); err != nil {
+				return err
+			}
+			s.metrics.rowsServed(strings.ToLower(name), 1)
+		}
+		last = next
+		switch err {
+		case nil:
+			return nil
+		case dbf.ErrRecordCountDecreased:
+			last = d.NumRecords()
+		case dbf.ErrStructureChanged:
+			ws.writeJSON(tailMessage{RecNo: last, Error: err.Error()})
+			return err
+		}
+		return ws.writeJSON(tailMessage{RecNo: last, Error: err.Error()})
+	}
+
+	// records after from which are already in the table
+	if err := send(); err != nil {
+		return
+	}
+	for event := range events {
+		if event.Err != nil && event.Err != dbf.ErrStructureChanged {
+			log.Printf("tail %s: %v", name, event.Err)
+			continue
+		}
+		if err := send(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	dbf "github.com/SebastiaanKlippert/go-foxpro-dbf"
+)
+
+// runTemplate executes a Go template for every record, writing all output to one file
+// or one file per record
+func runTemplate(args []string) error {
+	fs := flag.NewFlagSet("template", flag.ExitOnError)
+	templateFile := fs.String("template", "", "template file, executed for every record (required)")
+	out := fs.String("out", "", "file to write the output of all records to (default stdout)")
+	outDir := fs.String("out-dir", "", "directory to write one file per record to")
+	name := fs.String("name", "{{._RECNO}}.txt", "template of the file names in --out-dir")
+	html := fs.Bool("html", false, "use html/template, which escapes values for HTML")
+	encoding := fs.String("encoding", "win1250", "table encoding: win1250, big5 or utf8")
+	withDeleted := fs.Bool("deleted", false, "also execute the template for deleted records")
+	locale := fs.String("locale", "", "render the values in ._TEXT for a locale, like de")
+
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("expected exactly one DBF file")
+	}
+	if *templateFile == "" {
+		return errors.New("--template is required")
+	}
+	if *out != "" && *outDir != "" {
+		return errors.New("use either --out or --out-dir")
+	}
+	format, err := parseFormatOptions(*locale, "", "", "", "", "")
+	if err != nil {
+		return err
+	}
+	if format == nil {
+		format = new(dbf.FormatOptions)
+	}
+
+	text, err := os.ReadFile(*templateFile)
+	if err != nil {
+		return err
+	}
+	tmpl, err := parseRecordTemplate(filepath.Base(*templateFile), string(text), *html)
+	if err != nil {
+		return err
+	}
+	var nameTmpl *template.Template
+	if *outDir != "" {
+		if nameTmpl, err = template.New("name").Funcs(templateFuncs).Parse(*name); err != nil {
+			return fmt.Errorf("--name: %v", err)
+		}
+		if err := os.MkdirAll(*outDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	d, err := openTable(files[0], *encoding)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	// all output goes to one writer, unless a file is written per record
+	var w *bufio.Writer
+	if *outDir == "" {
+		dst := io.Writer(os.Stdout)
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			dst = f
+		}
+		w = bufio.NewWriter(dst)
+		defer w.Flush()
+	}
+
+	table := map[string]interface{}{
+		"File":       filepath.Base(files[0]),
+		"Fields":     d.FieldNames(),
+		"NumRecords": d.NumRecords(),
+	}
+	if w != nil && tmpl.has("header") {
+		if err := tmpl.execute(w, "header", table); err != nil {
+			return err
+		}
+	}
+
+	written := 0
+	for i := uint32(0); i < d.NumRecords(); i++ {
+		rec, err := d.RecordAt(i)
+		if err != nil {
+			return fmt.Errorf("record %d: %v", i, err)
+		}
+		if rec.Deleted && !*withDeleted {
+			continue
+		}
+		data := templateData(d, i, rec, format)
+
+		if w != nil {
+			if err := tmpl.execute(w, "", data); err != nil {
+				return fmt.Errorf("record %d: %v", i, err)
+			}
+			written++
+			continue
+		}
+		var buf bytes.Buffer
+		if err := nameTmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("record %d: file name: %v", i, err)
+		}
+		filename := sanitizeFilename(strings.TrimSpace(buf.String()))
+		buf.Reset()
+		if err := tmpl.execute(&buf, "", data); err != nil {
+			return fmt.Errorf("record %d: %v", i, err)
+		}
+		if err := os.WriteFile(filepath.Join(*outDir, filename), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		written++
+	}
+
+	if w != nil && tmpl.has("footer") {
+		if err := tmpl.execute(w, "footer", table); err != nil {
+			return err
+		}
+	}
+	if *out != "" || *outDir != "" {
+		fmt.Fprintf(os.Stderr, "Executed the template for %d records\n", written)
+	}
+	return nil
+}
+
+// recordTemplate is a text/template or html/template template
+type recordTemplate struct {
+	execute func(w io.Writer, name string, data interface{}) error // an empty name executes the main template
+	has     func(name string) bool
+}
+
+// parseRecordTemplate parses the template text with text/template, or html/template if html is set
+func parseRecordTemplate(name, text string, html bool) (*recordTemplate, error) {
+	if html {
+		t, err := htmltemplate.New(name).Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		return &recordTemplate{
+			execute: func(w io.Writer, name string, data interface{}) error {
+				if name == "" {
+					return t.Execute(w, data)
+				}
+				return t.ExecuteTemplate(w, name, data)
+			},
+			has: func(name string) bool { return t.Lookup(name) != nil },
+		}, nil
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &recordTemplate{
+		execute: func(w io.Writer, name string, data interface{}) error {
+			if name == "" {
+				return t.Execute(w, data)
+			}
+			return t.ExecuteTemplate(w, name, data)
+		},
+		has: func(name string) bool { return t.Lookup(name) != nil },
+	}, nil
+}
+
+// templateData returns the context of a record for the templates: the values by field name with the spaces of
+// C fields trimmed, _RECNO with the record number, _DELETED with the deletion flag and _TEXT with the values
+// rendered as text by format
+func templateData(d *dbf.DBF, recno uint32, rec *dbf.Record, format *dbf.FormatOptions) map[string]interface{} {
+	data := make(map[string]interface{}, d.NumFields()+3)
+	text := make(map[string]string, d.NumFields())
+	for i, f := range d.Fields() {
+		val, _ := rec.Field(i)
+		text[f.FieldName()] = format.FormatValue(val, &f)
+		if f.Type == 'C' {
+			val = dbf.ToTrimmedString(val)
+		}
+		data[f.FieldName()] = val
+	}
+	data["_RECNO"] = recno
+	data["_DELETED"] = rec.Deleted
+	data["_TEXT"] = text
+	return data
+}
+
+// templateFuncs are the functions available in the templates, next to the standard ones like printf
+var templateFuncs = template.FuncMap{
+	"trim":  strings.TrimSpace,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// date formats a D or T value with a Go layout, empty dates give an empty string
+	"date": func(layout string, val interface{}) string {
+		t := dbf.ToTime(val)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	},
+	// left returns the first n characters, to fit values in fixed width segments
+	"left": func(n int, s string) string {
+		if utf8.RuneCountInString(s) <= n {
+			return s
+		}
+		return string([]rune(s)[:n])
+	},
+	"now": time.Now,
+}
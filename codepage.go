@@ -0,0 +1,67 @@
+package dbf
+
+import "fmt"
+
+// CodePageInfo describes the code page mark (language driver id) stored in the DBF header
+type CodePageInfo struct {
+	Mark        byte   // Code page mark as stored in the header
+	Number      int    // Code page number, 0 when the mark has no code page or is unknown
+	Name        string // Short charset name, for example Big5 or Windows-1250
+	Description string // Description as used by FoxPro, for example Eastern European Windows
+}
+
+// codePages are the code page marks supported by Visual FoxPro
+// from https://docs.microsoft.com/en-us/previous-versions/visualstudio/foxpro/8t45x02s(v=vs.80)
+var codePages = map[byte]CodePageInfo{
+	0x01: {Number: 437, Name: "IBM437", Description: "U.S. MS-DOS"},
+	0x69: {Number: 620, Name: "Mazovia", Description: "Mazovia (Polish) MS-DOS"},
+	0x6A: {Number: 737, Name: "IBM737", Description: "Greek MS-DOS (437G)"},
+	0x02: {Number: 850, Name: "IBM850", Description: "International MS-DOS"},
+	0x64: {Number: 852, Name: "IBM852", Description: "Eastern European MS-DOS"},
+	0x6B: {Number: 857, Name: "IBM857", Description: "Turkish MS-DOS"},
+	0x67: {Number: 861, Name: "IBM861", Description: "Icelandic MS-DOS"},
+	0x66: {Number: 865, Name: "IBM865", Description: "Nordic MS-DOS"},
+	0x65: {Number: 866, Name: "IBM866", Description: "Russian MS-DOS"},
+	0x68: {Number: 895, Name: "Kamenicky", Description: "Kamenicky (Czech) MS-DOS"},
+	0x7C: {Number: 874, Name: "Windows-874", Description: "Thai Windows"},
+	0x7B: {Number: 932, Name: "Shift_JIS", Description: "Japanese Windows"},
+	0x7A: {Number: 936, Name: "GBK", Description: "Chinese (PRC, Singapore) Windows"},
+	0x79: {Number: 949, Name: "Windows-949", Description: "Korean Windows"},
+	0x78: {Number: 950, Name: "Big5", Description: "Chinese (Hong Kong SAR, Taiwan) Windows"},
+	0xC8: {Number: 1250, Name: "Windows-1250", Description: "Eastern European Windows"},
+	0xC9: {Number: 1251, Name: "Windows-1251", Description: "Russian Windows"},
+	0x03: {Number: 1252, Name: "Windows-1252", Description: "Windows ANSI"},
+	0xCB: {Number: 1253, Name: "Windows-1253", Description: "Greek Windows"},
+	0xCA: {Number: 1254, Name: "Windows-1254", Description: "Turkish Windows"},
+	0x7D: {Number: 1255, Name: "Windows-1255", Description: "Hebrew Windows"},
+	0x7E: {Number: 1256, Name: "Windows-1256", Description: "Arabic Windows"},
+	0x04: {Number: 10000, Name: "Macintosh", Description: "Standard Macintosh"},
+	0x98: {Number: 10006, Name: "MacGreek", Description: "Greek Macintosh"},
+	0x96: {Number: 10007, Name: "MacCyrillic", Description: "Russian Macintosh"},
+	0x97: {Number: 10029, Name: "MacCentralEurope", Description: "Macintosh EE"},
+}
+
+// LookupCodePage returns the code page of a code page mark, ok is false for unknown marks and for 0x00 (no code page)
+func LookupCodePage(mark byte) (info CodePageInfo, ok bool) {
+	info, ok = codePages[mark]
+	info.Mark = mark
+	return info, ok
+}
+
+// CodePageInfo returns the code page of the code page mark in the header
+func (h *DBFHeader) CodePageInfo() CodePageInfo {
+	info, _ := LookupCodePage(h.CodePage)
+	return info
+}
+
+// String returns the code page number and name, for example "950 Big5"
+func (c CodePageInfo) String() string {
+	switch {
+	case c.Number != 0:
+		return fmt.Sprintf("%d %s", c.Number, c.Name)
+	case c.Mark == 0:
+		return "none"
+	default:
+		return fmt.Sprintf("unknown (0x%02X)", c.Mark)
+	}
+}
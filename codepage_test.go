@@ -0,0 +1,36 @@
+package dbf
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCodePageInfo(t *testing.T) {
+	d, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	info := d.Header().CodePageInfo()
+	if info.Mark != 0x03 || info.Number != 1252 || info.String() != "1252 Windows-1252" {
+		t.Errorf("Want code page 1252, have %+v", info)
+	}
+
+	tests := []struct {
+		mark byte
+		ok   bool
+		want string
+	}{
+		{0x78, true, "950 Big5"},
+		{0xC8, true, "1250 Windows-1250"},
+		{0x00, false, "none"},
+		{0xFE, false, "unknown (0xFE)"},
+	}
+	for _, test := range tests {
+		info, ok := LookupCodePage(test.mark)
+		if ok != test.ok || info.String() != test.want || info.Mark != test.mark {
+			t.Errorf("0x%02X: want %s (%t), have %s (%t)", test.mark, test.want, test.ok, info, ok)
+		}
+	}
+}
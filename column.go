@@ -0,0 +1,254 @@
+package dbf
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// The column functions read all values of one field, for analytical reads of a few fields of many records.
+// The type of the field is checked before the first record is read, the records are read forward through
+// a buffer like with a Scanner and numeric, date and logical values are converted without interface{} values.
+// Deleted records are skipped and null values are returned as the zero value.
+
+// Column returns the values of field name as T, which is one of the types supported by NewValues
+func Column[T any](dbf *DBF, name string) ([]T, error) {
+	v, err := NewValues[T](dbf, name)
+	if err != nil {
+		return nil, err
+	}
+	col := make([]T, 0, dbf.columnCap())
+	for v.Next() {
+		val, err := v.Value()
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", v.RecNo(), err)
+		}
+		col = append(col, val)
+	}
+	return col, v.Err()
+}
+
+// Int64Column returns the values of an I field or an N field without decimals
+func (dbf *DBF) Int64Column(name string) ([]int64, error) {
+	return Column[int64](dbf, name)
+}
+
+// Float64Column returns the values of a B, F, N, Y or I field
+func (dbf *DBF) Float64Column(name string) ([]float64, error) {
+	return Column[float64](dbf, name)
+}
+
+// TimeColumn returns the values of a D or T field
+func (dbf *DBF) TimeColumn(name string) ([]time.Time, error) {
+	return Column[time.Time](dbf, name)
+}
+
+// BoolColumn returns the values of an L field
+func (dbf *DBF) BoolColumn(name string) ([]bool, error) {
+	return Column[bool](dbf, name)
+}
+
+// StringColumn returns the values of a C or M field, C values are not trimmed
+func (dbf *DBF) StringColumn(name string) ([]string, error) {
+	return Column[string](dbf, name)
+}
+
+// columnCap returns the capacity for the values of all records, the record count in the header is
+// not trusted beyond the records which fit in the file
+func (dbf *DBF) columnCap() int {
+	size, err := dbf.r.Seek(0, io.SeekEnd)
+	if err != nil || dbf.header.RecLen == 0 || size < int64(dbf.header.FirstRec) {
+		return 0
+	}
+	if n := (size - int64(dbf.header.FirstRec)) / int64(dbf.header.RecLen); n < int64(dbf.header.NumRec) {
+		return int(n)
+	}
+	return int(dbf.header.NumRec)
+}
+
+// valueFunc returns the conversion of the data of the field at pos to T,
+// or an error if the field type can not be read as T
+func valueFunc[T any](dbf *DBF, pos int) (func(raw []byte) (T, error), error) {
+	f := &dbf.fields[pos]
+	var conv interface{}
+	ok := false
+	switch interface{}((*T)(nil)).(type) {
+	case *int64:
+		ok = f.Type == 'I' || f.Type == 'N' && f.Decimals == 0
+		conv = func(raw []byte) (int64, error) { return dbf.int64Value(raw, pos) }
+	case *float64:
+		switch f.Type {
+		case 'B', 'F', 'N', 'Y', 'I':
+			ok = true
+		}
+		conv = func(raw []byte) (float64, error) { return dbf.float64Value(raw, pos) }
+	case *time.Time:
+		ok = f.Type == 'D' || f.Type == 'T'
+		conv = func(raw []byte) (time.Time, error) { return dbf.timeValue(raw, pos) }
+	case *bool:
+		ok = f.Type == 'L'
+		conv = func(raw []byte) (bool, error) { return dbf.boolValue(raw, pos) }
+	case *string:
+		ok = f.Type == 'C' || f.Type == 'M'
+		conv = func(raw []byte) (string, error) { return dbf.stringValue(raw, pos) }
+	case *interface{}:
+		ok = true
+		conv = func(raw []byte) (interface{}, error) { return dbf.fieldValue(raw, pos) }
+	default:
+		var zero T
+		return nil, fmt.Errorf("values of type %T are not supported", zero)
+	}
+	if !ok {
+		var zero T
+		return nil, typedFieldError(f, fmt.Sprintf("%T", zero))
+	}
+	return conv.(func(raw []byte) (T, error)), nil
+}
+
+// Values iterates over the values of one field of all records, it is used like a Scanner:
+//
+//	v, err := dbf.NewValues[float64](d, "AMOUNT")
+//	if err != nil {
+//		return err
+//	}
+//	for v.Next() {
+//		amount, err := v.Value()
+//		...
+//	}
+//	if err := v.Err(); err != nil {
+//		return err
+//	}
+//
+// Value returns the value of the current record as T. The methods of the other types return an error
+// if the field type can not be read as that type. One read buffer is used for all records.
+type Values[T any] struct {
+	// IncludeDeleted also returns the values of deleted records, by default they are skipped
+	IncludeDeleted bool
+
+	dbf   *DBF
+	pos   int
+	conv  func(raw []byte) (T, error)
+	s     *Scanner
+	buf   []byte
+	recno uint32
+	raw   []byte // field data of the current record
+	null  bool   // the value of the current record is null
+}
+
+// NewValues returns an iterator over the values of field name as T, of the records which are in the table when it is called.
+// T can be int64 for I fields and N fields without decimals, float64 for B, F, N, Y and I fields, time.Time for
+// D and T fields, bool for L fields, string for C and M fields or interface{} for all fields.
+// The field type is checked before the first record is read. The internal record pointer is not used or moved.
+func NewValues[T any](dbf *DBF, name string) (*Values[T], error) {
+	pos := dbf.FieldPos(name)
+	if pos < 0 {
+		return nil, fmt.Errorf("field %s not found", name)
+	}
+	conv, err := valueFunc[T](dbf, pos)
+	if err != nil {
+		return nil, err
+	}
+	return &Values[T]{dbf: dbf, pos: pos, conv: conv, s: dbf.Scanner(), buf: make([]byte, dbf.header.RecLen)}, nil
+}
+
+// Values returns an iterator over the values of field name, Value returns them as interface{} like Field.
+// See NewValues for an iterator of one type.
+func (dbf *DBF) Values(name string) (*Values[interface{}], error) {
+	return NewValues[interface{}](dbf, name)
+}
+
+// Next advances to the value of the next record, it returns false after the last record or when an error occurred
+func (v *Values[T]) Next() bool {
+	v.s.IncludeDeleted = v.IncludeDeleted
+	recno, data, ok := v.s.nextData(v.buf)
+	if !ok {
+		return false
+	}
+	v.recno = recno
+	v.raw = v.dbf.layout[v.pos].data(data)
+	v.null = isNull(v.dbf.nullFlags(data), v.dbf.layout[v.pos].null)
+	return true
+}
+
+// RecNo returns the record number of the current value
+func (v *Values[T]) RecNo() uint32 {
+	return v.recno
+}
+
+// IsNull returns if the current value is null, the typed methods return the zero value for null values
+func (v *Values[T]) IsNull() bool {
+	return v.null
+}
+
+// Value returns the current value as T
+func (v *Values[T]) Value() (T, error) {
+	if v.null {
+		var zero T
+		return zero, nil
+	}
+	return v.conv(v.raw)
+}
+
+// Int64 returns the current value of an I field or an N field without decimals
+func (v *Values[T]) Int64() (int64, error) {
+	if v.null {
+		return 0, nil
+	}
+	return v.dbf.int64Value(v.raw, v.pos)
+}
+
+// Float64 returns the current value of a B, F, N, Y or I field
+func (v *Values[T]) Float64() (float64, error) {
+	if v.null {
+		return 0, nil
+	}
+	return v.dbf.float64Value(v.raw, v.pos)
+}
+
+// Time returns the current value of a D or T field
+func (v *Values[T]) Time() (time.Time, error) {
+	if v.null {
+		return time.Time{}, nil
+	}
+	return v.dbf.timeValue(v.raw, v.pos)
+}
+
+// Bool returns the current value of an L field
+func (v *Values[T]) Bool() (bool, error) {
+	if v.null {
+		return false, nil
+	}
+	return v.dbf.boolValue(v.raw, v.pos)
+}
+
+// String returns the current value of a C or M field, memos are read from the memo file
+func (v *Values[T]) String() (string, error) {
+	if v.null {
+		return "", nil
+	}
+	return v.dbf.stringValue(v.raw, v.pos)
+}
+
+// stringValue returns the value of a C or M field as string
+func (dbf *DBF) stringValue(raw []byte, pos int) (string, error) {
+	f := &dbf.fields[pos]
+	if f.Type != 'C' && f.Type != 'M' {
+		return "", typedFieldError(f, "string")
+	}
+	val, err := dbf.fieldValue(raw, pos)
+	if err != nil {
+		return "", err
+	}
+	switch s := val.(type) {
+	case string:
+		return s, nil
+	case []byte:
+		return string(s), nil
+	}
+	return "", nil
+}
+
+// Err returns the first error that occurred while reading the records
+func (v *Values[T]) Err() error {
+	return v.s.Err()
+}
@@ -0,0 +1,209 @@
+package dbf
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColumns(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	ids, err := dbf.Int64Column("ID_NR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{100, 6425887, 0}) {
+		t.Errorf("Unexpected ID_NR %v", ids)
+	}
+	numbers, err := dbf.Float64Column("NUMBER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(numbers, []float64{1.66, 0, 0}) {
+		t.Errorf("Unexpected NUMBER %v", numbers)
+	}
+	dates, err := dbf.TimeColumn("DATUM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dates) != 3 || !dates[0].Equal(time.Date(2015, 1, 3, 0, 0, 0, 0, time.UTC)) || !dates[2].IsZero() {
+		t.Errorf("Unexpected DATUM %v", dates)
+	}
+	bools, err := dbf.BoolColumn("BOOL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bools, []bool{false, false, true}) {
+		t.Errorf("Unexpected BOOL %v", bools)
+	}
+	names, err := dbf.StringColumn("COMP_OS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 || strings.TrimSpace(names[1]) != "Windows 7 SP1" {
+		t.Errorf("Unexpected COMP_OS %q", names)
+	}
+	memos, err := dbf.StringColumn("MELDING")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memos[0] != "Message line 1\r\nMessage line 2" {
+		t.Errorf("Unexpected MELDING %q", memos[0])
+	}
+
+	// the field type is checked before reading
+	if _, err := dbf.Int64Column("NUMBER"); err == nil {
+		t.Error("Want an error reading N field with decimals as int64")
+	}
+	if _, err := dbf.BoolColumn("DATUM"); err == nil {
+		t.Error("Want an error reading D field as bool")
+	}
+	if _, err := dbf.StringColumn("MISSING"); err == nil {
+		t.Error("Want an error for a missing field")
+	}
+}
+
+func TestValues(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	v, err := dbf.Values("ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.IncludeDeleted = true
+	var recnos []uint32
+	for v.Next() {
+		if _, err := v.Int64(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := v.Time(); err == nil {
+			t.Error("Want an error reading I field as time")
+		}
+		if v.IsNull() {
+			t.Errorf("Record %d: unexpected null", v.RecNo())
+		}
+		recnos = append(recnos, v.RecNo())
+	}
+	if err := v.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recnos, []uint32{0, 1, 2, 3}) {
+		t.Errorf("Want all records, have %v", recnos)
+	}
+
+	// null values are zero
+	nt := nullTable(t)
+	v, err = nt.Values("BORN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nulls []bool
+	for v.Next() {
+		if _, err := v.Time(); err != nil {
+			t.Fatal(err)
+		}
+		nulls = append(nulls, v.IsNull())
+	}
+	if !reflect.DeepEqual(nulls, []bool{false, true}) {
+		t.Errorf("Unexpected nulls %v", nulls)
+	}
+}
+
+func TestGenericColumn(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	ids, err := Column[int64](dbf, "ID_NR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{100, 6425887, 0}) {
+		t.Errorf("Unexpected ID_NR %v", ids)
+	}
+	vals, err := Column[interface{}](dbf, "NUMBER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vals, []interface{}{1.66, float64(0), float64(0)}) {
+		t.Errorf("Unexpected NUMBER %v", vals)
+	}
+
+	v, err := NewValues[time.Time](dbf, "DATUM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dates []time.Time
+	for v.Next() {
+		date, err := v.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dates = append(dates, date)
+	}
+	if err := v.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(dates) != 3 || !dates[0].Equal(time.Date(2015, 1, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected DATUM %v", dates)
+	}
+
+	// the field type and T are checked before reading
+	if _, err := NewValues[string](dbf, "NUMBER"); err == nil {
+		t.Error("Want an error reading N field as string")
+	}
+	if _, err := Column[int32](dbf, "ID_NR"); err == nil || !strings.Contains(err.Error(), "int32") {
+		t.Errorf("Want an error for an unsupported type, have %v", err)
+	}
+	if _, err := Column[bool](dbf, "MISSING"); err == nil {
+		t.Error("Want an error for a missing field")
+	}
+}
+
+func TestColumnRecordCount(t *testing.T) {
+	dbfdata, fptdata := readTestFiles(t)
+	dbf, err := OpenStream(bytes.NewReader(dbfdata), bytes.NewReader(fptdata), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := dbf.columnCap(); n != 4 {
+		t.Errorf("Want capacity 4, have %d", n)
+	}
+	// a record count which the file does not have, like a table which is truncated after it was opened
+	dbf.header.NumRec = 1 << 30
+	if n := dbf.columnCap(); n != 4 {
+		t.Errorf("Want capacity 4 for a count beyond the end of the file, have %d", n)
+	}
+	if _, err := dbf.Int64Column("ID_NR"); err == nil || !strings.Contains(err.Error(), ErrIncomplete.Error()) {
+		t.Errorf("Want an incomplete read after the last record, have %v", err)
+	}
+}
+
+func BenchmarkFloat64Column(b *testing.B) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer dbf.Close()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := dbf.Float64Column("NUMBER"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package dbf
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CompactMemoTo writes a copy of the table to dbfout and a new memo file to fptout, which only contains the memos
+// the records point to. Unlike PackTo all records are kept, including the deleted records. The memo file is written
+// with blockSize, or with the block size of the table when blockSize is 0. Memos used by more than one pointer
+// are written once for every pointer.
+func (dbf *DBF) CompactMemoTo(dbfout io.Writer, fptout io.WriteSeeker, blockSize uint16) (*PackReport, error) {
+	if dbf.fptr == nil {
+		return nil, ErrNoFPTFile
+	}
+	return dbf.copyTable(dbfout, fptout, blockSize, true)
+}
+
+// CompactMemo writes a copy of the table d with a compacted memo file to outPath, the memo file is written next to it.
+// See CompactMemoTo for blockSize. The files are written with a Rewrite, so existing files at outPath are only
+// replaced when the copy is complete. outPath can not be the table itself, to compact a table in place use
+// CompactMemoTo with a Rewrite which is committed after the table is closed.
+func CompactMemo(d *DBF, outPath string, blockSize uint16) (*PackReport, error) {
+	if d.f != nil {
+		if src, err := d.f.Stat(); err == nil {
+			if dst, err := os.Stat(outPath); err == nil && os.SameFile(src, dst) {
+				return nil, errors.New("the compacted table can not replace the table itself")
+			}
+		}
+	}
+	outPath = filepath.Clean(outPath)
+	rw := NewRewrite()
+	dbfout, err := rw.Create(outPath)
+	if err != nil {
+		return nil, err
+	}
+	fptout, err := rw.Create(memoFileName(outPath))
+	if err != nil {
+		rw.Abort()
+		return nil, err
+	}
+	report, err := d.CompactMemoTo(dbfout, fptout, blockSize)
+	if err != nil {
+		rw.Abort()
+		return nil, err
+	}
+	return report, rw.Commit()
+}
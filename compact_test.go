@@ -0,0 +1,70 @@
+package dbf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompactMemo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfcompact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	out := filepath.Join(dir, "COMPACT.DBF")
+	report, err := CompactMemo(dbf, out, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Kept != 4 || report.Removed != 0 {
+		t.Errorf("Want all 4 records kept, have %+v", report)
+	}
+
+	compacted, err := OpenFile(out, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer compacted.Close()
+	if compacted.fptheader.BlockSize != 32 || compacted.fptheader.NextFree != report.MemoBlocks {
+		t.Errorf("Want block size 32 and next free block %d, have %+v", report.MemoBlocks, compacted.fptheader)
+	}
+	for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+		a, err := dbf.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := compacted.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diffs := DiffRecords(a, b); len(diffs) != 0 || a.Deleted != b.Deleted {
+			t.Errorf("Record %d: want the same values after compacting, have %+v", recno, diffs)
+		}
+	}
+	if rec, err := compacted.RecordAt(3); err != nil || rec.FieldSlice()[dbf.FieldPos("MELDING")] != "" {
+		t.Errorf("Want an empty memo, have %v (%v)", rec, err)
+	}
+	audit, err := compacted.AuditMemos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.Problems) != 0 || len(audit.Orphans) != 0 {
+		t.Errorf("Want a memo file without orphans or problems, have %+v", audit)
+	}
+
+	if _, err := CompactMemo(dbf, filepath.Join("testdata", "TEST.DBF"), 0); err == nil {
+		t.Error("Want an error for compacting a table onto itself")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "COMPACT.FPT")); err != nil {
+		t.Errorf("Want the memo file next to the table, have %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package dbf
+
+import (
+	"time"
+)
+
+// ConsistentReadOptions configures the consistent read mode, for reading tables which are written
+// by another application at the same time
+type ConsistentReadOptions struct {
+	// Retries is the number of times a short read is retried, and the number of times ScanConsistent restarts
+	Retries int
+
+	// Delay is the time to wait before a retry
+	Delay time.Duration
+}
+
+// DefaultConsistentReadOptions are the options used by SetConsistentRead when nil is passed
+var DefaultConsistentReadOptions = ConsistentReadOptions{Retries: 5, Delay: 50 * time.Millisecond}
+
+// SetConsistentRead enables the consistent read mode with the given options, nil uses DefaultConsistentReadOptions.
+// In consistent read mode records and fields which cannot be read completely, because they are still being written,
+// are read again after a delay instead of returning ErrIncomplete.
+// Use ScanConsistent to read all records of a table which is changing.
+func (dbf *DBF) SetConsistentRead(opts *ConsistentReadOptions) {
+	if opts == nil {
+		defaults := DefaultConsistentReadOptions
+		opts = &defaults
+	}
+	dbf.consistent = opts
+}
+
+// ScanConsistent calls fn for every record of a table which can be written by another application while scanning.
+// Records appended during the scan are included: when the last record is reached, the header is read again and the
+// scan continues with the new records. When the record count decreased or the record layout changed during the scan
+// (the table was packed, zapped or modified), the table is reopened and the scan restarts at record 0, so fn must
+// reset its state when it is called with recno 0 again. If the table keeps changing the scan stops after the
+// configured number of retries with ErrRecordCountDecreased or ErrStructureChanged.
+// Without consistent read mode the DefaultConsistentReadOptions are used.
+func (dbf *DBF) ScanConsistent(fn func(recno uint32, rec *Record) error) error {
+	opts := dbf.consistent
+	if opts == nil {
+		opts = &DefaultConsistentReadOptions
+	}
+	for restarts := 0; ; restarts++ {
+		err := dbf.scanOnce(fn)
+		if err != ErrRecordCountDecreased && err != ErrStructureChanged {
+			return err
+		}
+		if restarts >= opts.Retries {
+			return err
+		}
+		time.Sleep(opts.Delay)
+		if err := dbf.Reopen(); err != nil {
+			return err
+		}
+	}
+}
+
+// scanOnce scans all records until no new records are found
+func (dbf *DBF) scanOnce(fn func(recno uint32, rec *Record) error) error {
+	recno := uint32(0)
+	for {
+		for ; recno < dbf.header.NumRec; recno++ {
+			rec, err := dbf.RecordAt(recno)
+			if err != nil {
+				return err
+			}
+			if err := fn(recno, rec); err != nil {
+				return err
+			}
+		}
+		if err := dbf.RefreshHeader(); err != nil {
+			return err
+		}
+		if dbf.header.NumRec < recno {
+			return ErrRecordCountDecreased
+		}
+		if dbf.header.NumRec == recno {
+			return nil
+		}
+	}
+}
+
+// Reopen closes and opens the table files again, which is needed when the files were replaced
+// (for example by a pack) or the record layout has changed. Only tables opened with OpenFile can be reopened.
+// The internal record pointer is reset to the first record.
+func (dbf *DBF) Reopen() error {
+	if dbf.f == nil {
+		return ErrNotOnDisk
+	}
+	if dbf.upd != nil && dbf.upd.stage != nil {
+		return ErrStaged
+	}
+	reopened, err := openFile(dbf.f.Name(), dbf.dec, dbf.shareMode, dbf.decrypter, dbf.upd != nil)
+	if err != nil {
+		return err
+	}
+	old := *dbf
+	if err := dbf.replace(reopened); err != nil {
+		reopened.Close()
+		return err
+	}
+	old.Close()
+	return nil
+}
+
+// replace continues the table with the files of reopened, with the settings of the table.
+// The files of the table are not closed. Hardened tables are checked again, when the check fails the table
+// is not changed.
+func (dbf *DBF) replace(reopened *DBF) error {
+	if dbf.limits != nil {
+		// the file has changed, so it is checked again
+		if err := reopened.harden(*dbf.limits); err != nil {
+			return err
+		}
+	}
+	reopened.jsonOpts = dbf.jsonOpts
+	reopened.consistent = dbf.consistent
+	reopened.links = dbf.links
+	reopened.fieldProps = dbf.fieldProps
+	reopened.numbers = dbf.numbers
+	reopened.zone = dbf.zone
+	reopened.upd = dbf.upd
+	reopened.SetTypeMap(dbf.typeMap)
+	for _, name := range dbf.asciiFields() {
+		if pos := reopened.FieldPos(name); pos >= 0 {
+			reopened.layout[pos].ascii = true
+		}
+	}
+	*dbf = *reopened
+	return nil
+}
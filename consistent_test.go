@@ -0,0 +1,170 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConsistentReadRetriesShortReads(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpt, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := &growingReader{data: data}
+	dbf, err := OpenStream(stream, bytes.NewReader(fpt), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := dbf.Header()
+	end := int(h.FirstRec) + int(h.NumRec)*int(h.RecLen)
+	rec := append([]byte{}, data[h.FirstRec:int(h.FirstRec)+int(h.RecLen)]...)
+
+	// header says 5 records, but only half of the last record is written
+	stream.data = append(stream.data[:end:end], rec[:h.RecLen/2]...)
+	binary.LittleEndian.PutUint32(stream.data[4:], h.NumRec+1)
+	if err := dbf.RefreshHeader(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dbf.RecordAt(4); err == nil {
+		t.Fatal("Want an error for an incomplete record without consistent read mode")
+	}
+
+	// the writer completes the record after the first retry
+	dbf.SetConsistentRead(&ConsistentReadOptions{Retries: 3, Delay: time.Millisecond})
+	dbf.r = &completingReader{ReaderAtSeeker: stream, complete: func() {
+		stream.data = append(append(stream.data[:end:end], rec...), 0x1A)
+	}}
+	last, err := dbf.RecordAt(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.FieldSlice()[0] != int32(1) {
+		t.Errorf("Want ID 1 in the completed record, have %v", last.FieldSlice()[0])
+	}
+}
+
+func TestScanConsistent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfconsistent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"TEST.DBF", "TEST.FPT"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dbfname := filepath.Join(dir, "TEST.DBF")
+	dbf, err := OpenFile(dbfname, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	dbf.SetConsistentRead(&ConsistentReadOptions{Retries: 2, Delay: time.Millisecond})
+
+	f, err := os.OpenFile(dbfname, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	h := dbf.Header()
+	setNumRec := func(n uint32) {
+		numRec := make([]byte, 4)
+		binary.LittleEndian.PutUint32(numRec, n)
+		if _, err := f.WriteAt(numRec, 4); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a record appended during the scan is included
+	var ids []interface{}
+	err = dbf.ScanConsistent(func(recno uint32, rec *Record) error {
+		if recno == 0 {
+			ids = ids[:0]
+		}
+		ids = append(ids, rec.FieldSlice()[0])
+		if recno == 1 && len(ids) == 2 {
+			first := make([]byte, h.RecLen)
+			if _, err := f.ReadAt(first, int64(h.FirstRec)); err != nil {
+				return err
+			}
+			if _, err := f.WriteAt(append(first, 0x1A), int64(h.FirstRec)+int64(h.NumRec)*int64(h.RecLen)); err != nil {
+				return err
+			}
+			setNumRec(h.NumRec + 1)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 5 || ids[4] != int32(1) {
+		t.Fatalf("Want 5 records ending with ID 1, have %v", ids)
+	}
+
+	// a table which shrinks during the scan is scanned again from the start
+	calls := 0
+	ids = ids[:0]
+	err = dbf.ScanConsistent(func(recno uint32, rec *Record) error {
+		calls++
+		if recno == 0 {
+			ids = ids[:0]
+		}
+		ids = append(ids, rec.FieldSlice()[0])
+		if calls == 4 {
+			setNumRec(3)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || calls != 8 {
+		t.Errorf("Want 3 records after a restart and 8 calls, have %v and %d calls", ids, calls)
+	}
+
+	data, err := ioutil.ReadFile(dbfname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpt, err := ioutil.ReadFile(filepath.Join(dir, "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := OpenStream(bytes.NewReader(data), bytes.NewReader(fpt), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Reopen(); err != ErrNotOnDisk {
+		t.Errorf("Want ErrNotOnDisk, have %v", err)
+	}
+}
+
+// completingReader calls complete after the first read, to simulate a record which is written while reading
+type completingReader struct {
+	ReaderAtSeeker
+	complete func()
+	done     bool
+}
+
+func (c *completingReader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.ReaderAtSeeker.ReadAt(p, off)
+	if !c.done {
+		c.done = true
+		c.complete()
+	}
+	return n, err
+}
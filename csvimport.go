@@ -0,0 +1,170 @@
+package dbf
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVImportOptions controls ImportCSV
+type CSVImportOptions struct {
+	// Comma is the field delimiter, ',' when 0
+	Comma rune
+
+	// Columns has the CSV header of the column of each table field, in field order.
+	// When it is shorter than the fields or a column is empty, the field name is used.
+	Columns []string
+
+	// DateLayouts are the time layouts tried for D fields, by default 2006-01-02, 20060102 and 02.01.2006
+	DateLayouts []string
+
+	// DateTimeLayouts are the time layouts tried for T fields,
+	// by default 2006-01-02 15:04:05, 2006-01-02T15:04:05, RFC 3339 and 2006-01-02
+	DateTimeLayouts []string
+}
+
+var (
+	defaultDateLayouts     = []string{"2006-01-02", "20060102", "02.01.2006"}
+	defaultDateTimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", time.RFC3339, "2006-01-02"}
+)
+
+// ImportCSV appends the rows of CSV data with a header row to the table of the Writer, the inverse of CSVReader.
+// The columns are matched to the table fields by their header (case insensitive), see CSVImportOptions.Columns,
+// columns without field are ignored. The text is converted for the type of the field: numbers for N, F, B, Y and I
+// fields, dates and datetimes with the layouts of the options, logicals as T/F, Y/N, true/false or 1/0 and
+// G, P and W memos from base64. Empty text and missing columns at the end of a row result in empty fields.
+// Like encoding/csv does, line breaks in quoted values are read as \n.
+// ImportCSV returns the number of records appended, errors contain the line of the CSV data.
+// The Writer is not closed.
+func ImportCSV(r io.Reader, wr *Writer, opts CSVImportOptions) (uint32, error) {
+	reader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	reader.FieldsPerRecord = -1
+	if opts.DateLayouts == nil {
+		opts.DateLayouts = defaultDateLayouts
+	}
+	if opts.DateTimeLayouts == nil {
+		opts.DateTimeLayouts = defaultDateTimeLayouts
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("reading CSV header: %s", err)
+	}
+	columns, err := csvColumns(wr.fields, header, opts.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	var n uint32
+	line := 1
+	values := make([]interface{}, len(wr.fields))
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return n, nil
+		}
+		line++
+		if err != nil {
+			return n, err
+		}
+		for i := range wr.fields {
+			f := &wr.fields[i]
+			text := ""
+			if columns[i] < len(row) {
+				text = row[columns[i]]
+			}
+			if values[i], err = opts.parse(f, text); err != nil {
+				return n, fmt.Errorf("line %d, field %s: %s", line, f.FieldName(), err)
+			}
+		}
+		if err := wr.Append(values...); err != nil {
+			return n, fmt.Errorf("line %d: %s", line, err)
+		}
+		n++
+	}
+}
+
+// csvColumns returns the index of the CSV column of every field in header
+func csvColumns(fields []FieldHeader, header, names []string) ([]int, error) {
+	columns := make([]int, len(fields))
+	for i := range fields {
+		name := fields[i].FieldName()
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		columns[i] = -1
+		for col, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				columns[i] = col
+				break
+			}
+		}
+		if columns[i] < 0 {
+			return nil, fmt.Errorf("column %s not found in CSV header", name)
+		}
+	}
+	return columns, nil
+}
+
+// parse converts CSV text to the Go value which the Writer expects for field f, empty text results in nil
+func (opts *CSVImportOptions) parse(f *FieldHeader, text string) (interface{}, error) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" && f.Type != 'C' && f.Type != 'M' {
+		return nil, nil
+	}
+	switch f.Type {
+	case 'C', 'M':
+		if text == "" {
+			return nil, nil
+		}
+		return text, nil
+	case 'N', 'F', 'B', 'Y':
+		v, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", text)
+		}
+		return v, nil
+	case 'I':
+		v, err := strconv.ParseInt(trimmed, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", text)
+		}
+		return v, nil
+	case 'D':
+		return parseCSVTime(trimmed, opts.DateLayouts)
+	case 'T':
+		return parseCSVTime(trimmed, opts.DateTimeLayouts)
+	case 'L':
+		switch strings.ToUpper(trimmed) {
+		case "T", "TRUE", "Y", "YES", "1":
+			return true, nil
+		case "F", "FALSE", "N", "NO", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid logical %q", text)
+	case 'G', 'P', 'W':
+		v, err := base64.StdEncoding.DecodeString(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 %q", text)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported field type %s", f.FieldType())
+}
+
+// parseCSVTime parses text with the first matching layout
+func parseCSVTime(text string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", text)
+}
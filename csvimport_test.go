@@ -0,0 +1,127 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportCSV(t *testing.T) {
+	orig, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer orig.Close()
+	rows, err := NewCSVReader(orig).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := new(bytes.Buffer)
+	if err := csv.NewWriter(data).WriteAll(rows); err != nil {
+		t.Fatal(err)
+	}
+
+	// the exported CSV imports to the same records
+	dbffile, fptfile := new(memWriteSeeker), new(memWriteSeeker)
+	wr, err := NewWriter(dbffile, fptfile, orig.Fields(), new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := ImportCSV(data, wr, CSVImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("Want 3 imported records, have %d", n)
+	}
+	imported, err := OpenStream(bytes.NewReader(dbffile.buf), bytes.NewReader(fptfile.buf), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for recno, origno := range []uint32{0, 2, 3} {
+		a, err := orig.RecordAt(origno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := imported.RecordAt(uint32(recno))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the line breaks of the first memo are compared below
+		var ignore []string
+		if origno == 0 {
+			ignore = append(ignore, "MELDING")
+		}
+		if diffs := DiffRecords(a, b, ignore...); len(diffs) != 0 {
+			t.Errorf("Record %d: want record %d of the original table, have %+v", recno, origno, diffs)
+		}
+	}
+	if rec, err := imported.RecordAt(2); err != nil || rec.FieldSlice()[9] != "" {
+		t.Errorf("Want an empty memo, have %v (%v)", rec, err)
+	}
+	// CSV line breaks are read as \n
+	if rec, err := imported.RecordAt(0); err != nil || rec.FieldSlice()[9] != "Message line 1\nMessage line 2" {
+		t.Errorf("Want the memo of the first record, have %v (%v)", rec, err)
+	}
+}
+
+func TestImportCSVOptions(t *testing.T) {
+	fields := []FieldHeader{
+		newField("NAME", 'C', 10, 0),
+		newField("BORN", 'D', 0, 0),
+		newField("ACTIVE", 'L', 0, 0),
+		newField("PHOTO", 'W', 0, 0),
+	}
+	importCSV := func(data string, opts CSVImportOptions) (*DBF, error) {
+		t.Helper()
+		dbffile, fptfile := new(memWriteSeeker), new(memWriteSeeker)
+		wr, err := NewWriter(dbffile, fptfile, fields, new(UTF8Encoder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ImportCSV(strings.NewReader(data), wr, opts); err != nil {
+			return nil, err
+		}
+		if err := wr.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return OpenStream(bytes.NewReader(dbffile.buf), bytes.NewReader(fptfile.buf), new(UTF8Decoder))
+	}
+
+	opts := CSVImportOptions{
+		Comma:       ';',
+		Columns:     []string{"full name", "", "", "picture"},
+		DateLayouts: []string{"01/02/2006"},
+	}
+	dbf, err := importCSV("extra;ACTIVE;born;Full Name;picture\nx;Y;03/04/2021;Zoë;aGk=\nx\n", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"Zoë      ", time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), true, []byte("hi")}
+	for i, val := range want {
+		if have := rec.FieldSlice()[i]; !equalValue(have, val) {
+			t.Errorf("Field %d: want %#v, have %#v", i, val, have)
+		}
+	}
+	// missing columns are empty
+	if rec, err := dbf.RecordAt(1); err != nil || rec.FieldSlice()[0] != "          " {
+		t.Errorf("Want an empty name in the second record, have %v (%v)", rec, err)
+	}
+
+	if _, err := importCSV("NAME,BORN,ACTIVE\n", CSVImportOptions{}); err == nil || !strings.Contains(err.Error(), "PHOTO") {
+		t.Errorf("Want an error for the missing column PHOTO, have %v", err)
+	}
+	if _, err := importCSV("NAME,BORN,ACTIVE,PHOTO\na,,,\nb,2021-13-01,,\n", CSVImportOptions{}); err == nil || !strings.Contains(err.Error(), "line 3, field BORN") {
+		t.Errorf("Want an error for the invalid date on line 3, have %v", err)
+	}
+}
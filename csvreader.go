@@ -0,0 +1,87 @@
+package dbf
+
+import (
+	"io"
+)
+
+// CSVReader reads a table as CSV records, it has the same Read and ReadAll methods as encoding/csv.Reader
+// so it can be used by code consuming CSV input without writing an intermediate file.
+// The first record returned is the header with the field names, followed by one record per table record.
+// Values are formatted like the dbfreader CSV export: dates as 2006-01-02, datetimes as 2006-01-02 15:04:05,
+// logicals as true/false and binary memos as base64. Logicals and numbers can be rendered differently with Format.
+type CSVReader struct {
+	// IncludeDeleted also returns deleted records, by default they are skipped
+	IncludeDeleted bool
+
+	// KeepSpaces keeps the padding of C fields, by default spaces are trimmed
+	KeepSpaces bool
+
+	// Format controls how logicals and numbers are rendered, like 1/0 and decimal commas
+	Format FormatOptions
+
+	dbf     *DBF
+	header  bool        // header row has been returned
+	scanner *Scanner    // reads the records, created after the header row
+	lookups []csvLookup // description columns, see lookup.go
+}
+
+// NewCSVReader returns a CSVReader which reads all records of dbf.
+// The reader does not use or move the internal record pointer of dbf.
+func NewCSVReader(dbf *DBF) *CSVReader {
+	return &CSVReader{dbf: dbf}
+}
+
+// Read returns the next record as a slice of strings, it returns io.EOF after the last record
+func (r *CSVReader) Read() ([]string, error) {
+	if !r.header {
+		r.header = true
+		names := r.dbf.FieldNames()
+		for _, l := range r.lookups {
+			names = append(names, l.column)
+		}
+		return names, nil
+	}
+	if r.scanner == nil {
+		r.scanner = r.dbf.Scanner()
+		r.scanner.IncludeDeleted = r.IncludeDeleted
+	}
+	if r.scanner.Next() {
+		rec := r.scanner.Record()
+		row := make([]string, len(r.dbf.fields), len(r.dbf.fields)+len(r.lookups))
+		for i, val := range rec.data {
+			row[i] = r.format(val, &r.dbf.fields[i])
+		}
+		for _, l := range r.lookups {
+			desc, _ := l.lookup.Get(row[l.pos])
+			row = append(row, desc)
+		}
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ReadAll reads all remaining records
+func (r *CSVReader) ReadAll() ([][]string, error) {
+	var rows [][]string
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// format converts a field value to its CSV representation
+func (r *CSVReader) format(val interface{}, f *FieldHeader) string {
+	if s, ok := val.(string); ok && r.KeepSpaces {
+		return s
+	}
+	return r.Format.FormatValue(val, f)
+}
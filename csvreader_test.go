@@ -0,0 +1,65 @@
+package dbf
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+// csvRowReader is the method set of *csv.Reader used by most consumers
+type csvRowReader interface {
+	Read() ([]string, error)
+	ReadAll() ([][]string, error)
+}
+
+var _ csvRowReader = new(CSVReader)
+
+func TestCSVReader(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	r := NewCSVReader(dbf)
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// header and 3 records, 1 record is deleted
+	if len(rows) != 4 {
+		t.Fatalf("Want 4 rows, have %d", len(rows))
+	}
+	want := []string{"ID", "NIVEAU", "DATUM", "TIJD", "SOORT", "ID_NR", "USERNR", "COMP_NAME", "COMP_OS", "MELDING", "NUMBER", "FLOAT", "BOOL"}
+	for i, name := range want {
+		if rows[0][i] != name {
+			t.Errorf("Header %d: want %s, have %s", i, name, rows[0][i])
+		}
+	}
+	want = []string{"1", "0", "2015-01-03", "15:00", "3", "100", "1", "TEST", "Windows 8.1 Pro", "Message line 1\r\nMessage line 2", "1.66", "1", "false"}
+	for i, val := range want {
+		if rows[1][i] != val {
+			t.Errorf("Field %s: want %q, have %q", rows[0][i], val, rows[1][i])
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Want io.EOF, have %v", err)
+	}
+	if dbf.recpointer != 0 {
+		t.Errorf("Record pointer moved to %d", dbf.recpointer)
+	}
+
+	r = NewCSVReader(dbf)
+	r.IncludeDeleted = true
+	r.KeepSpaces = true
+	rows, err = r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("Want 5 rows, have %d", len(rows))
+	}
+	if rows[1][3] != "15:00   " {
+		t.Errorf("Want padded value, have %q", rows[1][3])
+	}
+}
@@ -0,0 +1,385 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrTableNotFound is returned by Database.Table for names which are not part of the database
+var ErrTableNotFound = errors.New("table not found in database")
+
+// DBCPropertyIDs maps the names of database container properties (as used by DBGETPROP) to the ids they are
+// stored with in the PROPERTY memo of the DBC. The ids are not documented by Microsoft, this map can be changed
+// or extended when a property is missing.
+var DBCPropertyIDs = map[string]byte{
+	"Path":           1,
+	"Class":          2,
+	"Comment":        7,
+	"RuleExpression": 9,
+	"RuleText":       10,
+	"DefaultValue":   11,
+	"ParameterList":  12,
+	"ChildTag":       13,
+	"InsertTrigger":  14,
+	"UpdateTrigger":  15,
+	"DeleteTrigger":  16,
+	"IsUnique":       17,
+	"ParentTable":    18,
+	"ParentTag":      19,
+	"PrimaryKey":     20,
+	"Version":        24,
+	"ConnectName":    32,
+	"SQL":            53,
+	"InputMask":      54,
+	"Format":         55,
+	"Caption":        56,
+}
+
+// DatabaseTable is a table which is part of a database container
+type DatabaseTable struct {
+	Name    string // Long name of the table
+	Path    string // Path of the DBF file, relative to the DBC file
+	Comment string
+	Fields  []FieldProperties // Properties of the fields, in table order
+
+	// RuleExpression is the record validation rule, which must be true before a record is saved,
+	// RuleText is the error message shown when the rule fails
+	RuleExpression string
+	RuleText       string
+
+	// Triggers are expressions, usually a call of a stored procedure, which must be true for the change
+	InsertTrigger string
+	UpdateTrigger string
+	DeleteTrigger string
+
+	objectID int32
+}
+
+// FieldProperties are the properties of a field of a table in a database container
+type FieldProperties struct {
+	LongName  string // Field name of up to 128 characters, the field header contains the first 10 characters
+	Caption   string // Column header for browse windows and forms
+	Comment   string
+	Format    string // Display format, like the FORMAT clause of @ ... SAY
+	InputMask string // Display mask, like PICTURE
+
+	DefaultValue string // Expression for the value of new records, see Writer.SetDefaultValues
+
+	// RuleExpression is the field validation rule, which must be true before a value is saved,
+	// RuleText is the error message shown when the rule fails
+	RuleExpression string
+	RuleText       string
+}
+
+// Relation is a persistent relation between two tables of a database container.
+// The relation is defined on index tags, the expressions of the tags are stored in the CDX files of the tables.
+type Relation struct {
+	ChildTable  string // Long name of the child (foreign key) table
+	ChildTag    string // Index tag of the child table
+	ParentTable string // Long name of the parent (primary key) table
+	ParentTag   string // Index tag of the parent table
+
+	// RIInfo contains the referential integrity rules for update, delete and insert,
+	// C (cascade), R (restrict) or I (ignore), for example "CRI"
+	RIInfo string
+}
+
+// Database is a Visual FoxPro database container (DBC) and its tables.
+// Tables are opened on first use by Table and closed by Close.
+type Database struct {
+	filename  string
+	dec       Decoder
+	tables    []DatabaseTable
+	relations []Relation
+	views     []DatabaseView
+	opened    map[int32]*DBF
+}
+
+// dbcObject is a record of the database container
+type dbcObject struct {
+	id         int32
+	parentID   int32
+	objectType string
+	name       string
+	properties map[byte][]byte
+	riInfo     string
+}
+
+// OpenDatabase reads the database container in filename (and its DCT memo file).
+// The Decoder is used for the DBC and all its tables.
+// The caller should call Database.Close to close the opened tables.
+func OpenDatabase(filename string, dec Decoder) (*Database, error) {
+	dbc, err := OpenFile(filename, dec)
+	if err != nil {
+		return nil, err
+	}
+	defer dbc.Close()
+
+	objects, err := dbc.dbcObjects()
+	if err != nil {
+		return nil, err
+	}
+	db := &Database{filename: filepath.Clean(filename), dec: dec, opened: make(map[int32]*DBF)}
+	names := make(map[int32]string)
+	for _, obj := range objects {
+		if obj.objectType != "Table" {
+			continue
+		}
+		names[obj.id] = obj.name
+		var fields []FieldProperties
+		for _, field := range objects {
+			if field.objectType != "Field" || field.parentID != obj.id {
+				continue
+			}
+			fields = append(fields, FieldProperties{
+				LongName:  field.name,
+				Caption:   field.property("Caption"),
+				Comment:   field.property("Comment"),
+				Format:    field.property("Format"),
+				InputMask: field.property("InputMask"),
+
+				DefaultValue:   field.property("DefaultValue"),
+				RuleExpression: field.property("RuleExpression"),
+				RuleText:       field.property("RuleText"),
+			})
+		}
+		db.tables = append(db.tables, DatabaseTable{
+			Name:    obj.name,
+			Path:    obj.property("Path"),
+			Comment: obj.property("Comment"),
+			Fields:  fields,
+
+			RuleExpression: obj.property("RuleExpression"),
+			RuleText:       obj.property("RuleText"),
+			InsertTrigger:  obj.property("InsertTrigger"),
+			UpdateTrigger:  obj.property("UpdateTrigger"),
+			DeleteTrigger:  obj.property("DeleteTrigger"),
+
+			objectID: obj.id,
+		})
+	}
+	for _, obj := range objects {
+		if obj.objectType == "View" {
+			db.views = append(db.views, DatabaseView{
+				Name:    obj.name,
+				SQL:     obj.property("SQL"),
+				Comment: obj.property("Comment"),
+				Remote:  obj.property("ConnectName") != "",
+			})
+			continue
+		}
+		if obj.objectType != "Relation" {
+			continue
+		}
+		// a relation is stored as child of the child table
+		db.relations = append(db.relations, Relation{
+			ChildTable:  names[obj.parentID],
+			ChildTag:    obj.property("ChildTag"),
+			ParentTable: obj.property("ParentTable"),
+			ParentTag:   obj.property("ParentTag"),
+			RIInfo:      obj.riInfo,
+		})
+	}
+	return db, nil
+}
+
+// Tables returns the tables of the database
+func (db *Database) Tables() []DatabaseTable {
+	return db.tables
+}
+
+// Relations returns the persistent relations of the database
+func (db *Database) Relations() []Relation {
+	return db.relations
+}
+
+// Table returns the table with the given long name (case insensitive), the table is opened on the first call.
+// The file name of the table, as accepted by TableName, can be used as well.
+// The returned DBF is shared between calls and is closed by Database.Close.
+func (db *Database) Table(name string) (*DBF, error) {
+	t, err := db.table(name)
+	if err == ErrTableNotFound {
+		var longName string
+		if longName, err = db.TableName(name); err == nil {
+			t, err = db.table(longName)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dbf, ok := db.opened[t.objectID]; ok {
+		return dbf, nil
+	}
+	dbf, err := OpenFile(db.tablePath(t), db.dec)
+	if err != nil {
+		return nil, fmt.Errorf("table %s: %s", t.Name, err)
+	}
+	if len(t.Fields) == len(dbf.fields) {
+		dbf.fieldProps = t.Fields
+	}
+	db.opened[t.objectID] = dbf
+	return dbf, nil
+}
+
+// TableFile returns the path of the DBF file of the table with the given long name (case insensitive).
+// Relative paths in the database container are resolved against the directory of the DBC file.
+func (db *Database) TableFile(name string) (string, error) {
+	t, err := db.table(name)
+	if err != nil {
+		return "", err
+	}
+	return db.tablePath(t), nil
+}
+
+// TableName returns the long name of the table stored in filename. File names are compared case insensitive,
+// a file name without directory matches a table in any directory, otherwise the full paths must match.
+func (db *Database) TableName(filename string) (string, error) {
+	base := filepath.Base(filename)
+	full, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range db.tables {
+		path := db.tablePath(t)
+		if filename == base {
+			if strings.EqualFold(filepath.Base(path), base) {
+				return t.Name, nil
+			}
+			continue
+		}
+		if abs, err := filepath.Abs(path); err == nil && strings.EqualFold(abs, full) {
+			return t.Name, nil
+		}
+	}
+	return "", ErrTableNotFound
+}
+
+// table finds a table by long name
+func (db *Database) table(name string) (DatabaseTable, error) {
+	for _, t := range db.tables {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+	return DatabaseTable{}, ErrTableNotFound
+}
+
+// tablePath returns the path of the DBF file of t, the path in the DBC is relative to the DBC file
+func (db *Database) tablePath(t DatabaseTable) string {
+	path := filepath.FromSlash(strings.Replace(t.Path, `\`, "/", -1))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(db.filename), path)
+	}
+	return path
+}
+
+// FieldProperties returns the properties of the field at fieldpos stored in the database container of the table.
+// Only tables opened with Database.Table have properties, for other tables and invalid positions an empty
+// FieldProperties is returned.
+func (dbf *DBF) FieldProperties(fieldpos int) FieldProperties {
+	if fieldpos < 0 || fieldpos >= len(dbf.fieldProps) {
+		return FieldProperties{}
+	}
+	return dbf.fieldProps[fieldpos]
+}
+
+// Close closes all tables opened by Table
+func (db *Database) Close() error {
+	var firstErr error
+	for id, dbf := range db.opened {
+		if err := dbf.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(db.opened, id)
+	}
+	return firstErr
+}
+
+// dbcObjects reads all not deleted records of a database container
+func (dbf *DBF) dbcObjects() ([]dbcObject, error) {
+	pos := make(map[string]int)
+	for _, name := range []string{"OBJECTID", "PARENTID", "OBJECTTYPE", "OBJECTNAME", "PROPERTY", "RIINFO"} {
+		pos[name] = dbf.FieldPos(name)
+		if pos[name] < 0 {
+			return nil, fmt.Errorf("not a database container, field %s not found", name)
+		}
+	}
+	// the ids are read as integers and the properties from the memo file
+	for _, name := range []string{"OBJECTID", "PARENTID", "PROPERTY"} {
+		f := &dbf.fields[pos[name]]
+		if name == "PROPERTY" && !f.isMemo() || name != "PROPERTY" && f.Type != 'I' {
+			return nil, fmt.Errorf("not a database container, field %s has type %s", name, f.FieldType())
+		}
+	}
+	var objects []dbcObject
+	for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+		data, err := dbf.readRecord(recno)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", recno, err)
+		}
+		if data[0] == 0x2A {
+			continue
+		}
+		field := func(name string) []byte {
+			return dbf.layout[pos[name]].data(data)
+		}
+		obj := dbcObject{
+			id:         int32(binary.LittleEndian.Uint32(field("OBJECTID"))),
+			parentID:   int32(binary.LittleEndian.Uint32(field("PARENTID"))),
+			objectType: strings.TrimSpace(string(field("OBJECTTYPE"))),
+			riInfo:     strings.TrimSpace(string(field("RIINFO"))),
+		}
+		name, err := dbf.dec.Decode(field("OBJECTNAME"))
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", recno, err)
+		}
+		obj.name = strings.TrimSpace(string(name))
+		block := field("PROPERTY")
+		b, err := memoBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", recno, err)
+		}
+		if b != 0 {
+			memo, _, err := dbf.readFPT(block)
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %s", recno, err)
+			}
+			obj.properties = parseDBCProperties(memo)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// parseDBCProperties parses a PROPERTY memo, which is a list of properties stored as:
+// total length (uint32), length of the id (uint16), id, value
+func parseDBCProperties(memo []byte) map[byte][]byte {
+	props := make(map[byte][]byte)
+	for len(memo) >= 7 {
+		size := int(binary.LittleEndian.Uint32(memo))
+		idLen := int(binary.LittleEndian.Uint16(memo[4:]))
+		if size < 6+idLen || size > len(memo) || idLen == 0 {
+			break
+		}
+		props[memo[6]] = memo[6+idLen : size]
+		memo = memo[size:]
+	}
+	return props
+}
+
+// property returns a string property by name, without the terminating zero byte
+func (obj dbcObject) property(name string) string {
+	id, ok := DBCPropertyIDs[name]
+	if !ok {
+		return ""
+	}
+	val := obj.properties[id]
+	if i := bytes.IndexByte(val, 0); i >= 0 {
+		val = val[:i]
+	}
+	return strings.TrimSpace(string(val))
+}
@@ -0,0 +1,261 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dbcProperty encodes one property of a DBC PROPERTY memo
+func dbcProperty(id byte, value string) []byte {
+	prop := make([]byte, 7, 7+len(value)+1)
+	binary.LittleEndian.PutUint32(prop, uint32(7+len(value)+1))
+	binary.LittleEndian.PutUint16(prop[4:], 1)
+	prop[6] = id
+	return append(append(prop, value...), 0)
+}
+
+// testViewSQL is the SQL of the local view in the test database
+const testViewSQL = "SELECT t.id, t.comp_name AS company, u.name FROM app!test_results t ;\r\n" +
+	"LEFT OUTER JOIN app!users u ON t.usernr = u.usernr WHERE t.id > ?minid ORDER BY t.id DESC"
+
+// writeTestDatabase writes a database container with the test table as "test_results", a "users" table with
+// two records, a relation from TEST.USERNR to USERS.USERNR and a local and a remote view
+func writeTestDatabase(t *testing.T, dir string) string {
+	write := func(name string, fields []FieldHeader, records ...[]interface{}) {
+		dbffile, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dbffile.Close()
+		fptfile, err := os.Create(memoFileName(filepath.Join(dir, name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fptfile.Close()
+		wr, err := NewWriter(dbffile, fptfile, fields, new(Win1250Encoder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, rec := range records {
+			if err := wr.Append(rec...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := wr.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"TEST.DBF", "TEST.FPT"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(dir, "data"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "data", strings.ToLower(name)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("users.dbf", []FieldHeader{newField("USERNR", 'I', 0, 0), newField("NAME", 'C', 20, 0)},
+		[]interface{}{int32(1), "Jan"}, []interface{}{int32(2), "Piet"})
+
+	relation := append(append(dbcProperty(13, "usernr"), dbcProperty(18, "users")...), dbcProperty(19, "usernr")...)
+	write("app.dbc", []FieldHeader{
+		newField("OBJECTID", 'I', 0, 0),
+		newField("PARENTID", 'I', 0, 0),
+		newField("OBJECTTYPE", 'C', 10, 0),
+		newField("OBJECTNAME", 'C', 128, 0),
+		newField("PROPERTY", 'M', 0, 0),
+		newField("CODE", 'M', 0, 0),
+		newField("RIINFO", 'C', 6, 0),
+		newField("USER", 'M', 0, 0),
+	},
+		[]interface{}{int32(1), int32(1), "Database", "Database", nil, nil, nil, nil},
+		[]interface{}{int32(2), int32(1), "Folder", "Tables", nil, nil, nil, nil},
+		[]interface{}{int32(3), int32(1), "Folder", "Relations", nil, nil, nil, nil},
+		[]interface{}{int32(4), int32(2), "Table", "test_results",
+			bytes.Join([][]byte{
+				dbcProperty(1, `data\test.dbf`), dbcProperty(7, "Test results"),
+				dbcProperty(9, "id > 0"), dbcProperty(10, "ID must be positive"),
+				dbcProperty(14, "__ri_insert_test()"), dbcProperty(15, "__ri_update_test()"), dbcProperty(16, "__ri_delete_test()"),
+			}, nil), nil, nil, nil},
+		[]interface{}{int32(5), int32(2), "Table", "users", dbcProperty(1, "users.dbf"), nil, nil, nil},
+		[]interface{}{int32(6), int32(4), "Relation", "Relation 1", relation, nil, "RRI", nil},
+		[]interface{}{int32(7), int32(1), "Folder", "Views", nil, nil, nil, nil},
+		[]interface{}{int32(8), int32(7), "View", "results_by_user", dbcProperty(53, testViewSQL), nil, nil, nil},
+		[]interface{}{int32(9), int32(7), "View", "remote_users",
+			append(dbcProperty(53, "SELECT * FROM users"), dbcProperty(32, "sqlserver")...), nil, nil, nil},
+		[]interface{}{int32(10), int32(5), "Field", "usernr",
+			append(dbcProperty(56, "User number"), dbcProperty(11, "1")...), nil, nil, nil},
+		[]interface{}{int32(11), int32(5), "Field", "name_of_the_user",
+			bytes.Join([][]byte{
+				dbcProperty(7, "Full name"), dbcProperty(55, "!"), dbcProperty(54, "XXXXXXXX"),
+				dbcProperty(9, "!EMPTY(name_of_the_user)"), dbcProperty(10, "Name is required"),
+			}, nil), nil, nil, nil},
+	)
+	return filepath.Join(dir, "app.dbc")
+}
+
+func TestOpenDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfdatabase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := OpenDatabase(writeTestDatabase(t, dir), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tables := db.Tables()
+	if len(tables) != 2 {
+		t.Fatalf("Want 2 tables, have %d", len(tables))
+	}
+	if tables[0].Name != "test_results" || tables[0].Path != `data\test.dbf` || tables[0].Comment != "Test results" {
+		t.Errorf("Unexpected table %+v", tables[0])
+	}
+	if tables[0].RuleExpression != "id > 0" || tables[0].RuleText != "ID must be positive" ||
+		tables[0].InsertTrigger != "__ri_insert_test()" || tables[0].UpdateTrigger != "__ri_update_test()" ||
+		tables[0].DeleteTrigger != "__ri_delete_test()" {
+		t.Errorf("Unexpected rules and triggers %+v", tables[0])
+	}
+
+	relations := db.Relations()
+	want := Relation{ChildTable: "test_results", ChildTag: "usernr", ParentTable: "users", ParentTag: "usernr", RIInfo: "RRI"}
+	if len(relations) != 1 || relations[0] != want {
+		t.Errorf("Want relation %+v, have %+v", want, relations)
+	}
+
+	if len(db.opened) != 0 {
+		t.Error("Want tables to be opened lazily")
+	}
+	results, err := db.Table("TEST_RESULTS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.NumRecords() != 4 {
+		t.Errorf("Want 4 records, have %d", results.NumRecords())
+	}
+	again, err := db.Table("test_results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != results {
+		t.Error("Want the same DBF for the second call")
+	}
+	users, err := db.Table("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantProps := FieldProperties{LongName: "name_of_the_user", Comment: "Full name", Format: "!", InputMask: "XXXXXXXX",
+		RuleExpression: "!EMPTY(name_of_the_user)", RuleText: "Name is required"}
+	if props := users.FieldProperties(1); props != wantProps {
+		t.Errorf("Want field properties %+v, have %+v", wantProps, props)
+	}
+	if caption := users.FieldProperties(0).Caption; caption != "User number" {
+		t.Errorf("Want caption User number, have %q", caption)
+	}
+	if props := results.FieldProperties(0); props != (FieldProperties{}) {
+		t.Errorf("Want no field properties for a table without field objects, have %+v", props)
+	}
+	rec, err := users.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.FieldSlice()[1] != "Jan                 " {
+		t.Errorf("Want user Jan, have %q", rec.FieldSlice()[1])
+	}
+	if byFile, err := db.Table("TEST.DBF"); err != nil || byFile != results {
+		t.Errorf("Want the test_results table by file name, have %v", err)
+	}
+
+	file, err := db.TableFile("Test_Results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantFile := filepath.Join(dir, "data", "test.dbf"); file != wantFile {
+		t.Errorf("Want file %s, have %s", wantFile, file)
+	}
+	for _, filename := range []string{"test.dbf", file, filepath.Join(dir, "DATA", "TEST.DBF")} {
+		if name, err := db.TableName(filename); err != nil || name != "test_results" {
+			t.Errorf("Want test_results for %s, have %q (%v)", filename, name, err)
+		}
+	}
+	if _, err := db.TableName(filepath.Join(dir, "test.dbf")); err != ErrTableNotFound {
+		t.Errorf("Want ErrTableNotFound for a file in another directory, have %v", err)
+	}
+	if _, err := db.TableFile("orders"); err != ErrTableNotFound {
+		t.Errorf("Want ErrTableNotFound, have %v", err)
+	}
+	if _, err := db.Table("orders"); err != ErrTableNotFound {
+		t.Errorf("Want ErrTableNotFound, have %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.opened) != 0 {
+		t.Error("Want no open tables after Close")
+	}
+
+	if _, err := OpenDatabase(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder)); err == nil {
+		t.Error("Want an error opening a table as database")
+	}
+}
+
+func TestOpenDatabaseFieldTypes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfdatabase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fields := func(objectID, property FieldHeader) []FieldHeader {
+		return []FieldHeader{
+			objectID,
+			newField("PARENTID", 'I', 0, 0),
+			newField("OBJECTTYPE", 'C', 10, 0),
+			newField("OBJECTNAME", 'C', 128, 0),
+			property,
+			newField("RIINFO", 'C', 6, 0),
+		}
+	}
+	tests := [][]FieldHeader{
+		fields(newField("OBJECTID", 'N', 10, 0), newField("PROPERTY", 'M', 0, 0)),
+		fields(newField("OBJECTID", 'I', 0, 0), newField("PROPERTY", 'C', 4, 0)),
+	}
+	for i, test := range tests {
+		filename := filepath.Join(dir, fmt.Sprintf("app%d.dbc", i))
+		dbffile, err := os.Create(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fptfile, err := os.Create(memoFileName(filename))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wr, err := NewWriter(dbffile, fptfile, test, new(Win1250Encoder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := wr.Close(); err != nil {
+			t.Fatal(err)
+		}
+		dbffile.Close()
+		fptfile.Close()
+
+		_, err = OpenDatabase(filename, new(Win1250Decoder))
+		if err == nil || !strings.Contains(err.Error(), "not a database container") {
+			t.Errorf("Test %d: want not a database container, have %v", i, err)
+		}
+	}
+}
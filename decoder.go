@@ -13,6 +13,9 @@ import (
 
 var ErrInvalidUTF8 = errors.New("invalid UTF-8 data")
 
+// ErrNotASCII is returned by an ASCIIDecoder without fallback when the data is not ASCII
+var ErrNotASCII = errors.New("non-ASCII data")
+
 // The charset decoding is all done in this file so you could use a different decoder
 
 // Decoder is the interface as passed to OpenFile
@@ -70,3 +73,31 @@ func (d *Big5Decoder) Decode(in []byte) ([]byte, error) {
 	}
 	return data, nil
 }
+
+// ASCIIDecoder is a fast decoder for tables which only contain ASCII text, ASCII data is returned as is.
+// Data with other bytes is decoded with Fallback, or ErrNotASCII is returned when Fallback is nil.
+// To skip the decoder entirely for columns which are known to be ASCII, use DBF.SetASCIIFields.
+type ASCIIDecoder struct {
+	Fallback Decoder
+}
+
+// Decode returns an ASCII byte slice unchanged and uses the fallback decoder for other data
+func (d *ASCIIDecoder) Decode(in []byte) ([]byte, error) {
+	if isASCII(in) {
+		return in, nil
+	}
+	if d.Fallback != nil {
+		return d.Fallback.Decode(in)
+	}
+	return nil, ErrNotASCII
+}
+
+// isASCII returns true when b only contains bytes below 0x80
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
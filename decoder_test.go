@@ -55,4 +55,29 @@ func TestUTF8UTF8Validator_Decode(t *testing.T) {
 
 
 
-}
\ No newline at end of file
+}
+func TestASCIIDecoder_Decode(t *testing.T) {
+	dec := new(ASCIIDecoder)
+	in := []byte("Testing 123")
+	b, err := dec.Decode(in)
+	if err != nil {
+		t.Fatalf("error in decode: %s", err)
+	}
+	if bytes.Equal(in, b) == false {
+		t.Errorf("Want %s, have %s", string(in), string(b))
+	}
+
+	in = []byte{0xC4, 0xF5}
+	if _, err = dec.Decode(in); err != ErrNotASCII {
+		t.Fatalf("wanted error %s, have %v", ErrNotASCII, err)
+	}
+
+	dec.Fallback = new(Win1250Decoder)
+	b, err = dec.Decode(in)
+	if err != nil {
+		t.Fatalf("error in decode: %s", err)
+	}
+	if want := "Äő"; string(b) != want {
+		t.Errorf("Want %s, have %s", want, string(b))
+	}
+}
@@ -0,0 +1,68 @@
+package dbf
+
+import (
+	"fmt"
+	"math"
+)
+
+// SetDefaultValues sets FoxPro expressions for the values of fields which are appended with a nil value,
+// like the DefaultValue of fields in a database container. The map is keyed by field name,
+// empty expressions are ignored. Expressions are evaluated for every appended record, so DATE() or DATETIME()
+// give the time of the append. Expressions cannot refer to fields.
+func (wr *Writer) SetDefaultValues(exprs map[string]string) error {
+	defaults := make([]exprNode, len(wr.fields))
+	for name, expr := range exprs {
+		if expr == "" {
+			continue
+		}
+		pos := -1
+		for i := range wr.fields {
+			if wr.fields[i].FieldName() == name {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			return fmt.Errorf("field %s not found", name)
+		}
+		node, err := parseExpr(expr)
+		if err != nil {
+			return fmt.Errorf("default value of field %s: %s", name, err)
+		}
+		defaults[pos] = node
+	}
+	wr.defaults = defaults
+	return nil
+}
+
+// defaultValue evaluates the default value of the field at pos
+func (wr *Writer) defaultValue(pos int) (interface{}, error) {
+	val, err := wr.defaults[pos].eval(nil)
+	if err != nil {
+		return nil, err
+	}
+	// expressions return numbers as float64, which cannot be written to I fields
+	if f, ok := val.(float64); ok && wr.fields[pos].Type == 'I' {
+		if f != math.Trunc(f) {
+			return nil, fmt.Errorf("value %v is not an integer", f)
+		}
+		return int64(f), nil
+	}
+	return val, nil
+}
+
+// DefaultValues returns the DefaultValue expressions of the fields of a table by field name,
+// to be used with Writer.SetDefaultValues. The table is opened if needed to read the field names.
+func (db *Database) DefaultValues(table string) (map[string]string, error) {
+	dbf, err := db.Table(table)
+	if err != nil {
+		return nil, err
+	}
+	defaults := make(map[string]string)
+	for i, f := range dbf.fields {
+		if expr := dbf.FieldProperties(i).DefaultValue; expr != "" {
+			defaults[f.FieldName()] = expr
+		}
+	}
+	return defaults, nil
+}
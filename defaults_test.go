@@ -0,0 +1,100 @@
+package dbf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriterDefaultValues(t *testing.T) {
+	out := new(memWriteSeeker)
+	wr, err := NewWriter(out, nil, []FieldHeader{
+		newField("NAME", 'C', 10, 0),
+		newField("QTY", 'I', 0, 0),
+		newField("PRICE", 'N', 8, 2),
+		newField("CREATED", 'D', 0, 0),
+		newField("ACTIVE", 'L', 0, 0),
+	}, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.SetDefaultValues(map[string]string{"NOTEXIST": "1"}); err == nil {
+		t.Error("Want an error for a missing field")
+	}
+	if err := wr.SetDefaultValues(map[string]string{"QTY": "1 +"}); err == nil {
+		t.Error("Want an error for an invalid expression")
+	}
+	err = wr.SetDefaultValues(map[string]string{
+		"NAME":    "'new'",
+		"QTY":     "2 * 3",
+		"PRICE":   "2.5 * 2",
+		"CREATED": "DATE()",
+		"ACTIVE":  ".T.",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append(nil, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append("given", 1, 0.5, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.SetDefaultValues(map[string]string{"QTY": "1.5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append(nil, nil, nil, nil, nil); err == nil {
+		t.Error("Want an error for a fraction as default of an I field")
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dbf, err := OpenStream(bytes.NewReader(out.buf), nil, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	want := [][]interface{}{
+		{"new       ", int32(6), float64(5), today, true},
+		{"given     ", int32(1), 0.5, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), false},
+	}
+	for i, values := range want {
+		rec, err := dbf.RecordAt(uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rec.FieldSlice(), values) {
+			t.Errorf("Record %d: want %v, have %v", i, values, rec.FieldSlice())
+		}
+	}
+}
+
+func TestDatabaseDefaultValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfdefaults")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := OpenDatabase(writeTestDatabase(t, dir), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	defaults, err := db.DefaultValues("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"USERNR": "1"}; !reflect.DeepEqual(defaults, want) {
+		t.Errorf("Want %v, have %v", want, defaults)
+	}
+	if _, err := db.DefaultValues("notexist"); err != ErrTableNotFound {
+		t.Errorf("Want ErrTableNotFound, have %v", err)
+	}
+}
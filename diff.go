@@ -0,0 +1,117 @@
+package dbf
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldDiff is a field with a different value in two records, see DiffRecords
+type FieldDiff struct {
+	Field string      // name of the field
+	A, B  interface{} // value in the first and second record, nil if the record has no such field
+}
+
+// RecordsEqual returns if records a and b have the same values, the fields in ignoreFields are not compared.
+// See DiffRecords for how values are compared.
+func RecordsEqual(a, b *Record, ignoreFields ...string) bool {
+	return len(DiffRecords(a, b, ignoreFields...)) == 0
+}
+
+// DiffRecords returns the fields with a different value in records a and b, in the field order of a followed by
+// the fields only b has. Fields are matched by name, the names in ignoreFields (case insensitive) are skipped,
+// like audit timestamps which change on every save. Records which were not read from a table are matched by
+// field position, their fields are named by position.
+//
+// Trailing spaces of strings are not compared, so C fields of different lengths can be equal. Dates and times
+// are compared with time.Time.Equal, numbers by value and NaN equals NaN. The deletion flag is not compared.
+func DiffRecords(a, b *Record, ignoreFields ...string) []FieldDiff {
+	ignore := make(map[string]bool, len(ignoreFields))
+	for _, name := range ignoreFields {
+		ignore[strings.ToUpper(name)] = true
+	}
+
+	namesA, namesB := a.diffNames(), b.diffNames()
+	posB := make(map[string]int, len(namesB))
+	for i, name := range namesB {
+		posB[name] = i
+	}
+
+	var diffs []FieldDiff
+	seen := make(map[string]bool, len(namesA))
+	for i, name := range namesA {
+		seen[name] = true
+		if name == "" || ignore[name] {
+			continue
+		}
+		var valB interface{}
+		if j, ok := posB[name]; ok {
+			valB = b.data[j]
+		} else {
+			diffs = append(diffs, FieldDiff{Field: name, A: a.data[i]})
+			continue
+		}
+		if !valuesEqual(a.data[i], valB) {
+			diffs = append(diffs, FieldDiff{Field: name, A: a.data[i], B: valB})
+		}
+	}
+	for j, name := range namesB {
+		if name != "" && !seen[name] && !ignore[name] {
+			diffs = append(diffs, FieldDiff{Field: name, B: b.data[j]})
+		}
+	}
+	return diffs
+}
+
+// diffNames returns the field names of the record, or the positions for records not read from a table.
+// System fields like _NullFlags get an empty name and are not compared, null values are compared as nil.
+func (r *Record) diffNames() []string {
+	names := make([]string, len(r.data))
+	for i := range r.data {
+		switch {
+		case r.fields == nil:
+			names[i] = strconv.Itoa(i)
+		case r.fields[i].System():
+			names[i] = ""
+		default:
+			names[i] = strings.ToUpper(r.fields[i].FieldName())
+		}
+	}
+	return names
+}
+
+// valuesEqual compares two field values
+func valuesEqual(a, b interface{}) bool {
+	switch va := a.(type) {
+	case string:
+		if vb, ok := b.(string); ok {
+			return strings.TrimRight(va, " ") == strings.TrimRight(vb, " ")
+		}
+	case []byte:
+		if vb, ok := b.([]byte); ok {
+			return bytes.Equal(va, vb)
+		}
+	case time.Time:
+		if vb, ok := b.(time.Time); ok {
+			return va.Equal(vb)
+		}
+	case float64, int64, int32:
+		if isNaN(a) || isNaN(b) {
+			return isNaN(a) && isNaN(b)
+		}
+		// numbers of different field types, like I and N, are compared by value
+		fa, _ := ToFloat64E(va)
+		if fb, err := ToFloat64E(b); err == nil {
+			return fa == fb
+		}
+	}
+	return a == b
+}
+
+// isNaN returns if v is a float64 NaN
+func isNaN(v interface{}) bool {
+	f, ok := v.(float64)
+	return ok && math.IsNaN(f)
+}
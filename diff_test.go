@@ -0,0 +1,82 @@
+package dbf
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffRecords(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	a, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !RecordsEqual(a, b) {
+		t.Error("Want equal records")
+	}
+
+	b.data[1] = int64(9)                                    // NIVEAU
+	b.data[2] = time.Date(2016, 1, 3, 0, 0, 0, 0, time.UTC) // DATUM
+	if RecordsEqual(a, b) {
+		t.Error("Want different records")
+	}
+	diffs := DiffRecords(a, b)
+	want := []FieldDiff{
+		{Field: "NIVEAU", A: int64(0), B: int64(9)},
+		{Field: "DATUM", A: a.data[2], B: b.data[2]},
+	}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Errorf("Want %v, have %v", want, diffs)
+	}
+	if !RecordsEqual(a, b, "niveau", "DATUM") {
+		t.Error("Want equal records when ignoring NIVEAU and DATUM")
+	}
+
+	c, err := dbf.RecordAt(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := DiffRecords(a, c, "ID", "MELDING"); len(diffs) != 10 {
+		t.Errorf("Want 10 differences, have %d: %v", len(diffs), diffs)
+	}
+}
+
+func TestDiffRecordsFields(t *testing.T) {
+	// records with other fields and types, matched by name
+	a := &Record{
+		fields: []FieldHeader{newField("NAME", 'C', 10, 0), newField("COUNT", 'I', 4, 0), newField("OLD", 'L', 1, 0)},
+		data:   []interface{}{"Jan       ", int32(3), true},
+	}
+	b := &Record{
+		fields: []FieldHeader{newField("COUNT", 'N', 5, 0), newField("NAME", 'C', 20, 0), newField("NEW", 'N', 5, 2)},
+		data:   []interface{}{int64(3), "Jan", math.NaN()},
+	}
+	diffs := DiffRecords(a, b)
+	want := []FieldDiff{{Field: "OLD", A: true}, {Field: "NEW", B: b.data[2]}}
+	if len(diffs) != 2 || diffs[0] != want[0] || diffs[1].Field != "NEW" || diffs[1].A != nil {
+		t.Errorf("Want %v, have %v", want, diffs)
+	}
+
+	// records not read from a table are matched by position
+	x := &Record{data: []interface{}{[]byte{1, 2}, math.NaN(), nil}}
+	y := &Record{data: []interface{}{[]byte{1, 2}, math.NaN(), nil}}
+	if !RecordsEqual(x, y) {
+		t.Errorf("Want equal records, have %v", DiffRecords(x, y))
+	}
+	y.data[0] = []byte{1}
+	if diffs := DiffRecords(x, y); len(diffs) != 1 || diffs[0].Field != "0" {
+		t.Errorf("Want a difference in field 0, have %v", diffs)
+	}
+}
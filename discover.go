@@ -0,0 +1,102 @@
+package dbf
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// FileSet is a table with the companion files found by DiscoverSet
+type FileSet struct {
+	Table string   // the table
+	Memo  string   // the memo file, empty when none was found
+	Index string   // the structural index, empty when none was found
+	Extra []string // other files with the name of the table, like IDX indexes or a memo file of another format
+
+	MemoMissing  bool // the table has a memo file according to its header, but it was not found
+	IndexMissing bool // the table has a structural index according to its header, but it was not found
+	OrphanMemo   bool // a memo file was found, but the table has no memo file according to its header
+	OrphanIndex  bool // a structural index was found, but the table has none according to its header
+}
+
+// DiscoverSet finds the memo file and index files of the table at path. Files belong to the table when they are in
+// the same directory and have the same name, ignoring case. The memo file can have any of the memo extensions
+// (FPT, DBT, SMT), the extension of the file version is preferred when there are more. The structural index is
+// the CDX file, for database containers the memo file is the DCT file and the index the DCX file.
+// The header tells which files the table should have, see Problems for the files which are missing or orphaned.
+func DiscoverSet(path string) (*FileSet, error) {
+	files, err := tableFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	h, err := readHeaderFile(files[0])
+	if err != nil {
+		return nil, err
+	}
+
+	memoExt, indexExt := "."+strings.ToLower(LookupFileVersion(h.FileVersion).MemoFile), ".cdx"
+	memoExts := map[string]bool{".fpt": true, ".dbt": true, ".smt": true}
+	if strings.EqualFold(filepath.Ext(path), ".dbc") {
+		memoExt, indexExt = ".dct", ".dcx"
+		memoExts = map[string]bool{".dct": true}
+	}
+
+	set := &FileSet{Table: files[0]}
+	var memos []string
+	for _, name := range files[1:] {
+		switch ext := strings.ToLower(filepath.Ext(name)); {
+		case memoExts[ext]:
+			memos = append(memos, name)
+		case ext == indexExt && set.Index == "":
+			set.Index = name
+		default:
+			set.Extra = append(set.Extra, name)
+		}
+	}
+	// the memo file of the file version, or the first one found
+	for _, name := range memos {
+		if set.Memo == "" || strings.EqualFold(filepath.Ext(name), memoExt) && !strings.EqualFold(filepath.Ext(set.Memo), memoExt) {
+			set.Memo = name
+		}
+	}
+	for _, name := range memos {
+		if name != set.Memo {
+			set.Extra = append(set.Extra, name)
+		}
+	}
+
+	set.MemoMissing = h.HasMemo() && set.Memo == ""
+	set.OrphanMemo = !h.HasMemo() && set.Memo != ""
+	set.IndexMissing = h.HasCDX() && set.Index == ""
+	set.OrphanIndex = !h.HasCDX() && set.Index != ""
+	return set, nil
+}
+
+// Files returns the table and all companion files which were found
+func (s *FileSet) Files() []string {
+	files := []string{s.Table}
+	for _, name := range []string{s.Memo, s.Index} {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return append(files, s.Extra...)
+}
+
+// Problems returns a description of the missing and orphaned files, nil when there are none
+func (s *FileSet) Problems() []string {
+	var problems []string
+	if s.MemoMissing {
+		problems = append(problems, fmt.Sprintf("the memo file of %s is missing", filepath.Base(s.Table)))
+	}
+	if s.OrphanMemo {
+		problems = append(problems, fmt.Sprintf("%s is not used, %s has no memo file", filepath.Base(s.Memo), filepath.Base(s.Table)))
+	}
+	if s.IndexMissing {
+		problems = append(problems, fmt.Sprintf("the structural index of %s is missing", filepath.Base(s.Table)))
+	}
+	if s.OrphanIndex {
+		problems = append(problems, fmt.Sprintf("%s is not used and not updated, %s has no structural index", filepath.Base(s.Index), filepath.Base(s.Table)))
+	}
+	return problems
+}
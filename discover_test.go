@@ -0,0 +1,71 @@
+package dbf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfdiscover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	copyTo := func(src, dst string) {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, dst), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	copyTo("TEST.DBF", "TEST.DBF")
+	copyTo("TEST.FPT", "test.Fpt")
+	copyTo("TEST.FPT", "TEST.DBT")
+	copyTo("TEST.DBF", "Test.cdx")
+	copyTo("TEST.DBF", "TEST.IDX")
+	copyTo("TEST.DBF", "TEST2.CDX")
+	table := filepath.Join(dir, "TEST.DBF")
+
+	set, err := DiscoverSet(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &FileSet{
+		Table:       table,
+		Memo:        filepath.Join(dir, "test.Fpt"),
+		Index:       filepath.Join(dir, "Test.cdx"),
+		Extra:       []string{filepath.Join(dir, "TEST.IDX"), filepath.Join(dir, "TEST.DBT")},
+		OrphanIndex: true,
+	}
+	if !reflect.DeepEqual(set, want) {
+		t.Errorf("Want %+v, have %+v", want, set)
+	}
+	if n := len(set.Files()); n != 5 {
+		t.Errorf("Want 5 files, have %d", n)
+	}
+	if problems := set.Problems(); len(problems) != 1 {
+		t.Errorf("Want the orphaned index as the only problem, have %q", problems)
+	}
+
+	for _, name := range []string{"test.Fpt", "TEST.DBT", "Test.cdx"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set, err = DiscoverSet(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.MemoMissing || set.OrphanIndex || set.Memo != "" || set.Index != "" {
+		t.Errorf("Want a missing memo file and no index, have %+v", set)
+	}
+
+	if _, err := DiscoverSet(filepath.Join(dir, "MISSING.DBF")); !os.IsNotExist(err) {
+		t.Errorf("Want a not exist error, have %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package dbf
+
+import (
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// The charset encoding for writing is done in this file, it is the inverse of decoder.go
+
+// Encoder is the interface as passed to NewWriter, it converts UTF8 to the charset of the table
+type Encoder interface {
+	Encode(in []byte) ([]byte, error)
+}
+
+// Win1250Encoder translates UTF8 to Windows-1250
+type Win1250Encoder struct{}
+
+// Encode encodes a UTF8 byte slice to a Windows-1250 byte slice
+func (e *Win1250Encoder) Encode(in []byte) ([]byte, error) {
+	return charmap.Windows1250.NewEncoder().Bytes(in)
+}
+
+// UTF8Encoder writes UTF8 as is
+type UTF8Encoder struct{}
+
+// Encode returns the UTF8 input unchanged
+func (e *UTF8Encoder) Encode(in []byte) ([]byte, error) {
+	return in, nil
+}
+
+// Big5Encoder translates UTF8 to Big5 (Traditional Chinese)
+type Big5Encoder struct{}
+
+// Encode encodes a UTF8 byte slice to a Big5 byte slice
+func (e *Big5Encoder) Encode(in []byte) ([]byte, error) {
+	return traditionalchinese.Big5.NewEncoder().Bytes(in)
+}
+
+// ASCIIEncoder is a fast encoder for tables which only contain ASCII text, ASCII data is returned as is.
+// Data with other characters is encoded with Fallback, or ErrNotASCII is returned when Fallback is nil.
+type ASCIIEncoder struct {
+	Fallback Encoder
+}
+
+// Encode returns an ASCII byte slice unchanged and uses the fallback encoder for other data
+func (e *ASCIIEncoder) Encode(in []byte) ([]byte, error) {
+	if isASCII(in) {
+		return in, nil
+	}
+	if e.Fallback != nil {
+		return e.Fallback.Encode(in)
+	}
+	return nil, ErrNotASCII
+}
+
+// codePageEncodings are the charsets of the code page marks which can be encoded, see codepage.go
+var codePageEncodings = map[byte]encoding.Encoding{
+	0x01: charmap.CodePage437,
+	0x02: charmap.CodePage850,
+	0x64: charmap.CodePage852,
+	0x65: charmap.CodePage866,
+	0x66: charmap.CodePage865,
+	0x7C: charmap.Windows874,
+	0x7B: japanese.ShiftJIS,
+	0x7A: simplifiedchinese.GBK,
+	0x79: korean.EUCKR,
+	0x78: traditionalchinese.Big5,
+	0xC8: charmap.Windows1250,
+	0xC9: charmap.Windows1251,
+	0x03: charmap.Windows1252,
+	0xCB: charmap.Windows1253,
+	0xCA: charmap.Windows1254,
+	0x7D: charmap.Windows1255,
+	0x7E: charmap.Windows1256,
+	0x04: charmap.Macintosh,
+	0x96: charmap.MacintoshCyrillic,
+}
+
+// CodePageEncoder returns an Encoder which translates UTF8 to the code page of a code page mark, like the
+// CodePage of a DBFHeader, so text is written in the charset of the table. ok is false for 0x00 (no code page)
+// and for the code pages which can not be encoded: 620, 737, 857, 861, 895, 10006 and 10029.
+// Characters which do not exist in the code page return an error.
+func CodePageEncoder(mark byte) (enc Encoder, ok bool) {
+	e, ok := codePageEncodings[mark]
+	if !ok {
+		return nil, false
+	}
+	return &charsetEncoder{charset: e}, true
+}
+
+// charsetEncoder translates UTF8 to a charset of golang.org/x/text
+type charsetEncoder struct {
+	charset encoding.Encoding
+}
+
+// Encode encodes a UTF8 byte slice to the charset
+func (e *charsetEncoder) Encode(in []byte) ([]byte, error) {
+	return e.charset.NewEncoder().Bytes(in)
+}
+
+// encoderCodePage returns the code page mark of the charset of enc, which NewWriter writes to the header,
+// or 0 when the charset has no code page mark, like UTF8
+func encoderCodePage(enc Encoder) byte {
+	switch e := enc.(type) {
+	case *Win1250Encoder:
+		return 0xC8
+	case *Big5Encoder:
+		return 0x78
+	case *ASCIIEncoder:
+		return encoderCodePage(e.Fallback)
+	case *charsetEncoder:
+		for mark, charset := range codePageEncodings {
+			if charset == e.charset {
+				return mark
+			}
+		}
+	}
+	return 0
+}
+
+// decoderEncoder returns the Encoder for the charset of dec, nil when it is not known
+func decoderEncoder(dec Decoder) Encoder {
+	switch d := dec.(type) {
+	case *Win1250Decoder:
+		return new(Win1250Encoder)
+	case *UTF8Decoder, *UTF8Validator:
+		return new(UTF8Encoder)
+	case *Big5Decoder:
+		return new(Big5Encoder)
+	case *ASCIIDecoder:
+		if d.Fallback == nil {
+			return new(ASCIIEncoder)
+		}
+		if enc := decoderEncoder(d.Fallback); enc != nil {
+			return &ASCIIEncoder{Fallback: enc}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package dbf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUTF8Encoder_Encode(t *testing.T) {
+	enc := new(UTF8Encoder)
+	in := []byte("Tésting ㇹ Д")
+	b, err := enc.Encode(in)
+	if err != nil {
+		t.Fatalf("error in encode: %s", err)
+	}
+	if bytes.Equal(in, b) == false {
+		t.Errorf("Want %s, have %s", string(in), string(b))
+	}
+}
+
+func TestWin1250Encoder_Encode(t *testing.T) {
+	enc := new(Win1250Encoder)
+	b, err := enc.Encode([]byte("Äő"))
+	if err != nil {
+		t.Fatalf("error in encode: %s", err)
+	}
+	want := []byte{0xC4, 0xF5}
+	if bytes.Equal(want, b) == false {
+		t.Errorf("Want %v, have %v", want, b)
+	}
+
+	// characters which do not exist in Windows-1250
+	if _, err := enc.Encode([]byte("ㇹ")); err == nil {
+		t.Error("wanted an error in Encode, but have no error")
+	}
+}
+
+func TestBig5Encoder_Encode(t *testing.T) {
+	in := []byte("中文")
+	b, err := new(Big5Encoder).Encode(in)
+	if err != nil {
+		t.Fatalf("error in encode: %s", err)
+	}
+	out, err := new(Big5Decoder).Decode(b)
+	if err != nil {
+		t.Fatalf("error in decode: %s", err)
+	}
+	if bytes.Equal(in, out) == false {
+		t.Errorf("Want %s, have %s", string(in), string(out))
+	}
+}
+
+func TestASCIIEncoder_Encode(t *testing.T) {
+	in := []byte("ABC 123")
+	b, err := new(ASCIIEncoder).Encode(in)
+	if err != nil || !bytes.Equal(in, b) {
+		t.Errorf("Want %s unchanged, have %s (%v)", in, b, err)
+	}
+	if _, err := new(ASCIIEncoder).Encode([]byte("Äő")); err != ErrNotASCII {
+		t.Errorf("Want ErrNotASCII, have %v", err)
+	}
+	b, err = (&ASCIIEncoder{Fallback: new(Win1250Encoder)}).Encode([]byte("Äő"))
+	if err != nil || !bytes.Equal(b, []byte{0xC4, 0xF5}) {
+		t.Errorf("Want the fallback encoding, have %v (%v)", b, err)
+	}
+}
+
+func TestCodePageEncoder(t *testing.T) {
+	tests := []struct {
+		mark byte
+		in   string
+		want []byte
+	}{
+		{0xC8, "Äő", []byte{0xC4, 0xF5}},
+		{0x03, "é", []byte{0xE9}},
+		{0x65, "Д", []byte{0x84}},
+		{0x01, "é", []byte{0x82}},
+		{0x78, "中", []byte{0xA4, 0xA4}},
+	}
+	for _, test := range tests {
+		enc, ok := CodePageEncoder(test.mark)
+		if !ok {
+			t.Fatalf("Want an encoder for 0x%02X", test.mark)
+		}
+		b, err := enc.Encode([]byte(test.in))
+		if err != nil || !bytes.Equal(b, test.want) {
+			t.Errorf("0x%02X: want % x, have % x (%v)", test.mark, test.want, b, err)
+		}
+	}
+	for mark := range codePageEncodings {
+		if _, ok := LookupCodePage(mark); !ok {
+			t.Errorf("Encoder for unknown code page mark 0x%02X", mark)
+		}
+	}
+	for _, mark := range []byte{0x00, 0x6A, 0xFF} {
+		if _, ok := CodePageEncoder(mark); ok {
+			t.Errorf("Want no encoder for 0x%02X", mark)
+		}
+	}
+	if enc, _ := CodePageEncoder(0xC8); enc != nil {
+		if _, err := enc.Encode([]byte("ㇹ")); err == nil {
+			t.Error("Want an error for a character which is not in the code page")
+		}
+	}
+}
+
+func TestEncoderCodePage(t *testing.T) {
+	tests := []struct {
+		enc  Encoder
+		mark byte
+	}{
+		{new(Win1250Encoder), 0xC8},
+		{new(Big5Encoder), 0x78},
+		{new(UTF8Encoder), 0},
+		{new(ASCIIEncoder), 0},
+		{&ASCIIEncoder{Fallback: new(Win1250Encoder)}, 0xC8},
+	}
+	for mark := range codePageEncodings {
+		enc, _ := CodePageEncoder(mark)
+		tests = append(tests, struct {
+			enc  Encoder
+			mark byte
+		}{enc, mark})
+	}
+	for _, test := range tests {
+		if mark := encoderCodePage(test.enc); mark != test.mark {
+			t.Errorf("%T: want code page 0x%02X, have 0x%02X", test.enc, test.mark, mark)
+		}
+		wr, err := NewWriter(new(memWriteSeeker), nil, []FieldHeader{newField("NAME", 'C', 10, 0)}, test.enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wr.Header().CodePage != test.mark {
+			t.Errorf("%T: want code page 0x%02X in the header, have 0x%02X", test.enc, test.mark, wr.Header().CodePage)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package dbf
+
+import "errors"
+
+// ErrEncrypted is returned when opening an encrypted table without a Decrypter
+var ErrEncrypted = errors.New("table is encrypted, open it with OpenFileDecrypted or OpenStreamDecrypted")
+
+// Decrypter decrypts the records of a table encrypted by dBASE IV (PROTECT).
+// The encryption algorithm is not documented, so no implementation is provided by this package.
+type Decrypter interface {
+	// Decrypt decrypts the record data at recno in place, data is the complete record including the deletion flag
+	Decrypt(recno uint32, data []byte) error
+}
+
+// DecrypterFunc is a function which can be used as Decrypter
+type DecrypterFunc func(recno uint32, data []byte) error
+
+// Decrypt calls f(recno, data)
+func (f DecrypterFunc) Decrypt(recno uint32, data []byte) error {
+	return f(recno, data)
+}
+
+// Encrypted returns if the encryption flag of dBASE IV is set in the header
+func (h *DBFHeader) Encrypted() bool {
+	// byte 15 of the header, which is part of the reserved bytes in FoxPro
+	return h.Reserved[3] == 0x01
+}
+
+// OpenFileDecrypted is OpenFile for tables encrypted by dBASE IV, the records are decrypted when read.
+// The Decrypter is not used for tables which are not encrypted.
+// Note that dBASE IV tables also require overriding ValidFileVersionFunc, see SetValidFileVersionFunc.
+func OpenFileDecrypted(filename string, dec Decoder, decrypter Decrypter) (*DBF, error) {
+	return openFile(filename, dec, ShareReadWrite, decrypter, false)
+}
+
+// OpenStreamDecrypted is OpenStream for tables encrypted by dBASE IV, the records are decrypted when read.
+// The Decrypter is not used for tables which are not encrypted.
+func OpenStreamDecrypted(dbffile, fptfile ReaderAtSeeker, dec Decoder, decrypter Decrypter) (*DBF, error) {
+	return openStream(dbffile, fptfile, dec, decrypter)
+}
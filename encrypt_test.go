@@ -0,0 +1,86 @@
+package dbf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpenStreamDecrypted(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	memo, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := OpenStream(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "encrypt" the records by inverting all bits
+	encrypted := append([]byte{}, data...)
+	encrypted[15] = 0x01
+	first := int(plain.Header().FirstRec)
+	end := first + int(plain.NumRecords())*int(plain.Header().RecLen)
+	for i := first; i < end; i++ {
+		encrypted[i] ^= 0xFF
+	}
+	decrypter := DecrypterFunc(func(recno uint32, data []byte) error {
+		for i := range data {
+			data[i] ^= 0xFF
+		}
+		return nil
+	})
+
+	if _, err := OpenStream(bytes.NewReader(encrypted), bytes.NewReader(memo), new(Win1250Decoder)); err != ErrEncrypted {
+		t.Fatalf("Want ErrEncrypted, have %v", err)
+	}
+
+	dbf, err := OpenStreamDecrypted(bytes.NewReader(encrypted), bytes.NewReader(memo), new(Win1250Decoder), decrypter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dbf.Header().Encrypted() {
+		t.Error("Want an encrypted header")
+	}
+	for recno := uint32(0); recno < plain.NumRecords(); recno++ {
+		want, err := plain.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have, err := dbf.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want.FieldSlice(), have.FieldSlice()) || want.Deleted != have.Deleted {
+			t.Errorf("Record %d: want %v, have %v", recno, want.FieldSlice(), have.FieldSlice())
+		}
+		deleted, err := dbf.DeletedAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted != want.Deleted {
+			t.Errorf("Record %d: want deleted %t, have %t", recno, want.Deleted, deleted)
+		}
+	}
+	if err := dbf.GoTo(2); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := dbf.Field(dbf.FieldPos("COMP_OS")); err != nil || ToTrimmedString(val) != "Windows 7 SP1" {
+		t.Errorf("Want COMP_OS Windows 7 SP1, have %v (%v)", val, err)
+	}
+
+	// tables which are not encrypted ignore the decrypter
+	notEncrypted, err := OpenStreamDecrypted(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder), decrypter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := notEncrypted.DeletedAt(1); err != nil || !deleted {
+		t.Errorf("Want record 1 deleted, have %t (%v)", deleted, err)
+	}
+}
@@ -0,0 +1,1086 @@
+package dbf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// This file contains a parser and evaluator for the subset of FoxPro expressions used in database containers
+// (default values, view definitions): literals, field references, parameters, operators and common functions.
+// String comparisons ignore trailing spaces, like comparisons of padded C fields in FoxPro with SET ANSI ON.
+
+// token kinds of the expression tokenizer
+const (
+	tokEOF = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokDate
+	tokOp
+	tokParam
+)
+
+type exprToken struct {
+	kind int
+	text string      // identifier (upper case for keywords is left to the parser), operator or parameter name
+	val  interface{} // value of number, string and date literals
+}
+
+// tokenizeExpr splits src into tokens, identifiers are returned as written and may contain a qualifier (alias.field)
+func tokenizeExpr(src string) ([]exprToken, error) {
+	var toks []exprToken
+	r := []rune(src)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '.' && dottedOperator(r[i:]) != "":
+			op := dottedOperator(r[i:])
+			i += len(op)
+			switch op {
+			case ".T.", ".Y.":
+				toks = append(toks, exprToken{kind: tokString, text: op, val: true})
+			case ".F.", ".N.":
+				toks = append(toks, exprToken{kind: tokString, text: op, val: false})
+			case ".NULL.":
+				toks = append(toks, exprToken{kind: tokString, text: op, val: nil})
+			default:
+				toks = append(toks, exprToken{kind: tokOp, text: strings.Trim(op, ".")})
+			}
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(r) && unicode.IsDigit(r[i+1])):
+			start := i
+			for i < len(r) && (unicode.IsDigit(r[i]) || r[i] == '.') {
+				i++
+			}
+			f, err := strconv.ParseFloat(string(r[start:i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s", string(r[start:i]))
+			}
+			toks = append(toks, exprToken{kind: tokNumber, text: string(r[start:i]), val: f})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(r) && (unicode.IsLetter(r[i]) || unicode.IsDigit(r[i]) || r[i] == '_' ||
+				(r[i] == '.' && i+1 < len(r) && dottedOperator(r[i:]) == "" && (unicode.IsLetter(r[i+1]) || r[i+1] == '_'))) {
+				i++
+			}
+			toks = append(toks, exprToken{kind: tokIdent, text: string(r[start:i])})
+		case c == '\'' || c == '"' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			j := i + 1
+			for j < len(r) && r[j] != end {
+				j++
+			}
+			if j == len(r) {
+				return nil, errors.New("unterminated string")
+			}
+			toks = append(toks, exprToken{kind: tokString, text: string(r[i : j+1]), val: string(r[i+1 : j])})
+			i = j + 1
+		case c == '{':
+			j := i + 1
+			for j < len(r) && r[j] != '}' {
+				j++
+			}
+			if j == len(r) {
+				return nil, errors.New("unterminated date")
+			}
+			t, err := parseDateLiteral(string(r[i+1 : j]))
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, exprToken{kind: tokDate, text: string(r[i : j+1]), val: t})
+			i = j + 1
+		case c == '?':
+			j := i + 1
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_' || r[j] == '.') {
+				j++
+			}
+			if j == i+1 {
+				return nil, errors.New("missing parameter name after ?")
+			}
+			toks = append(toks, exprToken{kind: tokParam, text: string(r[i+1 : j])})
+			i = j
+		default:
+			op := string(c)
+			if i+1 < len(r) {
+				switch two := string(r[i : i+2]); two {
+				case "==", "<>", "!=", "<=", ">=", "**":
+					op = two
+				}
+			}
+			if !strings.Contains("=<>!#+-*/%^(),$.", string(c)) {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			toks = append(toks, exprToken{kind: tokOp, text: op})
+			i += len(op)
+		}
+	}
+	return append(toks, exprToken{kind: tokEOF}), nil
+}
+
+// dottedOperator returns the dotted operator or literal at the start of r (.AND., .T., ...) or an empty string
+func dottedOperator(r []rune) string {
+	upper := strings.ToUpper(string(r[:minInt(len(r), 6)]))
+	for _, op := range []string{".AND.", ".OR.", ".NOT.", ".NULL.", ".T.", ".F.", ".Y.", ".N."} {
+		if strings.HasPrefix(upper, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// parseDateLiteral parses the contents of a strict date literal like {^2020-12-31} or {^2020-12-31 10:30:00},
+// an empty literal {} is an empty date
+func parseDateLiteral(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "/" || s == ":" {
+		return time.Time{}, nil
+	}
+	s = strings.Replace(strings.TrimPrefix(s, "^"), "/", "-", -1)
+	for _, layout := range []string{"2006-1-2", "2006-1-2 15:4", "2006-1-2 15:4:5", "2006-1-2T15:4:5", "2006-1-2 3:4:5 PM", "2006-1-2 3:4 PM"} {
+		if t, err := time.Parse(layout, strings.ToUpper(s)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date literal {%s}", s)
+}
+
+// exprEnv resolves field references and parameters during evaluation
+type exprEnv interface {
+	column(name string) (interface{}, error)
+	param(name string) (interface{}, error)
+}
+
+// exprNode is a parsed expression
+type exprNode interface {
+	eval(env exprEnv) (interface{}, error)
+}
+
+type (
+	literalNode struct{ val interface{} }
+	columnNode  struct{ name string }
+	paramNode   struct{ name string }
+	unaryNode   struct {
+		op string
+		x  exprNode
+	}
+	binaryNode struct {
+		op   string
+		l, r exprNode
+	}
+	callNode struct {
+		name string
+		args []exprNode
+	}
+	likeNode struct {
+		x, pattern exprNode
+		not        bool
+	}
+	isNullNode struct {
+		x   exprNode
+		not bool
+	}
+	inNode struct {
+		x    exprNode
+		list []exprNode
+		not  bool
+	}
+	betweenNode struct {
+		x, lo, hi exprNode
+		not       bool
+	}
+)
+
+// exprParser is a precedence climbing parser over a token stream, it is also used by the SQL parser,
+// parsing stops at the first token which cannot continue the expression
+type exprParser struct {
+	toks []exprToken
+	pos  int
+}
+
+// parseExpr parses a complete expression
+func parseExpr(src string) (exprNode, error) {
+	toks, err := tokenizeExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	node, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s", p.peek().text)
+	}
+	return node, nil
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.toks[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword returns if the next token is the identifier kw (case insensitive)
+func (p *exprParser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && strings.EqualFold(t.text, kw)
+}
+
+// isOp returns if the next token is the operator op
+func (p *exprParser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && strings.EqualFold(t.text, op)
+}
+
+// expect consumes the operator or keyword s or returns an error
+func (p *exprParser) expect(s string) error {
+	if p.isOp(s) || p.isKeyword(s) {
+		p.next()
+		return nil
+	}
+	return fmt.Errorf("expected %s, found %q", s, p.peek().text)
+}
+
+func (p *exprParser) expr() (exprNode, error) {
+	return p.or()
+}
+
+func (p *exprParser) or() (exprNode, error) {
+	l, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("OR") || p.isKeyword("OR") {
+		p.next()
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op: "OR", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) and() (exprNode, error) {
+	l, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("AND") || p.isKeyword("AND") {
+		p.next()
+		r, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op: "AND", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) not() (exprNode, error) {
+	if p.isOp("NOT") || p.isOp("!") || p.isKeyword("NOT") {
+		p.next()
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "NOT", x: x}, nil
+	}
+	return p.comparison()
+}
+
+// comparisonOps are the operators parsed by comparison
+var comparisonOps = map[string]bool{"=": true, "==": true, "<>": true, "!=": true, "#": true, "<": true, ">": true, "<=": true, ">=": true, "$": true}
+
+func (p *exprParser) comparison() (exprNode, error) {
+	l, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokOp && comparisonOps[t.text]:
+			p.next()
+			r, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			l = &binaryNode{op: t.text, l: l, r: r}
+		case p.isKeyword("IS"):
+			p.next()
+			not := false
+			if p.isKeyword("NOT") {
+				p.next()
+				not = true
+			}
+			if err := p.expect("NULL"); err != nil {
+				return nil, err
+			}
+			l = &isNullNode{x: l, not: not}
+		case p.isKeyword("LIKE"), p.isKeyword("IN"), p.isKeyword("BETWEEN"),
+			p.isKeyword("NOT") && p.pos+1 < len(p.toks) && p.toks[p.pos+1].kind == tokIdent:
+			not := false
+			if p.isKeyword("NOT") {
+				p.next()
+				not = true
+			}
+			kw := strings.ToUpper(p.next().text)
+			switch kw {
+			case "LIKE":
+				pattern, err := p.additive()
+				if err != nil {
+					return nil, err
+				}
+				l = &likeNode{x: l, pattern: pattern, not: not}
+			case "IN":
+				list, err := p.list()
+				if err != nil {
+					return nil, err
+				}
+				l = &inNode{x: l, list: list, not: not}
+			case "BETWEEN":
+				lo, err := p.additive()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect("AND"); err != nil {
+					return nil, err
+				}
+				hi, err := p.additive()
+				if err != nil {
+					return nil, err
+				}
+				l = &betweenNode{x: l, lo: lo, hi: hi, not: not}
+			default:
+				return nil, fmt.Errorf("unexpected NOT %s", kw)
+			}
+		default:
+			return l, nil
+		}
+	}
+}
+
+func (p *exprParser) additive() (exprNode, error) {
+	l, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.next().text
+		r, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) multiplicative() (exprNode, error) {
+	l, err := p.power()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*") || p.isOp("/") || p.isOp("%") {
+		op := p.next().text
+		r, err := p.power()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) power() (exprNode, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("^") || p.isOp("**") {
+		p.next()
+		r, err := p.power()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: "^", l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if p.isOp("-") || p.isOp("+") {
+		op := p.next().text
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, x: x}, nil
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber, tokString, tokDate:
+		return &literalNode{val: t.val}, nil
+	case tokParam:
+		return &paramNode{name: t.text}, nil
+	case tokIdent:
+		if strings.EqualFold(t.text, "NULL") {
+			return &literalNode{}, nil
+		}
+		if !p.isOp("(") {
+			return &columnNode{name: t.text}, nil
+		}
+		p.next()
+		var args []exprNode
+		if !p.isOp(")") {
+			for {
+				arg, err := p.expr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if !p.isOp(",") {
+					break
+				}
+				p.next()
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &callNode{name: strings.ToUpper(t.text), args: args}, nil
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+	case tokEOF:
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+// list parses a parenthesized list of expressions
+func (p *exprParser) list() ([]exprNode, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var list []exprNode
+	for {
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, x)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	return list, p.expect(")")
+}
+
+func (n *literalNode) eval(env exprEnv) (interface{}, error) {
+	return n.val, nil
+}
+
+func (n *columnNode) eval(env exprEnv) (interface{}, error) {
+	if env == nil {
+		return nil, fmt.Errorf("unknown field %s", n.name)
+	}
+	return env.column(n.name)
+}
+
+func (n *paramNode) eval(env exprEnv) (interface{}, error) {
+	if env == nil {
+		return nil, fmt.Errorf("unknown parameter %s", n.name)
+	}
+	return env.param(n.name)
+}
+
+func (n *unaryNode) eval(env exprEnv) (interface{}, error) {
+	x, err := n.x.eval(env)
+	if err != nil || x == nil {
+		return nil, err
+	}
+	switch n.op {
+	case "NOT":
+		b, ok := x.(bool)
+		if !ok {
+			return nil, fmt.Errorf("NOT requires a logical value, have %T", x)
+		}
+		return !b, nil
+	case "-":
+		f, ok := exprNumber(x)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %T", x)
+		}
+		return -f, nil
+	}
+	return x, nil
+}
+
+func (n *binaryNode) eval(env exprEnv) (interface{}, error) {
+	l, err := n.l.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "AND" || n.op == "OR" {
+		lb := exprTrue(l)
+		if (n.op == "AND" && !lb) || (n.op == "OR" && lb) {
+			return lb, nil
+		}
+		r, err := n.r.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		return exprTrue(r), nil
+	}
+	r, err := n.r.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+	switch n.op {
+	case "=", "==", "<>", "!=", "#", "<", ">", "<=", ">=":
+		c, err := exprCompare(l, r)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "=", "==":
+			return c == 0, nil
+		case "<>", "!=", "#":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case ">":
+			return c > 0, nil
+		case "<=":
+			return c <= 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case "$":
+		return strings.Contains(ToString(r), ToString(l)), nil
+	}
+	return exprArithmetic(n.op, l, r)
+}
+
+func (n *callNode) eval(env exprEnv) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		val, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = val
+	}
+	return callFunction(n.name, args)
+}
+
+func (n *likeNode) eval(env exprEnv) (interface{}, error) {
+	x, err := n.x.eval(env)
+	if err != nil || x == nil {
+		return nil, err
+	}
+	pattern, err := n.pattern.eval(env)
+	if err != nil || pattern == nil {
+		return nil, err
+	}
+	match := likeMatch([]rune(strings.TrimRight(ToString(x), " ")), []rune(strings.TrimRight(ToString(pattern), " ")))
+	return match != n.not, nil
+}
+
+func (n *isNullNode) eval(env exprEnv) (interface{}, error) {
+	x, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return (x == nil) != n.not, nil
+}
+
+func (n *inNode) eval(env exprEnv) (interface{}, error) {
+	x, err := n.x.eval(env)
+	if err != nil || x == nil {
+		return nil, err
+	}
+	for _, item := range n.list {
+		val, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			continue
+		}
+		if c, err := exprCompare(x, val); err == nil && c == 0 {
+			return !n.not, nil
+		}
+	}
+	return n.not, nil
+}
+
+func (n *betweenNode) eval(env exprEnv) (interface{}, error) {
+	x, err := n.x.eval(env)
+	if err != nil || x == nil {
+		return nil, err
+	}
+	lo, err := n.lo.eval(env)
+	if err != nil || lo == nil {
+		return nil, err
+	}
+	hi, err := n.hi.eval(env)
+	if err != nil || hi == nil {
+		return nil, err
+	}
+	c1, err := exprCompare(x, lo)
+	if err != nil {
+		return nil, err
+	}
+	c2, err := exprCompare(x, hi)
+	if err != nil {
+		return nil, err
+	}
+	return (c1 >= 0 && c2 <= 0) != n.not, nil
+}
+
+// exprTrue returns if val is the logical value true, NULL and other types are false
+func exprTrue(val interface{}) bool {
+	b, ok := val.(bool)
+	return ok && b
+}
+
+// exprNumber converts numeric values to float64
+func exprNumber(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// exprCompare compares two values of the same kind, it returns -1, 0 or 1
+func exprCompare(l, r interface{}) (int, error) {
+	if lf, ok := exprNumber(l); ok {
+		rf, ok := exprNumber(r)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare number with %T", r)
+		}
+		switch {
+		case lf < rf:
+			return -1, nil
+		case lf > rf:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	switch lv := l.(type) {
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare string with %T", r)
+		}
+		return strings.Compare(strings.TrimRight(lv, " "), strings.TrimRight(rv, " ")), nil
+	case bool:
+		rv, ok := r.(bool)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare logical with %T", r)
+		}
+		switch {
+		case lv == rv:
+			return 0, nil
+		case !lv:
+			return -1, nil
+		}
+		return 1, nil
+	case time.Time:
+		rv, ok := r.(time.Time)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare date with %T", r)
+		}
+		switch {
+		case lv.Before(rv):
+			return -1, nil
+		case lv.After(rv):
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("cannot compare %T", l)
+}
+
+// exprArithmetic applies an arithmetic operator, + also concatenates strings and adds days to dates
+func exprArithmetic(op string, l, r interface{}) (interface{}, error) {
+	if ls, ok := l.(string); ok {
+		rs, ok := r.(string)
+		if !ok || (op != "+" && op != "-") {
+			return nil, fmt.Errorf("invalid operation string %s %T", op, r)
+		}
+		if op == "-" {
+			// FoxPro - moves the trailing spaces of the left string to the end of the result
+			trimmed := strings.TrimRight(ls, " ")
+			return trimmed + rs + strings.Repeat(" ", len(ls)-len(trimmed)), nil
+		}
+		return ls + rs, nil
+	}
+	if lt, ok := l.(time.Time); ok {
+		if rt, ok := r.(time.Time); ok && op == "-" {
+			return lt.Sub(rt).Hours() / 24, nil
+		}
+		days, ok := exprNumber(r)
+		if !ok || (op != "+" && op != "-") {
+			return nil, fmt.Errorf("invalid operation date %s %T", op, r)
+		}
+		if op == "-" {
+			days = -days
+		}
+		return lt.Add(time.Duration(days * 24 * float64(time.Hour))), nil
+	}
+	lf, ok := exprNumber(l)
+	rf, ok2 := exprNumber(r)
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("invalid operation %T %s %T", l, op, r)
+	}
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return lf / rf, nil
+	case "%":
+		if rf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return math.Mod(lf, rf), nil
+	case "^":
+		return math.Pow(lf, rf), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+// likeMatch matches s against a SQL LIKE pattern with % and _ wildcards
+func likeMatch(s, pattern []rune) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '%':
+			for i := 0; i <= len(s); i++ {
+				if likeMatch(s[i:], pattern[1:]) {
+					return true
+				}
+			}
+			return false
+		case '_':
+			if len(s) == 0 {
+				return false
+			}
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+		}
+		s, pattern = s[1:], pattern[1:]
+	}
+	return len(s) == 0
+}
+
+// clampInt converts a position or length argument to an int, values beyond any string are limited
+// so the arithmetic on them can not overflow
+func clampInt(f float64) int {
+	if math.IsNaN(f) {
+		return 0
+	}
+	return int(math.Max(-1e9, math.Min(f, 1e9)))
+}
+
+// callFunction evaluates a FoxPro function
+func callFunction(name string, args []interface{}) (interface{}, error) {
+	argc := func(min, max int) error {
+		if len(args) < min || len(args) > max {
+			return fmt.Errorf("wrong number of arguments for %s()", name)
+		}
+		return nil
+	}
+	str := func(i int) string {
+		return ToString(args[i])
+	}
+	num := func(i int) (float64, error) {
+		f, ok := exprNumber(args[i])
+		if !ok {
+			return 0, fmt.Errorf("argument %d of %s() must be a number", i+1, name)
+		}
+		return f, nil
+	}
+	switch name {
+	case "DATE", "DATETIME":
+		if err := argc(0, 0); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		if name == "DATE" {
+			return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.UTC), nil
+	case "IIF":
+		if err := argc(3, 3); err != nil {
+			return nil, err
+		}
+		if exprTrue(args[0]) {
+			return args[1], nil
+		}
+		return args[2], nil
+	case "NVL":
+		if err := argc(2, 2); err != nil {
+			return nil, err
+		}
+		if args[0] == nil {
+			return args[1], nil
+		}
+		return args[0], nil
+	case "ISNULL":
+		if err := argc(1, 1); err != nil {
+			return nil, err
+		}
+		return args[0] == nil, nil
+	case "EMPTY":
+		if err := argc(1, 1); err != nil {
+			return nil, err
+		}
+		switch v := args[0].(type) {
+		case nil:
+			return false, nil
+		case string:
+			return strings.TrimSpace(v) == "", nil
+		case bool:
+			return !v, nil
+		case time.Time:
+			return v.IsZero(), nil
+		}
+		f, _ := exprNumber(args[0])
+		return f == 0, nil
+	case "INLIST", "BETWEEN":
+		if err := argc(2, 1<<16); err != nil {
+			return nil, err
+		}
+		if args[0] == nil {
+			return nil, nil
+		}
+		if name == "BETWEEN" {
+			if err := argc(3, 3); err != nil {
+				return nil, err
+			}
+			c1, err := exprCompare(args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			c2, err := exprCompare(args[0], args[2])
+			if err != nil {
+				return nil, err
+			}
+			return c1 >= 0 && c2 <= 0, nil
+		}
+		for _, item := range args[1:] {
+			if c, err := exprCompare(args[0], item); err == nil && c == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	// the remaining functions return NULL for NULL arguments
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+	switch name {
+	case "UPPER", "LOWER", "ALLTRIM", "TRIM", "RTRIM", "LTRIM", "LEN", "VAL":
+		if err := argc(1, 1); err != nil {
+			return nil, err
+		}
+		switch name {
+		case "UPPER":
+			return strings.ToUpper(str(0)), nil
+		case "LOWER":
+			return strings.ToLower(str(0)), nil
+		case "ALLTRIM":
+			return strings.TrimSpace(str(0)), nil
+		case "TRIM", "RTRIM":
+			return strings.TrimRight(str(0), " "), nil
+		case "LTRIM":
+			return strings.TrimLeft(str(0), " "), nil
+		case "LEN":
+			return float64(len([]rune(str(0)))), nil
+		default:
+			f, _ := strconv.ParseFloat(strings.TrimSpace(str(0)), 64)
+			return f, nil
+		}
+	case "SUBSTR", "LEFT", "RIGHT":
+		if err := argc(2, 3); err != nil {
+			return nil, err
+		}
+		if name != "SUBSTR" {
+			if err := argc(2, 2); err != nil {
+				return nil, err
+			}
+		}
+		n1, err := num(1)
+		if err != nil {
+			return nil, err
+		}
+		s := []rune(str(0))
+		start, length := 0, len(s)
+		switch name {
+		case "SUBSTR":
+			start = clampInt(n1) - 1
+			if len(args) == 3 {
+				n2, err := num(2)
+				if err != nil {
+					return nil, err
+				}
+				length = clampInt(n2)
+			}
+		case "LEFT":
+			length = clampInt(n1)
+		case "RIGHT":
+			length = clampInt(n1)
+			start = len(s) - length
+		}
+		if start < 0 {
+			length += start
+			start = 0
+		}
+		if start > len(s) || length <= 0 {
+			return "", nil
+		}
+		if start+length > len(s) {
+			length = len(s) - start
+		}
+		return string(s[start : start+length]), nil
+	case "STR":
+		if err := argc(1, 3); err != nil {
+			return nil, err
+		}
+		f, err := num(0)
+		if err != nil {
+			return nil, err
+		}
+		length, decimals := 10.0, 0.0
+		if len(args) > 1 {
+			if length, err = num(1); err != nil {
+				return nil, err
+			}
+		}
+		if len(args) > 2 {
+			if decimals, err = num(2); err != nil {
+				return nil, err
+			}
+		}
+		if !(length >= 1 && length <= 255) {
+			return nil, fmt.Errorf("STR() length %v is not 1 to 255", length)
+		}
+		if !(decimals >= 0 && decimals <= 18) {
+			return nil, fmt.Errorf("STR() decimals %v are not 0 to 18", decimals)
+		}
+		s := strconv.FormatFloat(f, 'f', int(decimals), 64)
+		if len(s) > int(length) {
+			return strings.Repeat("*", int(length)), nil
+		}
+		return strings.Repeat(" ", int(length)-len(s)) + s, nil
+	case "YEAR", "MONTH", "DAY":
+		if err := argc(1, 1); err != nil {
+			return nil, err
+		}
+		t, ok := args[0].(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("%s() requires a date", name)
+		}
+		switch name {
+		case "YEAR":
+			return float64(t.Year()), nil
+		case "MONTH":
+			return float64(t.Month()), nil
+		}
+		return float64(t.Day()), nil
+	case "INT", "ABS":
+		if err := argc(1, 1); err != nil {
+			return nil, err
+		}
+		f, err := num(0)
+		if err != nil {
+			return nil, err
+		}
+		if name == "INT" {
+			return math.Trunc(f), nil
+		}
+		return math.Abs(f), nil
+	case "ROUND":
+		if err := argc(1, 2); err != nil {
+			return nil, err
+		}
+		f, err := num(0)
+		if err != nil {
+			return nil, err
+		}
+		decimals := 0.0
+		if len(args) == 2 {
+			if decimals, err = num(1); err != nil {
+				return nil, err
+			}
+		}
+		if !(decimals >= -18 && decimals <= 18) {
+			return nil, fmt.Errorf("ROUND() decimals %v are not -18 to 18", decimals)
+		}
+		pow := math.Pow(10, math.Trunc(decimals))
+		return math.Round(f*pow) / pow, nil
+	}
+	return nil, fmt.Errorf("unsupported function %s()", name)
+}
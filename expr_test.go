@@ -0,0 +1,152 @@
+package dbf
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// mapEnv is an exprEnv with fields and parameters in maps
+type mapEnv struct {
+	fields map[string]interface{}
+	params map[string]interface{}
+}
+
+func (e mapEnv) column(name string) (interface{}, error) {
+	if val, ok := e.fields[name]; ok {
+		return val, nil
+	}
+	return nil, fmt.Errorf("unknown field %s", name)
+}
+
+func (e mapEnv) param(name string) (interface{}, error) {
+	if val, ok := e.params[name]; ok {
+		return val, nil
+	}
+	return nil, fmt.Errorf("unknown parameter %s", name)
+}
+
+func TestExpr(t *testing.T) {
+	env := mapEnv{
+		fields: map[string]interface{}{"name": "Jansen    ", "c.amount": int32(12), "paid": false, "due": time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)},
+		params: map[string]interface{}{"limit": 10.5},
+	}
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		{"1 + 2 * 3", float64(7)},
+		{"(1 + 2) * 3", float64(9)},
+		{"2 ^ 3 ** 2", float64(512)},
+		{"-c.amount + 2", float64(-10)},
+		{"10 % 4", float64(2)},
+		{"'abc' + [def] + \"g\"", "abcdefg"},
+		{"name = 'Jansen'", true},
+		{"name == 'Jansen'", true},
+		{"name <> 'Jansen'", false},
+		{"name # 'Jans'", true},
+		{"c.amount > ?limit", true},
+		{"c.amount >= 12 .AND. !paid", true},
+		{"c.amount < 12 .OR. paid", false},
+		{"NOT paid AND c.amount <= 12", true},
+		{".T. .and. .f.", false},
+		{"'ans' $ name", true},
+		{"name LIKE 'J_ns%'", true},
+		{"name NOT LIKE 'J%'", false},
+		{"c.amount IN (1, 12)", true},
+		{"c.amount NOT BETWEEN 1 AND 20", false},
+		{"due > {^2020-01-01}", true},
+		{"due + 1", time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"due - {^2020/01/01}", float64(30)},
+		{"{}", time.Time{}},
+		{"{^2020-01-31 10:30:00}", time.Date(2020, 1, 31, 10, 30, 0, 0, time.UTC)},
+		{".NULL. = 1", nil},
+		{"NULL IS NULL", true},
+		{"name IS NOT NULL", true},
+		{"UPPER(ALLTRIM(name))", "JANSEN"},
+		{"LOWER(RTRIM(name)) + '!'", "jansen!"},
+		{"LEN(TRIM(name))", float64(6)},
+		{"SUBSTR(name, 2, 3)", "ans"},
+		{"LEFT(name, 3) + RIGHT('abcdef', 2)", "Janef"},
+		{"VAL(' 12.5 ')", 12.5},
+		{"STR(3.14159, 6, 2)", "  3.14"},
+		{"YEAR(due) * 100 + MONTH(due)", float64(202001)},
+		{"DAY(due)", float64(31)},
+		{"IIF(paid, 'yes', 'no')", "no"},
+		{"NVL(.NULL., 5)", float64(5)},
+		{"ISNULL(name)", false},
+		{"EMPTY('  ') AND EMPTY(0) AND EMPTY({})", true},
+		{"INLIST(c.amount, 3, 12)", true},
+		{"BETWEEN(5, 1, 10)", true},
+		{"INT(-3.7) + ABS(-2) + ROUND(2.345, 2)", 1.35},
+		{"ROUND(2.5)", float64(3)},
+		{"ROUND(1234, -2)", float64(1200)},
+		{"STR(12)", "        12"},
+		{"SUBSTR('abc', -5, 10) + LEFT('abc', 10 ^ 300) + RIGHT('abc', -(10 ^ 300))", "abcabc"},
+	}
+	for _, test := range tests {
+		node, err := parseExpr(test.expr)
+		if err != nil {
+			t.Errorf("%s: %s", test.expr, err)
+			continue
+		}
+		have, err := node.eval(env)
+		if err != nil {
+			t.Errorf("%s: %s", test.expr, err)
+			continue
+		}
+		if f, ok := have.(float64); ok {
+			// avoid rounding differences in the comparison
+			have = math.Round(f*1e9) / 1e9
+		}
+		if have != test.want {
+			t.Errorf("%s: want %v (%T), have %v (%T)", test.expr, test.want, test.want, have, have)
+		}
+	}
+
+	for _, expr := range []string{"1 +", "(1", "'abc", "{^2020-13-45}", "1 2", "@", "?"} {
+		if _, err := parseExpr(expr); err == nil {
+			t.Errorf("%s: want a parse error", expr)
+		}
+	}
+	for _, expr := range []string{"notexist", "?notexist", "1 / 0", "'a' * 2", "'a' = 1", "UNKNOWN(1)", "UPPER()"} {
+		node, err := parseExpr(expr)
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+			continue
+		}
+		if _, err := node.eval(env); err == nil {
+			t.Errorf("%s: want an evaluation error", expr)
+		}
+	}
+}
+
+func TestExprFunctionErrors(t *testing.T) {
+	env := mapEnv{fields: map[string]interface{}{"due": time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)}}
+	tests := []string{
+		// wrong number of arguments
+		"DATE(1)", "DATETIME(1)", "IIF(.T., 1)", "NVL(1)", "ISNULL()", "EMPTY(1, 2)",
+		"INLIST(1)", "BETWEEN(1, 2)", "BETWEEN(1, 2, 3, 4)",
+		"UPPER()", "LOWER('a', 'b')", "ALLTRIM()", "TRIM()", "RTRIM()", "LTRIM()", "LEN()", "VAL()",
+		"SUBSTR('a')", "SUBSTR('a', 1, 2, 3)", "LEFT('a')", "LEFT('a', 1, 2)", "RIGHT('a', 1, 2)",
+		"STR()", "STR(1, 2, 3, 4)", "YEAR()", "MONTH(due, 1)", "DAY()",
+		"INT()", "INT(1, 2)", "ABS(1, 2)", "ROUND()", "ROUND(1, 2, 3)",
+		// invalid arguments
+		"SUBSTR('abc', 'x')", "SUBSTR('abc', 1, 'x')", "LEFT('abc', due)", "RIGHT('abc', .T.)",
+		"STR('x')", "STR(1, 'x')", "STR(1, 5, 'x')", "STR(1, -1)", "STR(1, 0)", "STR(1, 256)", "STR(1, 10 ^ 12)",
+		"STR(1, 10, -1)", "STR(1, 10, 19)",
+		"YEAR(1)", "MONTH('x')", "DAY(.T.)",
+		"INT('x')", "ABS(due)", "ROUND('x')", "ROUND(1, 'x')", "ROUND(1, 10 ^ 9)",
+	}
+	for _, expr := range tests {
+		node, err := parseExpr(expr)
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+			continue
+		}
+		if _, err := node.eval(env); err == nil {
+			t.Errorf("%s: want an evaluation error", expr)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package dbf
+
+import (
+	"encoding/binary"
+)
+
+// This file contains a minimal flatbuffers serializer, just enough to write the Arrow IPC metadata in arrow.go.
+// Objects are written front to back: every table is preceded by its vtable and followed by its children,
+// so all offsets to children point forward as required by the format.
+
+// fbTable is a flatbuffers table, the index in the slice is the field id. Absent fields are left zero.
+type fbTable []fbField
+
+// fbField is a scalar or an offset to a child object (table, string or vector)
+type fbField struct {
+	size   int    // size of a scalar in bytes, 0 for an offset to child
+	scalar uint64 // little endian value of a scalar
+	child  interface{}
+	set    bool
+}
+
+// fbString is a string child
+type fbString string
+
+// fbTables is a vector of tables
+type fbTables []fbTable
+
+// fbStructs is a vector of structs, data contains the already serialized structs
+type fbStructs struct {
+	align int
+	count int
+	data  []byte
+}
+
+func fbScalar(size int, v uint64) fbField {
+	return fbField{size: size, scalar: v, set: true}
+}
+
+func fbBool(v bool) fbField {
+	if v {
+		return fbScalar(1, 1)
+	}
+	return fbScalar(1, 0)
+}
+
+func fbChild(child interface{}) fbField {
+	return fbField{child: child, set: true}
+}
+
+// fbBuilder serializes a flatbuffer
+type fbBuilder struct {
+	buf []byte
+}
+
+// fbFinish serializes root and returns the buffer, padded to a multiple of 8 bytes
+func fbFinish(root fbTable) []byte {
+	b := &fbBuilder{buf: make([]byte, 4)}
+	pos := b.table(root)
+	binary.LittleEndian.PutUint32(b.buf, uint32(pos))
+	b.pad(8)
+	return b.buf
+}
+
+func (b *fbBuilder) pad(align int) {
+	for len(b.buf)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// table writes the vtable, the table and its children and returns the position of the table
+func (b *fbBuilder) table(t fbTable) int {
+	// inline layout: soffset to the vtable followed by the fields, each aligned to its size
+	offsets := make([]int, len(t))
+	size, maxAlign := 4, 4
+	for i, f := range t {
+		if !f.set {
+			continue
+		}
+		s := f.size
+		if s == 0 {
+			s = 4
+		}
+		for size%s != 0 {
+			size++
+		}
+		offsets[i] = size
+		size += s
+		if s > maxAlign {
+			maxAlign = s
+		}
+	}
+
+	b.pad(2)
+	vtable := len(b.buf)
+	b.buf = appendUint16(b.buf, uint16(4+2*len(t)))
+	b.buf = appendUint16(b.buf, uint16(size))
+	for _, off := range offsets {
+		b.buf = appendUint16(b.buf, uint16(off))
+	}
+
+	b.pad(maxAlign)
+	pos := len(b.buf)
+	inline := make([]byte, size)
+	binary.LittleEndian.PutUint32(inline, uint32(int32(pos-vtable)))
+	for i, f := range t {
+		if !f.set || f.size == 0 {
+			continue
+		}
+		var scalar [8]byte
+		binary.LittleEndian.PutUint64(scalar[:], f.scalar)
+		copy(inline[offsets[i]:], scalar[:f.size])
+	}
+	b.buf = append(b.buf, inline...)
+
+	for i, f := range t {
+		if f.set && f.size == 0 {
+			b.patch(pos+offsets[i], b.object(f.child))
+		}
+	}
+	return pos
+}
+
+// object writes a child object and returns its position
+func (b *fbBuilder) object(child interface{}) int {
+	switch c := child.(type) {
+	case fbTable:
+		return b.table(c)
+	case fbString:
+		b.pad(4)
+		pos := len(b.buf)
+		b.buf = appendUint32(b.buf, uint32(len(c)))
+		b.buf = append(b.buf, c...)
+		b.buf = append(b.buf, 0)
+		return pos
+	case fbTables:
+		b.pad(4)
+		pos := len(b.buf)
+		b.buf = appendUint32(b.buf, uint32(len(c)))
+		b.buf = append(b.buf, make([]byte, 4*len(c))...)
+		for i, t := range c {
+			b.patch(pos+4+4*i, b.table(t))
+		}
+		return pos
+	case fbStructs:
+		// the elements must be aligned, the length is written just before them
+		b.pad(4)
+		for (len(b.buf)+4)%c.align != 0 {
+			b.buf = append(b.buf, 0)
+		}
+		pos := len(b.buf)
+		b.buf = appendUint32(b.buf, uint32(c.count))
+		b.buf = append(b.buf, c.data...)
+		return pos
+	}
+	panic("unsupported flatbuffers object")
+}
+
+// patch writes the uoffset from at to target
+func (b *fbBuilder) patch(at, target int) {
+	binary.LittleEndian.PutUint32(b.buf[at:], uint32(target-at))
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v), byte(v>>8))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v)), uint32(v>>32))
+}
@@ -0,0 +1,225 @@
+package dbf
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FormatOptions control how values are rendered as text, they are used by CSVReader.
+// The zero value renders logicals as true/false and numbers with a point as decimal separator, without digit
+// grouping and with the decimals of the field (the shortest representation for F and B fields).
+type FormatOptions struct {
+	True, False      string         // text of logical values, by default "true" and "false"
+	DecimalSeparator string         // by default "."
+	GroupSeparator   string         // separator between groups of three digits of the integer part, by default none
+	Precision        map[string]int // decimals by field name for N, F, B and Y fields, -1 is the shortest representation
+	Rounding         RoundingMode   // how numbers are rounded to the decimals, by default RoundBinary
+}
+
+// RoundingMode selects how FormatOptions round numbers with more decimals than the precision
+type RoundingMode int
+
+const (
+	// RoundBinary rounds the binary floating point value like strconv.FormatFloat. Values like 2.675 are stored
+	// as 2.67499999..., so they are rounded down to 2.67.
+	RoundBinary RoundingMode = iota
+	// RoundHalfUp rounds the decimal value as stored in the table half away from zero, 2.675 to 2.68
+	// and -2.675 to -2.68, like FoxPro and most financial software
+	RoundHalfUp
+	// RoundHalfEven rounds the decimal value as stored in the table half to even (banker's rounding),
+	// 2.665 to 2.66 and 2.675 to 2.68
+	RoundHalfEven
+)
+
+// localeFormats are the logical words and number separators of spreadsheets by locale, see LocaleFormat
+var localeFormats = map[string]FormatOptions{
+	"en":    {True: "TRUE", False: "FALSE", DecimalSeparator: ".", GroupSeparator: ","},
+	"de":    {True: "WAHR", False: "FALSCH", DecimalSeparator: ",", GroupSeparator: "."},
+	"de-ch": {True: "WAHR", False: "FALSCH", DecimalSeparator: ".", GroupSeparator: "'"},
+	"nl":    {True: "WAAR", False: "ONWAAR", DecimalSeparator: ",", GroupSeparator: "."},
+	"fr":    {True: "VRAI", False: "FAUX", DecimalSeparator: ",", GroupSeparator: "\u00a0"},
+	"fr-ch": {True: "VRAI", False: "FAUX", DecimalSeparator: ".", GroupSeparator: "'"},
+	"es":    {True: "VERDADERO", False: "FALSO", DecimalSeparator: ",", GroupSeparator: "."},
+	"it":    {True: "VERO", False: "FALSO", DecimalSeparator: ",", GroupSeparator: "."},
+	"pt":    {True: "VERDADEIRO", False: "FALSO", DecimalSeparator: ",", GroupSeparator: "."},
+	"pl":    {True: "PRAWDA", False: "FAŁSZ", DecimalSeparator: ",", GroupSeparator: "\u00a0"},
+	"cs":    {True: "PRAVDA", False: "NEPRAVDA", DecimalSeparator: ",", GroupSeparator: "\u00a0"},
+}
+
+// LocaleFormat returns the FormatOptions of a locale, like "de" or "de-CH": logicals are rendered with the words
+// spreadsheets use in that language, numbers with its decimal separator and digit grouping. A locale with a region
+// which is not known uses the options of its language, so "de_AT" is rendered like "de".
+// Supported languages are en, de, nl, fr, es, it, pt, pl and cs, and the regions de-CH and fr-CH.
+func LocaleFormat(locale string) (FormatOptions, error) {
+	name := strings.ToLower(strings.Replace(locale, "_", "-", -1))
+	if o, ok := localeFormats[name]; ok {
+		return o, nil
+	}
+	if i := strings.IndexByte(name, '-'); i > 0 {
+		if o, ok := localeFormats[name[:i]]; ok {
+			return o, nil
+		}
+	}
+	return FormatOptions{}, fmt.Errorf("unsupported locale %q", locale)
+}
+
+// CSVDelimiter returns the field delimiter spreadsheets expect in CSV files with values formatted by the options:
+// a semicolon when the decimal separator is a comma, otherwise a comma
+func (o *FormatOptions) CSVDelimiter() rune {
+	if o.DecimalSeparator == "," {
+		return ';'
+	}
+	return ','
+}
+
+// FormatValue converts a field value to text: dates as 2006-01-02, datetimes as 2006-01-02 15:04:05,
+// binary memos as base64, C fields trimmed and logicals and numbers according to the options
+func (o *FormatOptions) FormatValue(val interface{}, f *FieldHeader) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		if f.Type == 'C' {
+			return strings.TrimSpace(v)
+		}
+		return v
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case bool:
+		return o.formatBool(v)
+	case int32:
+		return o.groupDigits(strconv.FormatInt(int64(v), 10))
+	case int64:
+		return o.groupDigits(strconv.FormatInt(v, 10))
+	case float64:
+		prec := -1
+		switch f.Type {
+		case 'N':
+			prec = int(f.Decimals)
+		case 'Y':
+			prec = 4
+		}
+		if p, ok := o.Precision[f.FieldName()]; ok {
+			prec = p
+		}
+		return o.formatFloat(v, prec)
+	}
+	if t := ToTime(val); !t.IsZero() {
+		if f.Type == 'D' {
+			return t.Format("2006-01-02")
+		}
+		return t.Format("2006-01-02 15:04:05")
+	} else if f.Type == 'D' || f.Type == 'T' {
+		return ""
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+func (o *FormatOptions) formatBool(v bool) string {
+	switch {
+	case v && o.True != "":
+		return o.True
+	case !v && o.False != "":
+		return o.False
+	}
+	return strconv.FormatBool(v)
+}
+
+// formatFloat formats v with prec decimals (-1 for the shortest representation) and the separators of the options
+func (o *FormatOptions) formatFloat(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if o.Rounding != RoundBinary && prec >= 0 && !math.IsInf(v, 0) && !math.IsNaN(v) {
+		s = roundDecimal(strconv.FormatFloat(v, 'f', -1, 64), prec, o.Rounding == RoundHalfEven)
+	}
+	if (o.DecimalSeparator == "" && o.GroupSeparator == "") || math.IsInf(v, 0) || math.IsNaN(v) {
+		return s
+	}
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+	integer = o.groupDigits(integer)
+	if fraction == "" {
+		return integer
+	}
+	sep := o.DecimalSeparator
+	if sep == "" {
+		sep = "."
+	}
+	return integer + sep + fraction
+}
+
+// roundDecimal rounds the decimal number s to prec decimals, half away from zero or half to even
+func roundDecimal(s string, prec int, halfEven bool) string {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+	for len(fraction) < prec {
+		fraction += "0"
+	}
+	digits := []byte(integer + fraction[:prec])
+	rest := fraction[prec:]
+
+	up := false
+	switch {
+	case rest == "" || rest[0] < '5':
+	case rest[0] > '5' || !halfEven || strings.TrimRight(rest[1:], "0") != "":
+		up = true
+	default:
+		// exactly half, round to the even digit
+		up = (digits[len(digits)-1]-'0')%2 == 1
+	}
+	if up {
+		i := len(digits) - 1
+		for ; i >= 0 && digits[i] == '9'; i-- {
+			digits[i] = '0'
+		}
+		if i < 0 {
+			digits = append([]byte{'1'}, digits...)
+		} else {
+			digits[i]++
+		}
+	}
+
+	n := len(digits) - prec
+	result := string(digits[:n])
+	if prec > 0 {
+		result += "." + string(digits[n:])
+	}
+	if neg && strings.Trim(string(digits), "0") != "" {
+		result = "-" + result
+	}
+	return result
+}
+
+// groupDigits inserts the group separator between groups of three digits of an integer
+func (o *FormatOptions) groupDigits(s string) string {
+	if o.GroupSeparator == "" {
+		return s
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= 3 {
+		return sign + s
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	first := len(s) % 3
+	if first == 0 {
+		first = 3
+	}
+	b.WriteString(s[:first])
+	for i := first; i < len(s); i += 3 {
+		b.WriteString(o.GroupSeparator)
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
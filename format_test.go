@@ -0,0 +1,144 @@
+package dbf
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatValue(t *testing.T) {
+	num := &FieldHeader{Type: 'N', Decimals: 2}
+	copy(num.Name[:], "AMOUNT")
+	flt := &FieldHeader{Type: 'F'}
+	copy(flt.Name[:], "RATE")
+	integer := &FieldHeader{Type: 'I'}
+	logical := &FieldHeader{Type: 'L'}
+
+	opts := &FormatOptions{
+		True:             "1",
+		False:            "0",
+		DecimalSeparator: ",",
+		GroupSeparator:   ".",
+		Precision:        map[string]int{"RATE": 3},
+	}
+	tests := []struct {
+		opts *FormatOptions
+		val  interface{}
+		f    *FieldHeader
+		want string
+	}{
+		{new(FormatOptions), true, logical, "true"},
+		{new(FormatOptions), 1234567.891, num, "1234567.89"},
+		{new(FormatOptions), 0.5, flt, "0.5"},
+		{opts, true, logical, "1"},
+		{opts, false, logical, "0"},
+		{opts, 1234567.891, num, "1.234.567,89"},
+		{opts, -1234.5, num, "-1.234,50"},
+		{opts, 123.0, num, "123,00"},
+		{opts, 0.5, flt, "0,500"},
+		{opts, int32(-1234567), integer, "-1.234.567"},
+		{opts, int64(100), integer, "100"},
+		{opts, math.Inf(1), flt, "+Inf"},
+		{&FormatOptions{Precision: map[string]int{"AMOUNT": 0}}, 1234.5, num, "1234"},
+		{&FormatOptions{Precision: map[string]int{"AMOUNT": -1}}, 1234.5, num, "1234.5"},
+	}
+	for _, test := range tests {
+		if have := test.opts.FormatValue(test.val, test.f); have != test.want {
+			t.Errorf("FormatValue(%v): want %q, have %q", test.val, test.want, have)
+		}
+	}
+}
+
+func TestCSVReaderFormat(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	r := NewCSVReader(dbf)
+	r.Format = FormatOptions{True: "J", False: "N", DecimalSeparator: ",", Precision: map[string]int{"FLOAT": 2}}
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := rows[0]
+	want := map[string]string{"NUMBER": "1,66", "FLOAT": "1,00", "BOOL": "N"}
+	for i, name := range header {
+		if val, ok := want[name]; ok && rows[1][i] != val {
+			t.Errorf("Field %s: want %q, have %q", name, val, rows[1][i])
+		}
+	}
+}
+
+func TestLocaleFormat(t *testing.T) {
+	num := &FieldHeader{Type: 'N', Len: 12, Decimals: 2}
+	logical := &FieldHeader{Type: 'L', Len: 1}
+	tests := []struct {
+		locale      string
+		number, yes string
+		delimiter   rune
+	}{
+		{"de", "1.234.567,50", "WAHR", ';'},
+		{"de_AT", "1.234.567,50", "WAHR", ';'},
+		{"de-CH", "1'234'567.50", "WAHR", ','},
+		{"fr-FR", "1\u00a0234\u00a0567,50", "VRAI", ';'},
+		{"EN-us", "1,234,567.50", "TRUE", ','},
+	}
+	for _, test := range tests {
+		o, err := LocaleFormat(test.locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := o.FormatValue(1234567.5, num); have != test.number {
+			t.Errorf("%s: want %q, have %q", test.locale, test.number, have)
+		}
+		if have := o.FormatValue(true, logical); have != test.yes {
+			t.Errorf("%s: want %q, have %q", test.locale, test.yes, have)
+		}
+		if have := o.CSVDelimiter(); have != test.delimiter {
+			t.Errorf("%s: want delimiter %q, have %q", test.locale, test.delimiter, have)
+		}
+	}
+	if _, err := LocaleFormat("xx"); err == nil {
+		t.Error("Want an error for an unknown locale")
+	}
+}
+
+func TestFormatRounding(t *testing.T) {
+	num := &FieldHeader{Type: 'N', Len: 10, Decimals: 2}
+	tests := []struct {
+		mode RoundingMode
+		val  float64
+		want string
+	}{
+		{RoundBinary, 2.675, "2.67"},
+		{RoundHalfUp, 2.675, "2.68"},
+		{RoundHalfUp, -2.675, "-2.68"},
+		{RoundHalfUp, 1.005, "1.01"},
+		{RoundHalfUp, 2.674, "2.67"},
+		{RoundHalfUp, 9.995, "10.00"},
+		{RoundHalfUp, -0.001, "0.00"},
+		{RoundHalfUp, 3, "3.00"},
+		{RoundHalfEven, 2.665, "2.66"},
+		{RoundHalfEven, 2.675, "2.68"},
+		{RoundHalfEven, 2.6651, "2.67"},
+		{RoundHalfEven, -0.125, "-0.12"},
+	}
+	for _, test := range tests {
+		o := &FormatOptions{Rounding: test.mode}
+		if have := o.FormatValue(test.val, num); have != test.want {
+			t.Errorf("Rounding %d of %v: want %q, have %q", test.mode, test.val, test.want, have)
+		}
+	}
+
+	total := &FieldHeader{Type: 'N', Len: 10, Decimals: 1}
+	copy(total.Name[:], "TOTAL")
+	o := &FormatOptions{Rounding: RoundHalfUp, DecimalSeparator: ",", GroupSeparator: ".", Precision: map[string]int{"TOTAL": 0}}
+	if have := o.FormatValue(1234.5, num); have != "1.234,50" {
+		t.Errorf("Want 1.234,50, have %q", have)
+	}
+	if have := o.FormatValue(1234.5, total); have != "1.235" {
+		t.Errorf("Want 1.235 with precision 0, have %q", have)
+	}
+}
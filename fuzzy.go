@@ -0,0 +1,163 @@
+package dbf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FuzzyMethod is the similarity measure used by FuzzyLookup
+type FuzzyMethod int
+
+const (
+	// FuzzyLevenshtein uses the edit distance relative to the length of the longest value
+	FuzzyLevenshtein FuzzyMethod = iota
+	// FuzzyTrigram uses the overlap of the sets of 3 character sequences (Jaccard index)
+	FuzzyTrigram
+)
+
+// FuzzyOptions controls a FuzzyLookup
+type FuzzyOptions struct {
+	Method FuzzyMethod
+
+	// MinScore is the minimum similarity between 0 and 1 of returned matches, 0 uses 0.5
+	MinScore float64
+
+	// Limit is the maximum number of returned matches, 0 returns all matches
+	Limit int
+}
+
+// FuzzyMatch is a record with the similarity of its value to the lookup value
+type FuzzyMatch struct {
+	RecNo uint32
+	Value string  // Trimmed field value
+	Score float64 // Similarity, 1 is an exact match (ignoring case and spaces)
+}
+
+// FuzzyLookup compares value with the C field of all non-deleted records and returns the records with a
+// similar value, best matches first. Comparison is case insensitive and ignores leading, trailing and repeated spaces.
+// This is meant for matching values like names between systems where no common exact key exists.
+// The internal record pointer is not used or moved.
+func (dbf *DBF) FuzzyLookup(field, value string, opts FuzzyOptions) ([]FuzzyMatch, error) {
+	pos := dbf.FieldPos(field)
+	if pos < 0 {
+		return nil, fmt.Errorf("field %s not found", field)
+	}
+	if dbf.fields[pos].Type != 'C' {
+		return nil, fmt.Errorf("field %s is not a character field", field)
+	}
+	if opts.MinScore <= 0 {
+		opts.MinScore = 0.5
+	}
+
+	query := fuzzyNormalize(value)
+	var queryTrigrams map[string]bool
+	if opts.Method == FuzzyTrigram {
+		queryTrigrams = trigrams(query)
+	}
+
+	var matches []FuzzyMatch
+	for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+		deleted, err := dbf.DeletedAt(recno)
+		if err != nil {
+			return nil, err
+		}
+		if deleted {
+			continue
+		}
+		str, err := dbf.stringAt(recno, pos)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", recno, err)
+		}
+		var score float64
+		if opts.Method == FuzzyTrigram {
+			score = jaccard(queryTrigrams, trigrams(fuzzyNormalize(str)))
+		} else {
+			score = levenshteinSimilarity(query, fuzzyNormalize(str))
+		}
+		if score >= opts.MinScore {
+			matches = append(matches, FuzzyMatch{RecNo: recno, Value: strings.TrimSpace(str), Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+	return matches, nil
+}
+
+// fuzzyNormalize lower cases s and removes leading, trailing and repeated spaces
+func fuzzyNormalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// levenshteinSimilarity returns 1 - the edit distance between a and b divided by the length of the longest
+func levenshteinSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// trigrams returns the set of 3 character sequences of s, padded with spaces
+func trigrams(s string) map[string]bool {
+	r := []rune("  " + s + " ")
+	set := make(map[string]bool)
+	for i := 0; i+3 <= len(r); i++ {
+		set[string(r[i:i+3])] = true
+	}
+	return set
+}
+
+// jaccard returns the size of the intersection of a and b divided by the size of their union
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for t := range a {
+		if b[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
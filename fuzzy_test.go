@@ -0,0 +1,59 @@
+package dbf
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFuzzyLookup(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	for _, method := range []FuzzyMethod{FuzzyLevenshtein, FuzzyTrigram} {
+		matches, err := dbf.FuzzyLookup("COMP_OS", "windows 7 sp 1", FuzzyOptions{Method: method, MinScore: 0.3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != 2 {
+			t.Fatalf("Method %d: want 2 matches, have %d", method, len(matches))
+		}
+		if matches[0].RecNo != 2 || matches[0].Value != "Windows 7 SP1" {
+			t.Errorf("Method %d: want record 2 as best match, have %+v", method, matches[0])
+		}
+		if matches[0].Score <= matches[1].Score {
+			t.Errorf("Method %d: matches are not sorted by score", method)
+		}
+	}
+
+	matches, err := dbf.FuzzyLookup("COMP_NAME", " test ", FuzzyOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Score != 1 || matches[0].RecNo != 0 {
+		t.Errorf("Want exact match on record 0, have %+v", matches)
+	}
+
+	if _, err := dbf.FuzzyLookup("ID", "1", FuzzyOptions{}); err == nil {
+		t.Error("Want an error for a non character field")
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"kitten", "sitting", 3},
+		{"čestmír", "cestmir", 2},
+		{"abc", "", 3},
+	}
+	for _, test := range tests {
+		if have := levenshtein([]rune(test.a), []rune(test.b)); have != test.want {
+			t.Errorf("%s - %s: want %d, have %d", test.a, test.b, test.want, have)
+		}
+	}
+}
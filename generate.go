@@ -0,0 +1,240 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Corruption selects the damage Generate does to a table, the modes can be combined with |
+type Corruption int
+
+const (
+	// CorruptTruncate cuts the file in the middle of the last record, like a copy which was interrupted
+	CorruptTruncate Corruption = 1 << iota
+	// CorruptMemoRefs sets the memo fields of every tenth record to a block beyond the end of the memo file
+	CorruptMemoRefs
+	// CorruptNumbers fills the first N or F field of every tenth record with asterisks, which FoxPro
+	// writes for values which do not fit in the field
+	CorruptNumbers
+)
+
+// GenerateOptions controls the table created by Generate
+type GenerateOptions struct {
+	Fields   []FieldHeader // the fields, like for NewWriter
+	Records  int           // number of records
+	CodePage byte          // code page mark stored in the header
+	Seed     int64         // seed of the random values, the same seed and options give the same values
+	Deleted  float64       // fraction of the records which is marked as deleted, 0 to 1
+	Corrupt  Corruption    // damage done to the table after writing
+}
+
+// Generate writes a table with random values to dbffile and fptfile, to test code against realistic files without
+// using production data. fptfile is only written when there are memo fields, it can be nil otherwise.
+// Character values are words (with accented letters when the Encoder supports them), about one in ten
+// C, D, T and M values is empty. The table is created in memory, so very large tables need a lot of memory.
+func Generate(dbffile, fptfile io.Writer, enc Encoder, opts GenerateOptions) error {
+	if opts.Records < 0 {
+		return errors.New("number of records can not be negative")
+	}
+	dbfbuf, fptbuf := new(generateBuffer), new(generateBuffer)
+	wr, err := NewWriter(dbfbuf, fptbuf, opts.Fields, enc)
+	if err != nil {
+		return err
+	}
+	wr.Header().CodePage = opts.CodePage
+
+	g := &generator{rnd: rand.New(rand.NewSource(opts.Seed)), enc: enc}
+	fields := wr.Fields()
+	values := make([]interface{}, len(fields))
+	deleted := make([]bool, opts.Records)
+	for recno := 0; recno < opts.Records; recno++ {
+		for i := range fields {
+			values[i] = g.value(&fields[i])
+		}
+		if err := wr.Append(values...); err != nil {
+			return err
+		}
+		deleted[recno] = g.rnd.Float64() < opts.Deleted
+	}
+	if err := wr.Close(); err != nil {
+		return err
+	}
+
+	data := dbfbuf.buf
+	h := wr.Header()
+	for recno, del := range deleted {
+		record := data[int(h.FirstRec)+recno*int(h.RecLen):][:h.RecLen]
+		if del {
+			record[0] = 0x2A
+		}
+		if recno%10 != 0 {
+			continue
+		}
+		corruptRecord(record, fields, opts.Corrupt, uint32(len(fptbuf.buf)))
+	}
+	if opts.Corrupt&CorruptTruncate != 0 && opts.Records > 0 {
+		data = data[:len(data)-1-int(h.RecLen)/2]
+	}
+
+	if _, err := dbffile.Write(data); err != nil {
+		return err
+	}
+	if fptbuf.buf != nil {
+		if fptfile == nil {
+			return errors.New("the table has memo fields, fptfile is required")
+		}
+		if _, err := fptfile.Write(fptbuf.buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// corruptRecord damages the record data according to the corruption modes, memoSize is the size of the memo file
+func corruptRecord(record []byte, fields []FieldHeader, c Corruption, memoSize uint32) {
+	numbers := c&CorruptNumbers != 0
+	for _, f := range fields {
+		data := record[f.Pos : f.Pos+uint32(f.Len)]
+		switch {
+		case c&CorruptMemoRefs != 0 && f.isMemo():
+			binary.LittleEndian.PutUint32(data, memoSize+0x10000)
+		case numbers && (f.Type == 'N' || f.Type == 'F'):
+			for i := range data {
+				data[i] = '*'
+			}
+			numbers = false
+		}
+	}
+}
+
+// generateWords are the words of generated character values, the second half has accented letters
+var generateWords = []string{
+	"alpha", "bravo", "delta", "echo", "garden", "house", "invoice", "lemon", "market", "north", "order", "river",
+	"stone", "table", "winter", "yellow",
+	"město", "řeka", "žluť", "čaj", "straße", "café", "señor", "łódź", "öl", "núñez", "ångström", "crème",
+}
+
+// generator creates random field values
+type generator struct {
+	rnd *rand.Rand
+	enc Encoder
+}
+
+// value returns a random value for field f
+func (g *generator) value(f *FieldHeader) interface{} {
+	empty := g.rnd.Intn(10) == 0
+	switch f.Type {
+	case 'C':
+		if empty {
+			return ""
+		}
+		return g.text(int(f.Len))
+	case 'M':
+		if empty {
+			return ""
+		}
+		return g.text(20 + g.rnd.Intn(400))
+	case 'N', 'F':
+		digits := int(f.Len) - 1 // room for the sign
+		if f.Decimals > 0 {
+			digits -= int(f.Decimals) + 1
+		}
+		if digits > 15 {
+			digits = 15
+		}
+		max := math.Pow10(digits) - 1
+		v := (g.rnd.Float64()*2 - 1) * max
+		if f.Decimals == 0 {
+			return int64(v)
+		}
+		scale := math.Pow10(int(f.Decimals))
+		return math.Trunc(v*scale) / scale
+	case 'I':
+		return int64(g.rnd.Int31()) - math.MaxInt32/2
+	case 'B':
+		return (g.rnd.Float64()*2 - 1) * 1e6
+	case 'Y':
+		return math.Round(g.rnd.Float64()*1e8) / 1e4
+	case 'L':
+		return g.rnd.Intn(2) == 0
+	case 'D', 'T':
+		if empty {
+			return time.Time{}
+		}
+		t := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(g.rnd.Int63n(40*365*24)) * time.Hour)
+		if f.Type == 'D' {
+			return t.Truncate(24 * time.Hour)
+		}
+		return t.Add(time.Duration(g.rnd.Intn(3600)) * time.Second)
+	case 'G', 'P', 'W':
+		b := make([]byte, 16+g.rnd.Intn(240))
+		g.rnd.Read(b)
+		return b
+	}
+	return nil
+}
+
+// text returns words which fit in size bytes after encoding
+func (g *generator) text(size int) string {
+	var words []string
+	length := 0
+	for {
+		w := generateWords[g.rnd.Intn(len(generateWords))]
+		enc, err := g.enc.Encode([]byte(w))
+		if err != nil {
+			// the charset has no accented letters like these
+			w = generateWords[g.rnd.Intn(len(generateWords)/2)]
+			enc = []byte(w)
+		}
+		n := len(enc)
+		if len(words) > 0 {
+			n++
+		}
+		if length+n > size {
+			break
+		}
+		words = append(words, w)
+		length += n
+	}
+	if len(words) == 0 {
+		// too small for a word, like a C(1) code
+		code := make([]byte, size)
+		for i := range code {
+			code[i] = byte('A' + g.rnd.Intn(26))
+		}
+		return string(code)
+	}
+	return strings.Join(words, " ")
+}
+
+// generateBuffer is an in memory io.WriteSeeker for the Writer used by Generate and the memos of AppendWriter
+type generateBuffer struct {
+	buf []byte
+	pos int
+}
+
+func (b *generateBuffer) Write(p []byte) (int, error) {
+	if end := b.pos + len(p); end > len(b.buf) {
+		b.buf = append(b.buf, make([]byte, end-len(b.buf))...)
+	}
+	copy(b.buf[b.pos:], p)
+	b.pos += len(p)
+	return len(p), nil
+}
+
+func (b *generateBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		b.pos = int(offset)
+	case io.SeekCurrent:
+		b.pos += int(offset)
+	case io.SeekEnd:
+		b.pos = len(b.buf) + int(offset)
+	}
+	return int64(b.pos), nil
+}
@@ -0,0 +1,130 @@
+package dbf
+
+import (
+	"bytes"
+	"testing"
+)
+
+// generateFields are fields of all types Generate supports
+var generateFields = []FieldHeader{
+	newField("CODE", 'C', 1, 0),
+	newField("NAME", 'C', 30, 0),
+	newField("AMOUNT", 'N', 12, 2),
+	newField("COUNT", 'N', 5, 0),
+	newField("RATE", 'F', 10, 4),
+	newField("ID", 'I', 0, 0),
+	newField("WEIGHT", 'B', 0, 0),
+	newField("PRICE", 'Y', 0, 0),
+	newField("ACTIVE", 'L', 0, 0),
+	newField("BORN", 'D', 0, 0),
+	newField("CHANGED", 'T', 0, 0),
+	newField("NOTES", 'M', 0, 0),
+	newField("PHOTO", 'G', 0, 0),
+}
+
+// generateTable generates a table with the options and opens it
+func generateTable(t *testing.T, opts GenerateOptions) *DBF {
+	dbffile, fptfile := new(bytes.Buffer), new(bytes.Buffer)
+	if err := Generate(dbffile, fptfile, new(Win1250Encoder), opts); err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(dbffile.Bytes()), bytes.NewReader(fptfile.Bytes()), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dbf
+}
+
+func TestGenerate(t *testing.T) {
+	dbf := generateTable(t, GenerateOptions{Fields: generateFields, Records: 200, CodePage: 0xC8, Seed: 1, Deleted: 0.25})
+	if dbf.NumRecords() != 200 || int(dbf.NumFields()) != len(generateFields) {
+		t.Fatalf("Want 200 records with %d fields, have %d with %d", len(generateFields), dbf.NumRecords(), dbf.NumFields())
+	}
+	if dbf.Header().CodePage != 0xC8 {
+		t.Errorf("Want code page mark 0xC8, have 0x%02X", dbf.Header().CodePage)
+	}
+
+	deleted := 0
+	s := dbf.Scanner()
+	s.IncludeDeleted = true
+	for s.Next() {
+		rec := s.Record()
+		if rec.Deleted {
+			deleted++
+		}
+		if code := rec.FieldSlice()[0].(string); len(code) != 1 {
+			t.Errorf("Record %d: want a code of 1 character, have %q", s.RecNo(), code)
+		}
+		if born := ToTime(rec.FieldSlice()[9]); !born.IsZero() && (born.Year() < 1990 || born.Year() > 2030) {
+			t.Errorf("Record %d: unexpected date %s", s.RecNo(), born)
+		}
+		if _, ok := rec.FieldSlice()[12].([]byte); !ok {
+			t.Errorf("Record %d: want a binary memo, have %T", s.RecNo(), rec.FieldSlice()[12])
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if deleted < 25 || deleted > 75 {
+		t.Errorf("Want about 50 deleted records, have %d", deleted)
+	}
+
+	// the same seed gives the same values
+	other := generateTable(t, GenerateOptions{Fields: generateFields, Records: 200, CodePage: 0xC8, Seed: 1, Deleted: 0.25})
+	for _, recno := range []uint32{0, 99, 199} {
+		a, err := dbf.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := other.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !RecordsEqual(a, b) {
+			t.Errorf("Record %d: want the same values for the same seed", recno)
+		}
+	}
+}
+
+func TestGenerateCorruption(t *testing.T) {
+	dbf := generateTable(t, GenerateOptions{Fields: generateFields, Records: 20, Corrupt: CorruptNumbers})
+	if _, err := dbf.RecordAt(0); err == nil {
+		t.Error("Want an invalid number error for record 0")
+	}
+	if _, err := dbf.RecordAt(1); err != nil {
+		t.Errorf("Want record 1 undamaged, have %v", err)
+	}
+	if _, err := dbf.RecordAt(10); err == nil {
+		t.Error("Want an invalid number error for record 10")
+	}
+
+	dbf = generateTable(t, GenerateOptions{Fields: generateFields, Records: 20, Corrupt: CorruptMemoRefs})
+	if _, err := dbf.RecordAt(0); err == nil {
+		t.Error("Want a memo error for record 0")
+	}
+	if _, err := dbf.RecordAt(1); err != nil {
+		t.Errorf("Want record 1 undamaged, have %v", err)
+	}
+
+	dbf = generateTable(t, GenerateOptions{Fields: generateFields, Records: 20, Corrupt: CorruptTruncate})
+	if _, err := dbf.RecordAt(18); err != nil {
+		t.Errorf("Want record 18 undamaged, have %v", err)
+	}
+	s := dbf.Scanner()
+	s.IncludeDeleted = true
+	for s.Next() {
+	}
+	if s.Err() == nil {
+		t.Error("Want an error for the truncated last record")
+	}
+
+	// no memo fields and no records
+	dbffile := new(bytes.Buffer)
+	fields := []FieldHeader{newField("NAME", 'C', 10, 0)}
+	if err := Generate(dbffile, nil, new(UTF8Encoder), GenerateOptions{Fields: fields, Corrupt: CorruptTruncate}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(dbffile, nil, new(UTF8Encoder), GenerateOptions{Fields: generateFields, Records: 1}); err == nil {
+		t.Error("Want an error for memo fields without fptfile")
+	}
+}
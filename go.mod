@@ -1,5 +1,5 @@
 module github.com/SebastiaanKlippert/go-foxpro-dbf
 
-go 1.12
+go 1.18
 
 require golang.org/x/text v0.7.0
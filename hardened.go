@@ -0,0 +1,129 @@
+package dbf
+
+import (
+	"fmt"
+	"io"
+)
+
+// Tables from untrusted sources, like files uploaded to a service, can be opened in hardened mode with
+// OpenFileHardened or OpenStreamHardened. The header is checked against the size of the file before any
+// record is read, memos are checked against a maximum length, and a panic on malformed data is returned
+// as a *MalformedError instead of stopping the program. All other failures are the usual typed errors.
+
+// ParseLimits bounds the resources used for a table in hardened mode, zero values use the defaults
+type ParseLimits struct {
+	MaxRecords    uint32 // maximum number of records, 0 for no limit besides the size of the file
+	MaxFields     int    // maximum number of fields, DefaultMaxFields when 0
+	MaxMemoLength int    // maximum length of one memo in bytes, DefaultMaxMemoLength when 0
+}
+
+// The defaults of ParseLimits
+const (
+	DefaultMaxFields     = 255 // the maximum of Visual FoxPro
+	DefaultMaxMemoLength = 16 << 20
+)
+
+// MalformedError is returned in hardened mode for data which does not follow the file format
+type MalformedError struct {
+	Part   string // the part of the file: header, record or memo
+	Detail string // description of the problem
+}
+
+func (e *MalformedError) Error() string {
+	return fmt.Sprintf("malformed %s: %s", e.Part, e.Detail)
+}
+
+// OpenFileHardened opens a DBF file like OpenFile, in hardened mode
+func OpenFileHardened(filename string, dec Decoder, limits ParseLimits) (dbf *DBF, err error) {
+	defer recoverMalformed(&err, "header")
+	dbf, err = OpenFile(filename, dec)
+	if err != nil {
+		return nil, err
+	}
+	if err := dbf.harden(limits); err != nil {
+		dbf.Close()
+		return nil, err
+	}
+	return dbf, nil
+}
+
+// OpenStreamHardened opens a DBF stream like OpenStream, in hardened mode
+func OpenStreamHardened(dbffile, fptfile ReaderAtSeeker, dec Decoder, limits ParseLimits) (dbf *DBF, err error) {
+	defer recoverMalformed(&err, "header")
+	dbf, err = OpenStream(dbffile, fptfile, dec)
+	if err != nil {
+		return nil, err
+	}
+	if err := dbf.harden(limits); err != nil {
+		return nil, err
+	}
+	return dbf, nil
+}
+
+// Hardened returns if the table was opened in hardened mode
+func (dbf *DBF) Hardened() bool {
+	return dbf.limits != nil
+}
+
+// harden checks the table against the limits and the size of the file and enables hardened mode
+func (dbf *DBF) harden(limits ParseLimits) error {
+	if limits.MaxFields <= 0 {
+		limits.MaxFields = DefaultMaxFields
+	}
+	if limits.MaxMemoLength <= 0 {
+		limits.MaxMemoLength = DefaultMaxMemoLength
+	}
+
+	// the fields and their lengths are checked by prepareDBF
+	h := dbf.header
+	if len(dbf.fields) > limits.MaxFields {
+		return &LimitError{Limit: "number of fields", Value: uint64(len(dbf.fields)), Max: uint64(limits.MaxFields)}
+	}
+	if end := dbf.layout[len(dbf.layout)-1].end; end > int(h.RecLen) {
+		return &MalformedError{Part: "header", Detail: fmt.Sprintf("the fields need %d bytes, the record length is %d", end, h.RecLen)}
+	}
+	if limits.MaxRecords > 0 && h.NumRec > limits.MaxRecords {
+		return &LimitError{Limit: "number of records", Value: uint64(h.NumRec), Max: uint64(limits.MaxRecords)}
+	}
+
+	size, err := dbf.r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if need := int64(h.FirstRec) + int64(h.NumRec)*int64(h.RecLen); need > size {
+		return &MalformedError{Part: "header", Detail: fmt.Sprintf("%d records need %d bytes, the file has %d bytes", h.NumRec, need, size)}
+	}
+	if dbf.fptheader != nil && !dbf.smt && dbf.fptheader.BlockSize == 0 {
+		return &MalformedError{Part: "memo", Detail: "the memo file has block size 0"}
+	}
+
+	dbf.limits = &limits
+	return nil
+}
+
+// checkMemoLength returns a LimitError for a memo which is longer than the maximum of hardened mode
+func (dbf *DBF) checkMemoLength(leng uint32) error {
+	if dbf.limits != nil && uint64(leng) > uint64(dbf.limits.MaxMemoLength) {
+		return &LimitError{Limit: "memo length", Value: uint64(leng), Max: uint64(dbf.limits.MaxMemoLength)}
+	}
+	return nil
+}
+
+// recoverHardened returns a panic as a *MalformedError in err when the table is in hardened mode,
+// it must be called with defer
+func (dbf *DBF) recoverHardened(err *error, part string) {
+	if dbf.limits == nil {
+		return
+	}
+	// recover only stops a panic when it is called by the deferred function itself
+	if r := recover(); r != nil {
+		*err = &MalformedError{Part: part, Detail: fmt.Sprint(r)}
+	}
+}
+
+// recoverMalformed returns a panic as a *MalformedError in err, it must be called with defer
+func recoverMalformed(err *error, part string) {
+	if r := recover(); r != nil {
+		*err = &MalformedError{Part: part, Detail: fmt.Sprint(r)}
+	}
+}
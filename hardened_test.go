@@ -0,0 +1,107 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenHardened(t *testing.T) {
+	dbf, err := OpenFileHardened(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder), ParseLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	if !dbf.Hardened() {
+		t.Error("Want the table in hardened mode")
+	}
+	if _, err := dbf.RecordAt(0); err != nil {
+		t.Fatal(err)
+	}
+
+	dbfdata, fptdata := readTestFiles(t)
+	open := func(dbfdata []byte, limits ParseLimits) error {
+		_, err := OpenStreamHardened(bytes.NewReader(dbfdata), bytes.NewReader(fptdata), new(Win1250Decoder), limits)
+		return err
+	}
+	if err := open(dbfdata, ParseLimits{MaxRecords: 3}); err == nil {
+		t.Error("Want a limit error for 4 records")
+	} else if _, ok := err.(*LimitError); !ok {
+		t.Errorf("Want a *LimitError, have %T", err)
+	}
+	if _, ok := open(dbfdata, ParseLimits{MaxFields: 12}).(*LimitError); !ok {
+		t.Error("Want a *LimitError for 13 fields")
+	}
+	if _, ok := open(dbfdata[:len(dbfdata)-100], ParseLimits{}).(*MalformedError); !ok {
+		t.Error("Want a *MalformedError for a truncated file")
+	}
+
+	// a record count which does not fit in the file, a 5th record which is cut off is accepted by OpenStream
+	damaged := append(append([]byte{}, dbfdata...), dbfdata[len(dbfdata)-10:]...)
+	binary.LittleEndian.PutUint32(damaged[4:], 5)
+	if _, ok := open(damaged, ParseLimits{}).(*MalformedError); !ok {
+		t.Error("Want a *MalformedError for a record count beyond the end of the file")
+	}
+
+	// memos longer than the limit
+	dbf, err = OpenStreamHardened(bytes.NewReader(dbfdata), bytes.NewReader(fptdata), new(Win1250Decoder), ParseLimits{MaxMemoLength: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dbf.RecordAt(0); err == nil {
+		t.Error("Want a limit error for a memo longer than 4 bytes")
+	} else if _, ok := err.(*LimitError); !ok {
+		t.Errorf("Want a *LimitError, have %T", err)
+	}
+}
+
+func TestHardenedMalformedInput(t *testing.T) {
+	dbfdata, fptdata := readTestFiles(t)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		dbfcopy := mutate(rnd, dbfdata)
+		fptcopy := fptdata
+		if i%2 == 1 {
+			fptcopy = mutate(rnd, fptdata)
+		}
+		dbf, err := OpenStreamHardened(bytes.NewReader(dbfcopy), bytes.NewReader(fptcopy), new(Win1250Decoder), ParseLimits{})
+		if err != nil {
+			continue
+		}
+		for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+			dbf.RecordAt(recno)
+			dbf.GoTo(recno)
+			for pos := 0; pos < int(dbf.NumFields()); pos++ {
+				dbf.Field(pos)
+			}
+		}
+	}
+}
+
+// readTestFiles returns the contents of TEST.DBF and TEST.FPT
+func readTestFiles(t *testing.T) ([]byte, []byte) {
+	dbfdata, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fptdata, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dbfdata, fptdata
+}
+
+// mutate returns a copy of data with a few random bytes changed, and sometimes cut short
+func mutate(rnd *rand.Rand, data []byte) []byte {
+	data = append([]byte{}, data...)
+	for n := 1 + rnd.Intn(8); n > 0; n-- {
+		data[rnd.Intn(len(data))] = byte(rnd.Intn(256))
+	}
+	if rnd.Intn(4) == 0 {
+		data = data[:rnd.Intn(len(data))]
+	}
+	return data
+}
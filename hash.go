@@ -0,0 +1,40 @@
+package dbf
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// Hash returns a hex encoded SHA-256 digest of the raw record data including the deleted flag, which can be used
+// as ETag or to detect changed records between snapshots of a table.
+// When includeMemos is true, the memo block numbers are replaced by the contents of the memos (as decoded), so
+// changes of memos are detected and a memo moved to another block is not seen as a change.
+func (r *Record) Hash(includeMemos bool) string {
+	h := sha256.New()
+	if !includeMemos {
+		h.Write(r.raw)
+		return hex.EncodeToString(h.Sum(nil))
+	}
+	h.Write(r.raw[:1])
+	for i, f := range r.fields {
+		raw := r.layout[i].data(r.raw)
+		if !f.isMemo() {
+			h.Write(raw)
+			continue
+		}
+		var memo []byte
+		switch val := r.data[i].(type) {
+		case string:
+			memo = []byte(val)
+		case []byte:
+			memo = val
+		}
+		// length prefix so the memo content cannot be confused with the next field
+		var size [4]byte
+		binary.LittleEndian.PutUint32(size[:], uint32(len(memo)))
+		h.Write(size[:])
+		h.Write(memo)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,80 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordHash(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpt, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	open := func(data, fpt []byte) *DBF {
+		dbf, err := OpenStream(bytes.NewReader(data), bytes.NewReader(fpt), new(Win1250Decoder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dbf
+	}
+	hashes := func(dbf *DBF, includeMemos bool) []string {
+		var list []string
+		for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+			rec, err := dbf.RecordAt(recno)
+			if err != nil {
+				t.Fatal(err)
+			}
+			list = append(list, rec.Hash(includeMemos))
+		}
+		return list
+	}
+
+	orig := open(data, fpt)
+	plain, memos := hashes(orig, false), hashes(orig, true)
+	if len(plain[0]) != 64 {
+		t.Errorf("Want a hex encoded SHA-256, have %q", plain[0])
+	}
+	if again := hashes(orig, false); again[2] != plain[2] {
+		t.Error("Want the same hash when reading a record again")
+	}
+	for i := 1; i < len(plain); i++ {
+		if plain[i] == plain[0] || memos[i] == memos[0] {
+			t.Errorf("Want a different hash for record %d", i)
+		}
+	}
+
+	// move the memo of record 0 to a new block at the end of the FPT
+	h := orig.Header()
+	pos := int(h.FirstRec) + int(orig.Fields()[orig.FieldPos("MELDING")].Pos)
+	block := binary.LittleEndian.Uint32(data[pos:])
+	size := int(orig.fptheader.BlockSize)
+	moved := append([]byte{}, fpt...)
+	for len(moved)%size != 0 {
+		moved = append(moved, 0)
+	}
+	newBlock := uint32(len(moved) / size)
+	moved = append(moved, fpt[int(block)*size:int(block+1)*size]...)
+	changed := append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(changed[pos:], newBlock)
+
+	other := open(changed, moved)
+	if hashes(other, false)[0] == plain[0] {
+		t.Error("Want a different raw hash when the memo block changed")
+	}
+	if hashes(other, true)[0] != memos[0] {
+		t.Error("Want the same hash including memos when only the memo block changed")
+	}
+
+	// delete record 0
+	changed[h.FirstRec] = 0x2A
+	if hashes(open(changed, moved), true)[0] == memos[0] {
+		t.Error("Want a different hash for a deleted record")
+	}
+}
@@ -16,3 +16,11 @@ func J2YMD(d int) (int, int, int) {
 	i = 100*(n-49) + i + l
 	return i, j, k
 }
+
+// YMD2J converts a year, month and day to a Julian day number, this is the inverse of J2YMD
+// j := jd.YMD2J(2006, 1, 2);
+// j==2453738 //=> true
+func YMD2J(y, m, d int) int {
+	a := (m - 14) / 12
+	return (1461*(y+4800+a))/4 + (367*(m-2-12*a))/12 - (3*((y+4900+a)/100))/4 + d - 32075
+}
@@ -27,3 +27,26 @@ func TestJ2YMD(t *testing.T) {
 		}
 	}
 }
+
+func TestYMD2J(t *testing.T) {
+	cases := []struct {
+		y, m, d int
+		want    int
+	}{
+		{2006, 1, 2, 2453738},
+		{2023, 7, 5, 2460131},
+		{1970, 1, 1, 2440588},
+		{1999, 12, 31, 2451544},
+		{2099, 2, 28, 2487763},
+	}
+	for _, c := range cases {
+		have := YMD2J(c.y, c.m, c.d)
+		if have != c.want {
+			t.Errorf("Date %s: want Julian date %d, have %d", ymd(c.y, c.m, c.d), c.want, have)
+		}
+		y, m, d := J2YMD(have)
+		if y != c.y || m != c.m || d != c.d {
+			t.Errorf("Julian date %d does not round trip: want %s, have %s", have, ymd(c.y, c.m, c.d), ymd(y, m, d))
+		}
+	}
+}
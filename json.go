@@ -0,0 +1,174 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONKeyStyle determines how field names are converted to JSON object keys
+type JSONKeyStyle int
+
+const (
+	// JSONKeysAsIs uses the field names as they are stored in the table (CUST_NAME)
+	JSONKeysAsIs JSONKeyStyle = iota
+	// JSONKeysLower uses lower case field names (cust_name)
+	JSONKeysLower
+	// JSONKeysCamel uses lower camel case field names (custName)
+	JSONKeysCamel
+)
+
+// JSONNullPolicy determines how empty values are written to JSON.
+// Empty values are blank C and M fields and blank D and T fields, numeric and logical fields are never empty.
+type JSONNullPolicy int
+
+const (
+	// JSONNullsZero writes empty values as their Go zero value ("" and "0001-01-01T00:00:00Z")
+	JSONNullsZero JSONNullPolicy = iota
+	// JSONNullsNull writes empty values as null
+	JSONNullsNull
+	// JSONNullsOmit leaves empty values out of the object
+	JSONNullsOmit
+)
+
+// JSONLogicalStyle determines how logical values are written to JSON
+type JSONLogicalStyle int
+
+const (
+	// JSONLogicalsBool writes logicals as true and false
+	JSONLogicalsBool JSONLogicalStyle = iota
+	// JSONLogicalsNumber writes logicals as 1 and 0
+	JSONLogicalsNumber
+)
+
+// JSONOptions contains the table level options used when a Record is marshalled to JSON
+type JSONOptions struct {
+	KeyStyle   JSONKeyStyle
+	TrimSpaces bool // Trim spaces from string values
+	Nulls      JSONNullPolicy
+	Logicals   JSONLogicalStyle
+	Precision  map[string]int // Number of decimals by field name for float values, trailing zeros are written
+}
+
+// SetJSONOptions sets the options used by Record.MarshalJSON for all records read from this table after the call
+func (dbf *DBF) SetJSONOptions(opts JSONOptions) {
+	dbf.jsonOpts = opts
+}
+
+// JSONOptions returns the JSON options of the table
+func (dbf *DBF) JSONOptions() JSONOptions {
+	return dbf.jsonOpts
+}
+
+// MarshalJSON implements json.Marshaler, a record is written as an object with the fields in table order.
+// The options set with DBF.SetJSONOptions on the table the record was read from are applied.
+// Records which were not read from a table are written as an array of values.
+// NaN and infinite numbers, which JSON can not represent, are written as null.
+func (r *Record) MarshalJSON() ([]byte, error) {
+	if r.fields == nil {
+		vals := make([]interface{}, len(r.data))
+		for i, val := range r.data {
+			vals[i] = jsonValue(val, "", new(JSONOptions))
+		}
+		return json.Marshal(vals)
+	}
+	opts := r.jsonOpts
+
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	first := true
+	for i, val := range r.data {
+		if r.fields[i].System() {
+			// _NullFlags, null values are written as null
+			continue
+		}
+		if str, ok := val.(string); ok && opts.TrimSpaces {
+			val = strings.TrimSpace(str)
+		}
+		if isEmptyValue(val) {
+			switch opts.Nulls {
+			case JSONNullsNull:
+				val = nil
+			case JSONNullsOmit:
+				continue
+			}
+		}
+		val = jsonValue(val, r.fields[i].FieldName(), &opts)
+		key, err := json.Marshal(jsonKey(r.fields[i].FieldName(), opts.KeyStyle))
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonValue applies the logical style and precision of the options to a value,
+// NaN and infinite floats are returned as nil
+func jsonValue(val interface{}, name string, opts *JSONOptions) interface{} {
+	switch v := val.(type) {
+	case bool:
+		if opts.Logicals == JSONLogicalsNumber {
+			if v {
+				return 1
+			}
+			return 0
+		}
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil
+		}
+		if prec, ok := opts.Precision[name]; ok {
+			return json.Number(strconv.FormatFloat(v, 'f', prec, 64))
+		}
+	}
+	return val
+}
+
+// isEmptyValue returns if val is a blank string or date
+func isEmptyValue(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case time.Time:
+		return v.IsZero()
+	}
+	return false
+}
+
+// jsonKey converts a field name to a JSON key in the given style
+func jsonKey(name string, style JSONKeyStyle) string {
+	switch style {
+	case JSONKeysLower:
+		return strings.ToLower(name)
+	case JSONKeysCamel:
+		key := ""
+		for _, p := range strings.Split(strings.ToLower(name), "_") {
+			switch {
+			case p == "":
+			case key == "":
+				key = p
+			default:
+				key += strings.ToUpper(p[:1]) + p[1:]
+			}
+		}
+		return key
+	}
+	return name
+}
@@ -0,0 +1,132 @@
+package dbf
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordMarshalJSON(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), `{"ID":1,"NIVEAU":0,"DATUM":"2015-01-03T00:00:00Z","TIJD":"15:00   ",`) {
+		t.Errorf("Unexpected JSON %s", b)
+	}
+
+	dbf.SetJSONOptions(JSONOptions{KeyStyle: JSONKeysCamel, TrimSpaces: true, Nulls: JSONNullsOmit})
+	rec, err = dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// record is used as part of a response struct
+	resp := struct {
+		Record *Record `json:"record"`
+	}{rec}
+	b, err = json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]map[string]interface{})
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["record"]["compName"] != "TEST" {
+		t.Errorf("Want compName TEST, have %v", m["record"]["compName"])
+	}
+	if m["record"]["tijd"] != "15:00" {
+		t.Errorf("Want trimmed tijd 15:00, have %v", m["record"]["tijd"])
+	}
+}
+
+func TestRecordMarshalJSONNulls(t *testing.T) {
+	fields := []FieldHeader{{Type: 'C'}, {Type: 'D'}, {Type: 'N'}}
+	copy(fields[0].Name[:], "NAME")
+	copy(fields[1].Name[:], "BORN")
+	copy(fields[2].Name[:], "COUNT")
+	data := []interface{}{"   ", time.Time{}, int64(0)}
+
+	tests := []struct {
+		opts JSONOptions
+		want string
+	}{
+		{JSONOptions{}, `{"NAME":"   ","BORN":"0001-01-01T00:00:00Z","COUNT":0}`},
+		{JSONOptions{KeyStyle: JSONKeysLower, Nulls: JSONNullsNull}, `{"name":null,"born":null,"count":0}`},
+		{JSONOptions{Nulls: JSONNullsOmit}, `{"COUNT":0}`},
+	}
+	for _, test := range tests {
+		rec := &Record{data: data, fields: fields, jsonOpts: test.opts}
+		b, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.want {
+			t.Errorf("Want %s, have %s", test.want, b)
+		}
+	}
+
+	// records without table are written as array
+	b, err := json.Marshal(&Record{data: []interface{}{"A", 1, math.NaN(), math.Inf(-1)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `["A",1,null,null]` {
+		t.Errorf("Want array, have %s", b)
+	}
+}
+
+func TestJSONKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		style JSONKeyStyle
+		want  string
+	}{
+		{"CUST_NAME", JSONKeysAsIs, "CUST_NAME"},
+		{"CUST_NAME", JSONKeysLower, "cust_name"},
+		{"CUST_NAME", JSONKeysCamel, "custName"},
+		{"ID", JSONKeysCamel, "id"},
+		{"_NULLFLAGS", JSONKeysCamel, "nullflags"},
+	}
+	for _, test := range tests {
+		if have := jsonKey(test.name, test.style); have != test.want {
+			t.Errorf("%s: want %s, have %s", test.name, test.want, have)
+		}
+	}
+}
+
+func TestRecordMarshalJSONLogicalsPrecision(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	dbf.SetJSONOptions(JSONOptions{Logicals: JSONLogicalsNumber, Precision: map[string]int{"NUMBER": 3, "FLOAT": 0}})
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"NUMBER":1.660`, `"FLOAT":1`, `"BOOL":0`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("Want %s in JSON %s", want, b)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package dbf
+
+import (
+	"fmt"
+	"math"
+)
+
+// JSONSchema is a JSON Schema document (draft 2020-12) describing the records of a table as written by Record.MarshalJSON
+type JSONSchema struct {
+	Schema               string                         `json:"$schema"`
+	Title                string                         `json:"title,omitempty"`
+	Type                 string                         `json:"type"`
+	Properties           map[string]*JSONSchemaProperty `json:"properties"`
+	Required             []string                       `json:"required,omitempty"`
+	AdditionalProperties bool                           `json:"additionalProperties"`
+}
+
+// JSONSchemaProperty describes one field in a JSONSchema.
+// Type is a string, or a slice of strings for fields which can be null.
+type JSONSchemaProperty struct {
+	Type            interface{} `json:"type"`
+	Description     string      `json:"description,omitempty"`
+	MaxLength       int         `json:"maxLength,omitempty"`
+	Format          string      `json:"format,omitempty"`
+	ContentEncoding string      `json:"contentEncoding,omitempty"`
+	Minimum         *float64    `json:"minimum,omitempty"`
+	Maximum         *float64    `json:"maximum,omitempty"`
+}
+
+// JSONSchema returns a JSON Schema describing the records of the table as written by Record.MarshalJSON,
+// taking the JSON options of the table (key style and null policy) into account.
+// title is optional and is used as the title of the schema.
+// The maximum length of C fields is the field length in bytes, which equals the number of characters for
+// single byte encodings.
+// Null values in fields with the nullable flag are not read yet, so these fields are only nullable
+// when the null policy writes empty values as null.
+func (dbf *DBF) JSONSchema(title string) *JSONSchema {
+	schema := &JSONSchema{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		Title:      title,
+		Type:       "object",
+		Properties: make(map[string]*JSONSchemaProperty, len(dbf.fields)),
+	}
+	for i := range dbf.fields {
+		f := &dbf.fields[i]
+		if f.System() {
+			continue
+		}
+		key := jsonKey(f.FieldName(), dbf.jsonOpts.KeyStyle)
+		mapped := dbf.mappedField(i)
+		prop := jsonSchemaProperty(&mapped)
+		prop.Description = jsonSchemaProperty(f).Description
+		if mapped.Type == 'L' && dbf.jsonOpts.Logicals == JSONLogicalsNumber {
+			prop.Type = "integer"
+		}
+
+		// C, M, D and T fields can be empty, see isEmptyValue, nullable fields can be null
+		canBeEmpty := f.Nullable()
+		switch f.Type {
+		case 'C', 'M', 'D', 'T':
+			canBeEmpty = true
+		}
+		if canBeEmpty && dbf.jsonOpts.Nulls == JSONNullsNull || f.Nullable() && dbf.jsonOpts.Nulls == JSONNullsZero {
+			prop.Type = []string{prop.Type.(string), "null"}
+		}
+		if !canBeEmpty || dbf.jsonOpts.Nulls != JSONNullsOmit {
+			schema.Required = append(schema.Required, key)
+		}
+		schema.Properties[key] = prop
+	}
+	return schema
+}
+
+// jsonSchemaProperty returns the schema of a field value
+func jsonSchemaProperty(f *FieldHeader) *JSONSchemaProperty {
+	prop := &JSONSchemaProperty{Description: fmt.Sprintf("%s field %s", f.FieldType(), f.FieldName())}
+	switch f.Type {
+	case 'C':
+		prop.Type = "string"
+		prop.MaxLength = int(f.Len)
+	case 'M':
+		prop.Type = "string"
+	case 'I':
+		prop.Type = "integer"
+		min, max := float64(math.MinInt32), float64(math.MaxInt32)
+		prop.Minimum, prop.Maximum = &min, &max
+	case 'N':
+		prop.Type = "number"
+		if f.Decimals == 0 {
+			prop.Type = "integer"
+		}
+	case 'F', 'B', 'Y':
+		prop.Type = "number"
+	case 'L':
+		prop.Type = "boolean"
+	case 'D', 'T':
+		prop.Type = "string"
+		prop.Format = "date-time"
+	default:
+		// []byte values are written as base64
+		prop.Type = "string"
+		prop.ContentEncoding = "base64"
+	}
+	return prop
+}
@@ -0,0 +1,65 @@
+package dbf
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	schema := dbf.JSONSchema("test")
+	if len(schema.Properties) != 13 || len(schema.Required) != 13 {
+		t.Fatalf("Want 13 properties and 13 required, have %d and %d", len(schema.Properties), len(schema.Required))
+	}
+	tests := []struct {
+		key       string
+		typ       interface{}
+		maxLength int
+		format    string
+	}{
+		{"ID", "integer", 0, ""},
+		{"COMP_NAME", "string", 40, ""},
+		{"DATUM", "string", 0, "date-time"},
+		{"NUMBER", "number", 0, ""},
+		{"SOORT", "integer", 0, ""},
+		{"BOOL", "boolean", 0, ""},
+		{"MELDING", "string", 0, ""},
+	}
+	for _, test := range tests {
+		prop := schema.Properties[test.key]
+		if prop == nil {
+			t.Errorf("Missing property %s", test.key)
+			continue
+		}
+		if !reflect.DeepEqual(prop.Type, test.typ) || prop.MaxLength != test.maxLength || prop.Format != test.format {
+			t.Errorf("%s: want %v %d %q, have %v %d %q", test.key, test.typ, test.maxLength, test.format, prop.Type, prop.MaxLength, prop.Format)
+		}
+	}
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// the schema follows the JSON options of the table
+	dbf.SetJSONOptions(JSONOptions{KeyStyle: JSONKeysCamel, Nulls: JSONNullsNull})
+	schema = dbf.JSONSchema("")
+	prop := schema.Properties["compName"]
+	if prop == nil || !reflect.DeepEqual(prop.Type, []string{"string", "null"}) {
+		t.Errorf("Want nullable compName, have %+v", prop)
+	}
+	dbf.SetJSONOptions(JSONOptions{Nulls: JSONNullsOmit, Logicals: JSONLogicalsNumber})
+	schema = dbf.JSONSchema("")
+	if prop := schema.Properties["BOOL"]; prop == nil || prop.Type != "integer" {
+		t.Errorf("Want integer BOOL for numeric logicals, have %+v", prop)
+	}
+	// C, M, D fields can be omitted
+	if len(schema.Required) != 8 {
+		t.Errorf("Want 8 required properties, have %d: %v", len(schema.Required), schema.Required)
+	}
+}
@@ -0,0 +1,43 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/SebastiaanKlippert/go-foxpro-dbf/jd"
+)
+
+// JulianAt returns the Julian day number and the milliseconds since midnight stored in a T field of the record
+// at recno, without any validation or time zone conversion. An empty datetime is stored as 0, 0.
+// Use it to copy datetimes byte for byte or to find datetimes which are read as the zero time.
+func (dbf *DBF) JulianAt(recno uint32, fieldpos int) (day, msec uint32, err error) {
+	raw, f, err := dbf.typedField(recno, fieldpos)
+	if err != nil {
+		return 0, 0, err
+	}
+	if f.Type != 'T' || len(raw) != 8 {
+		return 0, 0, typedFieldError(f, "Julian day")
+	}
+	return binary.LittleEndian.Uint32(raw[:4]), binary.LittleEndian.Uint32(raw[4:]), nil
+}
+
+// JulianToTime returns the UTC time of Julian day number day plus msec milliseconds, the way T values are read.
+// Days before the year 0 or after the year 9999, including the 0 of an empty datetime, return the zero time.
+// Milliseconds of more than a day continue on the next day.
+func JulianToTime(day, msec uint32) time.Time {
+	if int64(day) < int64(julianMin) || int64(day) > int64(julianMax) {
+		return time.Time{}
+	}
+	return julianTime(int(day), int(msec))
+}
+
+// TimeToJulian returns the Julian day number and the milliseconds since midnight of the date and time of t in its
+// location, the way T values are written. Smaller units than milliseconds are truncated, the zero time returns 0, 0.
+func TimeToJulian(t time.Time) (day, msec uint32) {
+	if t.IsZero() {
+		return 0, 0
+	}
+	day = uint32(jd.YMD2J(t.Year(), int(t.Month()), t.Day()))
+	msec = uint32(((t.Hour()*60+t.Minute())*60+t.Second())*1000 + t.Nanosecond()/int(time.Millisecond))
+	return day, msec
+}
@@ -0,0 +1,51 @@
+package dbf
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestJulian(t *testing.T) {
+	changed := time.Date(2019, 11, 3, 14, 5, 6, 789000000, time.UTC)
+	day, msec := TimeToJulian(changed)
+	if day != 2458791 || msec != 50706789 {
+		t.Errorf("Want day 2458791 and 50706789 ms, have %d and %d", day, msec)
+	}
+	if have := JulianToTime(day, msec); !have.Equal(changed) {
+		t.Errorf("Want %v, have %v", changed, have)
+	}
+	if day, msec := TimeToJulian(time.Time{}); day != 0 || msec != 0 {
+		t.Errorf("Want 0, 0 for the zero time, have %d, %d", day, msec)
+	}
+	if have := JulianToTime(0, 0); !have.IsZero() {
+		t.Errorf("Want the zero time for day 0, have %v", have)
+	}
+	if have := JulianToTime(day, 86400000); !have.Equal(time.Date(2019, 11, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Want the next day for a full day of milliseconds, have %v", have)
+	}
+
+	dbffile, fptfile := new(memWriteSeeker), new(memWriteSeeker)
+	fields := []FieldHeader{newField("BORN", 'D', 0, 0), newField("CHANGED", 'T', 0, 0)}
+	wr, err := NewWriter(dbffile, fptfile, fields, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append(changed, changed); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(dbffile.buf), bytes.NewReader(fptfile.buf), new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbf.SetTimeZone(time.FixedZone("CET", 3600), nil)
+	if d, ms, err := dbf.JulianAt(0, 1); err != nil || d != day || ms != msec {
+		t.Errorf("Want the stored day and milliseconds, have %d, %d (%v)", d, ms, err)
+	}
+	if _, _, err := dbf.JulianAt(0, 0); err == nil {
+		t.Error("Want an error for a D field")
+	}
+}
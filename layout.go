@@ -0,0 +1,60 @@
+package dbf
+
+import "fmt"
+
+// fieldLayout is the position and name of a field in the record data
+type fieldLayout struct {
+	start, end int
+	name       string // FieldName, which allocates a string on every call
+	ascii      bool   // the decoder is skipped, see SetASCIIFields
+	null       int    // bit in _NullFlags which is set when the value is null, -1 if the field is not nullable
+}
+
+// computeLayout returns the positions of the fields in the record data, the fields follow the deletion flag
+// in table order. The positions are calculated instead of taken from the field headers, because not all
+// file versions store the displacement of the fields.
+func computeLayout(fields []FieldHeader) []fieldLayout {
+	layout := make([]fieldLayout, len(fields))
+	bits := nullBits(fields)
+	offset := 1 // deletion flag
+	for i, f := range fields {
+		layout[i] = fieldLayout{start: offset, end: offset + int(f.Len), name: f.FieldName(), null: bits[i]}
+		offset += int(f.Len)
+	}
+	return layout
+}
+
+// data returns the data of the field in the record data
+func (l fieldLayout) data(record []byte) []byte {
+	return record[l.start:l.end]
+}
+
+// SetASCIIFields marks C and M fields which only contain ASCII text, their values are converted to strings
+// without calling the decoder. The data is not checked, non-ASCII bytes are returned as is.
+// Calling SetASCIIFields without names resets all fields to use the decoder.
+func (dbf *DBF) SetASCIIFields(fieldnames ...string) error {
+	positions := make([]int, len(fieldnames))
+	for i, name := range fieldnames {
+		if positions[i] = dbf.FieldPos(name); positions[i] < 0 {
+			return fmt.Errorf("field %s not found", name)
+		}
+	}
+	for i := range dbf.layout {
+		dbf.layout[i].ascii = false
+	}
+	for _, pos := range positions {
+		dbf.layout[pos].ascii = true
+	}
+	return nil
+}
+
+// asciiFields returns the names of the fields marked with SetASCIIFields
+func (dbf *DBF) asciiFields() []string {
+	var names []string
+	for _, l := range dbf.layout {
+		if l.ascii {
+			names = append(names, l.name)
+		}
+	}
+	return names
+}
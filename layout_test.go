@@ -0,0 +1,90 @@
+package dbf
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestComputeLayout(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	end := 1
+	for i, f := range dbf.Fields() {
+		l := dbf.layout[i]
+		if l.start != int(f.Pos) || l.end != int(f.Pos)+int(f.Len) {
+			t.Errorf("Field %s: want offset %d-%d, have %d-%d", f.FieldName(), f.Pos, int(f.Pos)+int(f.Len), l.start, l.end)
+		}
+		if l.name != f.FieldName() {
+			t.Errorf("Want field name %s, have %s", f.FieldName(), l.name)
+		}
+		end = l.end
+	}
+	if end != int(dbf.Header().RecLen) {
+		t.Errorf("Want the fields to end at the record length %d, have %d", dbf.Header().RecLen, end)
+	}
+
+	if pos := dbf.FieldPos("COMP_OS"); pos != 8 {
+		t.Errorf("Want COMP_OS at position 8, have %d", pos)
+	}
+	if pos := dbf.FieldPos("comp_os"); pos != -1 {
+		t.Errorf("Want -1 for a field name in the wrong case, have %d", pos)
+	}
+	if _, err := dbf.readField(0, len(dbf.Fields())); err != ErrInvalidField {
+		t.Errorf("Want ErrInvalidField reading past the last field, have %v", err)
+	}
+	raw, err := dbf.readField(2, dbf.FieldPos("COMP_OS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(raw); s != "Windows 7 SP1       " {
+		t.Errorf("Want Windows 7 SP1, have %q", s)
+	}
+}
+
+func TestSetASCIIFields(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	if err := dbf.SetASCIIFields("COMP_OS", "NOPE"); err == nil {
+		t.Error("Want an error for an unknown field")
+	}
+	if err := dbf.SetASCIIFields("TIJD", "COMP_NAME", "COMP_OS", "MELDING"); err != nil {
+		t.Fatal(err)
+	}
+	dec := new(countingDecoder)
+	dbf.dec = dec
+	rec, err := dbf.RecordAt(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := rec.FieldSlice()[dbf.FieldPos("COMP_OS")]; s != "Windows 7 SP1       " {
+		t.Errorf("Want Windows 7 SP1, have %q", s)
+	}
+	if dec.calls != 0 {
+		t.Errorf("Want the decoder to be skipped, have %d calls", dec.calls)
+	}
+
+	if err := dbf.SetASCIIFields(); err != nil {
+		t.Fatal(err)
+	}
+	if names := dbf.asciiFields(); len(names) != 0 {
+		t.Errorf("Want no ASCII fields after a reset, have %v", names)
+	}
+}
+
+// countingDecoder counts the calls to Decode
+type countingDecoder struct {
+	calls int
+}
+
+func (d *countingDecoder) Decode(in []byte) ([]byte, error) {
+	d.calls++
+	return in, nil
+}
@@ -0,0 +1,36 @@
+package dbf
+
+import (
+	"fmt"
+	"math"
+)
+
+// The limits of the file formats. The data area of a table is not limited to 4 GB, a table can have
+// MaxRecords records of MaxRecordLength bytes, all offsets are calculated as int64.
+const (
+	// MaxRecords is the maximum number of records of a table, the record count is stored as a 32 bit number
+	MaxRecords = math.MaxUint32
+	// MaxRecordLength is the maximum length of a record, including the deletion flag
+	MaxRecordLength = math.MaxUint16
+	// MaxHeaderLength is the maximum length of the table header, which is the position of the first record
+	MaxHeaderLength = math.MaxUint16
+	// MaxMemoBlocks is the maximum number of blocks in a memo file, block numbers are 32 bit numbers
+	MaxMemoBlocks = math.MaxUint32
+	// MaxMemoLength is the maximum length of one memo, the length is stored as a 32 bit number
+	MaxMemoLength = math.MaxUint32
+)
+
+// maxInt is the maximum value of an int, which limits the size of a memo that can be read on 32 bit platforms
+const maxInt = int(^uint(0) >> 1)
+
+// LimitError is returned when a table or memo file would exceed a limit of its file format,
+// values are never wrapped around silently
+type LimitError struct {
+	Limit string // description of the limit, like "number of records"
+	Value uint64 // the value which does not fit
+	Max   uint64 // the maximum of the file format
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %d exceeds the maximum of %d", e.Limit, e.Value, e.Max)
+}
@@ -0,0 +1,115 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLargeTable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbflimits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "LARGE.DBF")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wr, err := NewWriter(f, nil, []FieldHeader{newField("NAME", 'C', 254, 0)}, new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append("first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// grow the table to a data area of more than 4 GB, the file is sparse on most file systems
+	const numRec = 20000000
+	header := wr.Header()
+	last := int64(header.FirstRec) + int64(numRec-1)*int64(header.RecLen)
+	if last < 1<<32 {
+		t.Fatalf("Test table is too small, last record at %d", last)
+	}
+	num := make([]byte, 4)
+	binary.LittleEndian.PutUint32(num, numRec)
+	if _, err := f.WriteAt(num, 4); err != nil {
+		t.Fatal(err)
+	}
+	rec := append([]byte(" last"), bytes.Repeat([]byte(" "), int(header.RecLen)-5)...)
+	if _, err := f.WriteAt(append(rec, 0x1A), last); err != nil {
+		t.Skipf("Cannot create a large file: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dbf, err := OpenFile(filename, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	if size := dbf.Header().FileSize(); size != last+int64(header.RecLen) {
+		t.Errorf("Want file size %d, have %d", last+int64(header.RecLen), size)
+	}
+	for recno, want := range map[uint32]string{0: "first", numRec - 1: "last"} {
+		r, err := dbf.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name := strings.TrimSpace(r.FieldSlice()[0].(string)); name != want {
+			t.Errorf("Record %d: want %s, have %s", recno, want, name)
+		}
+	}
+}
+
+func TestLimitErrors(t *testing.T) {
+	h := &DBFHeader{FileVersion: 0x30, NumRec: MaxRecords, FirstRec: 296 + 32, RecLen: MaxRecordLength}
+	if want := int64(296+32) + int64(MaxRecords)*MaxRecordLength; h.FileSize() != want {
+		t.Errorf("Want file size %d, have %d", want, h.FileSize())
+	}
+
+	wr, err := NewWriter(new(memWriteSeeker), nil, []FieldHeader{newField("NAME", 'C', 10, 0)}, new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wr.header.NumRec = MaxRecords
+	if err := wr.Append("full"); !isLimitError(err, "number of records") {
+		t.Errorf("Want a LimitError for the number of records, have %v", err)
+	}
+	if wr.NumRecords() != MaxRecords {
+		t.Errorf("Want the record count to stay at %d, have %d", uint32(MaxRecords), wr.NumRecords())
+	}
+
+	fields := make([]FieldHeader, 2100)
+	for i := range fields {
+		fields[i] = newField(fmt.Sprintf("F%d", i), 'L', 0, 0)
+	}
+	if _, err := NewWriter(new(memWriteSeeker), nil, fields, new(Win1250Encoder)); !isLimitError(err, "number of fields") {
+		t.Errorf("Want a LimitError for the number of fields, have %v", err)
+	}
+
+	mw, err := newMemoWriter(new(memWriteSeeker), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mw.next = MaxMemoBlocks
+	if _, err := mw.write(1, []byte("no room")); !isLimitError(err, "number of memo blocks") {
+		t.Errorf("Want a LimitError for the number of memo blocks, have %v", err)
+	}
+}
+
+func isLimitError(err error, limit string) bool {
+	le, ok := err.(*LimitError)
+	return ok && le.Limit == limit
+}
@@ -0,0 +1,177 @@
+package dbf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Link relates a child table to a parent table on key fields, like SET RELATION in FoxPro.
+// Every time the record pointer of the parent is moved with GoTo or Skip, the record pointer of the child
+// is positioned at the first child record with the same key, or at EOF when there is none.
+// The other child records of the parent record are available with RecNos, Records and SkipChild.
+//
+// Keys are compared as trimmed strings, numbers are compared by value so an N field can be related to an I field.
+// Deleted child records are ignored. The child records are indexed in memory when the Link is created,
+// call Reindex when the child table has changed.
+// Links can be chained, moving the child pointer also moves the pointers of the children of the child.
+type Link struct {
+	parent       *DBF
+	child        *DBF
+	parentFields []int
+	childFields  []int
+
+	index   map[string][]uint32 // child record numbers by key
+	matches []uint32            // child record numbers of the current parent record
+	pos     int                 // position of the child pointer in matches
+	err     error
+}
+
+// NewLink links child to parent, parentFields and childFields are the names of the key fields in the same order.
+// The child pointer is positioned for the current parent record.
+func NewLink(parent *DBF, parentFields []string, child *DBF, childFields []string) (*Link, error) {
+	if len(parentFields) == 0 || len(parentFields) != len(childFields) {
+		return nil, errors.New("the same number of parent and child key fields is required")
+	}
+	l := &Link{parent: parent, child: child}
+	for i := range parentFields {
+		p := parent.FieldPos(parentFields[i])
+		if p < 0 {
+			return nil, fmt.Errorf("parent field %s not found", parentFields[i])
+		}
+		c := child.FieldPos(childFields[i])
+		if c < 0 {
+			return nil, fmt.Errorf("child field %s not found", childFields[i])
+		}
+		l.parentFields = append(l.parentFields, p)
+		l.childFields = append(l.childFields, c)
+	}
+	if err := l.Reindex(); err != nil {
+		return nil, err
+	}
+	parent.links = append(parent.links, l)
+	l.sync()
+	return l, l.err
+}
+
+// Reindex reads the keys of all child records again and positions the child pointer for the current parent record
+func (l *Link) Reindex() error {
+	index := make(map[string][]uint32)
+	for recno := uint32(0); recno < l.child.NumRecords(); recno++ {
+		deleted, err := l.child.DeletedAt(recno)
+		if err != nil {
+			return fmt.Errorf("child record %d: %s", recno, err)
+		}
+		if deleted {
+			continue
+		}
+		key, err := linkKey(l.child, recno, l.childFields)
+		if err != nil {
+			return fmt.Errorf("child record %d: %s", recno, err)
+		}
+		index[key] = append(index[key], recno)
+	}
+	l.index = index
+	if l.matches != nil {
+		l.sync()
+	}
+	return l.err
+}
+
+// Remove removes the link from the parent, like SET RELATION OFF. The child pointer is not moved.
+func (l *Link) Remove() {
+	for i, link := range l.parent.links {
+		if link == l {
+			l.parent.links = append(l.parent.links[:i], l.parent.links[i+1:]...)
+			return
+		}
+	}
+}
+
+// RecNos returns the record numbers of the child records of the current parent record
+func (l *Link) RecNos() []uint32 {
+	return l.matches
+}
+
+// Records reads the child records of the current parent record
+func (l *Link) Records() ([]*Record, error) {
+	records := make([]*Record, 0, len(l.matches))
+	for _, recno := range l.matches {
+		rec, err := l.child.RecordAt(recno)
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// SkipChild moves the child pointer to the next child record of the current parent record, like SET SKIP.
+// It returns ErrEOF and positions the child at EOF after the last child record.
+func (l *Link) SkipChild() error {
+	l.pos++
+	if l.pos >= len(l.matches) {
+		l.pos = len(l.matches)
+		l.child.GoTo(l.child.NumRecords())
+		return ErrEOF
+	}
+	return l.child.GoTo(l.matches[l.pos])
+}
+
+// Err returns the error of the last positioning of the child, when the key of the parent record could not be read
+func (l *Link) Err() error {
+	return l.err
+}
+
+// sync positions the child at the first child record of the current parent record
+func (l *Link) sync() {
+	l.matches, l.pos, l.err = []uint32{}, 0, nil
+	if !l.parent.EOF() {
+		key, err := linkKey(l.parent, l.parent.recpointer, l.parentFields)
+		if err != nil {
+			l.err = err
+		} else if recnos, ok := l.index[key]; ok {
+			l.matches = recnos
+		}
+	}
+	if len(l.matches) == 0 {
+		l.child.GoTo(l.child.NumRecords())
+		return
+	}
+	l.child.GoTo(l.matches[0])
+}
+
+// syncLinks positions the children of all links after the record pointer was moved
+func (dbf *DBF) syncLinks() {
+	for _, l := range dbf.links {
+		l.sync()
+	}
+}
+
+// linkKey returns the key of the record at recno, the values of the fields are joined with a tab
+func linkKey(dbf *DBF, recno uint32, fields []int) (string, error) {
+	format := new(CSVReader)
+	keys := make([]string, len(fields))
+	for i, pos := range fields {
+		raw, err := dbf.readField(recno, pos)
+		if err != nil {
+			return "", err
+		}
+		val, err := dbf.fieldValue(raw, pos)
+		if err != nil {
+			return "", err
+		}
+		switch v := val.(type) {
+		case int32:
+			keys[i] = strconv.FormatInt(int64(v), 10)
+		case int64:
+			keys[i] = strconv.FormatInt(v, 10)
+		case float64:
+			keys[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			keys[i] = strings.TrimSpace(format.format(val, &dbf.fields[pos]))
+		}
+	}
+	return strings.Join(keys, "\t"), nil
+}
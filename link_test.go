@@ -0,0 +1,92 @@
+package dbf
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLink(t *testing.T) {
+	out := new(memWriteSeeker)
+	wr, err := NewWriter(out, nil, []FieldHeader{newField("LEVEL", 'I', 0, 0), newField("NAME", 'C', 10, 0)}, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, level := range []int{1, 0, 5} {
+		if err := wr.Append(level, "level"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	parent, err := OpenStream(bytes.NewReader(out.buf), nil, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer child.Close()
+
+	if _, err := NewLink(parent, []string{"LEVEL"}, child, []string{"NOTEXIST"}); err == nil {
+		t.Error("Want an error for a missing child field")
+	}
+	link, err := NewLink(parent, []string{"LEVEL"}, child, []string{"NIVEAU"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// level 1: record 1 is deleted
+	if child.recpointer != 2 || !reflect.DeepEqual(link.RecNos(), []uint32{2}) {
+		t.Errorf("Want child at record 2, have %d and %v", child.recpointer, link.RecNos())
+	}
+	if err := link.SkipChild(); err != ErrEOF || !child.EOF() {
+		t.Errorf("Want ErrEOF and the child at EOF, have %v", err)
+	}
+
+	// level 0
+	if err := parent.Skip(1); err != nil {
+		t.Fatal(err)
+	}
+	if child.recpointer != 0 || !reflect.DeepEqual(link.RecNos(), []uint32{0, 3}) {
+		t.Errorf("Want child at record 0, have %d and %v", child.recpointer, link.RecNos())
+	}
+	if err := link.SkipChild(); err != nil || child.recpointer != 3 {
+		t.Errorf("Want child at record 3, have %d and %v", child.recpointer, err)
+	}
+	records, err := link.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].FieldSlice()[0] != int32(4) {
+		t.Errorf("Want the records with ID 1 and 4, have %v", records)
+	}
+
+	// level 5 has no children
+	if err := parent.GoTo(2); err != nil {
+		t.Fatal(err)
+	}
+	if !child.EOF() || len(link.RecNos()) != 0 {
+		t.Errorf("Want the child at EOF, have %d and %v", child.recpointer, link.RecNos())
+	}
+
+	// parent at EOF
+	parent.GoTo(0)
+	if err := parent.Skip(10); err != ErrEOF {
+		t.Fatal(err)
+	}
+	if !child.EOF() {
+		t.Error("Want the child at EOF when the parent is at EOF")
+	}
+
+	link.Remove()
+	if err := parent.GoTo(1); err != nil {
+		t.Fatal(err)
+	}
+	if !child.EOF() {
+		t.Error("Want the child not to move after Remove")
+	}
+}
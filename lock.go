@@ -0,0 +1,113 @@
+package dbf
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+var (
+	// ErrLocked is returned when a lock is held by another process or another Locker
+	ErrLocked = errors.New("record or table is locked by another user")
+
+	// ErrLockingNotSupported is returned by Locker on systems without byte range locks
+	ErrLockingNotSupported = errors.New("locking is not supported on this system")
+)
+
+// Visual FoxPro does not lock the bytes of a record itself, but single bytes in a region at the end of the 2GB
+// address range which the file never reaches. These offsets can be changed to match other lock schemes,
+// they must be equal in all applications sharing the table.
+var (
+	// VFPLockOffset is the offset of the header lock, which is held while appending records or updating the header.
+	// Record n (one based) is locked at VFPLockOffset - n.
+	VFPLockOffset int64 = 0x7FFFFFFE
+
+	// VFPLockPool is the size of the region below VFPLockOffset used for record locks,
+	// a table lock (FLOCK) locks the whole region including the header lock
+	VFPLockPool int64 = 0x07FFFFFE
+)
+
+// Locker places Visual FoxPro compatible locks on an open DBF file, so Go code changing a table and a running
+// FoxPro application do not overwrite each others changes. Like FoxPro, a writer should lock a record (RLOCK)
+// before changing it and lock the header before appending records or changing the header.
+// Readers do not need to lock.
+//
+// Locks are byte range locks of the operating system, they are mandatory on Windows and advisory on other systems.
+// Locks are released when the file is closed. On Windows and Linux a lock belongs to the opened file, so files
+// opened separately conflict also within one process. On other Unix systems locks belong to the process: they
+// only conflict with other processes, and closing any file of the table releases all locks of the process on it.
+type Locker struct {
+	f *os.File
+
+	// Retries is the number of times a lock is retried when it is held by someone else, like SET REPROCESS
+	Retries int
+
+	// Delay is the time to wait before a retry
+	Delay time.Duration
+
+	// Timeout is the time a lock is retried, in addition to Retries. When Timeout is set without Delay,
+	// the lock is retried every 10 milliseconds.
+	Timeout time.Duration
+}
+
+// NewLocker returns a Locker for the open DBF file f, which does not retry locks
+func NewLocker(f *os.File) *Locker {
+	return &Locker{f: f}
+}
+
+// Locker returns a Locker for the DBF file, or ErrNotOnDisk for tables not opened with OpenFile.
+// The file is opened read-only, on systems other than Windows this means only shared locks can be placed,
+// which still conflict with the locks of writers.
+func (dbf *DBF) Locker() (*Locker, error) {
+	if dbf.f == nil {
+		return nil, ErrNotOnDisk
+	}
+	return NewLocker(dbf.f), nil
+}
+
+// LockRecord locks the record at recno (zero based), it returns ErrLocked when the record is locked by someone else
+func (l *Locker) LockRecord(recno uint32) error {
+	return l.lock(VFPLockOffset-int64(recno)-1, 1)
+}
+
+// UnlockRecord releases the lock of the record at recno (zero based)
+func (l *Locker) UnlockRecord(recno uint32) error {
+	return unlockRange(l.f, VFPLockOffset-int64(recno)-1, 1)
+}
+
+// LockHeader locks the header, this is the lock FoxPro holds while appending a record
+func (l *Locker) LockHeader() error {
+	return l.lock(VFPLockOffset, 1)
+}
+
+// UnlockHeader releases the header lock
+func (l *Locker) UnlockHeader() error {
+	return unlockRange(l.f, VFPLockOffset, 1)
+}
+
+// LockTable locks the whole table, it fails when any record or the header is locked by someone else
+func (l *Locker) LockTable() error {
+	return l.lock(VFPLockOffset-VFPLockPool, VFPLockPool+1)
+}
+
+// UnlockTable releases the table lock
+func (l *Locker) UnlockTable() error {
+	return unlockRange(l.f, VFPLockOffset-VFPLockPool, VFPLockPool+1)
+}
+
+// lock locks a byte range, retrying when it is held by someone else
+func (l *Locker) lock(offset, length int64) error {
+	delay, deadline := l.Delay, time.Time{}
+	if l.Timeout > 0 {
+		deadline = time.Now().Add(l.Timeout)
+		if delay == 0 {
+			delay = 10 * time.Millisecond
+		}
+	}
+	err := lockRange(l.f, offset, length)
+	for retry := 0; err == ErrLocked && (retry < l.Retries || time.Now().Before(deadline)); retry++ {
+		time.Sleep(delay)
+		err = lockRange(l.f, offset, length)
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+//go:build linux
+// +build linux
+
+package dbf
+
+import (
+	"syscall"
+)
+
+// fOFDSetLk is F_OFD_SETLK, which the syscall package does not define
+const fOFDSetLk = 37
+
+// setLock places or releases an open file description lock, which belongs to the opened file instead of the
+// process, so files opened separately in one process conflict like those of two processes.
+// Kernels before Linux 3.15 do not have these locks and get a classic fcntl lock.
+func setLock(fd uintptr, lk *syscall.Flock_t) error {
+	err := syscall.FcntlFlock(fd, fOFDSetLk, lk)
+	if err == syscall.EINVAL {
+		err = syscall.FcntlFlock(fd, syscall.F_SETLK, lk)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package dbf
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// TestLockerSameProcess checks that the open file description locks of Linux conflict for files opened separately
+// in one process, and not for Lockers of the same file
+func TestLockerSameProcess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbflock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+	open := func() *os.File {
+		f, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	f1, f2 := open(), open()
+	defer f1.Close()
+	defer f2.Close()
+
+	l1, l2 := NewLocker(f1), NewLocker(f2)
+	if err := l1.LockRecord(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := l2.LockRecord(2); err != ErrLocked {
+		t.Errorf("Want ErrLocked for a record locked through another file, have %v", err)
+	}
+	if err := NewLocker(f1).LockRecord(2); err != nil {
+		t.Errorf("Want the lock of the same file to be placed again, have %v", err)
+	}
+	// closing another file of the table keeps the lock
+	f3 := open()
+	f3.Close()
+	if err := l2.LockRecord(2); err != ErrLocked {
+		t.Errorf("Want ErrLocked after closing another file, have %v", err)
+	}
+	if err := l1.UnlockRecord(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := l2.LockRecord(2); err != nil {
+		t.Errorf("Want the record lock after it was released, have %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !illumos && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!illumos,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package dbf
+
+import (
+	"os"
+)
+
+func lockRange(f *os.File, offset, length int64) error {
+	return ErrLockingNotSupported
+}
+
+func unlockRange(f *os.File, offset, length int64) error {
+	return ErrLockingNotSupported
+}
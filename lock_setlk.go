@@ -0,0 +1,14 @@
+//go:build aix || darwin || dragonfly || freebsd || illumos || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd illumos netbsd openbsd solaris
+
+package dbf
+
+import (
+	"syscall"
+)
+
+// setLock places or releases a classic fcntl lock, which belongs to the process: locks of one process never
+// conflict, and closing any file of the table releases all locks of the process on it
+func setLock(fd uintptr, lk *syscall.Flock_t) error {
+	return syscall.FcntlFlock(fd, syscall.F_SETLK, lk)
+}
@@ -0,0 +1,148 @@
+package dbf
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLockerHelperProcess is run as a separate process by TestLocker, because locks of the same process do not
+// conflict on all systems. It locks record 2 and the header of the file in DBF_LOCK_FILE until stdin is closed.
+func TestLockerHelperProcess(t *testing.T) {
+	filename := os.Getenv("DBF_LOCK_FILE")
+	if filename == "" {
+		t.Skip("helper process for TestLocker")
+	}
+	f, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	l := NewLocker(f)
+	if err := l.LockRecord(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.LockHeader(); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("locked")
+	ioutil.ReadAll(os.Stdin)
+}
+
+func TestLocker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbflock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"TEST.DBF", "TEST.FPT"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filename := filepath.Join(dir, "TEST.DBF")
+
+	f, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	l := NewLocker(f)
+	if err := l.LockTable(); err == ErrLockingNotSupported {
+		t.Skip(err)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.UnlockTable(); err != nil {
+		t.Fatal(err)
+	}
+
+	release := lockInOtherProcess(t, filename)
+
+	if err := l.LockRecord(2); err != ErrLocked {
+		t.Errorf("Want ErrLocked for a record locked by another process, have %v", err)
+	}
+	if err := l.LockHeader(); err != ErrLocked {
+		t.Errorf("Want ErrLocked for the header locked by another process, have %v", err)
+	}
+	l.Timeout = 30 * time.Millisecond
+	start := time.Now()
+	if err := l.LockHeader(); err != ErrLocked || time.Since(start) < l.Timeout {
+		t.Errorf("Want ErrLocked after retrying for %s, have %v after %s", l.Timeout, err, time.Since(start))
+	}
+	l.Timeout = 0
+	if err := l.LockTable(); err != ErrLocked {
+		t.Errorf("Want ErrLocked for the table, have %v", err)
+	}
+	if err := l.LockRecord(1); err != nil {
+		t.Errorf("Want record 1 to be unlocked, have %v", err)
+	}
+	if err := l.UnlockRecord(1); err != nil {
+		t.Error(err)
+	}
+
+	release()
+
+	// the locks of the helper are released when it exits
+	l.Retries = 3
+	if err := l.LockTable(); err != nil {
+		t.Errorf("Want the table lock after the other process exited, have %v", err)
+	}
+	if err := l.UnlockTable(); err != nil {
+		t.Error(err)
+	}
+
+	dbf, err := OpenFile(filename, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	rl, err := dbf.Locker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.LockRecord(0); err != nil {
+		t.Errorf("Want a lock with a read-only file, have %v", err)
+	}
+}
+
+// lockInOtherProcess runs TestLockerHelperProcess to lock record 2 and the header of filename,
+// the returned function releases the locks by ending the process
+func lockInOtherProcess(t *testing.T, filename string) func() {
+	cmd := exec.Command(os.Args[0], "-test.run=TestLockerHelperProcess")
+	cmd.Env = append(os.Environ(), "DBF_LOCK_FILE="+filename)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() && scanner.Text() != "locked" {
+	}
+	if scanner.Text() != "locked" {
+		stdin.Close()
+		cmd.Wait()
+		t.Fatalf("Helper process did not lock: %v", scanner.Err())
+	}
+	return func() {
+		stdin.Close()
+		if err := cmd.Wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+//go:build aix || darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd illumos linux netbsd openbsd solaris
+
+package dbf
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// lockRange places an fcntl lock on a byte range without waiting. An exclusive lock is used when the file is open
+// for writing, a shared lock otherwise.
+func lockRange(f *os.File, offset, length int64) error {
+	lk := syscall.Flock_t{Type: syscall.F_WRLCK, Whence: io.SeekStart, Start: offset, Len: length}
+	err := setLock(f.Fd(), &lk)
+	if err == syscall.EBADF {
+		lk.Type = syscall.F_RDLCK
+		err = setLock(f.Fd(), &lk)
+	}
+	if err == syscall.EAGAIN || err == syscall.EACCES {
+		return ErrLocked
+	}
+	return err
+}
+
+// unlockRange releases the lock of a byte range
+func unlockRange(f *os.File, offset, length int64) error {
+	lk := syscall.Flock_t{Type: syscall.F_UNLCK, Whence: io.SeekStart, Start: offset, Len: length}
+	return setLock(f.Fd(), &lk)
+}
@@ -0,0 +1,53 @@
+//go:build windows
+// +build windows
+
+package dbf
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileFailImmediately = 0x00000001
+	lockfileExclusiveLock   = 0x00000002
+
+	errorLockViolation syscall.Errno = 33
+)
+
+// lockRange places an exclusive LockFileEx lock on a byte range without waiting
+func lockRange(f *os.File, offset, length int64) error {
+	ol := overlapped(offset)
+	r, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock|lockfileFailImmediately, 0,
+		uintptr(uint32(length)), uintptr(uint32(length>>32)), uintptr(unsafe.Pointer(ol)))
+	if r != 0 {
+		return nil
+	}
+	if err == errorLockViolation {
+		return ErrLocked
+	}
+	return err
+}
+
+// unlockRange releases the lock of a byte range
+func unlockRange(f *os.File, offset, length int64) error {
+	ol := overlapped(offset)
+	r, _, err := procUnlockFileEx.Call(f.Fd(), 0,
+		uintptr(uint32(length)), uintptr(uint32(length>>32)), uintptr(unsafe.Pointer(ol)))
+	if r != 0 {
+		return nil
+	}
+	return err
+}
+
+// overlapped returns the OVERLAPPED structure with the offset of the range to lock
+func overlapped(offset int64) *syscall.Overlapped {
+	return &syscall.Overlapped{Offset: uint32(offset), OffsetHigh: uint32(offset >> 32)}
+}
@@ -0,0 +1,93 @@
+package dbf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lookup is a code table (code to description) loaded in memory, to decode the codes stored in other tables.
+// Codes and descriptions are trimmed, codes are matched case sensitive.
+type Lookup struct {
+	values map[string]string
+}
+
+// NewLookup loads the code and description fields of all not deleted records of dbf.
+// When a code occurs more than once, the first record is used.
+func NewLookup(dbf *DBF, codeField, descField string) (*Lookup, error) {
+	codePos := dbf.FieldPos(codeField)
+	if codePos < 0 {
+		return nil, fmt.Errorf("code field %s not found", codeField)
+	}
+	descPos := dbf.FieldPos(descField)
+	if descPos < 0 {
+		return nil, fmt.Errorf("description field %s not found", descField)
+	}
+	format := new(CSVReader)
+	l := &Lookup{values: make(map[string]string)}
+	for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+		deleted, err := dbf.DeletedAt(recno)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", recno, err)
+		}
+		if deleted {
+			continue
+		}
+		rec, err := dbf.RecordAt(recno)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", recno, err)
+		}
+		code := strings.TrimSpace(format.format(rec.data[codePos], &dbf.fields[codePos]))
+		if _, ok := l.values[code]; ok {
+			continue
+		}
+		l.values[code] = strings.TrimSpace(format.format(rec.data[descPos], &dbf.fields[descPos]))
+	}
+	return l, nil
+}
+
+// LoadLookup opens the code table in filename, loads it with NewLookup and closes it
+func LoadLookup(filename string, dec Decoder, codeField, descField string) (*Lookup, error) {
+	dbf, err := OpenFile(filename, dec)
+	if err != nil {
+		return nil, err
+	}
+	defer dbf.Close()
+	return NewLookup(dbf, codeField, descField)
+}
+
+// Get returns the description of a code, the code is trimmed before the lookup
+func (l *Lookup) Get(code string) (string, bool) {
+	desc, ok := l.values[strings.TrimSpace(code)]
+	return desc, ok
+}
+
+// Len returns the number of codes
+func (l *Lookup) Len() int {
+	return len(l.values)
+}
+
+// Enrich adds the description of the code in m[field] to m[column], for maps returned by RecordToMap.
+// Unknown codes get an empty description.
+func (l *Lookup) Enrich(m map[string]interface{}, field, column string) {
+	desc, _ := l.Get(ToString(m[field]))
+	m[column] = desc
+}
+
+// csvLookup is a column added to the output of a CSVReader by AddLookup
+type csvLookup struct {
+	pos    int
+	column string
+	lookup *Lookup
+}
+
+// AddLookup adds a column with the description of the code in field to the rows returned by the CSVReader.
+// The columns are added after the table fields, in the order AddLookup is called.
+// AddLookup must be called before the first Read.
+func (r *CSVReader) AddLookup(field string, l *Lookup, column string) error {
+	pos := r.dbf.FieldPos(field)
+	if pos < 0 {
+		return fmt.Errorf("field %s not found", field)
+	}
+	r.lookups = append(r.lookups, csvLookup{pos: pos, column: column, lookup: l})
+	return nil
+}
@@ -0,0 +1,75 @@
+package dbf
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	out := new(memWriteSeeker)
+	wr, err := NewWriter(out, nil, []FieldHeader{newField("CODE", 'C', 5, 0), newField("DESCR", 'C', 20, 0)}, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, values := range [][]interface{}{{"TEST", "First company"}, {"TEST2", "Second company"}, {"TEST", "Duplicate"}} {
+		if err := wr.Append(values...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	codes, err := OpenStream(bytes.NewReader(out.buf), nil, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewLookup(codes, "CODE", "NOTEXIST"); err == nil {
+		t.Error("Want an error for a missing description field")
+	}
+	l, err := NewLookup(codes, "CODE", "DESCR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Len() != 2 {
+		t.Errorf("Want 2 codes, have %d", l.Len())
+	}
+	if desc, ok := l.Get("TEST  "); !ok || desc != "First company" {
+		t.Errorf("Want First company, have %q", desc)
+	}
+	if _, ok := l.Get("OTHER"); ok {
+		t.Error("Want no description for an unknown code")
+	}
+
+	m := map[string]interface{}{"COMP_NAME": "TEST2     "}
+	l.Enrich(m, "COMP_NAME", "COMP_DESC")
+	if m["COMP_DESC"] != "Second company" {
+		t.Errorf("Want Second company, have %v", m["COMP_DESC"])
+	}
+
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	r := NewCSVReader(dbf)
+	if err := r.AddLookup("NOTEXIST", l, "X"); err == nil {
+		t.Error("Want an error for a missing field")
+	}
+	if err := r.AddLookup("COMP_NAME", l, "COMP_DESC"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var descs []string
+	for _, row := range rows {
+		descs = append(descs, row[len(row)-1])
+	}
+	if want := []string{"COMP_DESC", "First company", "Second company", ""}; !reflect.DeepEqual(descs, want) {
+		t.Errorf("Want descriptions %q, have %q", want, descs)
+	}
+}
@@ -0,0 +1,369 @@
+package dbf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AppendStruct writes a record with the values of the exported fields of struct v (or a pointer to a struct).
+// Struct fields are mapped to table fields by the name in their dbf tag, or by their name (case insensitive) if
+// there is no tag. The type and size options of the tag, see SchemaFromStruct, are not used.
+// Struct fields with the tag `dbf:"-"` are ignored. Table fields without a matching struct field are written empty.
+// Values are validated like in Append: strings must be encodable and fit in the field length.
+// Nil pointers are written as empty fields.
+//
+//	type Customer struct {
+//		ID    int       `dbf:"CUSTNO"`
+//		Name  string    `dbf:"NAME"`
+//		Since time.Time `dbf:"SINCE"`
+//		Notes *string   `dbf:"NOTES"`
+//	}
+func (wr *Writer) AppendStruct(v interface{}) error {
+	rv, err := structValue(v, "AppendStruct")
+	if err != nil {
+		return err
+	}
+	index, err := wr.structIndex(rv.Type())
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(wr.fields))
+	for i, idx := range index {
+		if idx == nil {
+			continue
+		}
+		fv, ok := fieldByIndex(rv, idx)
+		if !ok {
+			continue
+		}
+		values[i] = plainValue(fv)
+	}
+	return wr.Append(values...)
+}
+
+// structValue returns the struct of v, which is a struct or a pointer to a struct passed to the function fn
+func structValue(v interface{}, fn string) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return rv, fmt.Errorf("%s needs a non-nil struct", fn)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return rv, fmt.Errorf("%s needs a struct, have %T", fn, v)
+	}
+	return rv, nil
+}
+
+// structIndex returns the struct field index for every table field, nil if no struct field maps to it.
+// The result is cached per struct type.
+func (wr *Writer) structIndex(t reflect.Type) ([][]int, error) {
+	if index, ok := wr.structs[t]; ok {
+		return index, nil
+	}
+	index, err := mapStruct(t, wr.fields)
+	if err != nil {
+		return nil, err
+	}
+	if wr.structs == nil {
+		wr.structs = make(map[reflect.Type][][]int)
+	}
+	wr.structs[t] = index
+	return index, nil
+}
+
+// mapStruct returns the struct field index of struct type t for every field, nil if no struct field maps to it.
+// System fields are never mapped.
+func mapStruct(t reflect.Type, fields []FieldHeader) ([][]int, error) {
+	names := make(map[string]int, len(fields))
+	for i, f := range fields {
+		if !f.System() {
+			names[f.FieldName()] = i
+		}
+	}
+
+	sfs, err := structFields(t)
+	if err != nil {
+		return nil, err
+	}
+	index := make([][]int, len(fields))
+	for _, sf := range sfs {
+		pos, ok := names[sf.name]
+		if !ok {
+			if sf.tagged {
+				return nil, fmt.Errorf("struct field %s: table has no field %s", sf.field.Name, sf.name)
+			}
+			continue
+		}
+		if index[pos] != nil {
+			return nil, fmt.Errorf("struct field %s: field %s is already mapped", sf.field.Name, fields[pos].FieldName())
+		}
+		index[pos] = sf.index
+	}
+	return index, nil
+}
+
+// structField is an exported field of a struct which can be mapped to a table field
+type structField struct {
+	field  reflect.StructField
+	index  []int    // index for fieldByIndex
+	name   string   // upper case table field name, from the tag or the struct field name
+	tagged bool     // the name is set in the tag
+	opts   []string // the options after the name in the tag, like the type and the length
+}
+
+// structFields returns the fields of struct type t in order, embedded structs without tag are flattened.
+// The dbf tag has the table field name and optionally the type, length and decimals: `dbf:"AMOUNT,N,12,2"`.
+// Like encoding/json, an embedded struct which embeds itself, directly or through pointers, is flattened once.
+func structFields(t reflect.Type) ([]structField, error) {
+	var fields []structField
+	visiting := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type, parent []int)
+	walk = func(t reflect.Type, parent []int) {
+		visiting[t] = true
+		defer delete(visiting, t)
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("dbf")
+			if tag == "-" {
+				continue
+			}
+			idx := append(append([]int{}, parent...), i)
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			// embedded structs without tag are flattened
+			if sf.Anonymous && tag == "" && ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+				if !visiting[ft] {
+					walk(ft, idx)
+				}
+				continue
+			}
+			if sf.PkgPath != "" {
+				// unexported
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			f := structField{field: sf, index: idx, name: strings.ToUpper(strings.TrimSpace(parts[0])), opts: parts[1:]}
+			f.tagged = f.name != ""
+			if !f.tagged {
+				f.name = strings.ToUpper(sf.Name)
+			}
+			fields = append(fields, f)
+		}
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+	walk(t, nil)
+	return fields, nil
+}
+
+// SchemaFromStruct returns the fields of a table for the exported fields of struct v (or a pointer to a struct),
+// to create a table with NewWriter and fill it with AppendStruct. The names are taken from the dbf tag or the
+// struct field name, in upper case. The tag can also set the type, length and decimals after the name:
+//
+//	type Invoice struct {
+//		Number  int       `dbf:"INVNO,N,8"`
+//		Amount  float64   `dbf:"AMOUNT,N,12,2"`
+//		Date    time.Time `dbf:",D"`
+//		Note    string    `dbf:"NOTE,M"`
+//		Paid    bool
+//		Ignored string    `dbf:"-"`
+//	}
+//
+// Without a type the type follows from the Go type: strings are C(254), bools L, int8 to int32 and uint8 to uint16
+// I, other integers N(20), floats B, time.Time T and []byte M. Pointers are mapped like their element type.
+// A C field without length has length 254 and an N or F field length 20.
+func SchemaFromStruct(v interface{}) ([]FieldHeader, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil, errors.New("SchemaFromStruct needs a struct")
+	}
+	sfs, err := structFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]FieldHeader, 0, len(sfs))
+	names := make(map[string]bool, len(sfs))
+	for _, sf := range sfs {
+		if len(sf.name) > 10 {
+			return nil, fmt.Errorf("struct field %s: field name %s is longer than 10 characters", sf.field.Name, sf.name)
+		}
+		if names[sf.name] {
+			return nil, fmt.Errorf("struct field %s: field %s is already mapped", sf.field.Name, sf.name)
+		}
+		names[sf.name] = true
+
+		f, err := structFieldHeader(sf)
+		if err != nil {
+			return nil, fmt.Errorf("struct field %s: %s", sf.field.Name, err)
+		}
+		// validates the type and sets the length of fixed size types
+		if err := prepareField(&f); err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%s has no exported fields", t)
+	}
+	return fields, nil
+}
+
+// structFieldHeader returns the header of the table field of a struct field from the tag options or the Go type
+func structFieldHeader(sf structField) (FieldHeader, error) {
+	var f FieldHeader
+	copy(f.Name[:], sf.name)
+
+	if len(sf.opts) > 0 && strings.TrimSpace(sf.opts[0]) != "" {
+		typ := strings.ToUpper(strings.TrimSpace(sf.opts[0]))
+		if len(typ) != 1 {
+			return f, fmt.Errorf("invalid field type %q", typ)
+		}
+		f.Type = typ[0]
+	} else {
+		typ, err := goFieldType(sf.field.Type)
+		if err != nil {
+			return f, err
+		}
+		f.Type = typ
+	}
+
+	var sizeOpts []string
+	if len(sf.opts) > 1 {
+		sizeOpts = sf.opts[1:]
+	}
+	sizes := []*uint8{&f.Len, &f.Decimals}
+	for i, opt := range sizeOpts {
+		if i >= len(sizes) {
+			return f, fmt.Errorf("too many tag options %q", strings.Join(sf.opts, ","))
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(opt), 10, 8)
+		if err != nil {
+			return f, fmt.Errorf("invalid size %q", opt)
+		}
+		*sizes[i] = uint8(n)
+	}
+	if f.Len == 0 {
+		switch f.Type {
+		case 'C':
+			f.Len = 254
+		case 'N', 'F':
+			f.Len = 20
+		}
+	}
+	return f, nil
+}
+
+// goFieldType returns the field type for values of Go type t
+func goFieldType(t reflect.Type) (byte, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.ConvertibleTo(timeType) && t.Kind() == reflect.Struct {
+		return 'T', nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return 'C', nil
+	case reflect.Bool:
+		return 'L', nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return 'I', nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return 'N', nil
+	case reflect.Float32, reflect.Float64:
+		return 'B', nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return 'M', nil
+		}
+	}
+	return 0, fmt.Errorf("no field type for Go type %s, set the type in the dbf tag", t)
+}
+
+// fieldByIndex returns the nested field of v, it returns false if a nil embedded pointer is encountered
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// plainValue converts a struct field value to one of the Go types accepted by Append.
+// Named types are converted to their underlying type, nil pointers to nil.
+func plainValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.IsNil() {
+				return nil
+			}
+			return v.Bytes()
+		}
+	}
+	return v.Interface()
+}
+
+// WriteStruct writes the values of the exported fields of struct v (or a pointer to a struct) to the record at recno
+// of a table opened with OpenFileForUpdate or OpenFileStaged, or appends a new record when recno is NumRecords.
+// Struct fields are mapped to table fields like by Writer.AppendStruct, the values are converted like by
+// WriteFieldAt: time.Time to D and T fields, bool to L fields, numbers to N, F, I, B and Y fields and so on.
+// Table fields without a matching struct field are not changed, in a new record they are empty.
+// The record is only written when all values can be converted. A new record is appended after the records
+// which other programs appended since the table was opened, under the header lock; NumRecords includes them afterwards.
+func (dbf *DBF) WriteStruct(recno uint32, v interface{}) error {
+	rv, err := structValue(v, "WriteStruct")
+	if err != nil {
+		return err
+	}
+	index, err := mapStruct(rv.Type(), dbf.fields)
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(dbf.fields))
+	set := make([]bool, len(dbf.fields))
+	for i, idx := range index {
+		if idx == nil {
+			continue
+		}
+		set[i] = true
+		if fv, ok := fieldByIndex(rv, idx); ok {
+			values[i] = plainValue(fv)
+		}
+	}
+	return dbf.writeRecord(recno, values, set)
+}
@@ -0,0 +1,400 @@
+package dbf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCustomerBase struct {
+	ID int `dbf:"CUSTNO"`
+}
+
+type testCustomerCode string
+
+type testCustomer struct {
+	testCustomerBase
+	Name     testCustomerCode
+	Balance  float64   `dbf:"BALANCE"`
+	Since    time.Time `dbf:"SINCE"`
+	Active   *bool     `dbf:"ACTIVE"`
+	Notes    string    `dbf:"NOTES"`
+	Internal string    `dbf:"-"`
+	ignored  string
+}
+
+func TestAppendStruct(t *testing.T) {
+	fields := []FieldHeader{
+		newField("CUSTNO", 'I', 0, 0),
+		newField("NAME", 'C', 10, 0),
+		newField("BALANCE", 'N', 10, 2),
+		newField("SINCE", 'D', 0, 0),
+		newField("ACTIVE", 'L', 0, 0),
+		newField("NOTES", 'M', 0, 0),
+		newField("EXTRA", 'C', 5, 0),
+	}
+	dbffile, fptfile := new(memWriteSeeker), new(memWriteSeeker)
+	wr, err := NewWriter(dbffile, fptfile, fields, new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	active := true
+	since := time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)
+	customers := []interface{}{
+		testCustomer{testCustomerBase{7}, "Žofie", 10.25, since, &active, "Memo", "x", "y"},
+		&testCustomer{Name: "Nil"},
+	}
+	for _, c := range customers {
+		if err := wr.AppendStruct(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// validation errors
+	if err := wr.AppendStruct(testCustomer{Name: "Much too long"}); err == nil {
+		t.Error("Want an error for a too long value")
+	}
+	if err := wr.AppendStruct(testCustomer{Name: "ㇹ"}); err == nil {
+		t.Error("Want an error for a value which cannot be encoded")
+	}
+	if err := wr.AppendStruct(struct {
+		Missing string `dbf:"MISSING"`
+	}{}); err == nil {
+		t.Error("Want an error for a tag without field")
+	}
+	if err := wr.AppendStruct(42); err == nil {
+		t.Error("Want an error for a non struct")
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dbf, err := OpenStream(bytes.NewReader(dbffile.buf), bytes.NewReader(fptfile.buf), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dbf.NumRecords() != 2 {
+		t.Fatalf("Want 2 records, have %d", dbf.NumRecords())
+	}
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{int32(7), "Žofie     ", 10.25, since, true, "Memo", "     "}
+	for i, val := range want {
+		if have := rec.FieldSlice()[i]; !equalValue(val, have) {
+			t.Errorf("Field %s: want %v, have %v", fields[i].FieldName(), val, have)
+		}
+	}
+	rec, err = dbf.RecordAt(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []interface{}{int32(0), "Nil       ", float64(0), time.Time{}, false}
+	for i, val := range want {
+		if have := rec.FieldSlice()[i]; !equalValue(val, have) {
+			t.Errorf("Empty field %s: want %v, have %v", fields[i].FieldName(), val, have)
+		}
+	}
+}
+
+type testInvoice struct {
+	testCustomerBase
+	Number  int       `dbf:"INVNO,N,8"`
+	Amount  float64   `dbf:"AMOUNT,N,12,2"`
+	Date    time.Time `dbf:",D"`
+	Note    string    `dbf:"NOTE,M"`
+	Code    string    `dbf:",,3"`
+	Paid    *bool
+	Lines   int16
+	Data    []byte
+	Ignored string `dbf:"-"`
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	fields, err := SchemaFromStruct(&testInvoice{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name          string
+		typ           byte
+		len, decimals uint8
+	}{
+		{"CUSTNO", 'N', 20, 0},
+		{"INVNO", 'N', 8, 0},
+		{"AMOUNT", 'N', 12, 2},
+		{"DATE", 'D', 8, 0},
+		{"NOTE", 'M', 4, 0},
+		{"CODE", 'C', 3, 0},
+		{"PAID", 'L', 1, 0},
+		{"LINES", 'I', 4, 0},
+		{"DATA", 'M', 4, 0},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("Want %d fields, have %d", len(want), len(fields))
+	}
+	for i, w := range want {
+		f := fields[i]
+		if f.FieldName() != w.name || f.Type != w.typ || f.Len != w.len || f.Decimals != w.decimals {
+			t.Errorf("Field %d: want %s %c(%d,%d), have %s %c(%d,%d)",
+				i, w.name, w.typ, w.len, w.decimals, f.FieldName(), f.Type, f.Len, f.Decimals)
+		}
+	}
+
+	// the schema creates a table which AppendStruct can fill
+	dbffile, fptfile := new(memWriteSeeker), new(memWriteSeeker)
+	wr, err := NewWriter(dbffile, fptfile, fields, new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := testInvoice{Number: 12, Amount: 99.95, Date: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), Code: "ABC"}
+	if err := wr.AppendStruct(inv); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(dbffile.buf), bytes.NewReader(fptfile.buf), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := rec.FieldSlice()[2]; v != 99.95 {
+		t.Errorf("Want AMOUNT 99.95, have %v", v)
+	}
+
+	errs := []interface{}{
+		42,
+		nil,
+		struct{ x int }{},
+		struct {
+			Name string `dbf:"VERYLONGNAME"`
+		}{},
+		struct {
+			A string `dbf:"X"`
+			B string `dbf:"X"`
+		}{},
+		struct {
+			M map[string]int
+		}{},
+		struct {
+			A string `dbf:"A,XY"`
+		}{},
+		struct {
+			A string `dbf:"A,C,abc"`
+		}{},
+		struct {
+			A string `dbf:"A,C,300"`
+		}{},
+		struct {
+			A string `dbf:"A,Q"`
+		}{},
+	}
+	for i, v := range errs {
+		if _, err := SchemaFromStruct(v); err == nil {
+			t.Errorf("Test %d: want an error for %T", i, v)
+		}
+	}
+}
+
+func TestWriteStruct(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfstruct")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+
+	type message struct {
+		ID     int32     `dbf:"ID"`
+		Date   time.Time `dbf:"DATUM"`
+		Name   string    `dbf:"COMP_NAME"`
+		Number float64   `dbf:"NUMBER"`
+		OK     bool      `dbf:"BOOL"`
+		Text   *string   `dbf:"MELDING"`
+		Ignore string    `dbf:"-"`
+	}
+	text := "written from a struct"
+	date := time.Date(2022, 2, 3, 0, 0, 0, 0, time.UTC)
+	msg := message{ID: 7, Date: date, Name: "Zoë", Number: 1.5, OK: true, Text: &text}
+
+	dbf, err := OpenFileForUpdate(name, new(Win1250Decoder), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.WriteStruct(2, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.WriteStruct(dbf.NumRecords(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if dbf.NumRecords() != 5 {
+		t.Errorf("Want 5 records after appending, have %d", dbf.NumRecords())
+	}
+	// a record is written completely or not at all
+	long := msg
+	long.ID = 8
+	long.Name = strings.Repeat("x", 41)
+	if err := dbf.WriteStruct(4, long); err == nil {
+		t.Error("Want an error for a too long name")
+	}
+	if err := dbf.WriteStruct(6, msg); err != ErrEOF {
+		t.Errorf("Want ErrEOF, have %v", err)
+	}
+	if err := dbf.WriteStruct(0, struct {
+		X int `dbf:"NOFIELD"`
+	}{}); err == nil {
+		t.Error("Want an error for an unknown field")
+	}
+	if err := dbf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dbf, err = OpenFile(name, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	for _, recno := range []uint32{2, 4} {
+		rec, err := dbf.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]interface{}{
+			"ID":        int32(7),
+			"DATUM":     date,
+			"COMP_NAME": "Zoë" + strings.Repeat(" ", 37),
+			"NUMBER":    1.5,
+			"BOOL":      true,
+			"MELDING":   text,
+		}
+		for field, val := range want {
+			if have := rec.FieldSlice()[dbf.FieldPos(field)]; !equalValue(have, val) {
+				t.Errorf("Record %d %s: want %#v, have %#v", recno, field, val, have)
+			}
+		}
+		if rec.Deleted {
+			t.Errorf("Record %d: want not deleted", recno)
+		}
+	}
+	// fields without struct field are kept, or empty in a new record
+	if v, err := dbf.Int64At(2, dbf.FieldPos("USERNR")); err != nil || v == 0 {
+		t.Errorf("Want USERNR of record 2 unchanged, have %d (%v)", v, err)
+	}
+	if v, err := dbf.Int64At(4, dbf.FieldPos("USERNR")); err != nil || v != 0 {
+		t.Errorf("Want an empty USERNR in the new record, have %d (%v)", v, err)
+	}
+	if audit, err := dbf.AuditMemos(); err != nil || len(audit.Problems) != 0 {
+		t.Errorf("Want a consistent memo file, have %+v (%v)", audit, err)
+	}
+	if raw, err := dbf.rawAt(int64(dbf.header.FirstRec)+5*int64(dbf.header.RecLen), 1); err != nil || raw[0] != 0x1A {
+		t.Errorf("Want an EOF marker after the new record, have %v (%v)", raw, err)
+	}
+}
+
+func TestWriteStructAppendedByOthers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfstruct")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+
+	type message struct {
+		ID int32 `dbf:"ID"`
+	}
+	dbf, err := OpenFileForUpdate(name, new(Win1250Decoder), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	// another program appends a record after the table was opened
+	other, err := OpenFileForUpdate(name, new(Win1250Decoder), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.WriteStruct(other.NumRecords(), message{ID: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dbf.WriteStruct(dbf.NumRecords(), message{ID: 11}); err != nil {
+		t.Fatal(err)
+	}
+	if dbf.NumRecords() != 6 {
+		t.Errorf("Want 6 records, have %d", dbf.NumRecords())
+	}
+	for recno, id := range map[uint32]int64{4: 10, 5: 11} {
+		if v, err := dbf.Int64At(recno, dbf.FieldPos("ID")); err != nil || v != id {
+			t.Errorf("Record %d: want ID %d, have %d (%v)", recno, id, v, err)
+		}
+	}
+}
+
+type testNode struct {
+	*testNode
+	Name string
+}
+
+type testCycleA struct {
+	*testCycleB
+	A string
+}
+
+type testCycleB struct {
+	*testCycleA
+	B string
+}
+
+func TestStructCycles(t *testing.T) {
+	fields, err := SchemaFromStruct(testNode{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 1 || fields[0].FieldName() != "NAME" {
+		t.Errorf("Want only the field NAME, have %v", fields)
+	}
+	fields, err = SchemaFromStruct(testCycleA{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 || fields[0].FieldName() != "B" || fields[1].FieldName() != "A" {
+		t.Errorf("Want the fields B and A, have %v", fields)
+	}
+
+	dbffile := new(memWriteSeeker)
+	wr, err := NewWriter(dbffile, nil, fields, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.AppendStruct(testCycleA{testCycleB: &testCycleB{B: "b"}, A: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(dbffile.buf), nil, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.GoTo(0); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := dbf.Record()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, a := strings.TrimSpace(rec.FieldSlice()[0].(string)), strings.TrimSpace(rec.FieldSlice()[1].(string)); b != "b" || a != "a" {
+		t.Errorf("Want b and a, have %q and %q", b, a)
+	}
+}
@@ -0,0 +1,227 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// MemoProblemType is the kind of problem found by AuditMemos
+type MemoProblemType int
+
+const (
+	// MemoInvalidPointer is a memo field which does not contain a block number
+	MemoInvalidPointer MemoProblemType = iota + 1
+	// MemoOutOfRange is a block number in the memo file header or beyond the end of the memo file
+	MemoOutOfRange
+	// MemoTruncated is a memo which is longer than the rest of the memo file
+	MemoTruncated
+	// MemoBeyondNextFree is a memo after the next free block of the memo file header,
+	// FoxPro overwrites it when a memo is added
+	MemoBeyondNextFree
+	// MemoOverlap is a memo which shares blocks with the memo of another pointer
+	MemoOverlap
+)
+
+// String returns a description of the problem type
+func (t MemoProblemType) String() string {
+	switch t {
+	case MemoInvalidPointer:
+		return "invalid pointer"
+	case MemoOutOfRange:
+		return "out of range"
+	case MemoTruncated:
+		return "truncated"
+	case MemoBeyondNextFree:
+		return "beyond next free block"
+	case MemoOverlap:
+		return "overlap"
+	}
+	return fmt.Sprintf("MemoProblemType(%d)", int(t))
+}
+
+// MarshalText returns the description of the problem type, so JSON contains the description instead of the number
+func (t MemoProblemType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// MemoProblem is a memo pointer with a problem found by AuditMemos
+type MemoProblem struct {
+	Type  MemoProblemType
+	RecNo uint32 // record of the pointer
+	Field string // field of the pointer
+	Block uint32 // the block the pointer points to
+
+	// the other pointer of a MemoOverlap
+	OtherRecNo uint32
+	OtherField string
+}
+
+func (p MemoProblem) String() string {
+	s := fmt.Sprintf("record %d field %s block %d: %s", p.RecNo, p.Field, p.Block, p.Type)
+	if p.Type == MemoOverlap {
+		s += fmt.Sprintf(" with record %d field %s", p.OtherRecNo, p.OtherField)
+	}
+	return s
+}
+
+// MemoRange is a range of memo blocks
+type MemoRange struct {
+	Block  uint32 // first block
+	Blocks uint32 // number of blocks
+}
+
+// MemoAudit is the result of AuditMemos
+type MemoAudit struct {
+	BlockSize  uint16 // block size of the memo file
+	NextFree   uint32 // next free block according to the memo file header
+	FileBlocks uint32 // number of blocks in the memo file, including the header
+	Pointers   int    // number of memo pointers in the table, empty memos are not counted
+	UsedBlocks uint32 // number of blocks used by the memos, blocks used twice are counted once
+
+	Orphans  []MemoRange   // blocks before the next free block which no pointer uses
+	Problems []MemoProblem // pointers with problems
+}
+
+// OrphanBlocks returns the number of blocks which no pointer uses
+func (a *MemoAudit) OrphanBlocks() uint32 {
+	n := uint32(0)
+	for _, r := range a.Orphans {
+		n += r.Blocks
+	}
+	return n
+}
+
+// memoExtent is the blocks of a memo and the pointer which references it
+type memoExtent struct {
+	start, end uint32 // blocks, end is exclusive
+	recno      uint32
+	field      int
+}
+
+// AuditMemos checks the memo file against the memo pointers of all records, including deleted records.
+// It reports pointers which can not be read or which share blocks, and blocks which are not used by any pointer.
+// Memo files with problems can often be salvaged: memos which are out of range or truncated are lost, but the
+// memos of the other pointers can be copied with PackTo after the bad pointers are cleared.
+func (dbf *DBF) AuditMemos() (*MemoAudit, error) {
+	if dbf.fptr == nil {
+		return nil, ErrNoFPTFile
+	}
+	if dbf.smt {
+		return nil, errors.New("auditing SMT memo files is not supported")
+	}
+	bs := uint32(dbf.fptheader.BlockSize)
+	if bs == 0 {
+		return nil, errors.New("invalid memo block size 0")
+	}
+	size, err := dbf.fptr.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	audit := &MemoAudit{
+		BlockSize:  dbf.fptheader.BlockSize,
+		NextFree:   dbf.fptheader.NextFree,
+		FileBlocks: uint32((size + int64(bs) - 1) / int64(bs)),
+	}
+	first := (fptHeaderSize + bs - 1) / bs
+
+	var extents []memoExtent
+	header := make([]byte, 8)
+	for recno := uint32(0); recno < dbf.header.NumRec; recno++ {
+		data, err := dbf.readRecord(recno)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range dbf.fields {
+			if !f.isMemo() {
+				continue
+			}
+			problem := MemoProblem{RecNo: recno, Field: dbf.layout[i].name}
+			block, err := dbf.memoPointer(dbf.layout[i].data(data))
+			if err != nil {
+				problem.Type = MemoInvalidPointer
+				audit.Problems = append(audit.Problems, problem)
+				continue
+			}
+			if block == 0 {
+				// no memo
+				continue
+			}
+			audit.Pointers++
+			problem.Block = block
+			if block < first || block >= audit.FileBlocks {
+				problem.Type = MemoOutOfRange
+				audit.Problems = append(audit.Problems, problem)
+				continue
+			}
+
+			pos := int64(block) * int64(bs)
+			if n, err := dbf.fptr.ReadAt(header, pos); n < len(header) {
+				if err != nil && err != io.EOF {
+					return nil, err
+				}
+				problem.Type = MemoTruncated
+				audit.Problems = append(audit.Problems, problem)
+				continue
+			}
+			end := pos + 8 + int64(binary.BigEndian.Uint32(header[4:]))
+			if end > size {
+				problem.Type = MemoTruncated
+				audit.Problems = append(audit.Problems, problem)
+				end = size
+			}
+			ext := memoExtent{start: block, end: uint32((end + int64(bs) - 1) / int64(bs)), recno: recno, field: i}
+			if ext.end > audit.NextFree {
+				problem.Type = MemoBeyondNextFree
+				audit.Problems = append(audit.Problems, problem)
+			}
+			extents = append(extents, ext)
+		}
+	}
+
+	// memos are written one after the other, so sorted by block each memo must end before the next one starts
+	sort.SliceStable(extents, func(i, j int) bool { return extents[i].start < extents[j].start })
+	next := first
+	last := -1 // the extent which ends last
+	limit := audit.NextFree
+	if limit > audit.FileBlocks {
+		limit = audit.FileBlocks
+	}
+	for i, ext := range extents {
+		if last >= 0 && ext.start < extents[last].end {
+			other := extents[last]
+			audit.Problems = append(audit.Problems, MemoProblem{
+				Type: MemoOverlap, RecNo: ext.recno, Field: dbf.layout[ext.field].name, Block: ext.start,
+				OtherRecNo: other.recno, OtherField: dbf.layout[other.field].name,
+			})
+		}
+		if ext.start > next && next < limit {
+			audit.Orphans = append(audit.Orphans, MemoRange{Block: next, Blocks: minUint32(ext.start, limit) - next})
+		}
+		if ext.end > next {
+			if ext.start > next {
+				audit.UsedBlocks += ext.end - ext.start
+			} else {
+				audit.UsedBlocks += ext.end - next
+			}
+			next = ext.end
+		}
+		if last < 0 || ext.end > extents[last].end {
+			last = i
+		}
+	}
+	if next < limit {
+		audit.Orphans = append(audit.Orphans, MemoRange{Block: next, Blocks: limit - next})
+	}
+	return audit, nil
+}
+
+// minUint32 returns the smallest of a and b
+func minUint32(a, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
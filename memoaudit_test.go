@@ -0,0 +1,87 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditMemos(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	audit, err := dbf.AuditMemos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.Problems) != 0 || audit.Pointers == 0 || audit.BlockSize == 0 {
+		t.Errorf("Want a memo file without problems, have %+v", audit)
+	}
+
+	// a table with 4 memos of one block each, the memo file has block size 64
+	dbffile, fptfile := new(memWriteSeeker), new(memWriteSeeker)
+	wr, err := NewWriter(dbffile, fptfile, []FieldHeader{newField("NOTES", 'M', 0, 0)}, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, note := range []string{"one", "two", "three", "four"} {
+		if err := wr.Append(note); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	h := wr.Header()
+	setPointer := func(recno int, block uint32) {
+		binary.LittleEndian.PutUint32(dbffile.buf[int(h.FirstRec)+recno*int(h.RecLen)+1:], block)
+	}
+	// the memos are in blocks 8 to 11, record 1 points to the memo of record 0 and block 9 is orphaned,
+	// record 2 points beyond the end of the file
+	setPointer(1, 8)
+	setPointer(2, 500)
+
+	dbf, err = OpenStream(bytes.NewReader(dbffile.buf), bytes.NewReader(fptfile.buf), new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit, err = dbf.AuditMemos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.Pointers != 4 || audit.UsedBlocks != 2 || audit.NextFree != 12 || audit.FileBlocks != 12 {
+		t.Errorf("Unexpected audit %+v", audit)
+	}
+	want := []MemoProblem{
+		{Type: MemoOutOfRange, RecNo: 2, Field: "NOTES", Block: 500},
+		{Type: MemoOverlap, RecNo: 1, Field: "NOTES", Block: 8, OtherRecNo: 0, OtherField: "NOTES"},
+	}
+	if len(audit.Problems) != len(want) {
+		t.Fatalf("Want %v, have %v", want, audit.Problems)
+	}
+	for i := range want {
+		if audit.Problems[i] != want[i] {
+			t.Errorf("Want %s, have %s", want[i], audit.Problems[i])
+		}
+	}
+	if len(audit.Orphans) != 1 || audit.Orphans[0] != (MemoRange{Block: 9, Blocks: 2}) || audit.OrphanBlocks() != 2 {
+		t.Errorf("Want blocks 9 and 10 orphaned, have %v", audit.Orphans)
+	}
+
+	// a memo longer than the file, which also ends after the next free block
+	binary.BigEndian.PutUint32(fptfile.buf[11*64+4:], 1000)
+	dbf, err = OpenStream(bytes.NewReader(dbffile.buf), bytes.NewReader(fptfile.buf), new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit, err = dbf.AuditMemos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.Problems) != 3 || audit.Problems[1].Type != MemoTruncated {
+		t.Errorf("Want a truncated memo, have %v", audit.Problems)
+	}
+}
@@ -0,0 +1,60 @@
+package dbf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// Memo block types of FPT files, other types are written by third-party drivers, for example for compressed memos
+const (
+	memoPicture = 0
+	memoText    = 1
+	memoObject  = 2
+)
+
+// MemoDecompressor decompresses the data of a memo block with a custom block type and returns if the result is text
+type MemoDecompressor func(data []byte) (memo []byte, isText bool, err error)
+
+// UnknownMemoTypeError is returned when a memo block has a block type for which no MemoDecompressor is set
+type UnknownMemoTypeError struct {
+	Type uint32 // block type (signature) of the memo block
+}
+
+func (e *UnknownMemoTypeError) Error() string {
+	return fmt.Sprintf("unknown memo block type 0x%08X, the memo is probably compressed, see SetMemoDecompressor", e.Type)
+}
+
+// memoDecompressors are the decompressors by block type
+var memoDecompressors = map[uint32]MemoDecompressor{}
+
+// SetMemoDecompressor sets the function used to decompress memo blocks with the given block type (signature),
+// nil removes it. It should be called before reading tables.
+func SetMemoDecompressor(blockType uint32, d MemoDecompressor) {
+	if d == nil {
+		delete(memoDecompressors, blockType)
+		return
+	}
+	memoDecompressors[blockType] = d
+}
+
+// ZlibMemoDecompressor is a MemoDecompressor for text memos compressed with zlib
+func ZlibMemoDecompressor(data []byte) ([]byte, bool, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, err
+	}
+	defer r.Close()
+	memo, err := io.ReadAll(r)
+	return memo, true, err
+}
+
+// decodeMemoBlock returns the memo of a block with a custom block type
+func decodeMemoBlock(blockType uint32, data []byte) ([]byte, bool, error) {
+	d, ok := memoDecompressors[blockType]
+	if !ok {
+		return data, false, &UnknownMemoTypeError{Type: blockType}
+	}
+	return d(data)
+}
@@ -0,0 +1,45 @@
+package dbf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"testing"
+)
+
+func TestMemoDecompressor(t *testing.T) {
+	const compressed = 0x5A4C4942
+
+	data, memo := foxPro2Table()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte("hello world"))
+	zw.Close()
+	binary.BigEndian.PutUint32(memo[512:], compressed)
+	binary.BigEndian.PutUint32(memo[516:], uint32(buf.Len()))
+	copy(memo[520:], buf.Bytes())
+
+	dbf, err := OpenStream(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = dbf.RecordAt(0)
+	if typeErr, ok := err.(*UnknownMemoTypeError); !ok || typeErr.Type != compressed {
+		t.Fatalf("Want an UnknownMemoTypeError, have %v", err)
+	}
+
+	SetMemoDecompressor(compressed, ZlibMemoDecompressor)
+	defer SetMemoDecompressor(compressed, nil)
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notes := rec.FieldSlice()[1]; notes != "hello world" {
+		t.Errorf("Want hello world, have %v", notes)
+	}
+
+	SetMemoDecompressor(compressed, nil)
+	if _, err := dbf.RecordAt(0); err == nil {
+		t.Error("Want an error after removing the decompressor")
+	}
+}
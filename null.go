@@ -0,0 +1,254 @@
+package dbf
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Visual FoxPro stores null values in the hidden system field _NullFlags (field type 0). Every nullable field
+// and every variable length (V and Q) field has a bit in it, in field order. A variable length field which is
+// also nullable has two bits, the first for the length and the second for null.
+// Null values are returned as nil, Record.IsNull reports them and the Null types below can be used as
+// destination of Record.Scan to keep them apart from empty values.
+
+// Field flags of Visual FoxPro tables
+const (
+	fieldFlagSystem   = 0x01 // hidden system field, like _NullFlags
+	fieldFlagNullable = 0x02 // the field can store null values
+)
+
+// Nullable returns if the field can store null values
+func (f *FieldHeader) Nullable() bool {
+	return f.Flags&fieldFlagNullable != 0 && f.Type != '0'
+}
+
+// System returns if the field is a hidden system field like _NullFlags
+func (f *FieldHeader) System() bool {
+	return f.Flags&fieldFlagSystem != 0 || f.Type == '0'
+}
+
+// nullBits returns the bit in _NullFlags for every field, -1 for fields which are not nullable
+func nullBits(fields []FieldHeader) []int {
+	bits := make([]int, len(fields))
+	bit := 0
+	for i := range fields {
+		bits[i] = -1
+		if t := fields[i].Type; t == 'V' || t == 'Q' {
+			bit++
+		}
+		if fields[i].Nullable() {
+			bits[i] = bit
+			bit++
+		}
+	}
+	return bits
+}
+
+// nullFlagsPos returns the position of the _NullFlags field, -1 if the table has no such field
+func nullFlagsPos(fields []FieldHeader) int {
+	for i := range fields {
+		if fields[i].Type == '0' {
+			return i
+		}
+	}
+	return -1
+}
+
+// isNull returns if bit is set in the _NullFlags data
+func isNull(flags []byte, bit int) bool {
+	if bit < 0 || bit/8 >= len(flags) {
+		return false
+	}
+	return flags[bit/8]&(1<<uint(bit%8)) != 0
+}
+
+// nullFlags returns the _NullFlags data of the record data, nil if the table has no _NullFlags field
+func (dbf *DBF) nullFlags(record []byte) []byte {
+	if dbf.nullflags < 0 {
+		return nil
+	}
+	return dbf.layout[dbf.nullflags].data(record)
+}
+
+// fieldIsNull returns if field fieldpos of the record at recno is null
+func (dbf *DBF) fieldIsNull(recno uint32, fieldpos int) (bool, error) {
+	if dbf.nullflags < 0 || dbf.layout[fieldpos].null < 0 {
+		return false, nil
+	}
+	flags, err := dbf.readField(recno, dbf.nullflags)
+	if err != nil {
+		return false, err
+	}
+	return isNull(flags, dbf.layout[fieldpos].null), nil
+}
+
+// IsNull returns if the field at pos is null. Only nullable fields of Visual FoxPro tables can be null.
+func (r *Record) IsNull(pos int) bool {
+	return pos >= 0 && pos < len(r.data) && r.data[pos] == nil
+}
+
+// ScanField copies the value of the field at pos into dest, like Scan does for all fields
+func (r *Record) ScanField(pos int, dest interface{}) error {
+	if pos < 0 || pos >= len(r.data) {
+		return ErrInvalidField
+	}
+	if err := scanValue(dest, r.data[pos]); err != nil {
+		return fmt.Errorf("Scan error on field %s: %s", r.fields[pos].FieldName(), err)
+	}
+	return nil
+}
+
+// The Null types hold a value which can be null, they have the same fields as the sql.Null types.
+// They implement sql.Scanner and driver.Valuer, so they can be used with Record.Scan and in database code,
+// and json.Marshaler, null values are written as JSON null.
+// Empty D and T fields are null as well, like they are for Rows.
+
+// NullString is a string which can be null
+type NullString struct {
+	String string
+	Valid  bool // Valid is true if String is not null
+}
+
+// Scan implements sql.Scanner
+func (n *NullString) Scan(value interface{}) error {
+	n.String, n.Valid = "", value != nil
+	if !n.Valid {
+		return nil
+	}
+	return scanValue(&n.String, value)
+}
+
+// Value implements driver.Valuer
+func (n NullString) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.String, nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (n NullString) MarshalJSON() ([]byte, error) {
+	return nullJSON(n.Valid, n.String)
+}
+
+// NullInt64 is an int64 which can be null
+type NullInt64 struct {
+	Int64 int64
+	Valid bool // Valid is true if Int64 is not null
+}
+
+// Scan implements sql.Scanner
+func (n *NullInt64) Scan(value interface{}) error {
+	n.Int64, n.Valid = 0, value != nil
+	if !n.Valid {
+		return nil
+	}
+	return scanValue(&n.Int64, value)
+}
+
+// Value implements driver.Valuer
+func (n NullInt64) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Int64, nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (n NullInt64) MarshalJSON() ([]byte, error) {
+	return nullJSON(n.Valid, n.Int64)
+}
+
+// NullFloat64 is a float64 which can be null
+type NullFloat64 struct {
+	Float64 float64
+	Valid   bool // Valid is true if Float64 is not null
+}
+
+// Scan implements sql.Scanner
+func (n *NullFloat64) Scan(value interface{}) error {
+	n.Float64, n.Valid = 0, value != nil
+	if !n.Valid {
+		return nil
+	}
+	return scanValue(&n.Float64, value)
+}
+
+// Value implements driver.Valuer
+func (n NullFloat64) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Float64, nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (n NullFloat64) MarshalJSON() ([]byte, error) {
+	return nullJSON(n.Valid, n.Float64)
+}
+
+// NullBool is a bool which can be null
+type NullBool struct {
+	Bool  bool
+	Valid bool // Valid is true if Bool is not null
+}
+
+// Scan implements sql.Scanner
+func (n *NullBool) Scan(value interface{}) error {
+	n.Bool, n.Valid = false, value != nil
+	if !n.Valid {
+		return nil
+	}
+	return scanValue(&n.Bool, value)
+}
+
+// Value implements driver.Valuer
+func (n NullBool) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Bool, nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (n NullBool) MarshalJSON() ([]byte, error) {
+	return nullJSON(n.Valid, n.Bool)
+}
+
+// NullTime is a time.Time which can be null
+type NullTime struct {
+	Time  time.Time
+	Valid bool // Valid is true if Time is not null
+}
+
+// Scan implements sql.Scanner
+func (n *NullTime) Scan(value interface{}) error {
+	n.Time, n.Valid = time.Time{}, value != nil
+	if !n.Valid {
+		return nil
+	}
+	return scanValue(&n.Time, value)
+}
+
+// Value implements driver.Valuer
+func (n NullTime) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Time, nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (n NullTime) MarshalJSON() ([]byte, error) {
+	return nullJSON(n.Valid, n.Time)
+}
+
+// nullJSON writes val, or null if it is not valid
+func nullJSON(valid bool, val interface{}) ([]byte, error) {
+	if !valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(val)
+}
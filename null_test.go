@@ -0,0 +1,149 @@
+package dbf
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// nullTable returns a Visual FoxPro table with the nullable fields NAME and BORN,
+// the second record has null values for both fields
+func nullTable(t *testing.T) *DBF {
+	fields := []FieldHeader{
+		newField("ID", 'N', 5, 0),
+		newField("NAME", 'C', 10, 0),
+		newField("BORN", 'D', 0, 0),
+		newField("_NullFlags", 'C', 1, 0),
+	}
+	mw := new(memWriteSeeker)
+	wr, err := NewWriter(mw, nil, fields, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	born := time.Date(1980, 5, 1, 0, 0, 0, 0, time.UTC)
+	if err := wr.Append(1, "Jan", born, "\x00"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append(2, nil, nil, "\x03"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// the writer has no nullable fields, set the flags and the type of _NullFlags in the field descriptors
+	data := mw.buf
+	data[32+32*1+18] = fieldFlagNullable
+	data[32+32*2+18] = fieldFlagNullable
+	data[32+32*3+11] = '0'
+	data[32+32*3+18] = fieldFlagSystem | 0x04
+
+	dbf, err := OpenStream(bytes.NewReader(data), nil, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dbf
+}
+
+func TestNullFlags(t *testing.T) {
+	dbf := nullTable(t)
+	fields := dbf.Fields()
+	if fields[0].Nullable() || !fields[1].Nullable() || !fields[2].Nullable() || fields[3].Nullable() {
+		t.Error("Unexpected nullable fields")
+	}
+	if !fields[3].System() || fields[1].System() {
+		t.Error("Want _NullFlags as only system field")
+	}
+
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.IsNull(1) || rec.IsNull(2) || ToTrimmedString(rec.FieldSlice()[1]) != "Jan" {
+		t.Errorf("Unexpected first record %v", rec.FieldSlice())
+	}
+
+	rec, err = dbf.RecordAt(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.IsNull(0) || !rec.IsNull(1) || !rec.IsNull(2) || rec.IsNull(3) {
+		t.Errorf("Want NAME and BORN null, have %v", rec.FieldSlice())
+	}
+
+	dbf.GoTo(1)
+	if val, err := dbf.Field(1); err != nil || val != nil {
+		t.Errorf("Want nil NAME, have %v %v", val, err)
+	}
+	if val, err := dbf.Field(0); err != nil || val != int64(2) {
+		t.Errorf("Want ID 2, have %v %v", val, err)
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"ID":2,"NAME":null,"BORN":null}` {
+		t.Errorf("Unexpected JSON %s", b)
+	}
+	schema := dbf.JSONSchema("")
+	if _, ok := schema.Properties["_NullFlags"]; ok {
+		t.Error("Want no _NullFlags property")
+	}
+}
+
+func TestNullTypes(t *testing.T) {
+	dbf := nullTable(t)
+
+	var (
+		id   NullInt64
+		name NullString
+		born NullTime
+		std  sql.NullString
+	)
+	for recno, valid := range []bool{true, false} {
+		rec, err := dbf.RecordAt(uint32(recno))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rec.Scan(&id, &name, &born, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := rec.ScanField(1, &std); err != nil {
+			t.Fatal(err)
+		}
+		if !id.Valid || name.Valid != valid || born.Valid != valid || std.Valid != valid {
+			t.Errorf("Record %d: unexpected validity %v %v %v %v", recno, id, name, born, std)
+		}
+	}
+	if id.Int64 != 2 {
+		t.Errorf("Want ID 2, have %d", id.Int64)
+	}
+
+	// values and JSON
+	values := []driver.Valuer{NullString{"a", true}, NullInt64{}, NullFloat64{1.5, true}, NullBool{true, true}, NullTime{}}
+	want := []driver.Value{"a", nil, 1.5, true, nil}
+	for i, v := range values {
+		if have, err := v.Value(); err != nil || have != want[i] {
+			t.Errorf("Value %d: want %v, have %v %v", i, want[i], have, err)
+		}
+	}
+	b, err := json.Marshal(struct {
+		A NullString
+		B NullFloat64
+		C NullBool
+	}{NullString{"x", true}, NullFloat64{}, NullBool{false, true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"A":"x","B":null,"C":false}` {
+		t.Errorf("Unexpected JSON %s", b)
+	}
+
+	var f NullFloat64
+	if err := f.Scan("abc"); err == nil {
+		t.Error("Want an error scanning abc into NullFloat64")
+	}
+}
@@ -0,0 +1,128 @@
+package dbf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// InvalidNumberPolicy determines what is returned for N and F fields which do not contain a valid number,
+// like the asterisks FoxPro writes when a value does not fit the width of the field
+type InvalidNumberPolicy int
+
+const (
+	// InvalidNumbersError returns an *InvalidNumberError, the record can not be read (default)
+	InvalidNumbersError InvalidNumberPolicy = iota
+	// InvalidNumbersZero returns 0
+	InvalidNumbersZero
+	// InvalidNumbersNaN returns NaN for F fields and N fields with decimals,
+	// N fields without decimals return 0 because an int64 has no NaN
+	InvalidNumbersNaN
+	// InvalidNumbersRaw returns the field data with the spaces trimmed as string
+	InvalidNumbersRaw
+)
+
+// InvalidNumberError is returned when an N or F field does not contain a valid number
+// and the InvalidNumbersError policy is used
+type InvalidNumberError struct {
+	Field    string // name of the field
+	Data     string // field data with the spaces trimmed
+	Overflow bool   // the field contains asterisks (and the decimal point), the value did not fit the field
+}
+
+func (e *InvalidNumberError) Error() string {
+	if e.Overflow {
+		return fmt.Sprintf("numeric overflow in field %s: %q", e.Field, e.Data)
+	}
+	return fmt.Sprintf("invalid number in field %s: %q", e.Field, e.Data)
+}
+
+// SetInvalidNumberPolicy sets what is returned for N and F fields which do not contain a valid number.
+// Records with replaced values report the fields with InvalidFields.
+func (dbf *DBF) SetInvalidNumberPolicy(policy InvalidNumberPolicy) {
+	dbf.numbers = policy
+}
+
+// InvalidNumberPolicy returns the policy set with SetInvalidNumberPolicy
+func (dbf *DBF) InvalidNumberPolicy() InvalidNumberPolicy {
+	return dbf.numbers
+}
+
+// InvalidFields returns the names of the fields whose data was not a valid number and whose value
+// was replaced according to the InvalidNumberPolicy of the table
+func (r *Record) InvalidFields() []string {
+	if len(r.invalid) == 0 {
+		return nil
+	}
+	names := make([]string, len(r.invalid))
+	for i, pos := range r.invalid {
+		names[i] = r.fields[pos].FieldName()
+	}
+	return names
+}
+
+// invalidNumber returns the replacement value of the invalid data of field fieldpos and an *InvalidNumberError.
+// The replacement value is nil for the InvalidNumbersError policy.
+func (dbf *DBF) invalidNumber(raw []byte, fieldpos int) (interface{}, error) {
+	f := &dbf.fields[fieldpos]
+	trimmed := bytes.TrimSpace(raw)
+	err := &InvalidNumberError{
+		Field:    f.FieldName(),
+		Data:     string(trimmed),
+		Overflow: bytes.IndexByte(trimmed, '*') >= 0 && len(bytes.Trim(trimmed, "*.")) == 0,
+	}
+	integer := f.Type == 'N' && f.Decimals == 0
+	switch dbf.numbers {
+	case InvalidNumbersZero:
+		if integer {
+			return int64(0), err
+		}
+		return float64(0), err
+	case InvalidNumbersNaN:
+		if integer {
+			return int64(0), err
+		}
+		return math.NaN(), err
+	case InvalidNumbersRaw:
+		return string(trimmed), err
+	}
+	return nil, err
+}
+
+// acceptInvalidNumber returns if err is an *InvalidNumberError whose value is replaced by the policy of the table
+func (dbf *DBF) acceptInvalidNumber(err error) bool {
+	_, ok := err.(*InvalidNumberError)
+	return ok && dbf.numbers != InvalidNumbersError
+}
+
+// fieldValue is fieldDataToValue with invalid numbers replaced according to the policy of the table
+func (dbf *DBF) fieldValue(raw []byte, fieldpos int) (interface{}, error) {
+	val, err := dbf.fieldDataToValue(raw, fieldpos)
+	if dbf.acceptInvalidNumber(err) {
+		return val, nil
+	}
+	return val, err
+}
+
+// typedInvalidInt returns the replacement of an invalid number for Int64At,
+// the InvalidNumbersRaw policy has no int64 replacement and returns the error
+func (dbf *DBF) typedInvalidInt(raw []byte, fieldpos int) (int64, error) {
+	val, err := dbf.invalidNumber(raw, fieldpos)
+	if v, ok := val.(int64); ok {
+		return v, nil
+	}
+	return 0, err
+}
+
+// typedInvalidFloat returns the replacement of an invalid number for Float64At,
+// the InvalidNumbersRaw policy has no float64 replacement and returns the error
+func (dbf *DBF) typedInvalidFloat(raw []byte, fieldpos int) (float64, error) {
+	val, err := dbf.invalidNumber(raw, fieldpos)
+	switch v := val.(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	}
+	return 0, err
+}
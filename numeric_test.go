@@ -0,0 +1,125 @@
+package dbf
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// invalidNumbersTable returns a table with a valid first record and a second record
+// with an overflow in ID, garbage in AMOUNT and an overflow in RATE
+func invalidNumbersTable(t *testing.T) *DBF {
+	fields := []FieldHeader{
+		newField("ID", 'N', 5, 0),
+		newField("AMOUNT", 'N', 8, 2),
+		newField("RATE", 'F', 6, 2),
+	}
+	mw := new(memWriteSeeker)
+	wr, err := NewWriter(mw, nil, fields, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append(1, 12.5, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append(77777, 88888.88, 99.99); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Replace(mw.buf, []byte("77777"), []byte("*****"), 1)
+	data = bytes.Replace(data, []byte("88888.88"), []byte("12,34 EU"), 1)
+	data = bytes.Replace(data, []byte(" 99.99"), []byte("***.**"), 1)
+
+	dbf, err := OpenStream(bytes.NewReader(data), nil, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dbf
+}
+
+func TestInvalidNumbersError(t *testing.T) {
+	dbf := invalidNumbersTable(t)
+
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.InvalidFields() != nil {
+		t.Errorf("Want no invalid fields, have %v", rec.InvalidFields())
+	}
+
+	_, err = dbf.RecordAt(1)
+	nerr, ok := err.(*InvalidNumberError)
+	if !ok {
+		t.Fatalf("Want *InvalidNumberError, have %v", err)
+	}
+	if nerr.Field != "ID" || nerr.Data != "*****" || !nerr.Overflow {
+		t.Errorf("Unexpected error %+v", nerr)
+	}
+
+	dbf.GoTo(1)
+	_, err = dbf.Field(1)
+	if nerr, ok := err.(*InvalidNumberError); !ok || nerr.Overflow || nerr.Data != "12,34 EU" {
+		t.Errorf("Want invalid number error for AMOUNT, have %v", err)
+	}
+	if _, err := dbf.Float64At(1, 2); err == nil {
+		t.Error("Want an error for RATE")
+	}
+}
+
+func TestInvalidNumbersPolicy(t *testing.T) {
+	tests := []struct {
+		policy InvalidNumberPolicy
+		want   []interface{}
+	}{
+		{InvalidNumbersZero, []interface{}{int64(0), float64(0), float64(0)}},
+		{InvalidNumbersNaN, []interface{}{int64(0), math.NaN(), math.NaN()}},
+		{InvalidNumbersRaw, []interface{}{"*****", "12,34 EU", "***.**"}},
+	}
+	for _, test := range tests {
+		dbf := invalidNumbersTable(t)
+		dbf.SetInvalidNumberPolicy(test.policy)
+
+		rec, err := dbf.RecordAt(1)
+		if err != nil {
+			t.Fatalf("Policy %d: %v", test.policy, err)
+		}
+		if names := rec.InvalidFields(); !reflect.DeepEqual(names, []string{"ID", "AMOUNT", "RATE"}) {
+			t.Errorf("Policy %d: want all fields invalid, have %v", test.policy, names)
+		}
+		for i, want := range test.want {
+			have := rec.FieldSlice()[i]
+			if f, ok := want.(float64); ok && math.IsNaN(f) {
+				if h, ok := have.(float64); !ok || !math.IsNaN(h) {
+					t.Errorf("Policy %d field %d: want NaN, have %v", test.policy, i, have)
+				}
+				continue
+			}
+			if have != want {
+				t.Errorf("Policy %d field %d: want %#v, have %#v", test.policy, i, want, have)
+			}
+		}
+
+		// the first record is valid
+		rec, err = dbf.RecordAt(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.InvalidFields() != nil || rec.FieldSlice()[1] != 12.5 {
+			t.Errorf("Policy %d: unexpected first record %v %v", test.policy, rec.FieldSlice(), rec.InvalidFields())
+		}
+
+		// single fields and typed reads follow the policy
+		dbf.GoTo(1)
+		if _, err := dbf.Field(0); err != nil {
+			t.Errorf("Policy %d: %v", test.policy, err)
+		}
+		_, err = dbf.Int64At(1, 0)
+		if (test.policy == InvalidNumbersRaw) != (err != nil) {
+			t.Errorf("Policy %d: unexpected Int64At error %v", test.policy, err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package dbf
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// odsMimeType is the media type of OpenDocument spreadsheets, stored uncompressed as first file of the archive
+const odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+const odsManifest = xml.Header + `<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+ <manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="` + odsMimeType + `"/>
+ <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>
+`
+
+// odsContentHeader starts content.xml, with the cell styles of dates (ce1) and datetimes (ce2)
+const odsContentHeader = xml.Header + `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+	`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+	`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+	`xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0" office:version="1.2">
+<office:automatic-styles>
+ <number:date-style style:name="N1"><number:year number:style="long"/><number:text>-</number:text>` +
+	`<number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/></number:date-style>
+ <number:date-style style:name="N2"><number:year number:style="long"/><number:text>-</number:text>` +
+	`<number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/><number:text> </number:text>` +
+	`<number:hours number:style="long"/><number:text>:</number:text><number:minutes number:style="long"/>` +
+	`<number:text>:</number:text><number:seconds number:style="long"/></number:date-style>
+ <style:style style:name="ce1" style:family="table-cell" style:data-style-name="N1"/>
+ <style:style style:name="ce2" style:family="table-cell" style:data-style-name="N2"/>
+</office:automatic-styles>
+<office:body><office:spreadsheet>
+`
+
+const odsContentFooter = "</table:table>\n</office:spreadsheet></office:body>\n</office:document-content>\n"
+
+// ODSWriter is a RecordWriter which writes an OpenDocument spreadsheet, the native format of LibreOffice which
+// Excel opens as well. Unlike CSV the cells are typed, so spreadsheets show numbers and dates in the locale of
+// the user without conversion. The first row contains the field names. The values are written as follows:
+//
+//	strings                     text (trailing spaces of C fields are trimmed)
+//	integers and floats         numbers (NaN and infinity are left empty)
+//	logicals                    booleans
+//	dates and datetimes         dates, formatted as YYYY-MM-DD and YYYY-MM-DD hh:mm:ss
+//	null, empty dates, binary   empty cells
+//
+// The spreadsheet is written while the records are exported, so the table is never completely in memory.
+// Spreadsheet applications load at most about a million rows.
+type ODSWriter struct {
+	// SheetName is the name of the sheet, empty uses "Sheet1"
+	SheetName string
+
+	// KeepSpaces keeps the padding of C fields, by default trailing spaces are trimmed
+	KeepSpaces bool
+
+	zw     *zip.Writer
+	w      *bufio.Writer
+	fields []FieldHeader
+}
+
+var _ RecordWriter = (*ODSWriter)(nil)
+
+// NewODSWriter returns an ODSWriter which writes to w
+func NewODSWriter(w io.Writer) *ODSWriter {
+	return &ODSWriter{zw: zip.NewWriter(w)}
+}
+
+// WriteSchema writes the files of the spreadsheet before the sheet and the row with the field names
+func (ow *ODSWriter) WriteSchema(fields []FieldHeader) error {
+	ow.fields = fields
+
+	// the mime type must be the first file and may not be compressed
+	mt, err := ow.zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mt, odsMimeType); err != nil {
+		return err
+	}
+	manifest, err := ow.zw.Create("META-INF/manifest.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(manifest, odsManifest); err != nil {
+		return err
+	}
+	content, err := ow.zw.Create("content.xml")
+	if err != nil {
+		return err
+	}
+	ow.w = bufio.NewWriter(content)
+
+	name := ow.SheetName
+	if name == "" {
+		name = "Sheet1"
+	}
+	ow.w.WriteString(odsContentHeader)
+	ow.w.WriteString(`<table:table table:name="`)
+	xml.EscapeText(ow.w, []byte(name))
+	ow.w.WriteString("\">\n<table:table-row>")
+	for i := range fields {
+		ow.writeText(fields[i].FieldName())
+	}
+	_, err = ow.w.WriteString("</table:table-row>\n")
+	return err
+}
+
+// WriteRecord writes a row with a typed cell per value
+func (ow *ODSWriter) WriteRecord(rec *Record) error {
+	if len(rec.data) != len(ow.fields) {
+		return ErrNumFields
+	}
+	ow.w.WriteString("<table:table-row>")
+	for i, val := range rec.data {
+		ow.writeCell(val, &ow.fields[i])
+	}
+	_, err := ow.w.WriteString("</table:table-row>\n")
+	return err
+}
+
+// Close ends the sheet and writes the central directory of the archive, it does not close the underlying writer.
+// A spreadsheet without schema, of an export which failed before the first record, is not completed.
+func (ow *ODSWriter) Close() error {
+	if ow.w == nil {
+		return nil
+	}
+	ow.w.WriteString(odsContentFooter)
+	if err := ow.w.Flush(); err != nil {
+		return err
+	}
+	return ow.zw.Close()
+}
+
+// writeCell writes val as a cell of the type of the value
+func (ow *ODSWriter) writeCell(val interface{}, f *FieldHeader) {
+	switch v := val.(type) {
+	case string:
+		if f.Type == 'C' && !ow.KeepSpaces {
+			v = strings.TrimRight(v, " ")
+		}
+		ow.writeText(v)
+	case bool:
+		fmt.Fprintf(ow.w, `<table:table-cell office:value-type="boolean" office:boolean-value="%t"><text:p>%s</text:p></table:table-cell>`,
+			v, strings.ToUpper(strconv.FormatBool(v)))
+	case int32:
+		ow.writeNumber(strconv.FormatInt(int64(v), 10))
+	case int64:
+		ow.writeNumber(strconv.FormatInt(v, 10))
+	case int:
+		ow.writeNumber(strconv.Itoa(v))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			ow.w.WriteString("<table:table-cell/>")
+			return
+		}
+		ow.writeNumber(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		if v.IsZero() {
+			ow.w.WriteString("<table:table-cell/>")
+			return
+		}
+		if f.Type == 'D' {
+			fmt.Fprintf(ow.w, `<table:table-cell table:style-name="ce1" office:value-type="date" office:date-value="%s"><text:p>%[1]s</text:p></table:table-cell>`,
+				v.Format("2006-01-02"))
+			return
+		}
+		fmt.Fprintf(ow.w, `<table:table-cell table:style-name="ce2" office:value-type="date" office:date-value="%s"><text:p>%s</text:p></table:table-cell>`,
+			v.Format("2006-01-02T15:04:05.000"), v.Format("2006-01-02 15:04:05"))
+	case nil, []byte:
+		ow.w.WriteString("<table:table-cell/>")
+	default:
+		// values of transforms
+		ow.writeText(fmt.Sprint(v))
+	}
+}
+
+// writeNumber writes a float cell with the number s
+func (ow *ODSWriter) writeNumber(s string) {
+	fmt.Fprintf(ow.w, `<table:table-cell office:value-type="float" office:value="%s"><text:p>%[1]s</text:p></table:table-cell>`, s)
+}
+
+// writeText writes a string cell. Spaces after the first, tabs and line breaks are elements in OpenDocument text,
+// control characters which XML does not allow are dropped.
+func (ow *ODSWriter) writeText(s string) {
+	ow.w.WriteString(`<table:table-cell office:value-type="string"><text:p>`)
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ':
+			n := 1
+			for i+n < len(s) && s[i+n] == ' ' {
+				n++
+			}
+			// a single space is kept, except at the start
+			extra := n
+			if i > 0 {
+				ow.w.WriteByte(' ')
+				extra--
+			}
+			if extra > 0 {
+				fmt.Fprintf(ow.w, `<text:s text:c="%d"/>`, extra)
+			}
+			i += n
+		case c == '\t':
+			ow.w.WriteString("<text:tab/>")
+			i++
+		case c == '\n':
+			ow.w.WriteString("<text:line-break/>")
+			i++
+		case c < 0x20:
+			i++
+		default:
+			j := i + 1
+			for j < len(s) && s[j] > ' ' {
+				j++
+			}
+			xml.EscapeText(ow.w, []byte(s[i:j]))
+			i = j
+		}
+	}
+	ow.w.WriteString("</text:p></table:table-cell>")
+}
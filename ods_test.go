@@ -0,0 +1,140 @@
+package dbf
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestODSWriter(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	buf := new(bytes.Buffer)
+	w := NewODSWriter(buf)
+	w.SheetName = `Test & "sheet"`
+	n, err := dbf.Export(w, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("Want 3 exported records, have %d", n)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zr.File) != 3 || zr.File[0].Name != "mimetype" || zr.File[0].Method != zip.Store {
+		t.Fatalf("Want an uncompressed mimetype as first of 3 files, have %+v", zr.File[0].FileHeader)
+	}
+	content := readZipFile(t, zr, 2)
+	if mt := readZipFile(t, zr, 0); string(mt) != odsMimeType {
+		t.Errorf("Want mime type %s, have %s", odsMimeType, mt)
+	}
+
+	// the content must be well-formed XML with a header row and a row per record
+	var doc struct {
+		Rows []struct {
+			Cells []struct {
+				Type  string `xml:"value-type,attr"`
+				Value string `xml:"value,attr"`
+				Date  string `xml:"date-value,attr"`
+				Text  string `xml:"p"`
+			} `xml:"table-cell"`
+		} `xml:"body>spreadsheet>table>table-row"`
+	}
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Rows) != 4 {
+		t.Fatalf("Want 4 rows, have %d", len(doc.Rows))
+	}
+	for i, cell := range doc.Rows[0].Cells {
+		if cell.Type != "string" || cell.Text != dbf.Fields()[i].FieldName() {
+			t.Errorf("Want field name %s in the header row, have %+v", dbf.Fields()[i].FieldName(), cell)
+		}
+	}
+	if !bytes.Contains(content, []byte(`table:name="Test &amp; &#34;sheet&#34;"`)) {
+		t.Error("Want the escaped sheet name")
+	}
+
+	types := make(map[string]bool)
+	for _, row := range doc.Rows[1:] {
+		if len(row.Cells) != 13 {
+			t.Fatalf("Want 13 cells, have %d", len(row.Cells))
+		}
+		for i, cell := range row.Cells {
+			types[string(dbf.Fields()[i].Type)+cell.Type] = true
+			if cell.Type == "float" && cell.Value != cell.Text {
+				t.Errorf("Want the number as text, have %+v", cell)
+			}
+			if cell.Type == "date" && !strings.HasPrefix(cell.Date, cell.Text[:10]) {
+				t.Errorf("Want the date as text, have %+v", cell)
+			}
+		}
+	}
+	for _, typ := range []string{"Cstring", "Nfloat", "Lboolean", "Ddate", "Ifloat"} {
+		if !types[typ] {
+			t.Errorf("Want a %s cell for %s fields, have %v", typ[1:], typ[:1], types)
+		}
+	}
+}
+
+func TestODSText(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"a b", "a b"},
+		{"a   b", `a <text:s text:c="2"/>b`},
+		{"  a", `<text:s text:c="2"/>a`},
+		{"a\tb\nc\x01<d>", "a<text:tab/>b<text:line-break/>c&lt;d&gt;"},
+	}
+	for _, test := range tests {
+		buf := new(bytes.Buffer)
+		w := NewODSWriter(ioutil.Discard)
+		w.w = bufio.NewWriter(buf)
+		w.writeText(test.in)
+		w.w.Flush()
+		want := `<table:table-cell office:value-type="string"><text:p>` + test.out + "</text:p></table:table-cell>"
+		if buf.String() != want {
+			t.Errorf("%q: want %s, have %s", test.in, want, buf)
+		}
+	}
+}
+
+func TestODSDateTime(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewODSWriter(ioutil.Discard)
+	w.w = bufio.NewWriter(buf)
+	w.writeCell(time.Date(2021, 3, 4, 5, 6, 7, 8000000, time.UTC), &FieldHeader{Type: 'T'})
+	w.writeCell(time.Time{}, &FieldHeader{Type: 'T'})
+	w.w.Flush()
+	want := `<table:table-cell table:style-name="ce2" office:value-type="date" office:date-value="2021-03-04T05:06:07.008">` +
+		`<text:p>2021-03-04 05:06:07</text:p></table:table-cell><table:table-cell/>`
+	if buf.String() != want {
+		t.Errorf("Want %s, have %s", want, buf)
+	}
+}
+
+func readZipFile(t *testing.T, zr *zip.Reader, i int) []byte {
+	rc, err := zr.File[i].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,223 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// PackReport contains the results of a pack operation
+type PackReport struct {
+	Kept       uint32 // Number of records written
+	Removed    uint32 // Number of deleted records which were removed
+	MemoBlocks uint32 // Number of blocks in the new memo file, including the header blocks
+}
+
+// PackTo writes a copy of the table to dbfout without the deleted records and updates the record count.
+// If the table has a memo file, a compacted memo file is written to fptout which only contains the memos
+// of the remaining records, the memo pointers in the records are updated to match.
+// fptout can be nil for tables without memo file.
+// Index files are not updated, the structural index (CDX) flag is cleared in the written header so the packed
+// table does not refer to the stale index. The caller must drop the index files or rebuild them after packing.
+func (dbf *DBF) PackTo(dbfout io.Writer, fptout io.WriteSeeker) (*PackReport, error) {
+	return dbf.copyTable(dbfout, fptout, 0, false)
+}
+
+// copyTable writes a copy of the table with compacted memos, the deleted records are only written with keepDeleted.
+// The memo file is written with blockSize, or the block size of the table when it is 0.
+func (dbf *DBF) copyTable(dbfout io.Writer, fptout io.WriteSeeker, blockSize uint16, keepDeleted bool) (*PackReport, error) {
+	hasMemo := dbf.fptr != nil
+	if hasMemo && fptout == nil {
+		return nil, ErrNoFPTFile
+	}
+	if hasMemo && dbf.smt {
+		return nil, errors.New("copying tables with an SMT memo file is not supported")
+	}
+
+	var memo *memoWriter
+	if hasMemo {
+		if blockSize == 0 {
+			blockSize = dbf.fptheader.BlockSize
+		}
+		var err error
+		memo, err = newMemoWriter(fptout, blockSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := new(PackReport)
+
+	// count the remaining records first so the header can be written before the records
+	for i := uint32(0); i < dbf.header.NumRec; i++ {
+		deleted, err := dbf.DeletedAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if !deleted || keepDeleted {
+			report.Kept++
+		}
+	}
+	report.Removed = dbf.header.NumRec - report.Kept
+
+	header := make([]byte, dbf.header.FirstRec)
+	if _, err := dbf.r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	setModified(header, time.Now())
+	if dbf.decrypter != nil {
+		// the records are written decrypted
+		header[15] = 0
+	}
+	if !keepDeleted {
+		// the record numbers change, the structural index no longer matches the records
+		header[28] &^= 0x01
+	}
+	binary.LittleEndian.PutUint32(header[4:], report.Kept)
+	if _, err := dbfout.Write(header); err != nil {
+		return nil, err
+	}
+
+	for i := uint32(0); i < dbf.header.NumRec; i++ {
+		data, err := dbf.readRecord(i)
+		if err != nil {
+			return nil, err
+		}
+		if data[0] == 0x2A && !keepDeleted {
+			continue
+		}
+		if hasMemo {
+			if err := dbf.copyMemos(data, memo); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := dbfout.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := dbfout.Write([]byte{0x1A}); err != nil {
+		return nil, err
+	}
+
+	if hasMemo {
+		if err := memo.close(); err != nil {
+			return nil, err
+		}
+		report.MemoBlocks = memo.next
+	}
+	return report, nil
+}
+
+// copyMemos copies the memo blocks referenced by record data to w and updates the pointers in data.
+// The block type and the data of the memo blocks are copied unchanged.
+func (dbf *DBF) copyMemos(data []byte, w *memoWriter) error {
+	for i, f := range dbf.fields {
+		raw := dbf.layout[i].data(data)
+		if !f.isMemo() {
+			continue
+		}
+		block, err := dbf.memoPointer(raw)
+		if err != nil {
+			return err
+		}
+		if block == 0 {
+			// no memo
+			continue
+		}
+		sign, memo, err := dbf.readMemoBlock(block)
+		if err != nil {
+			return err
+		}
+		block, err = w.write(sign, memo)
+		if err != nil {
+			return err
+		}
+		putMemoBlock(raw, block)
+	}
+	return nil
+}
+
+// isMemo returns if the field stores its data in the memo file
+func (f *FieldHeader) isMemo() bool {
+	switch f.Type {
+	case 'M', 'G', 'P', 'W':
+		return true
+	}
+	return false
+}
+
+// memoWriter writes a new FPT file block by block
+type memoWriter struct {
+	w         io.WriteSeeker
+	blockSize uint32
+	next      uint32 // next free block
+}
+
+// fptHeaderSize is the size of the FPT header, the first memo block starts after the header
+const fptHeaderSize = 512
+
+func newMemoWriter(w io.WriteSeeker, blockSize uint16) (*memoWriter, error) {
+	if blockSize == 0 {
+		return nil, errors.New("invalid memo block size 0")
+	}
+	mw := &memoWriter{
+		w:         w,
+		blockSize: uint32(blockSize),
+		next:      (fptHeaderSize + uint32(blockSize) - 1) / uint32(blockSize),
+	}
+	// reserve the header, it is written on close when the next free block is known
+	if _, err := w.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(make([]byte, mw.next*mw.blockSize)); err != nil {
+		return nil, err
+	}
+	return mw, nil
+}
+
+// write writes one memo with its block header at the next free block and returns the block number
+func (mw *memoWriter) write(sign uint32, data []byte) (uint32, error) {
+	if uint64(len(data)) > MaxMemoLength {
+		return 0, &LimitError{Limit: "memo length", Value: uint64(len(data)), Max: MaxMemoLength}
+	}
+	block := mw.next
+	size := 8 + uint64(len(data))
+	blocks := (size + uint64(mw.blockSize) - 1) / uint64(mw.blockSize)
+	if uint64(block)+blocks > MaxMemoBlocks {
+		return 0, &LimitError{Limit: "number of memo blocks", Value: uint64(block) + blocks, Max: MaxMemoBlocks}
+	}
+
+	buf := make([]byte, blocks*uint64(mw.blockSize))
+	binary.BigEndian.PutUint32(buf[0:], sign)
+	binary.BigEndian.PutUint32(buf[4:], uint32(len(data)))
+	copy(buf[8:], data)
+
+	if _, err := mw.w.Seek(int64(block)*int64(mw.blockSize), io.SeekStart); err != nil {
+		return 0, err
+	}
+	if _, err := mw.w.Write(buf); err != nil {
+		return 0, err
+	}
+	mw.next += uint32(blocks)
+	return block, nil
+}
+
+// close writes the FPT header
+func (mw *memoWriter) close() error {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[0:], mw.next)
+	binary.BigEndian.PutUint16(header[6:], uint16(mw.blockSize))
+	if _, err := mw.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := mw.w.Write(header)
+	return err
+}
+
+// setModified sets the last update date in raw header bytes
+func setModified(header []byte, t time.Time) {
+	header[1] = byte(t.Year() % 100)
+	header[2] = byte(t.Month())
+	header[3] = byte(t.Day())
+}
@@ -0,0 +1,155 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackTo(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	dir, err := ioutil.TempDir("", "dbfpack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fpt, err := os.Create(filepath.Join(dir, "PACKED.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fpt.Close()
+	out := new(bytes.Buffer)
+
+	report, err := dbf.PackTo(out, fpt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Kept != 3 || report.Removed != 1 {
+		t.Errorf("Want 3 kept and 1 removed record, have %d and %d", report.Kept, report.Removed)
+	}
+
+	fptbytes, err := ioutil.ReadFile(fpt.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := OpenStream(bytes.NewReader(out.Bytes()), bytes.NewReader(fptbytes), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packed.NumRecords() != 3 {
+		t.Fatalf("Want 3 records, have %d", packed.NumRecords())
+	}
+	if int64(out.Len()) != packed.Header().FileSize()+1 {
+		t.Errorf("Want file size %d, have %d", packed.Header().FileSize()+1, out.Len())
+	}
+
+	// compare all remaining records with the original
+	orig := []uint32{0, 2, 3}
+	for i, recno := range orig {
+		want, err := dbf.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have, err := packed.RecordAt(uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have.Deleted {
+			t.Errorf("Record %d should not be deleted", i)
+		}
+		for pos, val := range want.FieldSlice() {
+			if !equalValue(val, have.FieldSlice()[pos]) {
+				t.Errorf("Record %d field %d: want %v, have %v", i, pos, val, have.FieldSlice()[pos])
+			}
+		}
+	}
+	// record 3 has no memo
+	if rec, err := packed.RecordAt(2); err != nil || rec.FieldSlice()[dbf.FieldPos("MELDING")] != "" {
+		t.Errorf("Want an empty memo, have %v (%v)", rec, err)
+	}
+}
+
+func equalValue(a, b interface{}) bool {
+	if ba, ok := a.([]byte); ok {
+		bb, ok := b.([]byte)
+		return ok && bytes.Equal(ba, bb)
+	}
+	return a == b
+}
+
+func TestPackToClearsCDXFlag(t *testing.T) {
+	dbfbytes, fptbytes := readTestFiles(t)
+	dbfbytes[28] |= 0x01
+	dbf, err := OpenStream(bytes.NewReader(dbfbytes), bytes.NewReader(fptbytes), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dbf.Header().HasCDX() {
+		t.Fatal("Want the CDX flag set")
+	}
+
+	out := new(bytes.Buffer)
+	if _, err := dbf.PackTo(out, new(memWriteSeeker)); err != nil {
+		t.Fatal(err)
+	}
+	if flags := out.Bytes()[28]; flags != 0x02 {
+		t.Errorf("Want table flags 0x02 after packing, have %#x", flags)
+	}
+}
+
+func TestPackToKeepsMemoBlockType(t *testing.T) {
+	dbfbytes, fptbytes := readTestFiles(t)
+	dbf, err := OpenStream(bytes.NewReader(dbfbytes), bytes.NewReader(fptbytes), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// turn the memo of the first record into an object (type 2) block
+	pos := dbf.FieldPos("MELDING")
+	data, err := dbf.readRecord(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := memoBlock(dbf.layout[pos].data(data))
+	if err != nil || block == 0 {
+		t.Fatalf("Want a memo in the first record, have block %d (%v)", block, err)
+	}
+	start := block * uint32(dbf.fptheader.BlockSize)
+	binary.BigEndian.PutUint32(fptbytes[start:], 2)
+	want := append([]byte(nil), fptbytes[start+8:start+8+binary.BigEndian.Uint32(fptbytes[start+4:])]...)
+
+	out, fptout := new(bytes.Buffer), new(memWriteSeeker)
+	if _, err := dbf.PackTo(out, fptout); err != nil {
+		t.Fatal(err)
+	}
+	packed, err := OpenStream(bytes.NewReader(out.Bytes()), bytes.NewReader(fptout.buf), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = packed.readRecord(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err = memoBlock(packed.layout[pos].data(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign, memo, err := packed.readMemoBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sign != 2 {
+		t.Errorf("Want block type 2, have %d", sign)
+	}
+	if !bytes.Equal(memo, want) {
+		t.Errorf("Want memo %q, have %q", want, memo)
+	}
+}
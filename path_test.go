@@ -0,0 +1,51 @@
+package dbf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenFileLongPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a directory tree deeper than MAX_PATH on Windows
+	deep := dir
+	for len(deep) < 300 {
+		deep = filepath.Join(deep, strings.Repeat("d", 40))
+	}
+	if err := os.MkdirAll(deep, 0755); err != nil {
+		t.Skipf("Cannot create a long path: %s", err)
+	}
+	for _, name := range []string{"TEST.DBF", "TEST.FPT"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(deep, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dbf, err := OpenFile(filepath.Join(deep, "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memo := rec.FieldSlice()[dbf.FieldPos("MELDING")]; memo == "" {
+		t.Error("Want the memo to be read from the FPT file in the same directory")
+	}
+	if err := dbf.Reopen(); err != nil {
+		t.Errorf("Reopen: %s", err)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build windows
+// +build windows
+
+package dbf
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxShortPath is the length from which paths get the extended-length prefix, this leaves room for
+// the 8.3 file name that is added to a directory within MAX_PATH (260), just like package os does
+const maxShortPath = 248
+
+// longPath returns the extended-length form (\\?\C:\... or \\?\UNC\server\share\...) of paths which are too long
+// for the Windows API, which is needed when a file is opened with syscall.CreateFile instead of package os.
+// Relative paths are made absolute first, short paths are returned unchanged.
+func longPath(path string) string {
+	if strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil || len(abs) < maxShortPath {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		// UNC path \\server\share\...
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,28 @@
+//go:build windows
+// +build windows
+
+package dbf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLongPath(t *testing.T) {
+	long := strings.Repeat(`\dir`, 70)
+	cases := []struct {
+		path, want string
+	}{
+		{`C:\data\TEST.DBF`, `C:\data\TEST.DBF`},
+		{`\\server\share\TEST.DBF`, `\\server\share\TEST.DBF`},
+		{`C:` + long + `\TEST.DBF`, `\\?\C:` + long + `\TEST.DBF`},
+		{`C:/data` + strings.Replace(long, `\`, `/`, -1) + `/TEST.DBF`, `\\?\C:\data` + long + `\TEST.DBF`},
+		{`\\server\share` + long + `\TEST.DBF`, `\\?\UNC\server\share` + long + `\TEST.DBF`},
+		{`\\?\C:` + long + `\TEST.DBF`, `\\?\C:` + long + `\TEST.DBF`},
+	}
+	for _, c := range cases {
+		if have := longPath(c.path); have != c.want {
+			t.Errorf("longPath(%s): want %s, have %s", c.path, c.want, have)
+		}
+	}
+}
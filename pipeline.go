@@ -0,0 +1,216 @@
+package dbf
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pipe is a chain of steps which is executed for every record of a table, see Pipeline.
+// The steps are executed in the order they are added, records are read and passed on one by one.
+type Pipe struct {
+	dbf            *DBF
+	ctx            context.Context
+	includeDeleted bool
+	progress       func(read, total uint32)
+	steps          []pipeStep
+	fields         []FieldHeader // fields after the last Select
+	err            error         // error while building the pipeline, returned when it is executed
+}
+
+// pipeStep is one step of a Pipe, only one of the fields is set
+type pipeStep struct {
+	filter    func(recno uint32, rec *Record) bool
+	mapper    func(recno uint32, rec *Record) error
+	positions []int         // Select: positions of the selected fields in the fields of the previous step
+	fields    []FieldHeader // Select: the selected fields
+	limit     int           // Limit: the maximum number of records passed on, -1 for none and 0 is not a Limit step
+}
+
+// Pipeline returns a Pipe which reads the records of d with a Scanner, so the internal record pointer is
+// not used or moved. Steps are added with the chainable methods and executed by To or Each:
+//
+//	n, err := dbf.Pipeline(d).
+//		Context(ctx).
+//		Filter(func(recno uint32, rec *dbf.Record) bool { return !rec.IsNull(3) }).
+//		Map(func(recno uint32, rec *dbf.Record) error { return rec.SetField(1, strings.ToUpper(...)) }).
+//		Select("CUSTNO", "NAME").
+//		Limit(100).
+//		To(dbf.NewCSVWriter(os.Stdout))
+//
+// Hidden system fields like _NullFlags are not passed on, the fields of values converted by the TypeMap of the table
+// have the type of the converted values. A Pipe can be executed more than once.
+func Pipeline(d *DBF) *Pipe {
+	p := &Pipe{dbf: d, ctx: context.Background()}
+	hide := pipeStep{positions: []int{}, fields: []FieldHeader{}}
+	for i := range d.fields {
+		if !d.fields[i].System() {
+			hide.positions = append(hide.positions, i)
+			hide.fields = append(hide.fields, d.mappedField(i))
+		}
+	}
+	p.fields = hide.fields
+	if len(hide.fields) < len(d.fields) {
+		p.steps = append(p.steps, hide)
+	}
+	return p
+}
+
+// Context sets the context which stops the execution when it is done, its error is returned
+func (p *Pipe) Context(ctx context.Context) *Pipe {
+	p.ctx = ctx
+	return p
+}
+
+// IncludeDeleted also reads deleted records, by default they are skipped
+func (p *Pipe) IncludeDeleted() *Pipe {
+	p.includeDeleted = true
+	return p
+}
+
+// Progress sets a function which is called after every record read with the number of records read
+// and the number of records in the table
+func (p *Pipe) Progress(fn func(read, total uint32)) *Pipe {
+	p.progress = fn
+	return p
+}
+
+// Filter adds a step which skips the records for which fn returns false
+func (p *Pipe) Filter(fn func(recno uint32, rec *Record) bool) *Pipe {
+	p.steps = append(p.steps, pipeStep{filter: fn})
+	return p
+}
+
+// Map adds a step which changes the records, values are changed with Record.SetField.
+// An error stops the execution.
+func (p *Pipe) Map(fn func(recno uint32, rec *Record) error) *Pipe {
+	p.steps = append(p.steps, pipeStep{mapper: fn})
+	return p
+}
+
+// Select adds a step which passes on only the named fields, in the given order
+func (p *Pipe) Select(names ...string) *Pipe {
+	step := pipeStep{positions: []int{}, fields: []FieldHeader{}}
+	for _, name := range names {
+		pos := p.fieldPos(name)
+		if pos < 0 {
+			return p
+		}
+		step.positions = append(step.positions, pos)
+		step.fields = append(step.fields, p.fields[pos])
+	}
+	p.fields = step.fields
+	p.steps = append(p.steps, step)
+	return p
+}
+
+// fieldPos returns the position of field name in the fields of the last step.
+// If the field is not found -1 is returned and the error is kept for the execution.
+func (p *Pipe) fieldPos(name string) int {
+	for i := range p.fields {
+		if p.fields[i].FieldName() == name {
+			return i
+		}
+	}
+	if p.err == nil {
+		p.err = fmt.Errorf("field %s not found", name)
+	}
+	return -1
+}
+
+// Limit adds a step which stops the execution after n records have passed it
+func (p *Pipe) Limit(n int) *Pipe {
+	if n <= 0 {
+		n = -1 // nothing passes
+	}
+	p.steps = append(p.steps, pipeStep{limit: n})
+	return p
+}
+
+// Fields returns the fields of the records after the last Select
+func (p *Pipe) Fields() []FieldHeader {
+	return p.fields
+}
+
+// To writes the records which pass all steps to w and returns the number of records written.
+// Close of w is always called, the first error of the execution or Close is returned.
+func (p *Pipe) To(w RecordWriter) (n uint32, err error) {
+	defer func() {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if p.err != nil {
+		return 0, p.err
+	}
+	if err := w.WriteSchema(p.fields); err != nil {
+		return 0, err
+	}
+	err = p.Each(func(recno uint32, rec *Record) error {
+		if err := w.WriteRecord(rec); err != nil {
+			return fmt.Errorf("record %d: %s", recno, err)
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// Each calls fn for every record which passes all steps, an error returned by fn stops the execution
+func (p *Pipe) Each(fn func(recno uint32, rec *Record) error) error {
+	if p.err != nil {
+		return p.err
+	}
+	passed := make([]int, len(p.steps)) // number of records passed per Limit step
+	total := p.dbf.NumRecords()
+
+	s := p.dbf.Scanner()
+	s.IncludeDeleted = p.includeDeleted
+	for s.Next() {
+		if err := p.ctx.Err(); err != nil {
+			return err
+		}
+		recno := s.RecNo()
+		rec, stop, err := p.run(recno, s.Record(), passed)
+		if err != nil {
+			return err
+		}
+		if rec != nil {
+			if err := fn(recno, rec); err != nil {
+				return err
+			}
+		}
+		if p.progress != nil {
+			p.progress(recno+1, total)
+		}
+		if stop {
+			return nil
+		}
+	}
+	return s.Err()
+}
+
+// run executes the steps for one record, it returns nil if the record is skipped and stop
+// when a Limit is reached and no more records have to be read
+func (p *Pipe) run(recno uint32, rec *Record, passed []int) (*Record, bool, error) {
+	stop := false
+	for i, step := range p.steps {
+		switch {
+		case step.filter != nil:
+			if !step.filter(recno, rec) {
+				return nil, stop, nil
+			}
+		case step.mapper != nil:
+			if err := step.mapper(recno, rec); err != nil {
+				return nil, true, fmt.Errorf("record %d: %s", recno, err)
+			}
+		case step.fields != nil:
+			rec = rec.project(step.fields, step.positions)
+		case step.limit < 0:
+			return nil, true, nil
+		case step.limit > 0:
+			passed[i]++
+			stop = stop || passed[i] == step.limit
+		}
+	}
+	return rec, stop, nil
+}
@@ -0,0 +1,121 @@
+package dbf
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	p := Pipeline(dbf).
+		IncludeDeleted().
+		Filter(func(recno uint32, rec *Record) bool { return recno > 0 }).
+		Map(func(recno uint32, rec *Record) error {
+			return rec.SetField(7, strings.ToLower(ToTrimmedString(rec.FieldSlice()[7])))
+		}).
+		Select("COMP_NAME", "ID").
+		Limit(2)
+	if len(p.Fields()) != 2 || p.Fields()[0].FieldName() != "COMP_NAME" {
+		t.Fatalf("Unexpected fields %v", p.Fields())
+	}
+
+	// a Pipe can be executed more than once
+	for run := 0; run < 2; run++ {
+		rw := new(recordingWriter)
+		n, err := p.To(rw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 || len(rw.records) != 2 || rw.closed != 1 {
+			t.Fatalf("Run %d: want 2 records written and closed once, have %d closed %d times", run, n, rw.closed)
+		}
+		if rw.records[0][1] != int32(2) || rw.records[1][1] != int32(3) {
+			t.Errorf("Run %d: want IDs 2 and 3, have %v", run, rw.records)
+		}
+		if len(rw.fields) != 2 || rw.fields[1].FieldName() != "ID" {
+			t.Errorf("Run %d: unexpected schema %v", run, rw.fields)
+		}
+	}
+
+	var read []uint32
+	err = Pipeline(dbf).
+		Progress(func(n, total uint32) { read = append(read, n) }).
+		Limit(1).
+		Each(func(recno uint32, rec *Record) error {
+			if recno != 0 || rec.FieldSlice()[7] != "TEST"+strings.Repeat(" ", 36) {
+				t.Errorf("Unexpected record %d %v", recno, rec.FieldSlice())
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 {
+		t.Errorf("Want the execution stopped after the limit, read %v", read)
+	}
+
+	n := 0
+	err = Pipeline(dbf).Limit(0).Each(func(uint32, *Record) error {
+		n++
+		return nil
+	})
+	if err != nil || n != 0 {
+		t.Errorf("Want no records for Limit(0), have %d (%v)", n, err)
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rw := new(recordingWriter)
+	if _, err := Pipeline(dbf).Context(ctx).To(rw); err != context.Canceled {
+		t.Errorf("Want context.Canceled, have %v", err)
+	}
+	if rw.closed != 1 {
+		t.Errorf("Want the writer closed once, closed %d times", rw.closed)
+	}
+
+	mapErr := errors.New("bad value")
+	err = Pipeline(dbf).Map(func(uint32, *Record) error { return mapErr }).Each(func(uint32, *Record) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "bad value") {
+		t.Errorf("Want the Map error, have %v", err)
+	}
+
+	stop := errors.New("stop")
+	if err := Pipeline(dbf).Each(func(uint32, *Record) error { return stop }); err != stop {
+		t.Errorf("Want the Each error, have %v", err)
+	}
+
+	if err := Pipeline(dbf).Select("ID").Select("NAME").Each(func(uint32, *Record) error { return nil }); err == nil {
+		t.Error("Want an error for a field which is not selected")
+	}
+}
+
+func TestPipelineNullFlags(t *testing.T) {
+	dbf := nullTable(t)
+
+	rw := new(recordingWriter)
+	if _, err := Pipeline(dbf).To(rw); err != nil {
+		t.Fatal(err)
+	}
+	if len(rw.fields) != 3 || len(rw.records[1]) != 3 {
+		t.Fatalf("Want 3 fields without _NullFlags, have %d", len(rw.fields))
+	}
+	if rw.records[1][1] != nil {
+		t.Errorf("Want a null NAME, have %v", rw.records[1][1])
+	}
+}
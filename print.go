@@ -0,0 +1,126 @@
+package dbf
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PrintOptions controls the output of Record.Print
+type PrintOptions struct {
+	Indent  string // prefix of every line
+	MaxMemo int    // memo values are truncated to this number of characters, 0 uses 60 and -1 does not truncate
+	Raw     bool   // also print the raw field data as hex bytes
+
+	// Format renders the values, like in a CSVReader
+	Format FormatOptions
+
+	// Mask is called with the position and the formatted text of every value, the returned text is printed.
+	// It can be used to hide sensitive values, the raw data of values which are changed is not printed.
+	Mask func(pos int, text string) string
+}
+
+// defaultMaxMemo is the number of memo characters printed when PrintOptions.MaxMemo is 0
+const defaultMaxMemo = 60
+
+// Print writes the fields of the record to w, one line per field with the names aligned:
+//
+//	ID        : 1
+//	COMP_NAME : "TEST"
+//	MELDING   : "Message line 1\r\nMessage line 2"
+//
+// Character and memo values are quoted with the spaces of C fields trimmed, binary values are printed as their
+// size and null values as NULL. System fields like _NullFlags are skipped. The raw data is only available for
+// records read from a table. opts can be nil to use the defaults.
+func (r *Record) Print(w io.Writer, opts *PrintOptions) error {
+	if opts == nil {
+		opts = new(PrintOptions)
+	}
+	maxMemo := opts.MaxMemo
+	if maxMemo == 0 {
+		maxMemo = defaultMaxMemo
+	}
+
+	names := make([]string, len(r.data))
+	width := 0
+	for i := range r.data {
+		names[i] = strconv.Itoa(i)
+		if r.fields != nil {
+			if r.fields[i].System() {
+				continue
+			}
+			names[i] = r.fields[i].FieldName()
+		}
+		if len(names[i]) > width {
+			width = len(names[i])
+		}
+	}
+
+	for i, val := range r.data {
+		f := &FieldHeader{}
+		if r.fields != nil {
+			f = &r.fields[i]
+		}
+		if f.System() {
+			continue
+		}
+		text, masked := r.printValue(i, val, f, opts, maxMemo)
+		if _, err := fmt.Fprintf(w, "%s%-*s : %s\n", opts.Indent, width, names[i], text); err != nil {
+			return err
+		}
+		if opts.Raw && !masked && r.raw != nil && i < len(r.layout) {
+			raw := hex.EncodeToString(r.layout[i].data(r.raw))
+			if _, err := fmt.Fprintf(w, "%s%-*s   raw %s\n", opts.Indent, width, "", spacedHex(raw)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// printValue returns the printed text of a value and if it was changed by the Mask function
+func (r *Record) printValue(pos int, val interface{}, f *FieldHeader, opts *PrintOptions, maxMemo int) (string, bool) {
+	if val == nil {
+		return "NULL", false
+	}
+	if b, ok := val.([]byte); ok && opts.Mask == nil {
+		return fmt.Sprintf("<%d bytes>", len(b)), false
+	}
+
+	text := opts.Format.FormatValue(val, f)
+	masked := false
+	if opts.Mask != nil {
+		m := opts.Mask(pos, text)
+		masked, text = m != text, m
+	}
+	if _, ok := val.(string); !ok {
+		return text, masked
+	}
+
+	if f.Type == 'M' && maxMemo > 0 && utf8.RuneCountInString(text) > maxMemo {
+		n := 0
+		for i := range text {
+			if n == maxMemo {
+				rest := utf8.RuneCountInString(text[i:])
+				return fmt.Sprintf("%s... (%d more characters)", strconv.Quote(text[:i]), rest), masked
+			}
+			n++
+		}
+	}
+	return strconv.Quote(text), masked
+}
+
+// spacedHex inserts a space between every byte of a hex string
+func spacedHex(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i += 2 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(s[i : i+2])
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package dbf
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordPrint(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := rec.Print(buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `ID        : 1
+NIVEAU    : 0
+DATUM     : 2015-01-03
+TIJD      : "15:00"
+SOORT     : 3
+ID_NR     : 100
+USERNR    : 1
+COMP_NAME : "TEST"
+COMP_OS   : "Windows 8.1 Pro"
+MELDING   : "Message line 1\r\nMessage line 2"
+NUMBER    : 1.66
+FLOAT     : 1
+BOOL      : false
+`
+	if buf.String() != want {
+		t.Errorf("Want\n%s\nhave\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	opts := &PrintOptions{
+		Indent:  "  ",
+		MaxMemo: 10,
+		Raw:     true,
+		Mask: func(pos int, text string) string {
+			if pos == 7 {
+				return "***"
+			}
+			return text
+		},
+	}
+	if err := rec.Print(buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	checks := map[int]string{
+		0:  `  ID        : 1`,
+		1:  `              raw 01 00 00 00`,
+		14: `  COMP_NAME : "***"`,
+		15: `  COMP_OS   : "Windows 8.1 Pro"`,
+		17: `  MELDING   : "Message li"... (20 more characters)`,
+	}
+	for i, line := range checks {
+		if i >= len(lines) {
+			t.Fatalf("Want at least %d lines, have %d", i+1, len(lines))
+		}
+		if lines[i] != line {
+			t.Errorf("Line %d: want %q, have %q", i, line, lines[i])
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package dbf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ProfileOptions controls the statistics calculated by Profile
+type ProfileOptions struct {
+	// TopK is the number of most frequent values per field, 0 uses 10
+	TopK int
+
+	// Buckets is the number of equal width buckets of the numeric distribution, 0 uses 10
+	Buckets int
+
+	// IncludeDeleted also profiles deleted records, by default they are skipped
+	IncludeDeleted bool
+}
+
+// ColumnProfile contains the statistics of one field
+type ColumnProfile struct {
+	Field string
+	Type  string
+
+	Count    uint32 // Number of profiled values
+	Empty    uint32 // Number of blank C, M, D and T values
+	Distinct uint32 // Number of distinct values
+
+	// Lengths is a histogram of the length in characters of trimmed C and M values, indexed by length
+	Lengths map[int]uint32
+
+	// Min, Max and Buckets describe the distribution of numeric fields (I, N, F, B and Y).
+	// Buckets are of equal width between Min and Max, the last bucket includes Max.
+	Min     float64
+	Max     float64
+	Buckets []ProfileBucket
+
+	// TopValues are the most frequent values, formatted like the CSV export, most frequent first
+	TopValues []ProfileValue
+}
+
+// ProfileBucket is a range of values in a numeric distribution
+type ProfileBucket struct {
+	Low   float64
+	High  float64
+	Count uint32
+}
+
+// ProfileValue is a value with the number of times it occurs
+type ProfileValue struct {
+	Value string
+	Count uint32
+}
+
+// Profile reads all records once and returns the statistics of every field.
+// The frequencies of all distinct values and all numeric values are kept in memory while profiling,
+// so memory use grows with the number of distinct values.
+func (dbf *DBF) Profile(opts ProfileOptions) ([]*ColumnProfile, error) {
+	profiles, _, err := dbf.profile(opts)
+	return profiles, err
+}
+
+// profile returns the statistics of every field and the number of deleted records
+func (dbf *DBF) profile(opts ProfileOptions) ([]*ColumnProfile, uint32, error) {
+	if opts.TopK <= 0 {
+		opts.TopK = 10
+	}
+	if opts.Buckets <= 0 {
+		opts.Buckets = 10
+	}
+
+	profiles := make([]*ColumnProfile, len(dbf.fields))
+	counts := make([]map[string]uint32, len(dbf.fields))
+	numbers := make([][]float64, len(dbf.fields))
+	for i := range dbf.fields {
+		f := &dbf.fields[i]
+		profiles[i] = &ColumnProfile{Field: f.FieldName(), Type: f.FieldType()}
+		if f.Type == 'C' || f.Type == 'M' {
+			profiles[i].Lengths = make(map[int]uint32)
+		}
+		counts[i] = make(map[string]uint32)
+	}
+
+	format := new(CSVReader)
+	deleted := uint32(0)
+	for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+		rec, err := dbf.RecordAt(recno)
+		if err != nil {
+			return nil, 0, fmt.Errorf("record %d: %s", recno, err)
+		}
+		if rec.Deleted {
+			deleted++
+			if !opts.IncludeDeleted {
+				continue
+			}
+		}
+		for i, val := range rec.data {
+			f := &dbf.fields[i]
+			p := profiles[i]
+			p.Count++
+			if isEmptyValue(val) {
+				p.Empty++
+			}
+			if p.Lengths != nil {
+				p.Lengths[utf8.RuneCountInString(strings.TrimSpace(ToString(val)))]++
+			}
+			if isNumericField(f) {
+				numbers[i] = append(numbers[i], numericValue(val))
+			}
+			counts[i][format.format(val, f)]++
+		}
+	}
+
+	for i, p := range profiles {
+		p.Distinct = uint32(len(counts[i]))
+		p.TopValues = topValues(counts[i], opts.TopK)
+		if len(numbers[i]) > 0 {
+			p.Min, p.Max, p.Buckets = distribution(numbers[i], opts.Buckets)
+		}
+	}
+	return profiles, deleted, nil
+}
+
+// isNumericField returns if the field contains numbers
+func isNumericField(f *FieldHeader) bool {
+	switch f.Type {
+	case 'I', 'N', 'F', 'B', 'Y':
+		return true
+	}
+	return false
+}
+
+// numericValue converts the value of a numeric field to float64
+func numericValue(val interface{}) float64 {
+	switch v := val.(type) {
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return ToFloat64(val)
+}
+
+// topValues returns the k most frequent values, values with the same count are sorted by value
+func topValues(counts map[string]uint32, k int) []ProfileValue {
+	values := make([]ProfileValue, 0, len(counts))
+	for v, n := range counts {
+		values = append(values, ProfileValue{Value: v, Count: n})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	if len(values) > k {
+		values = values[:k]
+	}
+	return values
+}
+
+// distribution returns the minimum, maximum and n equal width buckets of values
+func distribution(values []float64, n int) (float64, float64, []ProfileBucket) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	if min == max {
+		return min, max, []ProfileBucket{{Low: min, High: max, Count: uint32(len(values))}}
+	}
+	width := (max - min) / float64(n)
+	buckets := make([]ProfileBucket, n)
+	for i := range buckets {
+		buckets[i].Low = min + float64(i)*width
+		buckets[i].High = min + float64(i+1)*width
+	}
+	buckets[n-1].High = max
+	for _, v := range values {
+		b := int((v - min) / width)
+		if b >= n {
+			b = n - 1
+		}
+		buckets[b].Count++
+	}
+	return min, max, buckets
+}
@@ -0,0 +1,71 @@
+package dbf
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	profiles, err := dbf.Profile(ProfileOptions{TopK: 2, Buckets: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 13 {
+		t.Fatalf("Want 13 profiles, have %d", len(profiles))
+	}
+
+	id := profiles[0]
+	if id.Field != "ID" || id.Count != 3 || id.Distinct != 3 {
+		t.Errorf("ID: want 3 distinct values, have %+v", id)
+	}
+	if id.Min != 1 || id.Max != 4 || len(id.Buckets) != 3 {
+		t.Fatalf("ID: want min 1, max 4 and 3 buckets, have %v, %v and %d", id.Min, id.Max, len(id.Buckets))
+	}
+	for i, want := range []uint32{1, 0, 2} {
+		if id.Buckets[i].Count != want {
+			t.Errorf("ID bucket %d: want %d, have %d", i, want, id.Buckets[i].Count)
+		}
+	}
+	if len(id.TopValues) != 2 {
+		t.Errorf("ID: want 2 top values, have %d", len(id.TopValues))
+	}
+
+	name := profiles[dbf.FieldPos("COMP_NAME")]
+	if name.Empty != 1 {
+		t.Errorf("COMP_NAME: want 1 empty value, have %d", name.Empty)
+	}
+	if name.Lengths[4] != 1 || name.Lengths[5] != 1 || name.Lengths[0] != 1 {
+		t.Errorf("COMP_NAME: unexpected length histogram %v", name.Lengths)
+	}
+	if name.Buckets != nil {
+		t.Errorf("COMP_NAME: want no buckets, have %v", name.Buckets)
+	}
+
+	all, err := dbf.Profile(ProfileOptions{IncludeDeleted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all[0].Count != 4 {
+		t.Errorf("Want 4 profiled records, have %d", all[0].Count)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	min, max, buckets := distribution([]float64{0, 1, 2, 5, 10, 10}, 2)
+	if min != 0 || max != 10 {
+		t.Errorf("Want min 0 and max 10, have %v and %v", min, max)
+	}
+	if buckets[0].Count != 3 || buckets[1].Count != 3 || buckets[1].Low != 5 {
+		t.Errorf("Unexpected buckets %+v", buckets)
+	}
+	_, _, buckets = distribution([]float64{3, 3}, 5)
+	if len(buckets) != 1 || buckets[0].Count != 2 {
+		t.Errorf("Want 1 bucket for equal values, have %+v", buckets)
+	}
+}
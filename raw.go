@@ -0,0 +1,62 @@
+package dbf
+
+import "io"
+
+// The Raw methods return the bytes of the table file as they are stored, without decoding, decrypting or validating
+// them, for forensic and recovery tools. Every call reads the file again and returns a new slice.
+
+// RawHeader returns the 32 byte table header, see DBFHeader
+func (dbf *DBF) RawHeader() ([]byte, error) {
+	return dbf.rawAt(0, 32)
+}
+
+// RawFieldDescriptors returns the 32 byte descriptor of every field, in the order of Fields
+func (dbf *DBF) RawFieldDescriptors() ([][]byte, error) {
+	descriptors := make([][]byte, len(dbf.fields))
+	for i := range dbf.fields {
+		desc, err := dbf.rawAt(32+int64(i)*32, 32)
+		if err != nil {
+			return nil, err
+		}
+		descriptors[i] = desc
+	}
+	return descriptors, nil
+}
+
+// RawBacklink returns the bytes after the header terminator (0x0D) up to the first record. For Visual FoxPro tables
+// this is the 263 byte backlink area with the path of the database container, see Database.
+// Other tables usually have no bytes here, but some applications store data in a larger header.
+func (dbf *DBF) RawBacklink() ([]byte, error) {
+	start := 32 + int64(len(dbf.fields))*32 + 1
+	if start >= int64(dbf.header.FirstRec) {
+		return []byte{}, nil
+	}
+	return dbf.rawAt(start, int(int64(dbf.header.FirstRec)-start))
+}
+
+// RawRecordAt returns the bytes of the record at recno, including the deleted flag. Records of encrypted tables
+// are returned encrypted. Records after the number of records in the header can be read as long as they are
+// complete in the file, so records which a damaged header does not count can still be recovered.
+// It returns ErrEOF when the record is not in the file and ErrIncomplete when the file ends within the record.
+func (dbf *DBF) RawRecordAt(recno uint32) ([]byte, error) {
+	buf := make([]byte, dbf.header.RecLen)
+	n, err := dbf.r.ReadAt(buf, int64(dbf.header.FirstRec)+int64(recno)*int64(dbf.header.RecLen))
+	switch {
+	case n == len(buf):
+		return buf, nil
+	case n == 0 && (err == nil || err == io.EOF):
+		return nil, ErrEOF
+	case err == nil || err == io.EOF:
+		return nil, ErrIncomplete
+	}
+	return nil, err
+}
+
+// rawAt returns n bytes at pos of the table file
+func (dbf *DBF) rawAt(pos int64, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if err := dbf.readAt(buf, pos); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
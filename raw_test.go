@@ -0,0 +1,88 @@
+package dbf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestRaw(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	header, err := dbf.RawHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(header, data[:32]) {
+		t.Errorf("Want the first 32 bytes, have % x", header)
+	}
+
+	descriptors, err := dbf.RawFieldDescriptors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(descriptors) != 13 {
+		t.Fatalf("Want 13 field descriptors, have %d", len(descriptors))
+	}
+	for i, desc := range descriptors {
+		if !bytes.Equal(desc, data[32+i*32:64+i*32]) {
+			t.Errorf("Field %d: unexpected descriptor % x", i, desc)
+		}
+	}
+	if name := string(bytes.TrimRight(descriptors[0][:11], "\x00")); name != dbf.Fields()[0].FieldName() {
+		t.Errorf("Want descriptor of field %s, have %s", dbf.Fields()[0].FieldName(), name)
+	}
+
+	backlink, err := dbf.RawBacklink()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backlink) != backlinkSize || !bytes.Equal(backlink, data[32+13*32+1:dbf.Header().FirstRec]) {
+		t.Errorf("Want the %d byte backlink area, have %d bytes", backlinkSize, len(backlink))
+	}
+
+	rec, err := dbf.RawRecordAt(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := int(dbf.Header().FirstRec) + int(dbf.Header().RecLen)
+	if !bytes.Equal(rec, data[start:start+int(dbf.Header().RecLen)]) || rec[0] != 0x2A {
+		t.Errorf("Want the bytes of deleted record 1, have % x", rec)
+	}
+	if _, err := dbf.RawRecordAt(4); err != ErrEOF {
+		t.Errorf("Want ErrEOF after the last record, have %v", err)
+	}
+}
+
+func TestRawRecordAtBeyondHeader(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a header which counts only 2 of the 4 records, and a 5th record which is cut off
+	data = append(data, data[len(data)-10:]...)
+	data[4] = 2
+	memo, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dbf.RawRecordAt(3); err != nil {
+		t.Errorf("Want record 3 beyond the header count, have %v", err)
+	}
+	if _, err := dbf.RawRecordAt(4); err != ErrIncomplete {
+		t.Errorf("Want ErrIncomplete for a cut off record, have %v", err)
+	}
+}
@@ -62,13 +62,51 @@ type DBF struct {
 	dec Decoder
 
 	fields []FieldHeader
+	layout []fieldLayout // position of every field in the record data, computed when the table is opened
+
+	jsonOpts JSONOptions // options for Record.MarshalJSON, see json.go
+
+	consistent *ConsistentReadOptions // consistent read mode, see consistent.go
+
+	shareMode ShareMode // share mode the files were opened with, see share.go
+
+	links []*Link // child tables positioned when the record pointer moves, see link.go
+
+	fieldProps []FieldProperties // field properties from the database container, see database.go
+
+	decrypter Decrypter // decrypts the records of encrypted tables, see encrypt.go
+
+	smt bool // the memo file is an SMT file instead of an FPT file, see smt.go
+
+	closers []io.Closer // readers opened by an Opener, closed by Close, see url.go
+
+	numbers InvalidNumberPolicy // value of N and F fields which are not a valid number, see numeric.go
+
+	zone *timeZone // time zone conversion of T values, nil keeps them as stored, see timezone.go
+
+	upd *updater // changes records in place, nil when the table is read-only, see update.go
+
+	nullflags int // position of the _NullFlags field, -1 if there is none, see null.go
+
+	limits *ParseLimits // limits of hardened mode, nil when the table is not hardened, see hardened.go
+
+	typeMap TypeMap     // conversions of field values, see typemap.go
+	mapped  []*TypeRule // the rule of every field, nil for fields without a rule
 
 	recpointer uint32 // internal record pointer, can be moved using Skip() and GoTo()
 }
 
 // Close closes the file handlers to the disk files.
 // The caller is responsible for calling Close to close the file handle(s)!
+// The changes to a staged table are written first, see OpenFileStaged.
 func (dbf *DBF) Close() error {
+	if dbf.upd != nil && dbf.upd.stage != nil {
+		err := dbf.commitStage()
+		if closeErr := dbf.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
 	var dbferr, fpterr error
 	if dbf.f != nil {
 		dbferr = dbf.f.Close()
@@ -76,11 +114,19 @@ func (dbf *DBF) Close() error {
 	if dbf.fptf != nil {
 		fpterr = dbf.fptf.Close()
 	}
+	var urlerr error
+	for _, c := range dbf.closers {
+		if err := c.Close(); err != nil && urlerr == nil {
+			urlerr = err
+		}
+	}
 	switch {
 	case dbferr != nil:
 		return fmt.Errorf("error closing DBF: %s", dbferr)
 	case fpterr != nil:
 		return fmt.Errorf("error closing FPT: %s", fpterr)
+	case urlerr != nil:
+		return fmt.Errorf("error closing URL reader: %s", urlerr)
 	default:
 		return nil
 	}
@@ -88,7 +134,11 @@ func (dbf *DBF) Close() error {
 
 func (dbf *DBF) prepareFPT(fptfile ReaderAtSeeker) error {
 
-	fptheader, err := readFPTHeader(fptfile)
+	readHeader := readFPTHeader
+	if dbf.smt {
+		readHeader = readSMTHeader
+	}
+	fptheader, err := readHeader(fptfile)
 	if err != nil {
 		return err
 	}
@@ -136,10 +186,9 @@ func (dbf *DBF) NumFields() uint16 {
 
 // FieldNames returnes a slice of all the fieldnames
 func (dbf *DBF) FieldNames() []string {
-	num := len(dbf.fields)
-	names := make([]string, num)
-	for i := 0; i < num; i++ {
-		names[i] = dbf.fields[i].FieldName()
+	names := make([]string, len(dbf.layout))
+	for i, l := range dbf.layout {
+		names[i] = l.name
 	}
 	return names
 }
@@ -147,8 +196,8 @@ func (dbf *DBF) FieldNames() []string {
 // FieldPos returns the zero-based field position of a fieldname
 // or -1 if not found.
 func (dbf *DBF) FieldPos(fieldname string) int {
-	for i := 0; i < len(dbf.fields); i++ {
-		if dbf.fields[i].FieldName() == fieldname {
+	for i, l := range dbf.layout {
+		if l.name == fieldname {
 			return i
 		}
 	}
@@ -158,6 +207,7 @@ func (dbf *DBF) FieldPos(fieldname string) int {
 // GoTo sets the internal record pointer to record recno (zero based).
 // Returns ErrEOF if at EOF and positions the pointer at lastRec+1.
 func (dbf *DBF) GoTo(recno uint32) error {
+	defer dbf.syncLinks()
 	if recno >= dbf.header.NumRec {
 		dbf.recpointer = dbf.header.NumRec
 		return ErrEOF
@@ -171,6 +221,7 @@ func (dbf *DBF) GoTo(recno uint32) error {
 // Returns ErrBOF is recpointer would be become negative and positions the pointer at 0.
 // Does not skip deleted records.
 func (dbf *DBF) Skip(offset int64) error {
+	defer dbf.syncLinks()
 	newval := int64(dbf.recpointer) + offset
 	if newval >= int64(dbf.header.NumRec) {
 		dbf.recpointer = dbf.header.NumRec
@@ -242,13 +293,21 @@ func (dbf *DBF) RecordToJSON(nrec uint32, trimspaces bool) ([]byte, error) {
 }
 
 // Field reads field number fieldpos at the record number the internal pointer is pointing to and returns its Go value
-func (dbf *DBF) Field(fieldpos int) (interface{}, error) {
+func (dbf *DBF) Field(fieldpos int) (val interface{}, err error) {
+	defer dbf.recoverHardened(&err, "record")
 	data, err := dbf.readField(dbf.recpointer, fieldpos)
 	if err != nil {
 		return nil, err
 	}
 	// fieldpos is valid or readField would have returned an error
-	return dbf.fieldDataToValue(data, fieldpos)
+	if null, err := dbf.fieldIsNull(dbf.recpointer, fieldpos); null || err != nil {
+		return nil, err
+	}
+	val, err = dbf.fieldValue(data, fieldpos)
+	if err != nil {
+		return val, err
+	}
+	return dbf.mapValue(data, val, fieldpos)
 }
 
 // EOF returns if the internal recordpointer is at EoF
@@ -266,19 +325,21 @@ func (dbf *DBF) readField(recordpos uint32, fieldpos int) ([]byte, error) {
 	if recordpos >= dbf.header.NumRec {
 		return nil, ErrEOF
 	}
-	if fieldpos < 0 || fieldpos > int(dbf.NumFields()) {
+	if fieldpos < 0 || fieldpos >= len(dbf.layout) {
 		return nil, ErrInvalidField
 	}
-	buf := make([]byte, dbf.fields[fieldpos].Len)
-	pos := int64(dbf.header.FirstRec) + (int64(recordpos) * int64(dbf.header.RecLen)) + int64(dbf.fields[fieldpos].Pos)
-	read, err := dbf.r.ReadAt(buf, pos)
-	if err != nil {
-		return buf, err
-	}
-	if read != int(dbf.fields[fieldpos].Len) {
-		return buf, ErrIncomplete
+	if dbf.decrypter != nil {
+		// encrypted records can only be decrypted as a whole
+		data, err := dbf.readRecord(recordpos)
+		if err != nil {
+			return nil, err
+		}
+		return dbf.layout[fieldpos].data(data), nil
 	}
-	return buf, nil
+	l := dbf.layout[fieldpos]
+	buf := make([]byte, l.end-l.start)
+	pos := int64(dbf.header.FirstRec) + (int64(recordpos) * int64(dbf.header.RecLen)) + int64(l.start)
+	return buf, dbf.readAt(buf, pos)
 }
 
 // Reads raw record data of one record at recordpos
@@ -287,21 +348,47 @@ func (dbf *DBF) readRecord(recordpos uint32) ([]byte, error) {
 		return nil, ErrEOF
 	}
 	buf := make([]byte, dbf.header.RecLen)
-	read, err := dbf.r.ReadAt(buf, int64(dbf.header.FirstRec)+(int64(recordpos)*int64(dbf.header.RecLen)))
-	if err != nil {
+	if err := dbf.readAt(buf, int64(dbf.header.FirstRec)+(int64(recordpos)*int64(dbf.header.RecLen))); err != nil {
 		return buf, err
 	}
-	if read != int(dbf.header.RecLen) {
-		return buf, ErrIncomplete
+	if dbf.decrypter != nil {
+		if err := dbf.decrypter.Decrypt(recordpos, buf); err != nil {
+			return buf, fmt.Errorf("decrypting record %d: %s", recordpos, err)
+		}
 	}
 	return buf, nil
 }
 
+// Reads len(buf) bytes at pos from the DBF file, short reads are retried in consistent read mode
+func (dbf *DBF) readAt(buf []byte, pos int64) error {
+	read, err := dbf.r.ReadAt(buf, pos)
+	for retry := 0; dbf.consistent != nil && retry < dbf.consistent.Retries && read != len(buf); retry++ {
+		// the record is probably still being written by another application
+		time.Sleep(dbf.consistent.Delay)
+		read, err = dbf.r.ReadAt(buf, pos)
+	}
+	if read == len(buf) {
+		// io.ReaderAt may return io.EOF when the read ends exactly at the end of the file
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return ErrIncomplete
+}
+
 // DeletedAt returns if the record at recordpos is deleted
 func (dbf *DBF) DeletedAt(recordpos uint32) (bool, error) {
 	if recordpos >= dbf.header.NumRec {
 		return false, ErrEOF
 	}
+	if dbf.decrypter != nil {
+		data, err := dbf.readRecord(recordpos)
+		if err != nil {
+			return false, err
+		}
+		return data[0] == 0x2A, nil
+	}
 	buf := make([]byte, 1)
 	read, err := dbf.r.ReadAt(buf, int64(dbf.header.FirstRec)+(int64(recordpos)*int64(dbf.header.RecLen)))
 	if err != nil {
@@ -320,9 +407,10 @@ func (dbf *DBF) Deleted() (bool, error) {
 
 // Converts raw recorddata to a Record struct.
 // If the data points to a memo (FPT) file this file is also read.
-func (dbf *DBF) bytesToRecord(data []byte) (*Record, error) {
+func (dbf *DBF) bytesToRecord(data []byte) (rec *Record, err error) {
+	defer dbf.recoverHardened(&err, "record")
 
-	rec := new(Record)
+	rec = &Record{fields: dbf.fields, layout: dbf.layout, jsonOpts: dbf.jsonOpts, raw: data}
 
 	// a record should start with te delete flag, a space (0x20) or * (0x2A)
 	rec.Deleted = data[0] == 0x2A
@@ -331,17 +419,23 @@ func (dbf *DBF) bytesToRecord(data []byte) (*Record, error) {
 	}
 
 	rec.data = make([]interface{}, dbf.NumFields())
+	flags := dbf.nullFlags(data)
 
-	offset := uint16(1) // deleted flag already read
-	for i := 0; i < len(rec.data); i++ {
-		fieldinfo := dbf.fields[i]
-		val, err := dbf.fieldDataToValue(data[offset:offset+uint16(fieldinfo.Len)], i)
-		if err != nil {
+	for i, l := range dbf.layout {
+		if isNull(flags, l.null) {
+			// null values are nil
+			continue
+		}
+		val, err := dbf.fieldDataToValue(l.data(data), i)
+		if dbf.acceptInvalidNumber(err) {
+			rec.invalid = append(rec.invalid, i)
+		} else if err != nil {
+			return rec, err
+		}
+		if val, err = dbf.mapValue(l.data(data), val, i); err != nil {
 			return rec, err
 		}
 		rec.data[i] = val
-
-		offset += uint16(fieldinfo.Len)
 	}
 
 	return rec, nil
@@ -353,56 +447,75 @@ func (dbf *DBF) bytesToRecord(data []byte) (*Record, error) {
 func (dbf *DBF) fieldDataToValue(raw []byte, fieldpos int) (interface{}, error) {
 	// Not all field types have been implemented because we don't use them in our DBFs
 	// Extend this function if needed
-	if fieldpos < 0 || len(dbf.fields) < fieldpos {
+	if fieldpos < 0 || fieldpos >= len(dbf.fields) {
 		return nil, ErrInvalidField
 	}
 
-	switch dbf.fields[fieldpos].FieldType() {
+	switch dbf.fields[fieldpos].Type {
 	default:
 		return nil, fmt.Errorf("unsupported fieldtype: %s", dbf.fields[fieldpos].FieldType())
-	case "M":
+	case 'M':
 		// M values contain the address in the FPT file from where to read data
-		memo, isText, err := dbf.parseMemo(raw)
+		memo, isText, err := dbf.parseMemo(raw, dbf.layout[fieldpos].ascii)
 		if isText {
 			return string(memo), err
 		}
 		return memo, err
-	case "C":
+	case 'G', 'P', 'W':
+		// G (general), P (picture) and W (blob) values are stored in the FPT file like memos,
+		// but they are always binary so no charset conversion is done
+		memo, _, err := dbf.readFPT(raw)
+		if err != nil {
+			return []byte{}, err
+		}
+		return memo, nil
+	case 'C':
 		// C values are stored as strings, the returned string is not trimmed
+		if dbf.layout[fieldpos].ascii {
+			return string(raw), nil
+		}
 		return dbf.toUTF8String(raw)
-	case "I":
+	case 'I':
 		// I values are stored as numeric values
 		return int32(binary.LittleEndian.Uint32(raw)), nil
-	case "B":
+	case 'B':
 		// B (double) values are stored as numeric values
 		return math.Float64frombits(binary.LittleEndian.Uint64(raw)), nil
-	case "D":
+	case 'D':
 		// D values are stored as string in format YYYYMMDD, convert to time.Time
 		return dbf.parseDate(raw)
-	case "T":
+	case 'T':
 		// T values are stores as two 4 byte integers
 		//  integer one is the date in julian format
 		//  integer two is the number of milliseconds since midnight
 		// Above info from http://fox.wikis.com/wc.dll?Wiki~DateTime
 		return dbf.parseDateTime(raw)
-	case "L":
+	case 'L':
 		// L values are stored as strings T or F, we only check for T, the rest is false...
 		return string(raw) == "T", nil
-	case "V":
-		// V values just return the raw value
+	case 'V', '0':
+		// V values and the _NullFlags system field just return the raw value
 		return raw, nil
-	case "Y":
+	case 'Y':
 		// Y values are currency values stored as ints with 4 decimal places
-		return float64(float64(binary.LittleEndian.Uint64(raw)) / 10000), nil
-	case "N":
+		return float64(int64(binary.LittleEndian.Uint64(raw))) / 10000, nil
+	case 'N':
 		// N values are stored as string values, if no decimals return as int64, if decimals treat as float64
 		if dbf.fields[fieldpos].Decimals == 0 {
-			return dbf.parseNumericInt(raw)
+			val, err := dbf.parseNumericInt(raw)
+			if err != nil {
+				return dbf.invalidNumber(raw, fieldpos)
+			}
+			return val, nil
 		}
 		fallthrough // same as "F"
-	case "F":
+	case 'F':
 		// F values are stored as string values
-		return dbf.parseFloat(raw)
+		val, err := dbf.parseFloat(raw)
+		if err != nil {
+			return dbf.invalidNumber(raw, fieldpos)
+		}
+		return val, nil
 	}
 }
 
@@ -415,12 +528,12 @@ func (dbf *DBF) toUTF8String(raw []byte) (string, error) {
 	return string(utf8), nil
 }
 
-func (dbf *DBF) parseMemo(raw []byte) ([]byte, bool, error) {
+func (dbf *DBF) parseMemo(raw []byte, ascii bool) ([]byte, bool, error) {
 	memo, isText, err := dbf.readFPT(raw)
 	if err != nil {
 		return []byte{}, false, err
 	}
-	if isText {
+	if isText && !ascii {
 		memo, err = dbf.dec.Decode(memo)
 		if err != nil {
 			return []byte{}, false, err
@@ -430,45 +543,146 @@ func (dbf *DBF) parseMemo(raw []byte) ([]byte, bool, error) {
 }
 
 func (dbf *DBF) parseDate(raw []byte) (time.Time, error) {
-	if string(raw) == strings.Repeat(" ", 8) {
+	if len(raw) == 8 && bytes.Equal(raw, blankDate) {
 		return time.Time{}, nil
 	}
+	// fast path for valid dates, time.Parse allocates and returns the error for invalid dates
+	if len(raw) == 8 {
+		y, okY := parseDigits(raw[0:4])
+		m, okM := parseDigits(raw[4:6])
+		d, okD := parseDigits(raw[6:8])
+		if okY && okM && okD && m >= 1 && m <= 12 && d >= 1 && d <= daysIn(m, y) {
+			return julianTime(jd.YMD2J(y, m, d), 0), nil
+		}
+	}
 	return time.Parse("20060102", string(raw))
 }
 
+// blankDate is the value of an empty D field
+var blankDate = []byte("        ")
+
+// parseDigits parses a short unsigned number, ok is false when b contains anything but digits
+func parseDigits(b []byte) (n int, ok bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
+}
+
+// daysIn returns the number of days in month m of year y
+func daysIn(m, y int) int {
+	switch m {
+	case 2:
+		if y%4 == 0 && (y%100 != 0 || y%400 == 0) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
+
+// The Julian day numbers of the first and last day time.Parse accepts, and of the Unix epoch
+var (
+	julianMin   = jd.YMD2J(0, 1, 1)
+	julianMax   = jd.YMD2J(9999, 12, 31)
+	julianEpoch = jd.YMD2J(1970, 1, 1)
+)
+
+// julianTime returns the UTC time of Julian day number j plus mSec milliseconds,
+// this is the same as time.Date with the result of jd.J2YMD but a lot cheaper
+func julianTime(j, mSec int) time.Time {
+	sec := int64(j-julianEpoch)*86400 + int64(mSec/1000)
+	return time.Unix(sec, int64(mSec%1000)*int64(time.Millisecond)).UTC()
+}
+
 func (dbf *DBF) parseDateTime(raw []byte) (time.Time, error) {
 	if len(raw) != 8 {
 		return time.Time{}, ErrInvalidField
 	}
-	julDat := int(binary.LittleEndian.Uint32(raw[:4]))
-	mSec := int(binary.LittleEndian.Uint32(raw[4:]))
-	// determine year, month, day
-	y, m, d := jd.J2YMD(julDat)
-	if y < 0 || y > 9999 {
-		// TODO some dbf files seem to contain invalid dates, not sure if we want treat this an error until I know what is going on
-		return time.Time{}, nil
+	// TODO some dbf files seem to contain invalid dates, these are returned as the zero time,
+	// not sure if we want treat this an error until I know what is going on
+	t := JulianToTime(binary.LittleEndian.Uint32(raw[:4]), binary.LittleEndian.Uint32(raw[4:]))
+	if dbf.zone != nil && !t.IsZero() {
+		return dbf.zone.convert(t), nil
 	}
-	// calculate whole seconds and use the remainder as nanosecond resolution
-	nSec := mSec / 1000
-	mSec = mSec - (nSec * 1000)
-	// create time using ymd and nanosecond timestamp
-	return time.Date(y, time.Month(m), d, 0, 0, nSec, mSec*int(time.Millisecond), time.UTC), nil
+	return t, nil
 }
 
 func (dbf *DBF) parseNumericInt(raw []byte) (int64, error) {
-	trimmed := strings.TrimSpace(string(raw))
+	trimmed := bytes.TrimSpace(raw)
 	if len(trimmed) == 0 {
 		return int64(0), nil
 	}
-	return strconv.ParseInt(trimmed, 10, 64)
+	// fast path without converting to a string
+	if mantissa, decimals, ok := parseDecimal(trimmed); ok && decimals < 0 {
+		return mantissa, nil
+	}
+	return strconv.ParseInt(string(trimmed), 10, 64)
 }
 
 func (dbf *DBF) parseFloat(raw []byte) (float64, error) {
-	trimmed := strings.TrimSpace(string(raw))
+	trimmed := bytes.TrimSpace(raw)
 	if len(trimmed) == 0 {
 		return float64(0.0), nil
 	}
-	return strconv.ParseFloat(strings.TrimSpace(string(trimmed)), 64)
+	// fast path: a mantissa of up to 15 digits and a power of ten are both exact as float64,
+	// so one division is correctly rounded, just like strconv.ParseFloat
+	if mantissa, decimals, ok := parseDecimal(trimmed); ok && mantissa < 1<<53 && mantissa > -1<<53 {
+		if decimals <= 0 {
+			return float64(mantissa), nil
+		}
+		return float64(mantissa) / pow10[decimals], nil
+	}
+	return strconv.ParseFloat(string(trimmed), 64)
+}
+
+// pow10 are the powers of ten which are exact as float64
+var pow10 = [...]float64{1, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11, 1e12, 1e13, 1e14, 1e15}
+
+// parseDecimal parses a number with an optional sign and decimal point of up to 15 digits.
+// decimals is the number of digits after the point, -1 when there is no point.
+// ok is false for anything else, which should be parsed by strconv.
+func parseDecimal(b []byte) (mantissa int64, decimals int, ok bool) {
+	neg := false
+	switch b[0] {
+	case '-':
+		neg = true
+		b = b[1:]
+	case '+':
+		b = b[1:]
+	}
+	decimals = -1
+	digits := 0
+	for _, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			mantissa = mantissa*10 + int64(c-'0')
+			digits++
+			if decimals >= 0 {
+				decimals++
+			}
+		case c == '.' && decimals < 0:
+			decimals = 0
+		default:
+			return 0, 0, false
+		}
+	}
+	if digits == 0 || digits > 15 || (neg && mantissa == 0) {
+		// strconv keeps the sign of negative zero
+		return 0, 0, false
+	}
+	if neg {
+		mantissa = -mantissa
+	}
+	return mantissa, decimals, true
 }
 
 // Reads one or more blocks from the FPT file, called for each memo field.
@@ -478,39 +692,135 @@ func (dbf *DBF) readFPT(blockdata []byte) ([]byte, bool, error) {
 	if dbf.fptr == nil {
 		return nil, false, ErrNoFPTFile
 	}
+	if dbf.smt {
+		return dbf.readSMT(blockdata)
+	}
 
 	// Determine the block number
-	block := binary.LittleEndian.Uint32(blockdata)
-	// The position in the file is blocknumber*blocksize
-	if _, err := dbf.fptr.Seek(int64(dbf.fptheader.BlockSize)*int64(block), 0); err != nil {
+	block, err := memoBlock(blockdata)
+	if err != nil {
 		return nil, false, err
 	}
-
-	// Read the memo block header, instead of reading into a struct using binary.Read we just read the two
-	// uints in one buffer and then convert, this saves seconds for large DBF files with many memo fields
-	// as it avoids using the reflection in binary.Read
-	hbuf := make([]byte, 8)
-	_, err := dbf.fptr.Read(hbuf)
+	if block == 0 {
+		// block 0 is the memo file header, it means no memo: FoxPro 2.x stores spaces, Visual FoxPro and the Writer 0
+		return []byte{}, true, nil
+	}
+	sign, buf, err := dbf.readMemoBlock(block)
+	// the SIx driver uses its own type for text
+	isText := sign == memoText || sign == sixCharacter
 	if err != nil {
-		return nil, false, err
+		return buf, isText, err
+	}
+	if len(buf) == 0 {
+		// No data according to block header? Not sure if this should be an error instead
+		return []byte{}, isText, nil
+	}
+	return dbf.checkMemoBlock(block, sign, buf, isText)
+}
+
+// readMemoBlock returns the block type and the undecoded data of the memo which starts at block
+func (dbf *DBF) readMemoBlock(block uint32) (uint32, []byte, error) {
+	// The position in the file is blocknumber*blocksize
+	pos := int64(dbf.fptheader.BlockSize) * int64(block)
+
+	// Read the first block at once, with ReadAt instead of Seek and Read, most memos fit in the first block
+	// so they are read in one call. The memo block header is not read into a struct using binary.Read,
+	// this saves seconds for large DBF files with many memo fields as it avoids using the reflection in binary.Read
+	size := int(dbf.fptheader.BlockSize)
+	if size < 8 {
+		size = 8
+	}
+	first := make([]byte, size)
+	read, err := dbf.fptr.ReadAt(first, pos)
+	if read < 8 {
+		if err != nil && err != io.EOF {
+			return 0, nil, err
+		}
+		return 0, nil, ErrIncomplete
 	}
-	sign := binary.BigEndian.Uint32(hbuf[:4])
-	leng := binary.BigEndian.Uint32(hbuf[4:])
+	sign := binary.BigEndian.Uint32(first[:4])
+	leng := binary.BigEndian.Uint32(first[4:])
 
 	if leng == 0 {
-		// No data according to block header? Not sure if this should be an error instead
-		return []byte{}, sign == 1, nil
+		return sign, []byte{}, nil
+	}
+	if uint64(leng) > uint64(maxInt) {
+		return sign, nil, &LimitError{Limit: "memo length", Value: uint64(leng), Max: uint64(maxInt)}
+	}
+	if err := dbf.checkMemoLength(leng); err != nil {
+		return sign, nil, err
 	}
-	// Now read the actual data
+	if leng > memoPreallocLimit {
+		buf, err := dbf.readLargeMemo(pos+8, leng)
+		return sign, buf, err
+	}
+	// Now read the rest of the data
 	buf := make([]byte, leng)
-	read, err := dbf.fptr.Read(buf)
+	n := copy(buf, first[8:read])
+	if n < len(buf) {
+		rest, err := dbf.fptr.ReadAt(buf[n:], pos+8+int64(n))
+		if n+rest != len(buf) {
+			if err != nil && err != io.EOF {
+				return sign, buf, err
+			}
+			return sign, buf, ErrIncomplete
+		}
+	}
+	return sign, buf, nil
+}
+
+// checkMemoBlock returns the data of a memo block with a known block type, other block types are decoded
+func (dbf *DBF) checkMemoBlock(block, sign uint32, buf []byte, isText bool) ([]byte, bool, error) {
+	switch {
+	case block == 0, sign == memoPicture, sign == memoText, sign == memoObject, sign == sixCharacter:
+		return buf, isText, nil
+	default:
+		// third-party drivers use other block types for compressed memos, see memotype.go
+		return decodeMemoBlock(sign, buf)
+	}
+}
+
+// memoPreallocLimit is the largest memo for which the buffer is allocated at once,
+// longer memos are read in parts so a corrupt memo length does not allocate a huge buffer
+const memoPreallocLimit = 1 << 20
+
+// readLargeMemo reads leng bytes from the memo file at pos, the buffer grows while the data is read.
+// When the file ends before leng bytes are read the data read so far is returned with ErrIncomplete.
+func (dbf *DBF) readLargeMemo(pos int64, leng uint32) ([]byte, error) {
+	buf, err := io.ReadAll(io.NewSectionReader(dbf.fptr, pos, int64(leng)))
 	if err != nil {
-		return buf, false, err
+		return buf, err
 	}
-	if read != int(leng) {
-		return buf, sign == 1, ErrIncomplete
+	if int64(len(buf)) != int64(leng) {
+		return buf, ErrIncomplete
 	}
-	return buf, sign == 1, nil
+	return buf, nil
+}
+
+// memoBlock returns the block number of a memo field. Visual FoxPro stores the block number as a 4 byte integer,
+// FoxPro 2.x as a right aligned number of 10 characters.
+func memoBlock(raw []byte) (uint32, error) {
+	if len(raw) == 4 {
+		return binary.LittleEndian.Uint32(raw), nil
+	}
+	trimmed := strings.TrimSpace(string(raw))
+	if len(trimmed) == 0 {
+		return 0, nil
+	}
+	block, err := strconv.ParseUint(trimmed, 10, 32)
+	if err != nil {
+		return 0, ErrInvalidField
+	}
+	return uint32(block), nil
+}
+
+// putMemoBlock stores a block number in a memo field in the same format as memoBlock reads it
+func putMemoBlock(raw []byte, block uint32) {
+	if len(raw) == 4 {
+		binary.LittleEndian.PutUint32(raw, block)
+		return
+	}
+	copy(raw, fmt.Sprintf("%*d", len(raw), block))
 }
 
 // DBFHeader is the struct containing all raw DBF header fields.
@@ -539,12 +849,32 @@ func (h *DBFHeader) Modified() time.Time {
 // This is the fastest way to determine the number of records in the file.
 // Note: when OpenFile is used the fields have already been parsed so it is better to call DBF.NumFields in that case.
 func (h *DBFHeader) NumFields() uint16 {
-	return uint16((h.FirstRec - 296) / 32)
+	return uint16((int(h.FirstRec) - h.headerSize()) / 32)
 }
 
 // FileSize eturns the calculated file size based on the header info
 func (h *DBFHeader) FileSize() int64 {
-	return 296 + int64(h.NumFields()*32) + int64(h.NumRec*uint32(h.RecLen))
+	return int64(h.headerSize()) + int64(h.NumFields())*32 + int64(h.NumRec)*int64(h.RecLen)
+}
+
+// HasMemo returns if the table has a memo file, according to the table flags or, for FoxPro 2.x and SIx tables,
+// the file version
+func (h *DBFHeader) HasMemo() bool {
+	return h.TableFlags&0x02 != 0 || h.FileVersion == 0xF5 || h.FileVersion == 0xE5
+}
+
+// HasCDX returns if the table has a structural index, a CDX file with the name of the table
+func (h *DBFHeader) HasCDX() bool {
+	return h.TableFlags&0x01 != 0
+}
+
+// headerSize returns the size of the header without the field descriptors: 32 bytes for the header, one byte for
+// the terminator and, for Visual FoxPro tables, the backlink to the database container
+func (h *DBFHeader) headerSize() int {
+	if LookupFileVersion(h.FileVersion).VisualFoxPro {
+		return 32 + 1 + backlinkSize
+	}
+	return 32 + 1
 }
 
 // FieldHeader contains the raw field info structure from the DBF header.
@@ -575,6 +905,15 @@ func (f *FieldHeader) FieldType() string {
 type Record struct {
 	Deleted bool
 	data    []interface{}
+
+	// fields and options of the table the record was read from, used by MarshalJSON
+	fields   []FieldHeader
+	layout   []fieldLayout
+	jsonOpts JSONOptions
+
+	raw []byte // raw record data, used by Hash
+
+	invalid []int // positions of the fields with an invalid number replaced by the policy, see numeric.go
 }
 
 // Field gets a fields value by field pos (index)
@@ -585,6 +924,16 @@ func (r *Record) Field(pos int) (interface{}, error) {
 	return r.data[pos], nil
 }
 
+// SetField sets the value of the field at pos, for example in a Pipeline Map function.
+// The value is not checked against the field type and the raw data used by Hash is not changed.
+func (r *Record) SetField(pos int, val interface{}) error {
+	if pos < 0 || pos >= len(r.data) {
+		return ErrInvalidField
+	}
+	r.data[pos] = val
+	return nil
+}
+
 // FieldSlice gets all fields as a slice
 func (r *Record) FieldSlice() []interface{} {
 	return r.data
@@ -595,31 +944,49 @@ func (r *Record) FieldSlice() []interface{} {
 // should call DBF.Close() to close the embedded file handle(s).
 // The Decoder is used for charset translation to UTF8, see decoder.go
 func OpenFile(filename string, dec Decoder) (*DBF, error) {
+	return OpenFileShared(filename, dec, ShareReadWrite)
+}
+
+// OpenFileShared is OpenFile with the share mode used to open the files on Windows, see share.go
+func OpenFileShared(filename string, dec Decoder, mode ShareMode) (*DBF, error) {
+	return openFile(filename, dec, mode, nil, false)
+}
+
+// openFile opens a table from disk, the Decrypter is only used for encrypted tables.
+// With write the files are opened for reading and writing, see OpenFileForUpdate.
+func openFile(filename string, dec Decoder, mode ShareMode, decrypter Decrypter, write bool) (*DBF, error) {
 
 	filename = filepath.Clean(filename)
 
-	dbffile, err := os.Open(filename)
+	dbffile, err := openShared(filename, mode, write)
 	if err != nil {
 		return nil, err
 	}
 
-	dbf, err := prepareDBF(dbffile, dec)
+	dbf, err := prepareDBF(dbffile, dec, decrypter)
 	if err != nil {
 		return nil, err
 	}
 
 	dbf.f = dbffile
+	dbf.shareMode = mode
 
 	// Check if there is an FPT according to the header
 	// If there is we will try to open it in the same dir (using the same filename and case)
 	// If the FPT file does not exist an error is returned
-	if (dbf.header.TableFlags & 0x02) != 0 {
-		ext := filepath.Ext(filename)
-		fptext := ".fpt"
-		if strings.ToUpper(ext) == ext {
-			fptext = ".FPT"
+	// SMT memo files are used by the SIx driver of Clipper and Harbour, see smt.go
+	if dbf.header.HasMemo() {
+		memoname := memoFileName(filename)
+		if dbf.smt {
+			memoname = smtFileName(filename)
+		}
+		fptfile, err := openShared(memoname, mode, write)
+		if os.IsNotExist(err) && !dbf.smt {
+			// Harbour can also create tables with other file versions and an SMT file
+			if smtfile, smterr := openShared(smtFileName(filename), mode, write); smterr == nil {
+				fptfile, err, dbf.smt = smtfile, nil, true
+			}
 		}
-		fptfile, err := os.Open(strings.TrimSuffix(filename, ext) + fptext)
 		if err != nil {
 			return nil, err
 		}
@@ -639,13 +1006,18 @@ func OpenFile(filename string, dec Decoder) (*DBF, error) {
 // The fptfile parameter is optional, but if the DBF header has the FPT flag set, the fptfile must be provided.
 // The Decoder is used for charset translation to UTF8, see decoder.go
 func OpenStream(dbffile, fptfile ReaderAtSeeker, dec Decoder) (*DBF, error) {
+	return openStream(dbffile, fptfile, dec, nil)
+}
+
+// openStream opens a table from streams, the Decrypter is only used for encrypted tables
+func openStream(dbffile, fptfile ReaderAtSeeker, dec Decoder, decrypter Decrypter) (*DBF, error) {
 
-	dbf, err := prepareDBF(dbffile, dec)
+	dbf, err := prepareDBF(dbffile, dec, decrypter)
 	if err != nil {
 		return nil, err
 	}
 
-	if (dbf.header.TableFlags & 0x02) != 0 {
+	if dbf.header.HasMemo() {
 		if fptfile == nil {
 			return nil, ErrNoFPTFile
 		}
@@ -658,30 +1030,87 @@ func OpenStream(dbffile, fptfile ReaderAtSeeker, dec Decoder) (*DBF, error) {
 	return dbf, nil
 }
 
-func prepareDBF(dbffile ReaderAtSeeker, dec Decoder) (*DBF, error) {
+// fixedFieldLength is the length of field types which are stored as binary numbers
+var fixedFieldLength = map[byte]byte{'I': 4, 'B': 8, 'Y': 8, 'T': 8, 'D': 8}
+
+func prepareDBF(dbffile ReaderAtSeeker, dec Decoder, decrypter Decrypter) (*DBF, error) {
 
 	header, err := readDBFHeader(dbffile)
 	if err != nil {
 		return nil, err
 	}
 
+	// Encrypted tables can only be read with a Decrypter, see encrypt.go
+	if header.Encrypted() && decrypter == nil {
+		return nil, ErrEncrypted
+	}
+
 	// Check if the fileversion flag is expected, expand validFileVersion if needed
 	if err := ValidFileVersionFunc(header.FileVersion); err != nil {
 		return nil, err
 	}
 
+	// Check the header against the file size before anything is allocated, corrupt files can contain any value
+	size, err := dbffile.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if header.FirstRec < 32+1 || int64(header.FirstRec) > size {
+		return nil, fmt.Errorf("invalid header length %d for a file of %d bytes", header.FirstRec, size)
+	}
+
 	// Read fieldinfo
-	fields, err := readHeaderFields(dbffile)
+	fields, err := readHeaderFields(dbffile, int64(header.FirstRec))
 	if err != nil {
 		return nil, err
 	}
 
+	// FoxPro 2.x does not store the displacement of the fields in the record, so calculate it
+	if !LookupFileVersion(header.FileVersion).VisualFoxPro {
+		pos := uint32(1)
+		for i := range fields {
+			fields[i].Pos = pos
+			pos += uint32(fields[i].Len)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("invalid header, the table has no fields")
+	}
+	if header.RecLen < 1 {
+		return nil, fmt.Errorf("invalid record length %d", header.RecLen)
+	}
+	// binary numbers are decoded with their fixed size, a shorter field would be read beyond its data
+	for i := range fields {
+		if leng, ok := fixedFieldLength[fields[i].Type]; ok && fields[i].Len < leng {
+			return nil, fmt.Errorf("invalid length %d of field %s of type %s, it must be at least %d", fields[i].Len, fields[i].FieldName(), fields[i].FieldType(), leng)
+		}
+	}
+	layout := computeLayout(fields)
+	if layout[len(layout)-1].end > int(header.RecLen) {
+		return nil, fmt.Errorf("invalid record length %d, the fields need %d bytes", header.RecLen, layout[len(layout)-1].end)
+	}
+
+	// The last record may be incomplete while it is written (see ErrIncomplete), the records before it must be in the file
+	if header.NumRec > 0 {
+		if start := int64(header.FirstRec) + int64(header.NumRec-1)*int64(header.RecLen); start >= size {
+			need := int64(header.FirstRec) + int64(header.NumRec)*int64(header.RecLen)
+			return nil, fmt.Errorf("invalid record count %d, %d records need %d bytes, the file has %d bytes", header.NumRec, header.NumRec, need, size)
+		}
+	}
+
 	dbf := &DBF{
-		header: header,
-		r:      dbffile,
-		fields: fields,
-		dec:    dec,
+		header:    header,
+		r:         dbffile,
+		fields:    fields,
+		layout:    layout,
+		dec:       dec,
+		nullflags: nullFlagsPos(fields),
 	}
+	if header.Encrypted() {
+		dbf.decrypter = decrypter
+	}
+	dbf.smt = LookupFileVersion(header.FileVersion).MemoFile == "SMT"
 
 	return dbf, nil
 }
@@ -703,19 +1132,22 @@ func validFileVersion(version byte) error {
 	switch version {
 	default:
 		return fmt.Errorf("untested DBF file version: %d (%x hex), try overriding ValidFileVersionFunc to open this file anyway", version, version)
-	case 0x30, 0x31:
+	case 0x30, 0x31, 0xF5, 0xE5:
 		return nil
 	}
 }
 
 // Reads fieldinfo from DBF header, starting at pos 32.
-// Reads fields until it finds the Header record terminator (0x0D).
-func readHeaderFields(r io.ReadSeeker) ([]FieldHeader, error) {
+// Reads fields until it finds the Header record terminator (0x0D), which must be before headerLen.
+func readHeaderFields(r io.ReadSeeker, headerLen int64) ([]FieldHeader, error) {
 	fields := make([]FieldHeader, 0)
 
 	offset := int64(32)
 	b := make([]byte, 1)
 	for {
+		if offset >= headerLen {
+			return nil, fmt.Errorf("no field terminator within the header length %d", headerLen)
+		}
 		// Check if we are at 0x0D by reading one byte ahead
 		if _, err := r.Seek(offset, 0); err != nil {
 			return nil, err
@@ -768,3 +1200,21 @@ func readFPTHeader(r io.ReadSeeker) (*FPTHeader, error) {
 func SetValidFileVersionFunc(f func(version byte) error) {
 	ValidFileVersionFunc = f
 }
+
+// memoFileName returns the name of the memo file belonging to a table, in the same case as the extension.
+// Tables have an FPT file, database containers (DBC) a DCT file.
+func memoFileName(filename string) string {
+	if strings.EqualFold(filepath.Ext(filename), ".dbc") {
+		return replaceExt(filename, ".dct")
+	}
+	return replaceExt(filename, ".fpt")
+}
+
+// replaceExt replaces the extension of filename by ext, in upper case if the extension of filename is upper case
+func replaceExt(filename, ext string) string {
+	old := filepath.Ext(filename)
+	if strings.ToUpper(old) == old {
+		ext = strings.ToUpper(ext)
+	}
+	return strings.TrimSuffix(filename, old) + ext
+}
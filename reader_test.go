@@ -2,10 +2,12 @@ package dbf
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
@@ -277,7 +279,7 @@ func TestRecordToJson(t *testing.T) {
 	want1 := `{"BOOL":true,"COMP_NAME":"TEST2","COMP_OS":"Windows XP","DATUM":"2015-02-03T00:00:00Z","FLOAT":1.23456789e+08,"ID":2,"ID_NR":6425886,"MELDING":"Tësting wíth éncôdings!","NIVEAU":1,"NUMBER":1.2345678999e+08,"SOORT":12345678,"TIJD":"12:00","USERNR":-600}`
 	want12 := `{"BOOL":true,"COMP_NAME":"TEST2","COMP_OS":"Windows XP","DATUM":"2015-02-03T00:00:00Z","FLOAT":123456789,"ID":2,"ID_NR":6425886,"MELDING":"Tësting wíth éncôdings!","NIVEAU":1,"NUMBER":123456789.99,"SOORT":12345678,"TIJD":"12:00","USERNR":-600}`
 
-	want2 := `{"BOOL":true,"COMP_NAME":"                                        ","COMP_OS":"                    ","DATUM":"0001-01-01T00:00:00Z","FLOAT":0,"ID":4,"ID_NR":0,"MELDING":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA==","NIVEAU":0,"NUMBER":0,"SOORT":0,"TIJD":"        ","USERNR":0}`
+	want2 := `{"BOOL":true,"COMP_NAME":"                                        ","COMP_OS":"                    ","DATUM":"0001-01-01T00:00:00Z","FLOAT":0,"ID":4,"ID_NR":0,"MELDING":"","NIVEAU":0,"NUMBER":0,"SOORT":0,"TIJD":"        ","USERNR":0}`
 
 	err := testDbf.GoTo(3)
 	if err != nil {
@@ -516,6 +518,88 @@ func TestDkeza(t *testing.T) {
 	t.Logf("DTIME: %s", dtime)
 }
 
+// foxPro2Table returns a FoxPro 2.x (0xF5) table and its memo file, with a C and an M field, the field displacements
+// are not stored and the memo block numbers are stored as text
+func foxPro2Table() ([]byte, []byte) {
+	dbf := make([]byte, 32, 128)
+	dbf[0] = 0xF5
+	binary.LittleEndian.PutUint32(dbf[4:], 2)
+	binary.LittleEndian.PutUint16(dbf[8:], 32+2*32+1)
+	binary.LittleEndian.PutUint16(dbf[10:], 1+10+10)
+	for _, f := range []struct {
+		name string
+		typ  byte
+	}{{"NAME", 'C'}, {"NOTES", 'M'}} {
+		desc := make([]byte, 32)
+		copy(desc, f.name)
+		desc[11] = f.typ
+		desc[16] = 10
+		dbf = append(dbf, desc...)
+	}
+	dbf = append(dbf, 0x0D)
+	dbf = append(dbf, " Jan                8"...)
+	dbf = append(dbf, " Piet                "...)
+	dbf = append(dbf, 0x1A)
+
+	fpt := make([]byte, 512+64)
+	binary.BigEndian.PutUint32(fpt[0:], 9)
+	binary.BigEndian.PutUint16(fpt[6:], 64)
+	binary.BigEndian.PutUint32(fpt[512:], 1)
+	binary.BigEndian.PutUint32(fpt[516:], 5)
+	copy(fpt[520:], "hello")
+	return dbf, fpt
+}
+
+func TestFoxPro2x(t *testing.T) {
+	data, memo := foxPro2Table()
+	dbf, err := OpenStream(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dbf.Header().HasMemo() {
+		t.Error("Want a memo file for version 0xF5")
+	}
+	if n := dbf.Header().NumFields(); n != 2 {
+		t.Errorf("Want 2 fields from the header, have %d", n)
+	}
+	if size := dbf.Header().FileSize(); size != int64(len(data)-1) {
+		t.Errorf("Want file size %d, have %d", len(data)-1, size)
+	}
+	if pos := dbf.Fields()[1].Pos; pos != 11 {
+		t.Errorf("Want NOTES at position 11, have %d", pos)
+	}
+
+	want := [][]interface{}{{"Jan       ", "hello"}, {"Piet      ", ""}}
+	for recno, fields := range want {
+		rec, err := dbf.RecordAt(uint32(recno))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, val := range fields {
+			if rec.FieldSlice()[i] != val {
+				t.Errorf("Record %d field %d: want %q, have %q", recno, i, val, rec.FieldSlice()[i])
+			}
+		}
+		notes, err := dbf.readField(uint32(recno), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, err := memoBlock(notes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw := make([]byte, 10)
+		putMemoBlock(raw, block)
+		if block != 0 && !bytes.Equal(raw, notes) {
+			t.Errorf("Want memo pointer %q, have %q", notes, raw)
+		}
+	}
+
+	if _, err := OpenStream(bytes.NewReader(data), nil, new(Win1250Decoder)); err != ErrNoFPTFile {
+		t.Errorf("Want ErrNoFPTFile, have %v", err)
+	}
+}
+
 func TestSetValidFileVersionFunc(t *testing.T) {
 
 	// open the file without overriding the validation function
@@ -602,3 +686,154 @@ func BenchmarkRecordToJSONWithTrim(b *testing.B) {
 		}
 	}
 }
+
+// TestGeneralField tests reading a G field from an in-memory DBF/FPT pair
+func TestGeneralField(t *testing.T) {
+
+	// DBF with one G field
+	dbfbytes := make([]byte, 328)
+	dbfbytes[0] = 0x30
+	binary.LittleEndian.PutUint32(dbfbytes[4:], 1)   // 1 record
+	binary.LittleEndian.PutUint16(dbfbytes[8:], 328) // first record
+	binary.LittleEndian.PutUint16(dbfbytes[10:], 5)  // record length
+	dbfbytes[28] = 0x02                              // has FPT
+	copy(dbfbytes[32:], "PIC")
+	dbfbytes[43] = 'G'
+	dbfbytes[44] = 1 // pos
+	dbfbytes[48] = 4 // len
+	dbfbytes[64] = 0x0D
+	record := []byte{0x20, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(record[1:], 8)
+	dbfbytes = append(dbfbytes, record...)
+
+	// FPT with 64 byte blocks and the binary data in block 8
+	fptbytes := make([]byte, 512+64)
+	binary.BigEndian.PutUint32(fptbytes[0:], 9)
+	binary.BigEndian.PutUint16(fptbytes[6:], 64)
+	binary.BigEndian.PutUint32(fptbytes[512:], 0) // binary
+	binary.BigEndian.PutUint32(fptbytes[516:], 4)
+	copy(fptbytes[520:], "\x89PNG")
+
+	dbf, err := OpenStream(bytes.NewReader(dbfbytes), bytes.NewReader(fptbytes), new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	val, err := dbf.Field(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, ok := val.([]byte)
+	if !ok {
+		t.Fatalf("G field should be of type []byte, have %T", val)
+	}
+	if string(data) != "\x89PNG" {
+		t.Errorf("Want G value %q, have %q", "\x89PNG", data)
+	}
+}
+
+func TestPathologicalHeaders(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(data []byte) []byte
+	}{
+		{"header length beyond file", func(data []byte) []byte {
+			binary.LittleEndian.PutUint16(data[8:], 0xFFFF)
+			return data
+		}},
+		{"header length too small", func(data []byte) []byte {
+			binary.LittleEndian.PutUint16(data[8:], 0)
+			return data
+		}},
+		{"no field terminator", func(data []byte) []byte {
+			data[32+2*32] = 'X'
+			return data
+		}},
+		{"record length too small", func(data []byte) []byte {
+			binary.LittleEndian.PutUint16(data[10:], 0)
+			return data
+		}},
+		{"field length beyond record", func(data []byte) []byte {
+			data[32+16] = 0xFF
+			return data
+		}},
+		{"truncated header", func(data []byte) []byte {
+			return data[:20]
+		}},
+		{"record count beyond file", func(data []byte) []byte {
+			binary.LittleEndian.PutUint32(data[4:], 1<<30)
+			return data
+		}},
+		{"integer field too short", func(data []byte) []byte {
+			data[32+11] = 'I'
+			data[32+16] = 2
+			return data
+		}},
+		{"date field too short", func(data []byte) []byte {
+			data[32+11] = 'D'
+			data[32+16] = 4
+			return data
+		}},
+		{"no fields", func(data []byte) []byte {
+			binary.LittleEndian.PutUint32(data[4:], 5)
+			binary.LittleEndian.PutUint16(data[8:], 32+1)
+			binary.LittleEndian.PutUint16(data[10:], 0)
+			data[32] = 0x0D
+			return data
+		}},
+		{"records missing", func(data []byte) []byte {
+			return data[:len(data)-2*int(binary.LittleEndian.Uint16(data[10:]))]
+		}},
+	}
+	for _, c := range cases {
+		data, memo := foxPro2Table()
+		_, err := OpenStream(bytes.NewReader(c.mutate(data)), bytes.NewReader(memo), new(Win1250Decoder))
+		if err == nil {
+			t.Errorf("%s: want an error", c.name)
+		}
+	}
+}
+
+func TestCorruptMemoLength(t *testing.T) {
+	data, memo := foxPro2Table()
+	binary.BigEndian.PutUint32(memo[516:], 0xFFFFFFF0)
+	dbf, err := OpenStream(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dbf.RecordAt(0); err != ErrIncomplete {
+		t.Errorf("Want ErrIncomplete for a memo longer than the file, have %v", err)
+	}
+}
+
+// TestMutatedHeaders opens tables with random header bytes, which must return errors instead of panicking
+func TestMutatedHeaders(t *testing.T) {
+	original, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	memo, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		data := append([]byte(nil), original...)
+		for n := 0; n < 1+rnd.Intn(4); n++ {
+			data[rnd.Intn(32+13*32+1)] = byte(rnd.Intn(256))
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("Panic for mutation %d: %v", i, r)
+				}
+			}()
+			dbf, err := OpenStream(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder))
+			if err != nil {
+				return
+			}
+			for recno := uint32(0); recno < dbf.NumRecords() && recno < 10; recno++ {
+				dbf.RecordAt(recno)
+			}
+		}()
+	}
+}
@@ -0,0 +1,271 @@
+package dbf
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CacheOptions are the options of a CachingReader
+type CacheOptions struct {
+	BlockSize int // Size of a cached block, the default is 64 KB
+	MaxBlocks int // Maximum number of cached blocks, the default is 64 (4 MB with the default block size)
+}
+
+// DefaultCacheOptions are the options used for zero values in CacheOptions
+var DefaultCacheOptions = CacheOptions{BlockSize: 64 * 1024, MaxBlocks: 64}
+
+// CachingReader is an io.ReaderAt which caches the blocks read from a slow ReaderAt, like an HTTPReader.
+// The least recently used block is removed when the cache is full. Missing blocks next to each other are read
+// with one call, so reading the header or a range of records is one request.
+// It can be used by multiple goroutines at the same time.
+type CachingReader struct {
+	r      io.ReaderAt
+	size   int64
+	opts   CacheOptions
+	mu     sync.Mutex
+	blocks map[int64]*list.Element
+	lru    *list.List // most recently used block first
+}
+
+// cacheBlock is a block in the cache of a CachingReader
+type cacheBlock struct {
+	index int64
+	data  []byte
+}
+
+// NewCachingReader returns a CachingReader for a ReaderAt of size bytes
+func NewCachingReader(r io.ReaderAt, size int64, opts CacheOptions) *CachingReader {
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = DefaultCacheOptions.BlockSize
+	}
+	if opts.MaxBlocks <= 0 {
+		opts.MaxBlocks = DefaultCacheOptions.MaxBlocks
+	}
+	return &CachingReader{
+		r:      r,
+		size:   size,
+		opts:   opts,
+		blocks: make(map[int64]*list.Element),
+		lru:    list.New(),
+	}
+}
+
+// Size returns the size of the underlying file
+func (c *CachingReader) Size() int64 {
+	return c.size
+}
+
+// ReadAt implements io.ReaderAt, it reads from the cache and reads missing blocks from the underlying ReaderAt
+func (c *CachingReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= c.size {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	if end > c.size {
+		end = c.size
+	}
+	bs := int64(c.opts.BlockSize)
+	last := (end - 1) / bs
+	n := 0
+	for idx := off / bs; idx <= last; {
+		if data, ok := c.get(idx); ok {
+			n += copy(p[n:], data[off+int64(n)-idx*bs:])
+			idx++
+			continue
+		}
+		// read all missing blocks up to the next cached block at once
+		run := idx
+		for run < last && !c.has(run+1) {
+			run++
+		}
+		blocks, err := c.fetch(idx, run)
+		if err != nil {
+			return n, err
+		}
+		for _, data := range blocks {
+			n += copy(p[n:], data[off+int64(n)-idx*bs:])
+			idx++
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// get returns a cached block and marks it as most recently used
+func (c *CachingReader) get(idx int64) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.blocks[idx]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*cacheBlock).data, true
+}
+
+// has returns if a block is cached
+func (c *CachingReader) has(idx int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.blocks[idx]
+	return ok
+}
+
+// fetch reads the blocks first to last with one call and adds them to the cache
+func (c *CachingReader) fetch(first, last int64) ([][]byte, error) {
+	bs := int64(c.opts.BlockSize)
+	start, end := first*bs, (last+1)*bs
+	if end > c.size {
+		end = c.size
+	}
+	buf := make([]byte, end-start)
+	read, err := c.r.ReadAt(buf, start)
+	if read < len(buf) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	blocks := make([][]byte, 0, last-first+1)
+	for idx := first; idx <= last; idx++ {
+		from := (idx - first) * bs
+		to := from + bs
+		if to > int64(len(buf)) {
+			to = int64(len(buf))
+		}
+		// copied so an evicted block does not keep the whole buffer in memory
+		data := append([]byte(nil), buf[from:to]...)
+		blocks = append(blocks, data)
+		if e, ok := c.blocks[idx]; ok {
+			c.lru.MoveToFront(e)
+			continue
+		}
+		c.blocks[idx] = c.lru.PushFront(&cacheBlock{index: idx, data: data})
+		for c.lru.Len() > c.opts.MaxBlocks {
+			oldest := c.lru.Back()
+			c.lru.Remove(oldest)
+			delete(c.blocks, oldest.Value.(*cacheBlock).index)
+		}
+	}
+	return blocks, nil
+}
+
+// HTTPReader is an io.ReaderAt for a file on a web server, every ReadAt is an HTTP range request.
+// It works with all servers which support range requests, including S3 and other object storage
+// (using presigned URLs or public objects). Use it with a CachingReader to avoid reading the same data again.
+type HTTPReader struct {
+	client *http.Client
+	url    string
+	size   int64
+}
+
+// NewHTTPReader returns an HTTPReader for url, the size of the file is determined with a request for the first byte.
+// A nil client uses http.DefaultClient. When the file does not exist (404) an error for which os.IsNotExist
+// is true is returned.
+func NewHTTPReader(client *http.Client, url string) (*HTTPReader, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	h := &HTTPReader{client: client, url: url}
+	resp, err := h.get(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	// Content-Range: bytes 0-0/1234
+	cr := resp.Header.Get("Content-Range")
+	i := strings.LastIndex(cr, "/")
+	if i < 0 {
+		return nil, fmt.Errorf("%s: no file size in Content-Range %q", url, cr)
+	}
+	if h.size, err = strconv.ParseInt(cr[i+1:], 10, 64); err != nil {
+		return nil, fmt.Errorf("%s: no file size in Content-Range %q", url, cr)
+	}
+	return h, nil
+}
+
+// Size returns the size of the file
+func (h *HTTPReader) Size() int64 {
+	return h.size
+}
+
+// ReadAt implements io.ReaderAt with a range request
+func (h *HTTPReader) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if off >= h.size {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	if end > h.size {
+		end = h.size
+	}
+	resp, err := h.get(off, end-1)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	n, err := io.ReadFull(resp.Body, p[:end-off])
+	if err != nil {
+		return n, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// get requests the bytes from first to last (inclusive)
+func (h *HTTPReader) get(first, last int64) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", first, last))
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, &os.PathError{Op: "open", Path: h.url, Err: os.ErrNotExist}
+	case http.StatusOK:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: the server does not support range requests", h.url)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", h.url, resp.Status)
+	}
+}
+
+// HTTPOpener returns an Opener which reads files with an HTTPReader through a CachingReader,
+// register it with RegisterOpener for the http and https schemes. A nil client uses http.DefaultClient.
+func HTTPOpener(client *http.Client, opts CacheOptions) Opener {
+	return func(u *url.URL) (io.ReaderAt, int64, error) {
+		h, err := NewHTTPReader(client, u.String())
+		if err != nil {
+			return nil, 0, err
+		}
+		return NewCachingReader(h, h.Size(), opts), h.Size(), nil
+	}
+}
@@ -0,0 +1,144 @@
+package dbf
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingReaderAt counts the calls to ReadAt
+type countingReaderAt struct {
+	r     io.ReaderAt
+	calls int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.calls++
+	return c.r.ReadAt(p, off)
+}
+
+func TestCachingReader(t *testing.T) {
+	data := make([]byte, 10000)
+	rnd := rand.New(rand.NewSource(1))
+	rnd.Read(data)
+	src := &countingReaderAt{r: bytes.NewReader(data)}
+	c := NewCachingReader(src, int64(len(data)), CacheOptions{BlockSize: 100, MaxBlocks: 10})
+
+	// a read of multiple missing blocks is one call
+	buf := make([]byte, 450)
+	if n, err := c.ReadAt(buf, 30); n != len(buf) || err != nil || !bytes.Equal(buf, data[30:480]) {
+		t.Fatalf("ReadAt: have %d bytes (%v)", n, err)
+	}
+	if src.calls != 1 {
+		t.Errorf("Want 1 read for adjacent blocks, have %d", src.calls)
+	}
+	if n, err := c.ReadAt(buf[:100], 250); n != 100 || err != nil || !bytes.Equal(buf[:100], data[250:350]) {
+		t.Fatalf("ReadAt: have %d bytes (%v)", n, err)
+	}
+	if src.calls != 1 {
+		t.Errorf("Want a cached read, have %d reads", src.calls)
+	}
+
+	for i := 0; i < 1000; i++ {
+		off := rnd.Int63n(int64(len(data)) + 100)
+		buf := make([]byte, rnd.Intn(1500))
+		n, err := c.ReadAt(buf, off)
+		want := len(buf)
+		if off+int64(want) > int64(len(data)) {
+			want = len(data) - int(off)
+			if want < 0 {
+				want = 0
+			}
+			if err != io.EOF {
+				t.Fatalf("ReadAt(%d, %d): want io.EOF, have %v", len(buf), off, err)
+			}
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if n != want || (n > 0 && !bytes.Equal(buf[:n], data[off:off+int64(n)])) {
+			t.Fatalf("ReadAt(%d, %d): wrong data, have %d bytes", len(buf), off, n)
+		}
+		if c.lru.Len() > 10 || len(c.blocks) != c.lru.Len() {
+			t.Fatalf("Want at most 10 cached blocks, have %d (%d)", c.lru.Len(), len(c.blocks))
+		}
+	}
+}
+
+func TestHTTPOpener(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		f, err := os.Open(filepath.Join("testdata", filepath.Base(r.URL.Path)))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		http.ServeContent(w, r, f.Name(), time.Time{}, f)
+	}))
+	defer server.Close()
+
+	RegisterOpener("http", HTTPOpener(nil, CacheOptions{}))
+	defer RegisterOpener("http", nil)
+
+	local, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+	remote, err := OpenURL(server.URL+"/TEST.DBF", new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+
+	var after int32
+	for pass := 0; pass < 2; pass++ {
+		for recno := uint32(0); recno < local.NumRecords(); recno++ {
+			want, err := local.RecordAt(recno)
+			if err != nil {
+				t.Fatal(err)
+			}
+			have, err := remote.RecordAt(recno)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(want.FieldSlice(), have.FieldSlice()) {
+				t.Errorf("Record %d: want %v, have %v", recno, want.FieldSlice(), have.FieldSlice())
+			}
+		}
+		if pass == 0 {
+			after = atomic.LoadInt32(&requests)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != after {
+		t.Errorf("Want all reads of the second pass from the cache, have %d extra requests", n-after)
+	}
+
+	if _, err := OpenURL(server.URL+"/MISSING.DBF", new(Win1250Decoder)); !os.IsNotExist(err) {
+		t.Errorf("Want a not exist error, have %v", err)
+	}
+}
+
+func TestHTTPReaderNoRanges(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+	if _, err := NewHTTPReader(nil, server.URL+"/TEST.DBF"); err == nil {
+		t.Error("Want an error for a server without range requests")
+	}
+}
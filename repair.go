@@ -0,0 +1,122 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// RepairOptions controls which problems are fixed by Repair
+type RepairOptions struct {
+	// FixCount sets the record count in the header to the number of complete records found in the file.
+	// This fixes files where the header was not updated after an append and files with a truncated tail.
+	FixCount bool
+
+	// StripGarbage removes everything after the last record (including a partial record)
+	// and ends the file with an EOF marker.
+	StripGarbage bool
+}
+
+// RepairReport describes the state of a file as found by Repair and what was changed
+type RepairReport struct {
+	HeaderRecords uint32   // Number of records according to the header
+	FileRecords   uint32   // Number of complete records found in the file
+	TrailingBytes int64    // Bytes after the last complete record, excluding the EOF marker
+	MissingEOF    bool     // The file does not end with an EOF marker (0x1A)
+	Truncated     bool     // The file is shorter than the header says
+	Fixes         []string // Description of the applied fixes
+}
+
+// Repair copies a DBF from src to dst, fixing the problems enabled in opts.
+// size is the size of src in bytes. The file version is not validated so files which cannot be opened
+// otherwise can be repaired. Memo files are not changed by Repair.
+func Repair(dst io.Writer, src io.ReaderAt, size int64, opts RepairOptions) (*RepairReport, error) {
+	hbuf := make([]byte, 32)
+	if _, err := src.ReadAt(hbuf, 0); err != nil {
+		return nil, err
+	}
+	header := new(DBFHeader)
+	if err := binary.Read(bytes.NewReader(hbuf), binary.LittleEndian, header); err != nil {
+		return nil, err
+	}
+	if header.RecLen == 0 || header.FirstRec < 33 || int64(header.FirstRec) > size {
+		return nil, errors.New("invalid header, cannot determine record layout")
+	}
+
+	report := &RepairReport{HeaderRecords: header.NumRec}
+
+	// Count complete records which start with a valid deleted flag
+	recLen := int64(header.RecLen)
+	available := (size - int64(header.FirstRec)) / recLen
+	flag := make([]byte, 1)
+	for n := int64(0); n < available; n++ {
+		if _, err := src.ReadAt(flag, int64(header.FirstRec)+n*recLen); err != nil {
+			return nil, err
+		}
+		if flag[0] != 0x20 && flag[0] != 0x2A {
+			break
+		}
+		report.FileRecords++
+	}
+	dataEnd := int64(header.FirstRec) + int64(report.FileRecords)*recLen
+	report.TrailingBytes = size - dataEnd
+	if report.TrailingBytes > 0 {
+		if _, err := src.ReadAt(flag, size-1); err != nil {
+			return nil, err
+		}
+		if flag[0] == 0x1A {
+			report.TrailingBytes--
+		}
+	}
+	report.MissingEOF = flag[0] != 0x1A || size == dataEnd
+	report.Truncated = report.FileRecords < header.NumRec
+
+	// Header, with the record count updated if needed
+	numRec := header.NumRec
+	if opts.FixCount && numRec != report.FileRecords {
+		numRec = report.FileRecords
+		report.Fixes = append(report.Fixes, "record count changed")
+	}
+	headerBytes := make([]byte, header.FirstRec)
+	if _, err := src.ReadAt(headerBytes, 0); err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(headerBytes[4:], numRec)
+	if _, err := dst.Write(headerBytes); err != nil {
+		return nil, err
+	}
+
+	// Records
+	copyRecords := report.FileRecords
+	if numRec < copyRecords {
+		copyRecords = numRec
+	}
+	section := io.NewSectionReader(src, int64(header.FirstRec), int64(copyRecords)*recLen)
+	if _, err := io.Copy(dst, section); err != nil {
+		return nil, err
+	}
+
+	// Tail
+	tailStart := int64(header.FirstRec) + int64(copyRecords)*recLen
+	if opts.StripGarbage {
+		if report.TrailingBytes > 0 || copyRecords < report.FileRecords {
+			report.Fixes = append(report.Fixes, "trailing data removed")
+		}
+		if report.MissingEOF {
+			report.Fixes = append(report.Fixes, "EOF marker added")
+		}
+		_, err := dst.Write([]byte{0x1A})
+		return report, err
+	}
+	if _, err := io.Copy(dst, io.NewSectionReader(src, tailStart, size-tailStart)); err != nil {
+		return nil, err
+	}
+	if tailStart == size {
+		report.Fixes = append(report.Fixes, "EOF marker added")
+		if _, err := dst.Write([]byte{0x1A}); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
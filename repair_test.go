@@ -0,0 +1,88 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	orig, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpt, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cut the last record in half and add some garbage
+	damaged := append([]byte{}, orig[:len(orig)-60]...)
+	damaged = append(damaged, 0x00, 0x01, 0x02)
+
+	buf := new(bytes.Buffer)
+	report, err := Repair(buf, bytes.NewReader(damaged), int64(len(damaged)), RepairOptions{FixCount: true, StripGarbage: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HeaderRecords != 4 || report.FileRecords != 3 {
+		t.Errorf("Want 4 header records and 3 file records, have %d and %d", report.HeaderRecords, report.FileRecords)
+	}
+	if !report.Truncated || !report.MissingEOF {
+		t.Errorf("Want truncated file without EOF marker, have %+v", report)
+	}
+	if report.TrailingBytes != 127-60+3 {
+		t.Errorf("Want %d trailing bytes, have %d", 127-60+3, report.TrailingBytes)
+	}
+	if len(report.Fixes) != 3 {
+		t.Errorf("Want 3 fixes, have %v", report.Fixes)
+	}
+
+	repaired := buf.Bytes()
+	if len(repaired) != len(orig)-127+1 || repaired[len(repaired)-1] != 0x1A {
+		t.Fatalf("Repaired file has unexpected size %d or no EOF marker", len(repaired))
+	}
+	if n := binary.LittleEndian.Uint32(repaired[4:]); n != 3 {
+		t.Errorf("Want record count 3 in repaired header, have %d", n)
+	}
+
+	dbf, err := OpenStream(bytes.NewReader(repaired), bytes.NewReader(fpt), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := dbf.RecordAt(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Deleted {
+		t.Error("Record 2 should not be deleted")
+	}
+}
+
+func TestRepairUpdatesCountAfterAppend(t *testing.T) {
+	orig, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// header says 2 records but the file has 4
+	damaged := append([]byte{}, orig...)
+	binary.LittleEndian.PutUint32(damaged[4:], 2)
+
+	buf := new(bytes.Buffer)
+	report, err := Repair(buf, bytes.NewReader(damaged), int64(len(damaged)), RepairOptions{FixCount: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Truncated || report.FileRecords != 4 {
+		t.Errorf("Want 4 records in a complete file, have %+v", report)
+	}
+	if n := binary.LittleEndian.Uint32(buf.Bytes()[4:]); n != 4 {
+		t.Errorf("Want record count 4 in repaired header, have %d", n)
+	}
+	// the original file has no EOF marker, it is added
+	if buf.Len() != len(orig)+1 {
+		t.Errorf("Want size %d, have %d", len(orig)+1, buf.Len())
+	}
+}
@@ -0,0 +1,156 @@
+package dbf
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ErrRewriteDone is returned when a Rewrite is used after Commit or Abort
+var ErrRewriteDone = errors.New("rewrite is already committed or aborted")
+
+// Rewrite replaces the files of a table (DBF, memo file, CDX) together, for operations which write a complete new
+// table like PackTo. The new files are written to temporary files in the directories of the files they replace,
+// Commit renames them into place. A table is never half-written: when any step of Commit fails, the files which were
+// already replaced are restored. The files must not be open in this or another program while Commit runs.
+//
+// Commit first renames all current files to backup names, then renames the new files into place. When the program
+// stops between these steps, the original files can be found with their backup names.
+type Rewrite struct {
+	// Backup keeps the replaced files with a .bak extension added, otherwise they are removed after Commit
+	Backup bool
+
+	files []*rewriteFile
+	done  bool
+}
+
+// rewriteFile is a file replaced by a Rewrite
+type rewriteFile struct {
+	name   string   // the file which is replaced
+	tmp    *os.File // the new file, nil when the file is removed
+	backup string   // the backup name of the file, empty when it did not exist
+	placed bool     // the new file has been renamed to name
+}
+
+// NewRewrite returns a Rewrite without files
+func NewRewrite() *Rewrite {
+	return &Rewrite{}
+}
+
+// Create returns a new temporary file which replaces the file name on Commit.
+// The file must not be closed by the caller, Commit and Abort close it.
+func (rw *Rewrite) Create(name string) (*os.File, error) {
+	if rw.done {
+		return nil, ErrRewriteDone
+	}
+	name = filepath.Clean(name)
+	tmp, err := ioutil.TempFile(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	rw.files = append(rw.files, &rewriteFile{name: name, tmp: tmp})
+	return tmp, nil
+}
+
+// Remove removes the file name on Commit, like a file of the table which the operation does not write
+func (rw *Rewrite) Remove(name string) error {
+	if rw.done {
+		return ErrRewriteDone
+	}
+	rw.files = append(rw.files, &rewriteFile{name: filepath.Clean(name)})
+	return nil
+}
+
+// Commit replaces the files with the new files. The new files are synced to disk first and get the permissions
+// of the files they replace. On failure the original files are restored and the new files are removed.
+func (rw *Rewrite) Commit() error {
+	if rw.done {
+		return ErrRewriteDone
+	}
+	rw.done = true
+	for _, f := range rw.files {
+		if f.tmp == nil {
+			continue
+		}
+		if err := f.tmp.Sync(); err != nil {
+			rw.rollback()
+			return err
+		}
+		if err := f.tmp.Close(); err != nil {
+			rw.rollback()
+			return err
+		}
+		// temporary files are created with mode 0600
+		if stat, err := os.Stat(f.name); err == nil {
+			if err := os.Chmod(f.tmp.Name(), stat.Mode()); err != nil {
+				rw.rollback()
+				return err
+			}
+		}
+	}
+
+	// move all current files aside before any new file is put in place
+	for _, f := range rw.files {
+		if _, err := os.Lstat(f.name); os.IsNotExist(err) {
+			continue
+		}
+		backup := f.name + ".old"
+		if f.tmp != nil {
+			backup = f.tmp.Name() + ".old"
+		}
+		if rw.Backup {
+			backup = f.name + ".bak"
+		}
+		if err := os.Rename(f.name, backup); err != nil {
+			rw.rollback()
+			return err
+		}
+		f.backup = backup
+	}
+	for _, f := range rw.files {
+		if f.tmp == nil {
+			continue
+		}
+		if err := os.Rename(f.tmp.Name(), f.name); err != nil {
+			rw.rollback()
+			return err
+		}
+		f.placed = true
+	}
+
+	if !rw.Backup {
+		for _, f := range rw.files {
+			if f.backup != "" {
+				os.Remove(f.backup)
+			}
+		}
+	}
+	return nil
+}
+
+// Abort removes the new files, the files of the table are not changed
+func (rw *Rewrite) Abort() error {
+	if rw.done {
+		return ErrRewriteDone
+	}
+	rw.done = true
+	rw.rollback()
+	return nil
+}
+
+// rollback restores the original files and removes the new files
+func (rw *Rewrite) rollback() {
+	for _, f := range rw.files {
+		if f.placed {
+			os.Rename(f.name, f.tmp.Name())
+		}
+		if f.backup != "" {
+			os.Rename(f.backup, f.name)
+		}
+		if f.tmp != nil {
+			f.tmp.Close()
+			os.Remove(f.tmp.Name())
+		}
+	}
+}
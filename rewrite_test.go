@@ -0,0 +1,102 @@
+package dbf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfrewrite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dbffile, fptfile := filepath.Join(dir, "TEST.DBF"), filepath.Join(dir, "TEST.FPT")
+	for _, name := range []string{dbffile, fptfile} {
+		if err := ioutil.WriteFile(name, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// rewrite writes the new contents of both files
+	rewrite := func(backup bool) (*Rewrite, []*os.File) {
+		rw := NewRewrite()
+		rw.Backup = backup
+		var files []*os.File
+		for _, name := range []string{dbffile, fptfile} {
+			f, err := rw.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.WriteString("new"); err != nil {
+				t.Fatal(err)
+			}
+			files = append(files, f)
+		}
+		return rw, files
+	}
+	check := func(name, want string) {
+		t.Helper()
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Error(err)
+		} else if string(data) != want {
+			t.Errorf("Want %s to contain %q, have %q", filepath.Base(name), want, data)
+		}
+	}
+	numFiles := func() int {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(infos)
+	}
+
+	rw, _ := rewrite(false)
+	if err := rw.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	check(dbffile, "old")
+	if n := numFiles(); n != 2 {
+		t.Errorf("Want the temporary files removed, have %d files", n)
+	}
+	if err := rw.Commit(); err != ErrRewriteDone {
+		t.Errorf("Want ErrRewriteDone, have %v", err)
+	}
+
+	rw, _ = rewrite(true)
+	if err := rw.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	check(dbffile, "new")
+	check(fptfile, "new")
+	check(dbffile+".bak", "old")
+	check(fptfile+".bak", "old")
+	if stat, err := os.Stat(dbffile); err != nil || stat.Mode().Perm() != 0644 {
+		t.Errorf("Want the permissions of the replaced file, have %v (%v)", stat.Mode(), err)
+	}
+
+	// a failure while renaming restores the files which were replaced already
+	if err := os.Remove(fptfile); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(dbffile, []byte("current"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rw, files := rewrite(false)
+	if err := os.Remove(files[1].Name()); err != nil {
+		t.Fatal(err)
+	}
+	if err := rw.Commit(); err == nil {
+		t.Fatal("Want an error for a missing new file")
+	}
+	check(dbffile, "current")
+	if _, err := os.Stat(fptfile); !os.IsNotExist(err) {
+		t.Errorf("Want no memo file, have %v", err)
+	}
+	if n := numFiles(); n != 3 {
+		t.Errorf("Want the table and 2 backups, have %d files", n)
+	}
+}
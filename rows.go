@@ -0,0 +1,134 @@
+package dbf
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// Rows is a database/sql/driver.Rows implementation over an open table, without the need for a complete driver.
+// Deleted records are skipped. Field values are converted to the driver.Value types: I fields to int64,
+// memo fields to string or []byte, empty D and T fields to nil.
+type Rows struct {
+	dbf    *DBF
+	next   uint32
+	closed bool
+}
+
+var (
+	_ driver.Rows                           = (*Rows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*Rows)(nil)
+	_ driver.RowsColumnTypeLength           = (*Rows)(nil)
+	_ driver.RowsColumnTypePrecisionScale   = (*Rows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*Rows)(nil)
+)
+
+// NewRows returns driver.Rows for all non-deleted records of dbf.
+// Closing the Rows does not close the table, it does not use or move the internal record pointer of dbf.
+func NewRows(dbf *DBF) *Rows {
+	return &Rows{dbf: dbf}
+}
+
+// Columns returns the field names
+func (r *Rows) Columns() []string {
+	return r.dbf.FieldNames()
+}
+
+// Close stops the iteration, it does not close the table
+func (r *Rows) Close() error {
+	r.closed = true
+	return nil
+}
+
+// Next reads the next non-deleted record into dest, it returns io.EOF after the last record
+func (r *Rows) Next(dest []driver.Value) error {
+	if r.closed {
+		return io.EOF
+	}
+	for r.next < r.dbf.NumRecords() {
+		recno := r.next
+		r.next++
+		deleted, err := r.dbf.DeletedAt(recno)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			continue
+		}
+		rec, err := r.dbf.RecordAt(recno)
+		if err != nil {
+			return fmt.Errorf("record %d: %s", recno, err)
+		}
+		for i := range dest {
+			if i < len(rec.data) {
+				dest[i] = driverValue(rec.data[i])
+			}
+		}
+		return nil
+	}
+	return io.EOF
+}
+
+// driverValue converts a field value to one of the types allowed as driver.Value
+func driverValue(val interface{}) driver.Value {
+	switch v := val.(type) {
+	case int32:
+		return int64(v)
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+	}
+	return val
+}
+
+// ColumnTypeDatabaseTypeName returns the FoxPro field type, like C or N
+func (r *Rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.dbf.fields[index].FieldType()
+}
+
+// ColumnTypeLength returns the length of C fields, other field types have no variable length
+func (r *Rows) ColumnTypeLength(index int) (int64, bool) {
+	f := r.dbf.fields[index]
+	if f.Type == 'C' {
+		return int64(f.Len), true
+	}
+	return 0, false
+}
+
+// ColumnTypePrecisionScale returns the length and decimals of N and F fields
+func (r *Rows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	f := r.dbf.fields[index]
+	switch f.Type {
+	case 'N', 'F':
+		return int64(f.Len), int64(f.Decimals), true
+	case 'Y':
+		return 19, 4, true
+	}
+	return 0, 0, false
+}
+
+// ColumnTypeScanType returns the Go type of the values of a field, after the conversion of the TypeMap of the table
+func (r *Rows) ColumnTypeScanType(index int) reflect.Type {
+	f := r.dbf.mappedField(index)
+	switch f.Type {
+	case 'C', 'M':
+		return reflect.TypeOf("")
+	case 'I':
+		return reflect.TypeOf(int64(0))
+	case 'N':
+		if f.Decimals == 0 {
+			return reflect.TypeOf(int64(0))
+		}
+		return reflect.TypeOf(float64(0))
+	case 'F', 'B', 'Y':
+		return reflect.TypeOf(float64(0))
+	case 'D', 'T':
+		return reflect.TypeOf(time.Time{})
+	case 'L':
+		return reflect.TypeOf(false)
+	}
+	return reflect.TypeOf([]byte(nil))
+}
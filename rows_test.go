@@ -0,0 +1,70 @@
+package dbf
+
+import (
+	"database/sql/driver"
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRows(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	rows := NewRows(dbf)
+	columns := rows.Columns()
+	if len(columns) != 13 || columns[0] != "ID" {
+		t.Fatalf("Unexpected columns %v", columns)
+	}
+
+	dest := make([]driver.Value, len(columns))
+	n := 0
+	for {
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, val := range dest {
+			if val != nil && !driver.IsValue(val) {
+				t.Errorf("Column %s: %T is not a driver.Value", columns[i], val)
+			}
+		}
+		if n == 0 {
+			if dest[0] != int64(1) {
+				t.Errorf("Want ID 1, have %v", dest[0])
+			}
+			if dest[2] != time.Date(2015, 1, 3, 0, 0, 0, 0, time.UTC) {
+				t.Errorf("Want DATUM 2015-01-03, have %v", dest[2])
+			}
+		}
+		n++
+	}
+	// 1 record is deleted
+	if n != 3 {
+		t.Errorf("Want 3 rows, have %d", n)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if name := rows.ColumnTypeDatabaseTypeName(7); name != "C" {
+		t.Errorf("Want type C, have %s", name)
+	}
+	if length, ok := rows.ColumnTypeLength(7); !ok || length != 40 {
+		t.Errorf("Want length 40, have %d", length)
+	}
+	if p, s, ok := rows.ColumnTypePrecisionScale(10); !ok || p != 12 || s != 2 {
+		t.Errorf("Want precision 12 and scale 2, have %d and %d", p, s)
+	}
+	if typ := rows.ColumnTypeScanType(0); typ != reflect.TypeOf(int64(0)) {
+		t.Errorf("Want int64 scan type, have %s", typ)
+	}
+}
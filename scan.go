@@ -0,0 +1,205 @@
+package dbf
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scan copies the field values of the record into the values pointed at by dest, like sql.Rows.Scan.
+// The number of values in dest must be the same as the number of fields, a nil dest skips the field.
+//
+// Values are converted to the type of the destination: numbers to any integer or float type (an integer
+// destination needs a value without fraction which fits), strings are parsed as numbers or logicals, and every
+// value can be stored in a string. C fields are not trimmed, like the values of FieldSlice. Destinations implementing sql.Scanner, like sql.NullString, receive the
+// same values as with Rows. Empty D and T fields are null: a pointer destination (like **time.Time) is set
+// to nil, a *time.Time to the zero time and other types return an error.
+//
+//	var (
+//		id    int
+//		name  string
+//		total float64
+//		born  *time.Time
+//	)
+//	err := rec.Scan(&id, &name, &total, &born)
+func (r *Record) Scan(dest ...interface{}) error {
+	if len(dest) != len(r.data) {
+		return fmt.Errorf("Scan expected %d destination arguments, not %d", len(r.data), len(dest))
+	}
+	for i, d := range dest {
+		if d == nil {
+			continue
+		}
+		if err := scanValue(d, r.data[i]); err != nil {
+			return fmt.Errorf("Scan error on field %s: %s", r.fields[i].FieldName(), err)
+		}
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// scanValue stores field value val in dest
+func scanValue(dest, val interface{}) error {
+	src := driverValue(val)
+	switch d := dest.(type) {
+	case sql.Scanner:
+		return d.Scan(src)
+	case *interface{}:
+		*d = src
+		return nil
+	case *time.Time:
+		if src == nil {
+			*d = time.Time{}
+			return nil
+		}
+	case *[]byte:
+		switch v := src.(type) {
+		case nil:
+			*d = nil
+			return nil
+		case []byte:
+			*d = append([]byte(nil), v...)
+			return nil
+		case string:
+			*d = []byte(v)
+			return nil
+		}
+	}
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("destination is not a non-nil pointer")
+	}
+	ev := rv.Elem()
+
+	// pointer destinations are set to nil for null values or to a new value
+	if ev.Kind() == reflect.Ptr {
+		if src == nil {
+			ev.Set(reflect.Zero(ev.Type()))
+			return nil
+		}
+		p := reflect.New(ev.Type().Elem())
+		if err := scanValue(p.Interface(), val); err != nil {
+			return err
+		}
+		ev.Set(p)
+		return nil
+	}
+	if src == nil {
+		return fmt.Errorf("converting null to %s is unsupported", ev.Type())
+	}
+
+	switch ev.Kind() {
+	case reflect.String:
+		ev.SetString(scanString(src))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := scanInt(src)
+		if err != nil {
+			return err
+		}
+		if ev.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows %s", n, ev.Type())
+		}
+		ev.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := scanInt(src)
+		if err != nil {
+			return err
+		}
+		if n < 0 || ev.OverflowUint(uint64(n)) {
+			return fmt.Errorf("value %d overflows %s", n, ev.Type())
+		}
+		ev.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := scanFloat(src)
+		if err != nil {
+			return err
+		}
+		if ev.OverflowFloat(f) {
+			return fmt.Errorf("value %g overflows %s", f, ev.Type())
+		}
+		ev.SetFloat(f)
+		return nil
+	case reflect.Bool:
+		b, err := scanBool(src)
+		if err != nil {
+			return err
+		}
+		ev.SetBool(b)
+		return nil
+	}
+	// time.Time and types defined as time.Time
+	if t, ok := src.(time.Time); ok && ev.Type().ConvertibleTo(timeType) {
+		ev.Set(reflect.ValueOf(t).Convert(ev.Type()))
+		return nil
+	}
+	return &CastError{Value: src, Type: ev.Type().String()}
+}
+
+// scanString converts a non-null value to a string
+func scanString(src interface{}) string {
+	switch v := src.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%v", src)
+}
+
+// scanInt converts a number or a string containing an integer to an int64
+func scanInt(src interface{}) (int64, error) {
+	if s, ok := src.(string); ok {
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return 0, &CastError{Value: src, Type: "int64"}
+		}
+		return n, nil
+	}
+	return ToInt64E(src)
+}
+
+// scanFloat converts a number or a string containing a number to a float64
+func scanFloat(src interface{}) (float64, error) {
+	if s, ok := src.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return 0, &CastError{Value: src, Type: "float64"}
+		}
+		return f, nil
+	}
+	return ToFloat64E(src)
+}
+
+// scanBool converts a logical, the numbers 1 and 0 or a string like T, F, true or false to a bool
+func scanBool(src interface{}) (bool, error) {
+	switch v := src.(type) {
+	case bool:
+		return v, nil
+	case int64:
+		if v == 0 || v == 1 {
+			return v == 1, nil
+		}
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b, nil
+		}
+	}
+	return false, &CastError{Value: src, Type: "bool"}
+}
@@ -0,0 +1,146 @@
+package dbf
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordScan(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type level uint8
+	var (
+		id      int
+		niveau  level
+		datum   time.Time
+		tijd    string
+		soort   float32
+		idnr    *int64
+		usernr  sql.NullInt64
+		name    string
+		os      []byte
+		melding interface{}
+		number  float64
+		float   int
+		boolean bool
+	)
+	err = rec.Scan(&id, &niveau, &datum, &tijd, &soort, &idnr, &usernr, &name, &os, &melding, &number, &float, &boolean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 || niveau != 0 || soort != 3 || idnr == nil || *idnr != 100 || !usernr.Valid || usernr.Int64 != 1 {
+		t.Errorf("Unexpected numbers %d %d %v %v %v", id, niveau, soort, idnr, usernr)
+	}
+	if !datum.Equal(time.Date(2015, 1, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected date %v", datum)
+	}
+	if strings.TrimSpace(tijd) != "15:00" || strings.TrimSpace(name) != "TEST" || strings.TrimSpace(string(os)) != "Windows 8.1 Pro" {
+		t.Errorf("Unexpected strings %q %q %q", tijd, name, os)
+	}
+	if melding != "Message line 1\r\nMessage line 2" {
+		t.Errorf("Unexpected memo %q", melding)
+	}
+	if number != 1.66 || float != 1 || boolean {
+		t.Errorf("Unexpected values %v %v %v", number, float, boolean)
+	}
+
+	// nil skips a field, numbers can be scanned into strings
+	var numberText string
+	err = rec.Scan(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &numberText, nil, nil)
+	if err != nil || numberText != "1.66" {
+		t.Errorf("Want 1.66, have %q %v", numberText, err)
+	}
+
+	// record 3 has an empty date
+	rec, err = dbf.RecordAt(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	born := new(time.Time)
+	var nullDate sql.NullString
+	err = rec.Scan(nil, nil, &born, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err != nil || born != nil {
+		t.Errorf("Want nil date, have %v %v", born, err)
+	}
+	err = rec.Scan(nil, nil, &nullDate, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err != nil || nullDate.Valid {
+		t.Errorf("Want invalid NullString, have %v %v", nullDate, err)
+	}
+	var text string
+	if err := rec.Scan(nil, nil, &text, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Want an error scanning a null date into a string")
+	}
+}
+
+func TestRecordScanErrors(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Scan(new(int)); err == nil {
+		t.Error("Want an error for the number of arguments")
+	}
+
+	var (
+		i      int
+		small  int8
+		b      bool
+		d      time.Time
+		notPtr int
+	)
+	tests := []struct {
+		pos  int
+		dest interface{}
+	}{
+		{10, &i},      // NUMBER 1.66 has a fraction
+		{5, &small},   // ID_NR 100 fits, see below
+		{7, &b},       // COMP_NAME is not a logical
+		{7, &d},       // COMP_NAME is not a date
+		{0, notPtr},   // not a pointer
+		{2, new(int)}, // date into an int
+	}
+	for _, test := range tests {
+		dest := make([]interface{}, dbf.NumFields())
+		dest[test.pos] = test.dest
+		err := rec.Scan(dest...)
+		if test.pos == 5 {
+			if err != nil || small != 100 {
+				t.Errorf("Want 100, have %d %v", small, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("Field %d into %T: want an error", test.pos, test.dest)
+		} else if !strings.Contains(err.Error(), dbf.fields[test.pos].FieldName()) {
+			t.Errorf("Want the field name in %v", err)
+		}
+	}
+
+	// overflow of a smaller integer type
+	rec, err = dbf.RecordAt(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := make([]interface{}, dbf.NumFields())
+	dest[5] = &small
+	if err := rec.Scan(dest...); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Errorf("Want an overflow error, have %v", err)
+	}
+}
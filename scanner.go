@@ -0,0 +1,106 @@
+package dbf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// scanBufferSize is the size of the read buffer of a Scanner
+const scanBufferSize = 64 * 1024
+
+// Scanner reads all records of a table sequentially, see DBF.Scanner.
+// Instead of reading every record separately the records are read forward through a buffer,
+// which is a lot faster for full table scans. It is used like bufio.Scanner:
+//
+//	s := d.Scanner()
+//	for s.Next() {
+//		fmt.Println(s.RecNo(), s.Record().FieldSlice())
+//	}
+//	if err := s.Err(); err != nil {
+//		return err
+//	}
+type Scanner struct {
+	// IncludeDeleted also returns deleted records, by default they are skipped
+	IncludeDeleted bool
+
+	dbf   *DBF
+	r     *bufio.Reader
+	next  uint32 // next record number
+	recno uint32
+	rec   *Record
+	err   error
+}
+
+// Scanner returns a Scanner for the records which are in the table when it is called.
+// The internal record pointer is not used or moved. The Scanner does not retry incomplete reads,
+// use ScanConsistent for tables which are written while reading.
+func (dbf *DBF) Scanner() *Scanner {
+	size := int64(dbf.header.NumRec) * int64(dbf.header.RecLen)
+	section := io.NewSectionReader(dbf.r, int64(dbf.header.FirstRec), size)
+	return &Scanner{dbf: dbf, r: bufio.NewReaderSize(section, scanBufferSize)}
+}
+
+// Next advances to the next record, it returns false after the last record or when an error occurred
+func (s *Scanner) Next() bool {
+	recno, data, ok := s.nextData(nil)
+	if !ok {
+		return false
+	}
+	rec, err := s.dbf.bytesToRecord(data)
+	if err != nil {
+		s.err = fmt.Errorf("record %d: %s", recno, err)
+		return false
+	}
+	s.recno, s.rec = recno, rec
+	return true
+}
+
+// nextData reads the data of the next record into buf, or a new slice if buf is nil.
+// Deleted records are skipped unless IncludeDeleted is set.
+func (s *Scanner) nextData(buf []byte) (uint32, []byte, bool) {
+	if s.err != nil {
+		return 0, nil, false
+	}
+	for s.next < s.dbf.header.NumRec {
+		recno := s.next
+		s.next++
+		data := buf
+		if data == nil {
+			data = make([]byte, s.dbf.header.RecLen)
+		}
+		if _, err := io.ReadFull(s.r, data); err != nil {
+			if err == io.ErrUnexpectedEOF || err == io.EOF {
+				err = ErrIncomplete
+			}
+			s.err = fmt.Errorf("record %d: %s", recno, err)
+			return 0, nil, false
+		}
+		if s.dbf.decrypter != nil {
+			if err := s.dbf.decrypter.Decrypt(recno, data); err != nil {
+				s.err = fmt.Errorf("decrypting record %d: %s", recno, err)
+				return 0, nil, false
+			}
+		}
+		if data[0] == 0x2A && !s.IncludeDeleted {
+			continue
+		}
+		return recno, data, true
+	}
+	return 0, nil, false
+}
+
+// Record returns the current record
+func (s *Scanner) Record() *Record {
+	return s.rec
+}
+
+// RecNo returns the record number of the current record
+func (s *Scanner) RecNo() uint32 {
+	return s.recno
+}
+
+// Err returns the first error that occurred while scanning
+func (s *Scanner) Err() error {
+	return s.err
+}
@@ -0,0 +1,96 @@
+package dbf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	for _, includeDeleted := range []bool{false, true} {
+		s := dbf.Scanner()
+		s.IncludeDeleted = includeDeleted
+		var recnos []uint32
+		for s.Next() {
+			recnos = append(recnos, s.RecNo())
+			want, err := dbf.RecordAt(s.RecNo())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have := s.Record(); !reflect.DeepEqual(want.FieldSlice(), have.FieldSlice()) || want.Deleted != have.Deleted {
+				t.Errorf("Record %d: want %v, have %v", s.RecNo(), want.FieldSlice(), have.FieldSlice())
+			}
+		}
+		if err := s.Err(); err != nil {
+			t.Fatal(err)
+		}
+		want := []uint32{0, 2, 3}
+		if includeDeleted {
+			want = []uint32{0, 1, 2, 3}
+		}
+		if !reflect.DeepEqual(recnos, want) {
+			t.Errorf("Want records %v, have %v", want, recnos)
+		}
+	}
+}
+
+func TestScannerIncomplete(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	memo, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cut off the last record
+	end := int(dbf.Header().FirstRec) + int(dbf.Header().RecLen)*3 + 10
+	dbf, err = OpenStream(bytes.NewReader(data[:end]), bytes.NewReader(memo), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := dbf.Scanner()
+	n := 0
+	for s.Next() {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("Want 2 records before the incomplete record, have %d", n)
+	}
+	if err := s.Err(); err == nil || err.Error() != "record 3: "+ErrIncomplete.Error() {
+		t.Errorf("Want an incomplete read of record 3, have %v", err)
+	}
+}
+
+// Benchmark for reading all records with a Scanner, compare with BenchmarkReadRecords
+func BenchmarkScanner(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		err := func() error {
+			dbf, err := OpenFile(filepath.Join("testdata", "dbase_30.dbf"), new(Win1250Decoder))
+			if err != nil {
+				return err
+			}
+			defer dbf.Close()
+			s := dbf.Scanner()
+			s.IncludeDeleted = true
+			for s.Next() {
+			}
+			return s.Err()
+		}()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package dbf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaError is returned by SchemaBuilder.Build for a field which FoxPro does not accept
+type SchemaError struct {
+	Field  int    // position of the field, -1 for the table
+	Name   string // name of the field as given
+	Detail string // description of the problem
+}
+
+func (e *SchemaError) Error() string {
+	if e.Field < 0 {
+		return "schema: " + e.Detail
+	}
+	return fmt.Sprintf("field %d (%s): %s", e.Field+1, e.Name, e.Detail)
+}
+
+// SchemaBuilder builds the fields of a new table for NewWriter and Create and checks them against the rules of
+// Visual FoxPro, so the table can be opened by FoxPro. The methods add a field and return the builder, the first
+// problem is returned by Build:
+//
+//	fields, err := dbf.NewSchemaBuilder().
+//		Char("NAME", 40).
+//		Numeric("AMOUNT", 12, 2).
+//		Date("ORDERED").
+//		Memo("NOTES").
+//		Build()
+//
+// Names have 1 to 10 letters, digits and underscores and start with a letter or underscore, they are stored in
+// upper case and must be unique without regard to case. C fields have a length of 1 to 254, N and F fields of
+// 1 to 20 with at most length-2 decimals (the point and a digit before it), B fields have at most 18 decimals.
+// A table has at most 255 fields.
+type SchemaBuilder struct {
+	fields []FieldHeader
+	names  map[string]bool
+	err    error
+}
+
+// NewSchemaBuilder returns a SchemaBuilder without fields
+func NewSchemaBuilder() *SchemaBuilder {
+	return &SchemaBuilder{names: make(map[string]bool)}
+}
+
+// Char adds a C field with length characters
+func (b *SchemaBuilder) Char(name string, length int) *SchemaBuilder {
+	return b.Field(name, 'C', length, 0)
+}
+
+// Numeric adds an N field, a number stored as text of length characters including the point and the sign
+func (b *SchemaBuilder) Numeric(name string, length, decimals int) *SchemaBuilder {
+	return b.Field(name, 'N', length, decimals)
+}
+
+// Float adds an F field, which is stored like an N field
+func (b *SchemaBuilder) Float(name string, length, decimals int) *SchemaBuilder {
+	return b.Field(name, 'F', length, decimals)
+}
+
+// Integer adds an I field
+func (b *SchemaBuilder) Integer(name string) *SchemaBuilder {
+	return b.Field(name, 'I', 0, 0)
+}
+
+// Double adds a B field, decimals is the number of decimals FoxPro displays
+func (b *SchemaBuilder) Double(name string, decimals int) *SchemaBuilder {
+	return b.Field(name, 'B', 0, decimals)
+}
+
+// Currency adds a Y field
+func (b *SchemaBuilder) Currency(name string) *SchemaBuilder {
+	return b.Field(name, 'Y', 0, 0)
+}
+
+// Date adds a D field
+func (b *SchemaBuilder) Date(name string) *SchemaBuilder {
+	return b.Field(name, 'D', 0, 0)
+}
+
+// DateTime adds a T field
+func (b *SchemaBuilder) DateTime(name string) *SchemaBuilder {
+	return b.Field(name, 'T', 0, 0)
+}
+
+// Logical adds an L field
+func (b *SchemaBuilder) Logical(name string) *SchemaBuilder {
+	return b.Field(name, 'L', 0, 0)
+}
+
+// Memo adds an M field
+func (b *SchemaBuilder) Memo(name string) *SchemaBuilder {
+	return b.Field(name, 'M', 0, 0)
+}
+
+// Blob adds a W field
+func (b *SchemaBuilder) Blob(name string) *SchemaBuilder {
+	return b.Field(name, 'W', 0, 0)
+}
+
+// Field adds a field of any type the Writer supports. The length of fixed size types, like D and M, is set
+// automatically and may be 0 or the fixed length. Decimals are only allowed for N, F and B fields.
+func (b *SchemaBuilder) Field(name string, typ byte, length, decimals int) *SchemaBuilder {
+	if b.err != nil {
+		return b
+	}
+	pos := len(b.fields)
+	fail := func(format string, args ...interface{}) *SchemaBuilder {
+		b.err = &SchemaError{Field: pos, Name: name, Detail: fmt.Sprintf(format, args...)}
+		return b
+	}
+
+	// the name is checked before it is stored, the FieldHeader holds at most 11 bytes
+	if detail := checkFieldName(name); detail != "" {
+		return fail("%s", detail)
+	}
+	if pos == DefaultMaxFields {
+		return fail("a table has at most %d fields", DefaultMaxFields)
+	}
+	if length < 0 || length > 255 || decimals < 0 || decimals > 255 {
+		return fail("invalid length %d or decimals %d", length, decimals)
+	}
+
+	f := FieldHeader{Type: typ, Len: uint8(length), Decimals: uint8(decimals)}
+	copy(f.Name[:], strings.ToUpper(name))
+	switch typ {
+	case 'C':
+		if length < 1 || length > 254 {
+			return fail("C fields have a length of 1 to 254, not %d", length)
+		}
+	case 'N', 'F':
+		if length < 1 || length > 20 {
+			return fail("%c fields have a length of 1 to 20, not %d", typ, length)
+		}
+	case 'B':
+		if decimals > 18 {
+			return fail("B fields have at most 18 decimals, not %d", decimals)
+		}
+	case 'D', 'T', 'Y', 'I', 'M', 'G', 'P', 'W', 'L':
+		// the fixed length is set by prepareField
+	default:
+		if typ < ' ' || typ > '~' {
+			return fail("invalid field type 0x%02X", typ)
+		}
+		return fail("field type %c is not supported, use C, N, F, I, B, Y, D, T, L, M, G, P or W", typ)
+	}
+	if decimals > 0 && typ != 'N' && typ != 'F' && typ != 'B' {
+		return fail("%c fields have no decimals", typ)
+	}
+	// the rules of NewWriter, which sets the length of fixed size types
+	if err := prepareNewField(&f, b.names); err != nil {
+		return fail("%s", strings.TrimPrefix(err.Error(), "field "+f.FieldName()+": "))
+	}
+	if length != 0 && length != int(f.Len) {
+		return fail("%c fields have a length of %d, not %d", typ, f.Len, length)
+	}
+
+	b.fields = append(b.fields, f)
+	return b
+}
+
+// Build returns the fields, or the first problem found as *SchemaError
+func (b *SchemaBuilder) Build() ([]FieldHeader, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.fields) == 0 {
+		return nil, &SchemaError{Field: -1, Detail: "a table needs at least one field"}
+	}
+	fields := make([]FieldHeader, len(b.fields))
+	copy(fields, b.fields)
+	return fields, nil
+}
@@ -0,0 +1,90 @@
+package dbf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	fields, err := NewSchemaBuilder().
+		Char("name", 40).
+		Numeric("AMOUNT", 12, 2).
+		Float("RATE", 10, 4).
+		Integer("ID").
+		Double("WEIGHT", 3).
+		Currency("PRICE").
+		Date("ORDERED").
+		DateTime("CHANGED").
+		Logical("PAID").
+		Memo("NOTES").
+		Blob("PHOTO").
+		Field("LOGO", 'G', 4, 0).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name          string
+		typ           byte
+		len, decimals uint8
+	}{
+		{"NAME", 'C', 40, 0}, {"AMOUNT", 'N', 12, 2}, {"RATE", 'F', 10, 4}, {"ID", 'I', 4, 0},
+		{"WEIGHT", 'B', 8, 3}, {"PRICE", 'Y', 8, 4}, {"ORDERED", 'D', 8, 0}, {"CHANGED", 'T', 8, 0},
+		{"PAID", 'L', 1, 0}, {"NOTES", 'M', 4, 0}, {"PHOTO", 'W', 4, 0}, {"LOGO", 'G', 4, 0},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("Want %d fields, have %d", len(want), len(fields))
+	}
+	for i, w := range want {
+		f := fields[i]
+		if f.FieldName() != w.name || f.Type != w.typ || f.Len != w.len || f.Decimals != w.decimals {
+			t.Errorf("Field %d: want %+v, have %s %c %d %d", i, w, f.FieldName(), f.Type, f.Len, f.Decimals)
+		}
+	}
+	if _, err := NewWriter(new(memWriteSeeker), new(memWriteSeeker), fields, new(UTF8Encoder)); err != nil {
+		t.Errorf("Want the fields accepted by NewWriter, have %v", err)
+	}
+}
+
+func TestSchemaBuilderErrors(t *testing.T) {
+	tests := []struct {
+		build func(b *SchemaBuilder) *SchemaBuilder
+		field int
+		err   string
+	}{
+		{func(b *SchemaBuilder) *SchemaBuilder { return b }, -1, "at least one field"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Char("CUSTOMERNAME", 10) }, 0, "1 to 10 characters"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Char("", 10) }, 0, "1 to 10 characters"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Char("1ST", 10) }, 0, "starts with a digit"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Char("FIRST NAME", 10) }, 0, "contains ' '"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Char("NAAM", 10).Date("Naam") }, 1, "duplicate field name NAAM"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Char("NAME", 0) }, 0, "C fields have a length of 1 to 254"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Char("NAME", 255) }, 0, "C fields have a length of 1 to 254"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Numeric("AMOUNT", 21, 0) }, 0, "N fields have a length of 1 to 20"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Numeric("AMOUNT", 4, 3) }, 0, "3 decimals do not fit in length 4"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Double("WEIGHT", 19) }, 0, "at most 18 decimals"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Field("ID", 'I', 0, 2) }, 0, "I fields have no decimals"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Field("BORN", 'D', 10, 0) }, 0, "D fields have a length of 8, not 10"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Field("NAME", 'V', 10, 0) }, 0, "field type V is not supported"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Field("NAME", 0, 10, 0) }, 0, "invalid field type 0x00"},
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Char("NAME", -1) }, 0, "invalid length -1"},
+		// the first problem is returned
+		{func(b *SchemaBuilder) *SchemaBuilder { return b.Logical("OK").Char("NAME", 0).Char("", 1) }, 1, "C fields"},
+	}
+	for i, test := range tests {
+		_, err := test.build(NewSchemaBuilder()).Build()
+		serr, ok := err.(*SchemaError)
+		if !ok || serr.Field != test.field || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Test %d: want a SchemaError for field %d with %q, have %v", i, test.field, test.err, err)
+		}
+	}
+
+	b := NewSchemaBuilder()
+	for i := 0; i < 256; i++ {
+		b.Logical(fmt.Sprintf("F%d", i))
+	}
+	if _, err := b.Build(); err == nil || !strings.Contains(err.Error(), "field 256 (F255): a table has at most 255 fields") {
+		t.Errorf("Want an error for the 256th field, have %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package dbf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Searcher iterates over the records matching a full text search, see DBF.Search.
+// It is used like bufio.Scanner:
+//
+//	s := d.Search("invoice 2019", "NOTES", "SUBJECT")
+//	for s.Next() {
+//		fmt.Println(s.RecNo())
+//	}
+//	if err := s.Err(); err != nil {
+//		return err
+//	}
+type Searcher struct {
+	dbf    *DBF
+	tokens []string
+	fields []int
+	next   uint32
+	recno  uint32
+	err    error
+}
+
+// Search returns a Searcher for the non-deleted records which contain all words of query in the given C and M fields.
+// When no fields are given all C and M fields are searched.
+// The query is split into words on everything which is not a letter or digit. Matching is case insensitive
+// and done on the decoded (UTF8) values, a word matches when it is part of a field value, so "inv" matches "Invoice".
+// The internal record pointer is not used or moved.
+func (dbf *DBF) Search(query string, fields ...string) *Searcher {
+	s := &Searcher{dbf: dbf, tokens: searchTokens(query)}
+	s.fields, s.err = dbf.searchFields(fields)
+	return s
+}
+
+// searchFields returns the positions of the named fields, or of all C and M fields if no names are given
+func (dbf *DBF) searchFields(names []string) ([]int, error) {
+	var positions []int
+	if len(names) == 0 {
+		for i, f := range dbf.fields {
+			if f.Type == 'C' || f.Type == 'M' {
+				positions = append(positions, i)
+			}
+		}
+	}
+	for _, name := range names {
+		pos := dbf.FieldPos(name)
+		if pos < 0 {
+			return nil, fmt.Errorf("field %s not found", name)
+		}
+		positions = append(positions, pos)
+	}
+	return positions, nil
+}
+
+// Next advances to the next matching record, it returns false when there are no more matches or an error occurred
+func (s *Searcher) Next() bool {
+	if s.err != nil || len(s.tokens) == 0 {
+		return false
+	}
+	for s.next < s.dbf.NumRecords() {
+		recno := s.next
+		s.next++
+		match, err := s.match(recno)
+		if err != nil {
+			s.err = fmt.Errorf("record %d: %s", recno, err)
+			return false
+		}
+		if match {
+			s.recno = recno
+			return true
+		}
+	}
+	return false
+}
+
+// RecNo returns the record number of the current match
+func (s *Searcher) RecNo() uint32 {
+	return s.recno
+}
+
+// Err returns the first error that occurred during the search
+func (s *Searcher) Err() error {
+	return s.err
+}
+
+// match returns if all tokens occur in the searched fields of record recno
+func (s *Searcher) match(recno uint32) (bool, error) {
+	deleted, err := s.dbf.DeletedAt(recno)
+	if err != nil || deleted {
+		return false, err
+	}
+	values := make([]string, 0, len(s.fields))
+	for _, pos := range s.fields {
+		str, err := s.dbf.stringAt(recno, pos)
+		if err != nil {
+			return false, err
+		}
+		values = append(values, strings.ToLower(str))
+	}
+	for _, token := range s.tokens {
+		found := false
+		for _, v := range values {
+			if strings.Contains(v, token) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// stringAt returns the decoded value of a field as string, non string values are returned as an empty string
+func (dbf *DBF) stringAt(recno uint32, pos int) (string, error) {
+	raw, err := dbf.readField(recno, pos)
+	if err != nil {
+		return "", err
+	}
+	val, err := dbf.fieldValue(raw, pos)
+	if err != nil {
+		return "", err
+	}
+	return ToString(val), nil
+}
+
+// searchTokens splits a query into lower case words
+func searchTokens(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// GrepMatch is a field value matching the regular expression of a Grep
+type GrepMatch struct {
+	RecNo   uint32  // Record number
+	Field   int     // Field position
+	Name    string  // Field name
+	Value   string  // Decoded field value
+	Indexes [][]int // Start and end positions of all matches in Value, as returned by regexp.FindAllStringIndex
+}
+
+// Grepper iterates over the field values matching a regular expression, see DBF.Grep.
+// It is used like Searcher.
+type Grepper struct {
+	dbf    *DBF
+	re     *regexp.Regexp
+	fields []int
+	recno  uint32
+	field  int // index in fields of the next field to check
+	match  GrepMatch
+	err    error
+}
+
+// Grep returns a Grepper for all values of the given C and M fields of non-deleted records which match re.
+// When no fields are given all C and M fields are searched, including memos.
+// The regular expression is applied to the decoded (UTF8) values, every matching field value is returned separately.
+// The internal record pointer is not used or moved.
+func (dbf *DBF) Grep(re *regexp.Regexp, fields ...string) *Grepper {
+	g := &Grepper{dbf: dbf, re: re}
+	g.fields, g.err = dbf.searchFields(fields)
+	return g
+}
+
+// Next advances to the next matching field value, it returns false when there are no more matches or an error occurred
+func (g *Grepper) Next() bool {
+	for g.err == nil && g.recno < g.dbf.NumRecords() {
+		if g.field == 0 {
+			deleted, err := g.dbf.DeletedAt(g.recno)
+			if err != nil {
+				g.err = err
+				return false
+			}
+			if deleted {
+				g.recno++
+				continue
+			}
+		}
+		if g.field >= len(g.fields) {
+			g.recno++
+			g.field = 0
+			continue
+		}
+		pos := g.fields[g.field]
+		g.field++
+		value, err := g.dbf.stringAt(g.recno, pos)
+		if err != nil {
+			g.err = fmt.Errorf("record %d: %s", g.recno, err)
+			return false
+		}
+		if indexes := g.re.FindAllStringIndex(value, -1); indexes != nil {
+			g.match = GrepMatch{RecNo: g.recno, Field: pos, Name: g.dbf.fields[pos].FieldName(), Value: value, Indexes: indexes}
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the current match
+func (g *Grepper) Match() GrepMatch {
+	return g.match
+}
+
+// Err returns the first error that occurred during the search
+func (g *Grepper) Err() error {
+	return g.err
+}
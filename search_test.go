@@ -0,0 +1,92 @@
+package dbf
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	tests := []struct {
+		query  string
+		fields []string
+		want   []uint32
+	}{
+		{"test", nil, []uint32{0, 2}},
+		{"TEST2", nil, []uint32{2}},
+		{"windows, line", nil, []uint32{0}},
+		{"ÉNCÔDINGS", []string{"MELDING"}, []uint32{2}},
+		{"windows", []string{"MELDING"}, nil},
+		{"", nil, nil},
+	}
+	for _, test := range tests {
+		var have []uint32
+		s := dbf.Search(test.query, test.fields...)
+		for s.Next() {
+			have = append(have, s.RecNo())
+		}
+		if err := s.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, test.want) {
+			t.Errorf("Search %q: want %v, have %v", test.query, test.want, have)
+		}
+	}
+
+	s := dbf.Search("test", "NOFIELD")
+	if s.Next() || s.Err() == nil {
+		t.Error("Want an error for an unknown field")
+	}
+}
+
+func TestSearchTokens(t *testing.T) {
+	have := searchTokens(" Invoice-2019, Čestmír ")
+	want := []string{"invoice", "2019", "čestmír"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+}
+
+func TestGrep(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	var have []GrepMatch
+	g := dbf.Grep(regexp.MustCompile(`(?i)line \d`))
+	for g.Next() {
+		have = append(have, g.Match())
+	}
+	if err := g.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(have) != 1 {
+		t.Fatalf("Want 1 match, have %d", len(have))
+	}
+	m := have[0]
+	if m.RecNo != 0 || m.Name != "MELDING" || len(m.Indexes) != 2 {
+		t.Errorf("Unexpected match %+v", m)
+	}
+	if m.Value[m.Indexes[1][0]:m.Indexes[1][1]] != "line 2" {
+		t.Errorf("Want second match line 2, have %q", m.Value[m.Indexes[1][0]:m.Indexes[1][1]])
+	}
+
+	// every matching field is returned
+	n := 0
+	g = dbf.Grep(regexp.MustCompile(`^TEST|Windows`), "COMP_NAME", "COMP_OS")
+	for g.Next() {
+		n++
+	}
+	if n != 4 {
+		t.Errorf("Want 4 matches, have %d", n)
+	}
+}
@@ -0,0 +1,20 @@
+package dbf
+
+// ShareMode controls which access other processes keep to the table files while they are opened by OpenFileShared.
+// The share mode is only used on Windows, where a file can only be opened when the requested access is compatible
+// with the share modes of all processes which have the file open. On other systems files are opened with os.Open.
+type ShareMode int
+
+const (
+	// ShareReadWrite allows other processes to read and write the files, this is needed to open tables which are
+	// in use by a running FoxPro application (FILE_SHARE_READ|FILE_SHARE_WRITE). This is the default for OpenFile.
+	ShareReadWrite ShareMode = iota
+
+	// ShareRead allows other processes to read but not write the files, the open fails when another process has
+	// the files open for writing, like FoxPro USE ... EXCLUSIVE would (FILE_SHARE_READ)
+	ShareRead
+
+	// ShareReadWriteDelete is ShareReadWrite which also allows other processes to delete or rename the files,
+	// for example to replace a table after a pack (FILE_SHARE_READ|FILE_SHARE_WRITE|FILE_SHARE_DELETE)
+	ShareReadWriteDelete
+)
@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package dbf
+
+import (
+	"os"
+)
+
+// openShared opens filename read-only, or for reading and writing when write is set.
+// Share modes are not used outside Windows.
+func openShared(filename string, mode ShareMode, write bool) (*os.File, error) {
+	if write {
+		return os.OpenFile(filename, os.O_RDWR, 0)
+	}
+	return os.Open(filename)
+}
@@ -0,0 +1,38 @@
+package dbf
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenFileShared(t *testing.T) {
+	for _, mode := range []ShareMode{ShareReadWrite, ShareRead, ShareReadWriteDelete} {
+		dbf, err := OpenFileShared(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder), mode)
+		if err != nil {
+			t.Fatalf("Share mode %d: %s", mode, err)
+		}
+		if dbf.shareMode != mode {
+			t.Errorf("Want share mode %d, have %d", mode, dbf.shareMode)
+		}
+		rec, err := dbf.RecordAt(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.FieldSlice()[0] != int32(3) {
+			t.Errorf("Want ID 3, have %v", rec.FieldSlice()[0])
+		}
+		if err := dbf.Reopen(); err != nil {
+			t.Fatal(err)
+		}
+		if dbf.shareMode != mode {
+			t.Errorf("Want share mode %d after Reopen, have %d", mode, dbf.shareMode)
+		}
+		if err := dbf.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := OpenFileShared(filepath.Join("testdata", "NOTEXIST.DBF"), new(Win1250Decoder), ShareRead); err == nil {
+		t.Error("Want an error opening a missing file")
+	}
+}
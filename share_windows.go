@@ -0,0 +1,37 @@
+//go:build windows
+// +build windows
+
+package dbf
+
+import (
+	"os"
+	"syscall"
+)
+
+// openShared opens filename read-only, or for reading and writing when write is set, with the share mode
+// as dwShareMode.
+// Long paths and long UNC paths are opened with the extended-length prefix, the returned file keeps the original name.
+func openShared(filename string, mode ShareMode, write bool) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(longPath(filename))
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: err}
+	}
+	var share uint32
+	switch mode {
+	case ShareRead:
+		share = syscall.FILE_SHARE_READ
+	case ShareReadWriteDelete:
+		share = syscall.FILE_SHARE_READ | syscall.FILE_SHARE_WRITE | syscall.FILE_SHARE_DELETE
+	default:
+		share = syscall.FILE_SHARE_READ | syscall.FILE_SHARE_WRITE
+	}
+	access := uint32(syscall.GENERIC_READ)
+	if write {
+		access |= syscall.GENERIC_WRITE
+	}
+	h, err := syscall.CreateFile(name, access, share, nil, syscall.OPEN_EXISTING, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: err}
+	}
+	return os.NewFile(uintptr(h), filename), nil
+}
@@ -0,0 +1,173 @@
+package dbf
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+)
+
+// RecordWriter is the destination of Export. The export reads the records, applies the options like the
+// filter and the field selection and passes the result to a RecordWriter, so every output format gets the same
+// features. CSVWriter, JSONLinesWriter, ArrowWriter and ODSWriter are included, other formats can implement RecordWriter.
+type RecordWriter interface {
+	// WriteSchema is called once with the exported fields, before the first record
+	WriteSchema(fields []FieldHeader) error
+
+	// WriteRecord is called for every exported record, the values are in the order of the fields.
+	// The record is not used by Export after WriteRecord returns.
+	WriteRecord(rec *Record) error
+
+	// Close is called once after the last record, also when the export failed
+	Close() error
+}
+
+// ExportOptions controls which records and fields are passed to the RecordWriter by Export
+type ExportOptions struct {
+	// IncludeDeleted also exports deleted records, by default they are skipped
+	IncludeDeleted bool
+
+	// Fields are the names of the exported fields in output order, empty exports all fields.
+	// Hidden system fields like _NullFlags are never exported.
+	Fields []string
+
+	// Filter is called with every record before the field selection, records for which it returns false are skipped
+	Filter func(recno uint32, rec *Record) bool
+
+	// Transforms converts the values of fields by name, after the field selection
+	Transforms map[string]FieldTransform
+
+	// Progress is called after every record with the number of records read and the number of records in the table
+	Progress func(read, total uint32)
+}
+
+// Export writes the records of the table to w and returns the number of exported records.
+// It is a Pipeline with the Filter, Select and Transform steps of the options.
+// Close of w is always called, the first error of the export or Close is returned.
+func (dbf *DBF) Export(w RecordWriter, opts ExportOptions) (uint32, error) {
+	p := Pipeline(dbf).Progress(opts.Progress)
+	if opts.IncludeDeleted {
+		p.IncludeDeleted()
+	}
+	if opts.Filter != nil {
+		p.Filter(opts.Filter)
+	}
+	if len(opts.Fields) > 0 {
+		p.Select(opts.Fields...)
+	}
+	return p.transform(opts.Transforms).To(w)
+}
+
+// project returns a record with the values of the fields at positions, fields are the headers of those fields
+func (r *Record) project(fields []FieldHeader, positions []int) *Record {
+	p := &Record{
+		Deleted:  r.Deleted,
+		data:     make([]interface{}, len(positions)),
+		fields:   fields,
+		layout:   make([]fieldLayout, len(positions)),
+		jsonOpts: r.jsonOpts,
+		raw:      r.raw,
+	}
+	for i, pos := range positions {
+		p.data[i] = r.data[pos]
+		p.layout[i] = r.layout[pos]
+	}
+	for _, pos := range r.invalid {
+		for i, ppos := range positions {
+			if ppos == pos {
+				p.invalid = append(p.invalid, i)
+			}
+		}
+	}
+	return p
+}
+
+// CSVWriter is a RecordWriter which writes a header row with the field names and a row per record
+type CSVWriter struct {
+	// KeepSpaces keeps the padding of C fields, by default spaces are trimmed
+	KeepSpaces bool
+
+	// Format controls how logicals and numbers are rendered, like 1/0 and decimal commas
+	Format FormatOptions
+
+	w      *csv.Writer
+	fields []FieldHeader
+	row    []string
+}
+
+var _ RecordWriter = (*CSVWriter)(nil)
+
+// NewCSVWriter returns a CSVWriter which writes to w with the csv.Writer defaults
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// CSV returns the underlying csv.Writer, to change the delimiter for example
+func (cw *CSVWriter) CSV() *csv.Writer {
+	return cw.w
+}
+
+// WriteSchema writes the header row
+func (cw *CSVWriter) WriteSchema(fields []FieldHeader) error {
+	cw.fields = fields
+	cw.row = make([]string, len(fields))
+	for i := range fields {
+		cw.row[i] = fields[i].FieldName()
+	}
+	return cw.w.Write(cw.row)
+}
+
+// WriteRecord writes a row with the formatted values
+func (cw *CSVWriter) WriteRecord(rec *Record) error {
+	if len(rec.data) != len(cw.fields) {
+		return ErrNumFields
+	}
+	for i, val := range rec.data {
+		if s, ok := val.(string); ok && cw.KeepSpaces {
+			cw.row[i] = s
+			continue
+		}
+		cw.row[i] = cw.Format.FormatValue(val, &cw.fields[i])
+	}
+	return cw.w.Write(cw.row)
+}
+
+// Close flushes the rows, it does not close the underlying writer
+func (cw *CSVWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// JSONLinesWriter is a RecordWriter which writes every record as a JSON object on its own line.
+// The records are written with Record.MarshalJSON, so the JSON options of the table are applied.
+type JSONLinesWriter struct {
+	w *bufio.Writer
+}
+
+var _ RecordWriter = (*JSONLinesWriter)(nil)
+
+// NewJSONLinesWriter returns a JSONLinesWriter which writes to w
+func NewJSONLinesWriter(w io.Writer) *JSONLinesWriter {
+	return &JSONLinesWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteSchema does nothing, JSON Lines has no header
+func (jw *JSONLinesWriter) WriteSchema(fields []FieldHeader) error {
+	return nil
+}
+
+// WriteRecord writes the record as a line of JSON
+func (jw *JSONLinesWriter) WriteRecord(rec *Record) error {
+	b, err := rec.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if _, err := jw.w.Write(b); err != nil {
+		return err
+	}
+	return jw.w.WriteByte('\n')
+}
+
+// Close flushes the output, it does not close the underlying writer
+func (jw *JSONLinesWriter) Close() error {
+	return jw.w.Flush()
+}
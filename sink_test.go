@@ -0,0 +1,173 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordingWriter is a RecordWriter which keeps the schema and the values of the records
+type recordingWriter struct {
+	fields  []FieldHeader
+	records [][]interface{}
+	closed  int
+	err     error // returned by WriteRecord
+}
+
+func (rw *recordingWriter) WriteSchema(fields []FieldHeader) error {
+	rw.fields = fields
+	return nil
+}
+
+func (rw *recordingWriter) WriteRecord(rec *Record) error {
+	if rw.err != nil {
+		return rw.err
+	}
+	rw.records = append(rw.records, rec.FieldSlice())
+	return nil
+}
+
+func (rw *recordingWriter) Close() error {
+	rw.closed++
+	return nil
+}
+
+func TestExport(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	rw := new(recordingWriter)
+	n, err := dbf.Export(rw, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || len(rw.records) != 3 || len(rw.fields) != 13 || rw.closed != 1 {
+		t.Fatalf("Want 3 records with 13 fields closed once, have %d (%d) with %d fields closed %d times",
+			n, len(rw.records), len(rw.fields), rw.closed)
+	}
+
+	var progress []uint32
+	rw = new(recordingWriter)
+	n, err = dbf.Export(rw, ExportOptions{
+		IncludeDeleted: true,
+		Fields:         []string{"COMP_NAME", "ID"},
+		Filter: func(recno uint32, rec *Record) bool {
+			return recno != 0
+		},
+		Progress: func(read, total uint32) {
+			if total != 4 {
+				t.Errorf("Want total 4, have %d", total)
+			}
+			progress = append(progress, read)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("Want 3 records, have %d", n)
+	}
+	if rw.fields[0].FieldName() != "COMP_NAME" || rw.fields[1].FieldName() != "ID" {
+		t.Errorf("Want fields COMP_NAME and ID, have %s and %s", rw.fields[0].FieldName(), rw.fields[1].FieldName())
+	}
+	if len(rw.records[0]) != 2 || rw.records[0][1] != int32(2) {
+		t.Errorf("Want 2 values with ID 2, have %v", rw.records[0])
+	}
+	if len(progress) != 4 || progress[3] != 4 {
+		t.Errorf("Want progress 1 to 4, have %v", progress)
+	}
+
+	rw = new(recordingWriter)
+	if _, err := dbf.Export(rw, ExportOptions{Fields: []string{"NOPE"}}); err == nil {
+		t.Error("Want an error for an unknown field")
+	}
+	if rw.closed != 1 {
+		t.Errorf("Want the writer closed once after an error, closed %d times", rw.closed)
+	}
+
+	rw = &recordingWriter{err: errors.New("disk full")}
+	if _, err := dbf.Export(rw, ExportOptions{}); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Want the WriteRecord error, have %v", err)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	buf := new(bytes.Buffer)
+	w := NewCSVWriter(buf)
+	w.CSV().Comma = ';'
+	w.Format.True, w.Format.False = "Y", "N"
+	if _, err := dbf.Export(w, ExportOptions{Fields: []string{"ID", "COMP_NAME", "NUMBER", "BOOL"}}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Want 4 lines, have %d", len(lines))
+	}
+	if lines[0] != "ID;COMP_NAME;NUMBER;BOOL" {
+		t.Errorf("Unexpected header %q", lines[0])
+	}
+	if lines[1] != "1;TEST;1.66;N" {
+		t.Errorf("Unexpected first row %q", lines[1])
+	}
+}
+
+func TestExportJSONLines(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	dbf.SetJSONOptions(JSONOptions{TrimSpaces: true})
+
+	buf := new(bytes.Buffer)
+	if _, err := dbf.Export(NewJSONLinesWriter(buf), ExportOptions{Fields: []string{"ID", "COMP_NAME"}}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Want 3 lines, have %d", len(lines))
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if len(obj) != 2 || obj["ID"] != float64(1) || obj["COMP_NAME"] != "TEST" {
+		t.Errorf("Unexpected first object %v", obj)
+	}
+}
+
+func TestExportJSONLinesInvalidNumbers(t *testing.T) {
+	dbf := invalidNumbersTable(t)
+	dbf.SetInvalidNumberPolicy(InvalidNumbersNaN)
+	dbf.SetJSONOptions(JSONOptions{Precision: map[string]int{"AMOUNT": 2}})
+
+	buf := new(bytes.Buffer)
+	if n, err := dbf.Export(NewJSONLinesWriter(buf), ExportOptions{}); err != nil || n != 2 {
+		t.Fatalf("Want 2 records, have %d (%v)", n, err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`{"ID":1,"AMOUNT":12.50,"RATE":0.25}`,
+		`{"ID":0,"AMOUNT":null,"RATE":null}`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Want %d lines, have %q", len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Line %d: want %s, have %s", i, want[i], lines[i])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// The SIx driver of Clipper, FlagShip and Harbour stores memos in SMT files. An SMT file has the same header as an
+// FPT file, but its integers are stored with the least significant byte first. The memo blocks have no block header,
+// the memo field of the table is 10 bytes long and contains the type (uint16), the block number (uint32) and the
+// length (uint32) of the memo.
+// The SIx driver can also write FPT files, in which text memos can have their own block type.
+
+const (
+	// smtCharacter is the type of text memos in SMT files
+	smtCharacter = 1
+
+	// sixCharacter is the block type of text memos in FPT files written by the SIx driver
+	sixCharacter = 0x0400
+)
+
+// readSMTHeader reads the header of an SMT file
+func readSMTHeader(r io.ReadSeeker) (*FPTHeader, error) {
+	h := new(FPTHeader)
+	if _, err := r.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	err := binary.Read(r, binary.LittleEndian, h)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// readSMT reads a memo from the SMT file, the return values are the same as for readFPT
+func (dbf *DBF) readSMT(fielddata []byte) ([]byte, bool, error) {
+	if len(fielddata) != 10 {
+		return nil, false, ErrInvalidField
+	}
+	typ := binary.LittleEndian.Uint16(fielddata)
+	block := binary.LittleEndian.Uint32(fielddata[2:])
+	leng := binary.LittleEndian.Uint32(fielddata[6:])
+	if block == 0 || leng == 0 {
+		// no memo
+		return []byte{}, true, nil
+	}
+	if uint64(leng) > uint64(maxInt) {
+		return nil, false, &LimitError{Limit: "memo length", Value: uint64(leng), Max: uint64(maxInt)}
+	}
+	isText := typ == smtCharacter
+	if leng > memoPreallocLimit {
+		buf, err := dbf.readLargeMemo(int64(dbf.fptheader.BlockSize)*int64(block), leng)
+		return buf, isText, err
+	}
+	buf := make([]byte, leng)
+	read, err := dbf.fptr.ReadAt(buf, int64(dbf.fptheader.BlockSize)*int64(block))
+	if read != int(leng) {
+		if err != nil && err != io.EOF {
+			return buf, false, err
+		}
+		return buf, isText, ErrIncomplete
+	}
+	return buf, isText, nil
+}
+
+// memoPointer returns the block number of a memo field, 0 means the field has no memo
+func (dbf *DBF) memoPointer(raw []byte) (uint32, error) {
+	if dbf.smt {
+		if len(raw) != 10 {
+			return 0, ErrInvalidField
+		}
+		return binary.LittleEndian.Uint32(raw[2:]), nil
+	}
+	return memoBlock(raw)
+}
+
+// smtFileName returns the name of the SMT file belonging to a table, in the same case as the extension
+func smtFileName(filename string) string {
+	return replaceExt(filename, ".smt")
+}
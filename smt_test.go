@@ -0,0 +1,91 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// smtTable returns a SIx (0xE5) table and its SMT file with a C and an M field
+func smtTable() ([]byte, []byte) {
+	data, _ := foxPro2Table()
+	data[0] = 0xE5
+	first := 32 + 2*32 + 1
+	pointer := make([]byte, 10)
+	binary.LittleEndian.PutUint16(pointer, smtCharacter)
+	binary.LittleEndian.PutUint32(pointer[2:], 16)
+	binary.LittleEndian.PutUint32(pointer[6:], 5)
+	copy(data[first+11:], pointer)
+	copy(data[first+21+11:], make([]byte, 10))
+
+	smt := make([]byte, 512+32)
+	binary.LittleEndian.PutUint32(smt[0:], 17)
+	binary.LittleEndian.PutUint16(smt[6:], 32)
+	copy(smt[512:], "hello")
+	return data, smt
+}
+
+func TestSMT(t *testing.T) {
+	data, memo := smtTable()
+
+	dir, err := ioutil.TempDir("", "dbfsmt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "SIX.DBF"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "SIX.SMT"), memo, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromFile, err := OpenFile(filepath.Join(dir, "SIX.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fromFile.Close()
+	fromStream, err := OpenStream(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dbf := range []*DBF{fromFile, fromStream} {
+		if dbf.VersionInfo().MemoFile != "SMT" {
+			t.Errorf("Want an SMT memo file, have %+v", dbf.VersionInfo())
+		}
+		want := []interface{}{"hello", ""}
+		for recno, val := range want {
+			rec, err := dbf.RecordAt(uint32(recno))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if notes := rec.FieldSlice()[1]; notes != val {
+				t.Errorf("Record %d: want %q, have %q", recno, val, notes)
+			}
+		}
+	}
+
+	if _, err := fromStream.PackTo(new(bytes.Buffer), &memWriteSeeker{}); err == nil {
+		t.Error("Want an error packing a table with an SMT file")
+	}
+}
+
+func TestSIxCharacterMemo(t *testing.T) {
+	data, memo := foxPro2Table()
+	binary.BigEndian.PutUint32(memo[512:], sixCharacter)
+	dbf, err := OpenStream(bytes.NewReader(data), bytes.NewReader(memo), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notes := rec.FieldSlice()[1]; notes != "hello" {
+		t.Errorf("Want text memo hello, have %v", notes)
+	}
+}
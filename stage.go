@@ -0,0 +1,190 @@
+package dbf
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrStaged is returned when reopening a table which was opened with OpenFileStaged
+	ErrStaged = errors.New("table is staged, use Flush to write the changes")
+	// ErrNotStaged is returned when discarding the changes of a table which was not opened with OpenFileStaged
+	ErrNotStaged = errors.New("table is not staged")
+)
+
+// staging holds the copies of the files of a table opened with OpenFileStaged
+type staging struct {
+	rw       *Rewrite // replaces the files with the copies
+	filename string   // the DBF file
+	memoname string   // the memo file, empty when the table has none
+}
+
+// OpenFileStaged opens a table for changes like OpenFileForUpdate, but the changes are not written to the files of
+// the table. The files are copied to temporary files in the same directory which are changed instead, Flush and Close
+// replace the files of the table with the copies at once with a Rewrite. Other programs read the table unchanged
+// until then, and a program which stops halfway through a series of changes leaves the table as it was.
+// Discard closes the table without writing the changes.
+// Changes of other programs to the files after they were copied are lost on Flush, use a Locker to prevent them.
+func OpenFileStaged(filename string, dec Decoder, enc Encoder) (*DBF, error) {
+	orig, err := openFile(filename, dec, ShareReadWrite, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	enc, err = orig.tableEncoder(enc)
+	memoname := ""
+	if orig.fptf != nil {
+		memoname = orig.fptf.Name()
+	}
+	dbf := &DBF{dec: dec, smt: orig.smt, shareMode: ShareReadWrite, upd: &updater{enc: enc}}
+	orig.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := dbf.stage(filepath.Clean(filename), memoname); err != nil {
+		return nil, err
+	}
+	return dbf, nil
+}
+
+// Flush replaces the files of a staged table with the changed copies, the table stays open with new copies for
+// further changes. When Flush fails the files of the table are not changed, the changes are lost and the table can
+// only be closed. For tables opened with OpenFileForUpdate, which are changed directly, Flush syncs the files to disk.
+func (dbf *DBF) Flush() error {
+	if dbf.upd == nil {
+		return nil
+	}
+	st := dbf.upd.stage
+	if st == nil {
+		if err := dbf.f.Sync(); err != nil {
+			return err
+		}
+		if dbf.fptf != nil {
+			return dbf.fptf.Sync()
+		}
+		return nil
+	}
+	if err := dbf.commitStage(); err != nil {
+		return err
+	}
+	dbf.upd.modified = false
+	return dbf.stage(st.filename, st.memoname)
+}
+
+// Discard closes a staged table without writing the changes since the last Flush, the copies are removed
+func (dbf *DBF) Discard() error {
+	if dbf.upd == nil || dbf.upd.stage == nil {
+		return ErrNotStaged
+	}
+	st := dbf.upd.stage
+	dbf.upd.stage = nil
+	// the copies are closed by the rewrite
+	dbf.f, dbf.fptf = nil, nil
+	st.rw.Abort()
+	return dbf.Close()
+}
+
+// commitStage replaces the files of the table with the copies, the table has no files afterwards
+func (dbf *DBF) commitStage() error {
+	st := dbf.upd.stage
+	dbf.upd.stage = nil
+	// the copies are closed by the rewrite
+	dbf.f, dbf.fptf = nil, nil
+	return st.rw.Commit()
+}
+
+// stage copies the files filename and memoname to new temporary files and continues the table with the copies
+func (dbf *DBF) stage(filename, memoname string) error {
+	st, dbffile, fptfile, err := newStaging(filename, memoname)
+	if err != nil {
+		return err
+	}
+	err = copyFileTo(dbffile, filename)
+	if err == nil && fptfile != nil {
+		err = copyFileTo(fptfile, memoname)
+	}
+	if err == nil {
+		err = dbf.openStaged(dbffile, fptfile)
+	}
+	if err != nil {
+		st.rw.Abort()
+		return err
+	}
+	dbf.upd.stage = st
+	return nil
+}
+
+// packStaged packs a staged table to new copies, which replace the current copies
+func (dbf *DBF) packStaged() (*PackReport, error) {
+	st, dbfout, fptout, err := newStaging(dbf.upd.stage.filename, dbf.upd.stage.memoname)
+	if err != nil {
+		return nil, err
+	}
+	var memo io.WriteSeeker
+	if fptout != nil {
+		memo = fptout
+	}
+	report, err := dbf.PackTo(dbfout, memo)
+	if err == nil {
+		// the current copies are closed by aborting their rewrite
+		old := dbf.upd.stage
+		if err = dbf.openStaged(dbfout, fptout); err == nil {
+			old.rw.Abort()
+			dbf.upd.stage = st
+			return report, nil
+		}
+	}
+	st.rw.Abort()
+	return nil, err
+}
+
+// newStaging creates the temporary files which replace filename and memoname, the memo file is nil without memoname
+func newStaging(filename, memoname string) (*staging, *os.File, *os.File, error) {
+	st := &staging{rw: NewRewrite(), filename: filename, memoname: memoname}
+	dbffile, err := st.rw.Create(filename)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var fptfile *os.File
+	if memoname != "" {
+		if fptfile, err = st.rw.Create(memoname); err != nil {
+			st.rw.Abort()
+			return nil, nil, nil, err
+		}
+	}
+	return st, dbffile, fptfile, nil
+}
+
+// openStaged continues the table with the copies dbffile and fptfile, with the settings of the table.
+// The current files are not closed.
+func (dbf *DBF) openStaged(dbffile, fptfile *os.File) error {
+	staged, err := prepareDBF(dbffile, dbf.dec, nil)
+	if err != nil {
+		return err
+	}
+	staged.f = dbffile
+	staged.shareMode = dbf.shareMode
+	if staged.header.HasMemo() {
+		if fptfile == nil {
+			return ErrNoFPTFile
+		}
+		staged.smt = dbf.smt
+		if err := staged.prepareFPT(fptfile); err != nil {
+			return err
+		}
+		staged.fptf = fptfile
+	}
+	return dbf.replace(staged)
+}
+
+// copyFileTo copies the contents of the file name to dst
+func copyFileTo(dst *os.File, name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = io.Copy(dst, src)
+	return err
+}
@@ -0,0 +1,145 @@
+package dbf
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestOpenFileStaged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfstage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+
+	dbf, err := OpenFileStaged(name, new(Win1250Decoder), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	if err := dbf.WriteFieldAt(2, 0, 42); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.WriteFieldAt(2, dbf.FieldPos("MELDING"), "staged memo"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := dbf.Int64At(2, 0); err != nil || v != 42 {
+		t.Errorf("Want the staged table to read 42, have %d (%v)", v, err)
+	}
+	if err := dbf.Reopen(); err != ErrStaged {
+		t.Errorf("Want ErrStaged, have %v", err)
+	}
+
+	// the table is not changed until Flush
+	stagedID := func() int64 {
+		t.Helper()
+		read, err := OpenFile(name, new(Win1250Decoder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer read.Close()
+		v, err := read.Int64At(2, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if v := stagedID(); v == 42 {
+		t.Error("Want the table unchanged before Flush")
+	}
+	if err := dbf.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if v := stagedID(); v != 42 {
+		t.Errorf("Want 42 after Flush, have %d", v)
+	}
+
+	// changes after Flush and a pack are staged again
+	if err := dbf.MarkDeleted(3); err != nil {
+		t.Fatal(err)
+	}
+	report, err := dbf.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Kept != 2 || dbf.NumRecords() != 2 {
+		t.Errorf("Want 2 records after the pack, have %+v", report)
+	}
+	if original, err := OpenFile(name, new(Win1250Decoder)); err != nil || original.NumRecords() != 4 {
+		t.Errorf("Want 4 records in the table before Close (%v)", err)
+	} else {
+		original.Close()
+	}
+	if err := dbf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	packed, err := OpenFile(name, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer packed.Close()
+	if packed.NumRecords() != 2 {
+		t.Errorf("Want 2 records after Close, have %d", packed.NumRecords())
+	}
+	rec, err := packed.RecordAt(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memo := rec.FieldSlice()[packed.FieldPos("MELDING")]; memo != "staged memo" {
+		t.Errorf("Want the staged memo, have %q", memo)
+	}
+	if report, err := packed.AuditMemos(); err != nil || len(report.Problems) != 0 {
+		t.Errorf("Want a consistent memo file, have %+v (%v)", report, err)
+	}
+	if files, err := ioutil.ReadDir(dir); err != nil || len(files) != 2 {
+		t.Errorf("Want only the table and its memo file, have %d files (%v)", len(files), err)
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfstage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+	dbfdata, fptdata := readTestFiles(t)
+
+	dbf, err := OpenFileStaged(name, new(Win1250Decoder), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.WriteFieldAt(0, dbf.FieldPos("MELDING"), "discarded"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.Zap(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(name); err != nil || string(data) != string(dbfdata) {
+		t.Errorf("Want the DBF file unchanged (%v)", err)
+	}
+	if data, err := ioutil.ReadFile(memoFileName(name)); err != nil || string(data) != string(fptdata) {
+		t.Errorf("Want the FPT file unchanged (%v)", err)
+	}
+	if files, err := ioutil.ReadDir(dir); err != nil || len(files) != 2 {
+		t.Errorf("Want no copies left, have %d files (%v)", len(files), err)
+	}
+
+	direct, err := OpenFileForUpdate(name, new(Win1250Decoder), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer direct.Close()
+	if err := direct.Discard(); err != ErrNotStaged {
+		t.Errorf("Want ErrNotStaged, have %v", err)
+	}
+	if err := direct.Flush(); err != nil {
+		t.Error(err)
+	}
+}
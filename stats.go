@@ -0,0 +1,136 @@
+package dbf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// StatsCacheExt is added to the file name of a table to get the name of its statistics cache, see CachedStats
+const StatsCacheExt = ".stats"
+
+// TableStats contains the record counts and the column statistics of a table
+type TableStats struct {
+	Records uint32           // Number of records, including deleted records
+	Deleted uint32           // Number of deleted records
+	Columns []*ColumnProfile // Statistics of every field, see Profile
+
+	// Cached is set by CachedStats when the statistics were read from the cache
+	Cached bool `json:"-"`
+}
+
+// Stats reads all records once and returns the record counts and the statistics of every field
+func (dbf *DBF) Stats(opts ProfileOptions) (*TableStats, error) {
+	columns, deleted, err := dbf.profile(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &TableStats{Records: dbf.NumRecords(), Deleted: deleted, Columns: columns}, nil
+}
+
+// statsCache is the contents of a statistics cache file
+type statsCache struct {
+	Header  []byte      // the first 32 bytes of the table
+	Files   []cacheFile // the table and its memo file
+	Options ProfileOptions
+	Stats   *TableStats
+}
+
+// cacheFile is the size and modification time of a file the statistics were calculated from
+type cacheFile struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// CachedStats returns the statistics of the table at path like Stats, and keeps them in a cache file next to the
+// table (the file name of the table with StatsCacheExt added). When the table and its memo file have the same size,
+// modification time and header as when the cache was written, and the options are the same, the statistics are
+// read from the cache without opening the table. Otherwise the table is opened with dec and read completely, and the
+// cache is replaced. A cache which can not be written, like in a read-only directory, is not an error.
+func CachedStats(path string, dec Decoder, opts ProfileOptions) (*TableStats, error) {
+	cacheName := path + StatsCacheExt
+	before, err := readStatsKey(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := ioutil.ReadFile(cacheName); err == nil {
+		cache := new(statsCache)
+		if json.Unmarshal(data, cache) == nil && cache.Stats != nil && cache.sameKey(before) {
+			cache.Stats.Cached = true
+			return cache.Stats, nil
+		}
+	}
+
+	d, err := OpenFile(path, dec)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := d.Stats(opts)
+	d.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// statistics of a table which changed while it was read are not cached
+	after, err := readStatsKey(path, opts)
+	if err != nil || !after.sameKey(before) {
+		return stats, nil
+	}
+	before.Stats = stats
+	writeStatsCache(cacheName, before)
+	return stats, nil
+}
+
+// readStatsKey returns a cache without statistics, with the state of the table at path and the options
+func readStatsKey(path string, opts ProfileOptions) (*statsCache, error) {
+	set, err := DiscoverSet(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{set.Table}
+	if set.Memo != "" {
+		files = append(files, set.Memo)
+	}
+	state, err := readTableState(files)
+	if err != nil {
+		return nil, err
+	}
+	cache := &statsCache{Header: state.header, Options: opts}
+	for i := range files {
+		cache.Files = append(cache.Files, cacheFile{Size: state.sizes[i], ModTime: state.modTimes[i]})
+	}
+	return cache, nil
+}
+
+// sameKey returns if both caches are for the same state of the table and the same options
+func (c *statsCache) sameKey(other *statsCache) bool {
+	if string(c.Header) != string(other.Header) || c.Options != other.Options || len(c.Files) != len(other.Files) {
+		return false
+	}
+	for i := range c.Files {
+		if c.Files[i].Size != other.Files[i].Size || !c.Files[i].ModTime.Equal(other.Files[i].ModTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeStatsCache replaces the cache file with a Rewrite, so a cache is never half-written. Errors are ignored,
+// without a cache the statistics are calculated again the next time.
+func writeStatsCache(name string, cache *statsCache) {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		// like NaN values, which JSON does not support
+		return
+	}
+	rw := NewRewrite()
+	f, err := rw.Create(name)
+	if err != nil {
+		return
+	}
+	if _, err := f.Write(data); err != nil {
+		rw.Abort()
+		return
+	}
+	rw.Commit()
+}
@@ -0,0 +1,85 @@
+package dbf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	stats, err := dbf.Stats(ProfileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Records != 4 || stats.Deleted != 1 || len(stats.Columns) != 13 || stats.Columns[0].Count != 3 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestCachedStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfstats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"TEST.DBF", "TEST.FPT"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	table := filepath.Join(dir, "TEST.DBF")
+
+	opts := ProfileOptions{TopK: 3}
+	first, err := CachedStats(table, new(Win1250Decoder), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached || first.Records != 4 || first.Deleted != 1 {
+		t.Errorf("Want calculated stats, have %+v", first)
+	}
+	if _, err := os.Stat(table + StatsCacheExt); err != nil {
+		t.Fatalf("Want a cache file, have %v", err)
+	}
+
+	second, err := CachedStats(table, new(Win1250Decoder), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Cached || second.Records != 4 || second.Deleted != 1 || len(second.Columns) != 13 {
+		t.Errorf("Want cached stats, have %+v", second)
+	}
+	if second.Columns[0].Distinct != first.Columns[0].Distinct || len(second.Columns[0].TopValues) != 3 {
+		t.Errorf("Want the same column stats from the cache, have %+v", second.Columns[0])
+	}
+
+	// other options and a changed memo file are calculated again
+	other, err := CachedStats(table, new(Win1250Decoder), ProfileOptions{TopK: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Cached {
+		t.Error("Want stats with other options calculated again")
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "TEST.FPT"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := CachedStats(table, new(Win1250Decoder), ProfileOptions{TopK: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Cached {
+		t.Error("Want stats of a changed table calculated again")
+	}
+}
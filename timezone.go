@@ -0,0 +1,43 @@
+package dbf
+
+import "time"
+
+// timeZone is the time zone conversion of T values set with SetTimeZone
+type timeZone struct {
+	source *time.Location
+	target *time.Location
+}
+
+// SetTimeZone sets the time zone of T (datetime) values. FoxPro stores datetimes without a time zone, by default
+// they are returned as UTC times with the stored date and time. With a source location the stored values are the
+// local time in that zone, they are converted to target or kept in source when target is nil. A nil source removes
+// the conversion.
+//
+// The converted values are returned by all readers, so every export uses them: CSV and Print write the date and time
+// in target, JSON writes RFC 3339 with the offset of target and Arrow writes the moment in time. D values do not have
+// a time and are never converted. Stored times which do not exist in source, because the clock is moved forward for
+// daylight saving time, are normalized like time.Date does.
+func (dbf *DBF) SetTimeZone(source, target *time.Location) {
+	if source == nil {
+		dbf.zone = nil
+		return
+	}
+	if target == nil {
+		target = source
+	}
+	dbf.zone = &timeZone{source: source, target: target}
+}
+
+// TimeZone returns the locations set with SetTimeZone, nil when T values are not converted
+func (dbf *DBF) TimeZone() (source, target *time.Location) {
+	if dbf.zone == nil {
+		return nil, nil
+	}
+	return dbf.zone.source, dbf.zone.target
+}
+
+// convert interprets the date and time of the UTC time t in the source location and returns it in the target location
+func (z *timeZone) convert(t time.Time) time.Time {
+	local := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), z.source)
+	return local.In(z.target)
+}
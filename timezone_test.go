@@ -0,0 +1,86 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSetTimeZone(t *testing.T) {
+	dbffile, fptfile := new(memWriteSeeker), new(memWriteSeeker)
+	fields := []FieldHeader{newField("BORN", 'D', 0, 0), newField("CHANGED", 'T', 0, 0)}
+	wr, err := NewWriter(dbffile, fptfile, fields, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	born := time.Date(1985, 4, 12, 0, 0, 0, 0, time.UTC)
+	changed := time.Date(2019, 11, 3, 14, 5, 6, 0, time.UTC)
+	if err := wr.Append(born, changed); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(dbffile.buf), bytes.NewReader(fptfile.buf), new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cet := time.FixedZone("CET", 3600)
+	est := time.FixedZone("EST", -5*3600)
+	dbf.SetTimeZone(cet, est)
+	if source, target := dbf.TimeZone(); source != cet || target != est {
+		t.Errorf("Want CET and EST, have %v and %v", source, target)
+	}
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have := rec.FieldSlice()[1].(time.Time)
+	if !have.Equal(changed.Add(-time.Hour)) || have.Location() != est || have.Hour() != 8 {
+		t.Errorf("Want 08:05:06 EST, have %v", have)
+	}
+	if d := rec.FieldSlice()[0].(time.Time); !d.Equal(born) {
+		t.Errorf("Want the date unchanged, have %v", d)
+	}
+	if text := new(FormatOptions).FormatValue(have, &fields[1]); text != "2019-11-03 08:05:06" {
+		t.Errorf("Want the time in EST, have %q", text)
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`"2019-11-03T08:05:06-05:00"`)) {
+		t.Errorf("Want the time with the EST offset in %s", data)
+	}
+
+	// without target the values stay in the source zone, empty values stay empty
+	dbf.SetTimeZone(cet, nil)
+	rec, err = dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := rec.FieldSlice()[1].(time.Time); have.Location() != cet || have.Hour() != 14 {
+		t.Errorf("Want 14:05:06 CET, have %v", have)
+	}
+	empty, err := dbf.RecordAt(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := empty.FieldSlice()[1].(time.Time); !have.IsZero() {
+		t.Errorf("Want an empty datetime, have %v", have)
+	}
+
+	dbf.SetTimeZone(nil, nil)
+	rec, err = dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := rec.FieldSlice()[1].(time.Time); !have.Equal(changed) || have.Location() != time.UTC {
+		t.Errorf("Want the stored time in UTC, have %v", have)
+	}
+}
@@ -0,0 +1,99 @@
+package dbf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldTransform converts the value of a field during an export, see ExportOptions.Transforms and Pipe.Transform.
+// It receives nil for null values. The returned value does not have to be of the field type: writers which
+// format values, like CSVWriter and JSONLinesWriter, write it as is, writers with typed columns like ArrowWriter
+// convert it to the type of the field.
+type FieldTransform func(val interface{}, f *FieldHeader) (interface{}, error)
+
+// TrimValue is a FieldTransform which removes the leading and trailing spaces of string values
+func TrimValue(val interface{}, f *FieldHeader) (interface{}, error) {
+	if s, ok := val.(string); ok {
+		return strings.TrimSpace(s), nil
+	}
+	return val, nil
+}
+
+// UpperValue is a FieldTransform which converts string values to upper case
+func UpperValue(val interface{}, f *FieldHeader) (interface{}, error) {
+	if s, ok := val.(string); ok {
+		return strings.ToUpper(s), nil
+	}
+	return val, nil
+}
+
+// LowerValue is a FieldTransform which converts string values to lower case
+func LowerValue(val interface{}, f *FieldHeader) (interface{}, error) {
+	if s, ok := val.(string); ok {
+		return strings.ToLower(s), nil
+	}
+	return val, nil
+}
+
+// LookupValue returns a FieldTransform which replaces codes by their description in l.
+// Numeric codes are formatted with the decimals of the field. Unknown codes and null values are not changed.
+func LookupValue(l *Lookup) FieldTransform {
+	return func(val interface{}, f *FieldHeader) (interface{}, error) {
+		if val == nil {
+			return nil, nil
+		}
+		if desc, ok := l.Get(new(FormatOptions).FormatValue(val, f)); ok {
+			return desc, nil
+		}
+		return val, nil
+	}
+}
+
+// LabelValue returns a FieldTransform which replaces codes by their label, like LookupValue with a map
+func LabelValue(labels map[string]string) FieldTransform {
+	return LookupValue(&Lookup{values: labels})
+}
+
+// ChainTransforms returns a FieldTransform which applies the transforms in order
+func ChainTransforms(transforms ...FieldTransform) FieldTransform {
+	return func(val interface{}, f *FieldHeader) (interface{}, error) {
+		for _, t := range transforms {
+			var err error
+			if val, err = t(val, f); err != nil {
+				return nil, err
+			}
+		}
+		return val, nil
+	}
+}
+
+// Transform adds a step which replaces the value of field name by the result of fn
+func (p *Pipe) Transform(name string, fn FieldTransform) *Pipe {
+	pos := p.fieldPos(name)
+	if pos < 0 {
+		return p
+	}
+	f := p.fields[pos]
+	return p.Map(func(recno uint32, rec *Record) error {
+		val, err := fn(rec.data[pos], &f)
+		if err != nil {
+			return fmt.Errorf("field %s: %s", name, err)
+		}
+		rec.data[pos] = val
+		return nil
+	})
+}
+
+// transform adds a Transform step for every field in transforms, sorted by name
+func (p *Pipe) transform(transforms map[string]FieldTransform) *Pipe {
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p.Transform(name, transforms[name])
+	}
+	return p
+}
@@ -0,0 +1,85 @@
+package dbf
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFieldTransforms(t *testing.T) {
+	f := &FieldHeader{Type: 'C'}
+	tests := []struct {
+		transform FieldTransform
+		in, want  interface{}
+	}{
+		{TrimValue, "  Jan  ", "Jan"},
+		{UpperValue, "Jan", "JAN"},
+		{LowerValue, "Jan", "jan"},
+		{UpperValue, int32(5), int32(5)},
+		{UpperValue, nil, nil},
+		{ChainTransforms(TrimValue, UpperValue), " jan ", "JAN"},
+		{LabelValue(map[string]string{"A": "Active"}), "A ", "Active"},
+		{LabelValue(map[string]string{"A": "Active"}), "B", "B"},
+		{LabelValue(map[string]string{"A": "Active"}), nil, nil},
+	}
+	for i, test := range tests {
+		have, err := test.transform(test.in, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have != test.want {
+			t.Errorf("Test %d: want %v, have %v", i, test.want, have)
+		}
+	}
+
+	// numeric codes are formatted with the decimals of the field
+	have, _ := LabelValue(map[string]string{"1.50": "one and a half"})(1.5, &FieldHeader{Type: 'N', Len: 5, Decimals: 2})
+	if have != "one and a half" {
+		t.Errorf("Want the label of 1.50, have %v", have)
+	}
+}
+
+func TestExportTransforms(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	// the same transforms apply to every writer
+	opts := ExportOptions{
+		Fields: []string{"ID", "COMP_NAME"},
+		Transforms: map[string]FieldTransform{
+			"COMP_NAME": ChainTransforms(TrimValue, LowerValue),
+			"ID":        LabelValue(map[string]string{"1": "first"}),
+		},
+	}
+	buf := new(bytes.Buffer)
+	if _, err := dbf.Export(NewCSVWriter(buf), opts); err != nil {
+		t.Fatal(err)
+	}
+	if line := strings.Split(buf.String(), "\n")[1]; line != "first,test" {
+		t.Errorf("Unexpected CSV row %q", line)
+	}
+	buf.Reset()
+	if _, err := dbf.Export(NewJSONLinesWriter(buf), opts); err != nil {
+		t.Fatal(err)
+	}
+	if line := strings.Split(buf.String(), "\n")[0]; line != `{"ID":"first","COMP_NAME":"test"}` {
+		t.Errorf("Unexpected JSON line %q", line)
+	}
+
+	opts.Transforms = map[string]FieldTransform{"NIVEAU": TrimValue}
+	if _, err := dbf.Export(new(recordingWriter), opts); err == nil {
+		t.Error("Want an error for a transform of a field which is not exported")
+	}
+
+	opts.Transforms = map[string]FieldTransform{"ID": func(val interface{}, f *FieldHeader) (interface{}, error) {
+		return nil, errors.New("no IDs")
+	}}
+	if _, err := dbf.Export(new(recordingWriter), opts); err == nil || !strings.Contains(err.Error(), "field ID: no IDs") {
+		t.Errorf("Want the transform error, have %v", err)
+	}
+}
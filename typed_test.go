@@ -0,0 +1,226 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/SebastiaanKlippert/go-foxpro-dbf/jd"
+)
+
+func TestTypedFields(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+		rec, err := dbf.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for pos, f := range dbf.Fields() {
+			want := rec.FieldSlice()[pos]
+			switch f.Type {
+			case 'I':
+				if have, err := dbf.Int64At(recno, pos); err != nil || have != int64(want.(int32)) {
+					t.Errorf("Record %d field %s: want %v, have %v (%v)", recno, f.FieldName(), want, have, err)
+				}
+				if have, err := dbf.Float64At(recno, pos); err != nil || have != float64(want.(int32)) {
+					t.Errorf("Record %d field %s: want %v, have %v (%v)", recno, f.FieldName(), want, have, err)
+				}
+			case 'N':
+				if f.Decimals == 0 {
+					if have, err := dbf.Int64At(recno, pos); err != nil || have != want {
+						t.Errorf("Record %d field %s: want %v, have %v (%v)", recno, f.FieldName(), want, have, err)
+					}
+					continue
+				}
+				fallthrough
+			case 'F':
+				if have, err := dbf.Float64At(recno, pos); err != nil || have != want {
+					t.Errorf("Record %d field %s: want %v, have %v (%v)", recno, f.FieldName(), want, have, err)
+				}
+			case 'D':
+				if have, err := dbf.TimeAt(recno, pos); err != nil || !have.Equal(want.(time.Time)) {
+					t.Errorf("Record %d field %s: want %v, have %v (%v)", recno, f.FieldName(), want, have, err)
+				}
+			case 'L':
+				if have, err := dbf.BoolAt(recno, pos); err != nil || have != want {
+					t.Errorf("Record %d field %s: want %v, have %v (%v)", recno, f.FieldName(), want, have, err)
+				}
+			}
+		}
+	}
+
+	if _, err := dbf.Int64At(0, dbf.FieldPos("COMP_NAME")); err == nil {
+		t.Error("Want an error reading a C field as int64")
+	}
+	if _, err := dbf.TimeAt(0, len(dbf.Fields())); err != ErrInvalidField {
+		t.Errorf("Want ErrInvalidField, have %v", err)
+	}
+}
+
+func TestParseNumbers(t *testing.T) {
+	dbf := new(DBF)
+	for _, s := range []string{"0", "  12", "-600", "+7", "123456789012345", "1234567890123456789", "-0", "1.5", "", "-", "1e3"} {
+		want, wantErr := strconv.ParseInt(s, 10, 64)
+		if s == "  12" {
+			want, wantErr = 12, nil
+		}
+		if s == "" {
+			wantErr = nil
+		}
+		have, err := dbf.parseNumericInt([]byte(s))
+		if have != want || (err == nil) != (wantErr == nil) {
+			t.Errorf("parseNumericInt(%q): want %d (%v), have %d (%v)", s, want, wantErr, have, err)
+		}
+	}
+	for _, s := range []string{"1.66", "-1.66", " 0.1", "12345678.123", "-0.00", ".5", "1.", "0.1234567890123456", "1e3", "abc"} {
+		want, wantErr := strconv.ParseFloat(s, 64)
+		if s == " 0.1" {
+			want, wantErr = 0.1, nil
+		}
+		have, err := dbf.parseFloat([]byte(s))
+		if have != want || math.Signbit(have) != math.Signbit(want) || (err == nil) != (wantErr == nil) {
+			t.Errorf("parseFloat(%q): want %v (%v), have %v (%v)", s, want, wantErr, have, err)
+		}
+	}
+	for _, s := range []string{"20150103", "20150131", "20000229", "20010229", "21000229", "20150431", "20151301", "00000000", "2015-1-3", "        "} {
+		want, wantErr := time.Parse("20060102", s)
+		if s == "        " {
+			wantErr = nil
+		}
+		have, err := dbf.parseDate([]byte(s))
+		if !have.Equal(want) || (err == nil) != (wantErr == nil) {
+			t.Errorf("parseDate(%q): want %v (%v), have %v (%v)", s, want, wantErr, have, err)
+		}
+	}
+}
+
+func TestParseDates(t *testing.T) {
+	dbf := new(DBF)
+	raw := make([]byte, 8)
+	for day := time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC); day.Year() < 2500; day = day.AddDate(0, 0, 1) {
+		want := day.Format("20060102")
+		have, err := dbf.parseDate([]byte(want))
+		if err != nil || !have.Equal(day) || have.Location() != time.UTC {
+			t.Fatalf("parseDate(%s): have %v (%v)", want, have, err)
+		}
+
+		// reference implementation of parseDateTime
+		julDat := jd.YMD2J(day.Year(), int(day.Month()), day.Day())
+		mSec := (julDat * 7919) % 86400000
+		y, m, d := jd.J2YMD(julDat)
+		wantTime := time.Date(y, time.Month(m), d, 0, 0, mSec/1000, (mSec%1000)*int(time.Millisecond), time.UTC)
+		binary.LittleEndian.PutUint32(raw[:4], uint32(julDat))
+		binary.LittleEndian.PutUint32(raw[4:], uint32(mSec))
+		if have, err := dbf.parseDateTime(raw); err != nil || !have.Equal(wantTime) {
+			t.Fatalf("parseDateTime(%d, %d): want %v, have %v (%v)", julDat, mSec, wantTime, have, err)
+		}
+	}
+	for _, julDat := range []uint32{0, 1, uint32(julianMin) - 1, uint32(julianMax) + 1, math.MaxUint32} {
+		binary.LittleEndian.PutUint32(raw[:4], julDat)
+		if have, err := dbf.parseDateTime(raw); err != nil || !have.IsZero() {
+			t.Errorf("parseDateTime(%d): want the zero time, have %v (%v)", julDat, have, err)
+		}
+	}
+}
+
+func BenchmarkParseDate(b *testing.B) {
+	dbf := new(DBF)
+	raw := []byte("20151231")
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := dbf.parseDate(raw); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseDateTime(b *testing.B) {
+	dbf := new(DBF)
+	raw := make([]byte, 8)
+	binary.LittleEndian.PutUint32(raw[:4], 2457388)
+	binary.LittleEndian.PutUint32(raw[4:], 54000123)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := dbf.parseDateTime(raw); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFieldInterface(b *testing.B) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer dbf.Close()
+	pos := dbf.FieldPos("NUMBER")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		raw, err := dbf.readField(0, pos)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := dbf.fieldDataToValue(raw, pos); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFloat64At(b *testing.B) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer dbf.Close()
+	pos := dbf.FieldPos("NUMBER")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := dbf.Float64At(0, pos); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCurrencyRoundTrip(t *testing.T) {
+	dbffile := new(memWriteSeeker)
+	wr, err := NewWriter(dbffile, nil, []FieldHeader{newField("PRICE", 'Y', 0, 0)}, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := []float64{-5.5, 12.3456, 0, -922337203685.4775}
+	for _, v := range values {
+		if err := wr.Append(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(dbffile.buf), nil, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for recno, want := range values {
+		rec, err := dbf.RecordAt(uint32(recno))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := rec.FieldSlice()[0]; have != want {
+			t.Errorf("Record %d: want %v, have %v", recno, want, have)
+		}
+		if have, err := dbf.Float64At(uint32(recno), 0); err != nil || have != want {
+			t.Errorf("Record %d: want %v from Float64At, have %v (%v)", recno, want, have, err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package dbf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MappedType is the target type of a TypeRule
+type MappedType int
+
+const (
+	// TypeString converts values to text like FormatValue, C and M values are not changed
+	TypeString MappedType = iota + 1
+	// TypeInt64 converts numbers without a fraction, logicals and character values containing an integer to int64
+	TypeInt64
+	// TypeFloat64 converts numbers, logicals and character values containing a number to float64
+	TypeFloat64
+	// TypeDecimal converts numbers to a string with the exact decimals of the field, like "12.50",
+	// for targets which store decimals without rounding errors
+	TypeDecimal
+	// TypeBool converts character values to a bool using TypeRule.True, numbers are true when they are not 0
+	TypeBool
+)
+
+// String returns the name of the type, which is the Go type of the converted values or "decimal"
+func (t MappedType) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeInt64:
+		return "int64"
+	case TypeFloat64:
+		return "float64"
+	case TypeDecimal:
+		return "decimal"
+	case TypeBool:
+		return "bool"
+	}
+	return "MappedType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// TypeRule selects fields by name or by type and size and converts their values to the Target type
+type TypeRule struct {
+	Field    string // field name, empty matches all fields
+	Type     byte   // field type, 0 matches all types
+	Len      int    // field length, 0 matches all lengths; when Len is set Decimals must match as well
+	Decimals int
+
+	Target MappedType
+
+	// True are the character values which are true for TypeBool, trimmed and case insensitive.
+	// Nil uses Y and T, other values are false and empty values are null.
+	True []string
+}
+
+// TypeMap maps the values of fields to other types. The first rule which matches a field is used.
+//
+//	d.SetTypeMap(dbf.TypeMap{
+//		{Type: 'N', Len: 12, Decimals: 2, Target: dbf.TypeDecimal},
+//		{Type: 'C', Len: 1, Target: dbf.TypeBool, True: []string{"Y", "J"}},
+//		{Field: "ZIPCODE", Target: dbf.TypeString},
+//	})
+//
+// The converted values are returned by Field, the records of RecordAt and Scanner, and so by Scan, Rows and the exports.
+// The Pipeline passes field headers of the converted type to its RecordWriter, so exporters like ArrowWriter
+// write a column of that type. The typed readers like Int64At and Values read the stored values.
+type TypeMap []TypeRule
+
+// match returns if the rule applies to field f
+func (r *TypeRule) match(f *FieldHeader) bool {
+	if r.Field != "" && r.Field != f.FieldName() {
+		return false
+	}
+	if r.Type != 0 && r.Type != f.Type {
+		return false
+	}
+	return r.Len == 0 || r.Len == int(f.Len) && r.Decimals == int(f.Decimals)
+}
+
+// SetTypeMap sets the conversions of field values, nil removes them. System fields are never converted.
+func (dbf *DBF) SetTypeMap(m TypeMap) {
+	dbf.typeMap, dbf.mapped = m, nil
+	if len(m) == 0 {
+		return
+	}
+	dbf.mapped = make([]*TypeRule, len(dbf.fields))
+	for i := range dbf.fields {
+		if dbf.fields[i].System() {
+			continue
+		}
+		for r := range m {
+			if m[r].match(&dbf.fields[i]) {
+				dbf.mapped[i] = &m[r]
+				break
+			}
+		}
+	}
+}
+
+// TypeMap returns the conversions of field values set with SetTypeMap
+func (dbf *DBF) TypeMap() TypeMap {
+	return dbf.typeMap
+}
+
+// MappedType returns the target type of the field at pos, 0 if its values are not converted
+func (dbf *DBF) MappedType(pos int) MappedType {
+	if pos < 0 || pos >= len(dbf.mapped) || dbf.mapped[pos] == nil {
+		return 0
+	}
+	return dbf.mapped[pos].Target
+}
+
+// mappedField returns the header of field pos with the FoxPro type of the converted values:
+// C or M for strings and decimals, N without decimals for int64, B for float64 and L for bool.
+func (dbf *DBF) mappedField(pos int) FieldHeader {
+	f := dbf.fields[pos]
+	switch dbf.MappedType(pos) {
+	case TypeString, TypeDecimal:
+		if f.Type != 'C' {
+			f.Type, f.Len, f.Decimals = 'M', 10, 0
+		}
+	case TypeInt64:
+		f.Type, f.Len, f.Decimals = 'N', 20, 0
+	case TypeFloat64:
+		f.Type, f.Len = 'B', 8
+	case TypeBool:
+		f.Type, f.Len, f.Decimals = 'L', 1, 0
+	}
+	return f
+}
+
+// mapValue converts value val of field pos, raw is the stored data of the field
+func (dbf *DBF) mapValue(raw []byte, val interface{}, pos int) (interface{}, error) {
+	if dbf.mapped == nil || dbf.mapped[pos] == nil || val == nil {
+		return val, nil
+	}
+	rule, f := dbf.mapped[pos], &dbf.fields[pos]
+	s, isString := val.(string)
+	if isString && rule.Target != TypeString {
+		if s = strings.TrimSpace(s); s == "" {
+			// empty character values have no number or logical
+			return nil, nil
+		}
+	}
+
+	switch rule.Target {
+	case TypeString:
+		if isString {
+			return s, nil
+		}
+		if b, ok := val.([]byte); ok {
+			return string(b), nil
+		}
+		return new(FormatOptions).FormatValue(val, f), nil
+	case TypeInt64:
+		if isString {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n, nil
+			}
+			return nil, &CastError{Value: val, Type: "int64"}
+		}
+		if b, ok := val.(bool); ok {
+			return boolToInt64(b), nil
+		}
+		return ToInt64E(val)
+	case TypeFloat64:
+		if isString {
+			if n, err := strconv.ParseFloat(s, 64); err == nil {
+				return n, nil
+			}
+			return nil, &CastError{Value: val, Type: "float64"}
+		}
+		if b, ok := val.(bool); ok {
+			return float64(boolToInt64(b)), nil
+		}
+		return ToFloat64E(val)
+	case TypeDecimal:
+		if f.Type == 'N' || f.Type == 'F' {
+			// the stored text is exact, invalid numbers have been handled by the policy already
+			if text := strings.TrimSpace(string(raw)); text != "" {
+				if _, err := strconv.ParseFloat(text, 64); err == nil {
+					return text, nil
+				}
+			}
+		}
+		if isString {
+			return s, nil
+		}
+		return new(FormatOptions).FormatValue(val, f), nil
+	case TypeBool:
+		switch v := val.(type) {
+		case bool:
+			return v, nil
+		case string:
+			trues := rule.True
+			if trues == nil {
+				trues = []string{"Y", "T"}
+			}
+			for _, t := range trues {
+				if strings.EqualFold(s, strings.TrimSpace(t)) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		n, err := ToFloat64E(val)
+		if err != nil {
+			return nil, &CastError{Value: val, Type: "bool"}
+		}
+		return n != 0, nil
+	}
+	return val, nil
+}
+
+// boolToInt64 returns 1 for true and 0 for false
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package dbf
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypeMap(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	dbf.SetTypeMap(TypeMap{
+		{Type: 'N', Len: 12, Decimals: 2, Target: TypeDecimal},
+		{Field: "ID", Target: TypeString},
+		{Field: "COMP_NAME", Target: TypeBool, True: []string{"test"}},
+		{Type: 'I', Target: TypeFloat64},
+		{Type: 'L', Target: TypeInt64},
+	})
+	want := map[string]MappedType{"NUMBER": TypeDecimal, "ID": TypeString, "COMP_NAME": TypeBool,
+		"ID_NR": TypeFloat64, "USERNR": TypeFloat64, "BOOL": TypeInt64, "NIVEAU": 0}
+	for name, typ := range want {
+		if have := dbf.MappedType(dbf.FieldPos(name)); have != typ {
+			t.Errorf("Field %s: want %s, have %s", name, typ, have)
+		}
+	}
+
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]interface{}{
+		"NUMBER":    "1.66",
+		"ID":        "1",
+		"COMP_NAME": true,
+		"ID_NR":     float64(100),
+		"BOOL":      int64(0),
+	}
+	for name, val := range values {
+		if have := rec.FieldSlice()[dbf.FieldPos(name)]; have != val {
+			t.Errorf("Field %s: want %v (%T), have %v (%T)", name, val, val, have, have)
+		}
+	}
+	if err := dbf.GoTo(0); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := dbf.Field(dbf.FieldPos("NUMBER")); err != nil || val != "1.66" {
+		t.Errorf("Want Field to return the decimal 1.66, have %v (%v)", val, err)
+	}
+
+	// TIJD contains 15:00, which is not an integer
+	dbf.SetTypeMap(TypeMap{{Field: "TIJD", Target: TypeInt64}})
+	if _, err := dbf.Field(dbf.FieldPos("TIJD")); err == nil {
+		t.Error("Want an error for a value which can not be converted")
+	}
+	if _, err := dbf.RecordAt(0); err == nil {
+		t.Error("Want an error for a record with a value which can not be converted")
+	}
+
+	// the typed readers are not changed
+	ids, err := dbf.Int64Column("ID")
+	if err != nil || len(ids) != 3 || ids[0] != 1 {
+		t.Errorf("Want the stored IDs, have %v (%v)", ids, err)
+	}
+
+	dbf.SetTypeMap(TypeMap{{Field: "COMP_NAME", Target: TypeBool}, {Field: "NUMBER", Target: TypeDecimal}})
+	rows := NewRows(dbf)
+	if typ := rows.ColumnTypeScanType(dbf.FieldPos("COMP_NAME")); typ != reflect.TypeOf(false) {
+		t.Errorf("Want a bool scan type, have %s", typ)
+	}
+	schema := dbf.JSONSchema("")
+	if typ := schema.Properties["NUMBER"].Type; typ != "string" {
+		t.Errorf("Want a string property for a decimal, have %v", typ)
+	}
+
+	// exporters receive the converted types
+	buf := new(bytes.Buffer)
+	w := NewCSVWriter(buf)
+	w.Format.True, w.Format.False = "Y", "N"
+	if _, err := dbf.Export(w, ExportOptions{Fields: []string{"COMP_NAME", "NUMBER"}}); err != nil {
+		t.Fatal(err)
+	}
+	if line := strings.Split(buf.String(), "\n")[1]; line != "N,1.66" {
+		t.Errorf("Unexpected CSV row %q", line)
+	}
+	p := Pipeline(dbf)
+	if f := p.Fields()[dbf.FieldPos("COMP_NAME")]; f.Type != 'L' {
+		t.Errorf("Want an L field for the converted COMP_NAME, have %s", f.FieldType())
+	}
+
+	dbf.SetTypeMap(nil)
+	if dbf.MappedType(0) != 0 || dbf.TypeMap() != nil {
+		t.Error("Want the type map removed")
+	}
+}
+
+func TestTypeRuleMatch(t *testing.T) {
+	f := newField("AMOUNT", 'N', 12, 2)
+	tests := []struct {
+		rule TypeRule
+		want bool
+	}{
+		{TypeRule{}, true},
+		{TypeRule{Field: "AMOUNT"}, true},
+		{TypeRule{Field: "amount"}, false},
+		{TypeRule{Type: 'N'}, true},
+		{TypeRule{Type: 'C'}, false},
+		{TypeRule{Type: 'N', Len: 12, Decimals: 2}, true},
+		{TypeRule{Type: 'N', Len: 12}, false},
+		{TypeRule{Len: 10, Decimals: 2}, false},
+	}
+	for i, test := range tests {
+		if have := test.rule.match(&f); have != test.want {
+			t.Errorf("Test %d: want %v, have %v", i, test.want, have)
+		}
+	}
+}
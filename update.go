@@ -0,0 +1,427 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	// ErrReadOnly is returned when changing a table which was not opened with OpenFileForUpdate
+	ErrReadOnly = errors.New("table is opened read-only")
+
+	// ErrStructuralIndex is returned by Pack and Zap for tables with a structural index (CDX),
+	// which would point to the wrong records afterwards
+	ErrStructuralIndex = errors.New("tables with a structural index can not be packed or zapped, the index is not updated")
+)
+
+// updater changes the records of a table in place, see OpenFileForUpdate
+type updater struct {
+	enc      Encoder  // converts strings to the charset of the table
+	modified bool     // the last update date in the header has been set
+	stage    *staging // the copies which are changed instead of the files, nil when changes are written directly, see stage.go
+}
+
+// OpenFileForUpdate opens a table like OpenFile, with the files opened for reading and writing, so the records
+// can be changed in place with WriteFieldAt. The Encoder is used to convert UTF8 strings to the charset of the table,
+// the inverse of the Decoder. When enc is nil the CodePageEncoder of the code page of the table is used, or the
+// Encoder of the Decoder's charset for tables without code page mark.
+// Changes are written to the files immediately. Other programs which change the table at the same time should
+// be locked out with a Locker, see lock.go.
+func OpenFileForUpdate(filename string, dec Decoder, enc Encoder) (*DBF, error) {
+	dbf, err := openFile(filename, dec, ShareReadWrite, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if enc, err = dbf.tableEncoder(enc); err != nil {
+		dbf.Close()
+		return nil, err
+	}
+	dbf.upd = &updater{enc: enc}
+	return dbf, nil
+}
+
+// tableEncoder returns enc, or the CodePageEncoder of the code page of the table when enc is nil.
+// Tables without code page mark use the Encoder of the charset of the Decoder.
+func (dbf *DBF) tableEncoder(enc Encoder) (Encoder, error) {
+	if enc != nil {
+		return enc, nil
+	}
+	enc, ok := CodePageEncoder(dbf.header.CodePage)
+	if !ok && dbf.header.CodePage == 0 {
+		// tables without code page mark, like UTF8 tables, are written in the charset of the Decoder
+		enc = decoderEncoder(dbf.dec)
+		ok = enc != nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("no encoder for code page %s", dbf.header.CodePageInfo())
+	}
+	return enc, nil
+}
+
+// WriteFieldAt encodes value to the stored representation of the field at fieldpos and writes it to the record
+// at recno, the other fields of the record are not changed. The value is converted like by Writer.Append,
+// nil writes an empty value, or null for nullable fields. The last update date in the header is set once.
+// Field types which the Writer does not support can not be written.
+// A memo is written to new blocks at the end of the memo file, the blocks of the previous memo are no longer used,
+// see CompactMemo to remove them.
+func (dbf *DBF) WriteFieldAt(recno uint32, fieldpos int, value interface{}) error {
+	if dbf.upd == nil {
+		return ErrReadOnly
+	}
+	if recno >= dbf.header.NumRec {
+		return ErrEOF
+	}
+	if fieldpos < 0 || fieldpos >= len(dbf.fields) || dbf.fields[fieldpos].System() {
+		return ErrInvalidField
+	}
+	if dbf.decrypter != nil {
+		return errors.New("records of encrypted tables can not be changed")
+	}
+	f := dbf.fields[fieldpos]
+	if err := checkWritable(f); err != nil {
+		return err
+	}
+
+	data := make([]byte, f.Len)
+	wr := &Writer{enc: dbf.upd.enc}
+	if f.isMemo() {
+		if err := dbf.prepareMemoWrite(wr); err != nil {
+			return err
+		}
+	}
+	if err := wr.encodeField(data, &f, value); err != nil {
+		return fmt.Errorf("field %s: %s", f.FieldName(), err)
+	}
+	if wr.memo != nil {
+		// the memo is written before the memo file header and the record point to it
+		if err := wr.memo.close(); err != nil {
+			return err
+		}
+		dbf.fptheader.NextFree = wr.memo.next
+	}
+	if err := dbf.writeRecordData(recno, dbf.layout[fieldpos].start, data); err != nil {
+		return err
+	}
+	if bit := dbf.layout[fieldpos].null; bit >= 0 && dbf.nullflags >= 0 {
+		if err := dbf.writeNullFlag(recno, bit, value == nil); err != nil {
+			return err
+		}
+	}
+	return dbf.setModified()
+}
+
+// writeRecord encodes the values of the fields which are set to the record at recno, or to a new record at the end
+// of the table when recno is the number of records. The other fields of a new record are written empty.
+// The values are converted like by WriteFieldAt, the record is only written when all values can be converted.
+// A new record is written with the EOF marker before the record count in the header is updated, under the header
+// lock and after the records which other programs appended since the table was opened. When the header is locked
+// by someone else ErrLocked is returned.
+func (dbf *DBF) writeRecord(recno uint32, values []interface{}, set []bool) error {
+	if dbf.upd == nil {
+		return ErrReadOnly
+	}
+	if dbf.decrypter != nil {
+		return errors.New("records of encrypted tables can not be changed")
+	}
+	appending := recno == dbf.header.NumRec
+	if recno > dbf.header.NumRec {
+		return ErrEOF
+	}
+	if appending {
+		// like the AppendWriter, other programs can have appended records since the table was opened
+		unlock, err := dbf.lockAppend()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		recno = dbf.header.NumRec
+	}
+	var data []byte
+	switch {
+	case appending && recno == MaxRecords:
+		return &LimitError{Limit: "number of records", Value: uint64(MaxRecords) + 1, Max: MaxRecords}
+	case appending:
+		data = make([]byte, dbf.header.RecLen)
+		fillSpaces(data)
+		if dbf.nullflags >= 0 {
+			l := dbf.layout[dbf.nullflags]
+			for j := l.start; j < l.end; j++ {
+				data[j] = 0
+			}
+		}
+	default:
+		var err error
+		if data, err = dbf.RawRecordAt(recno); err != nil {
+			return err
+		}
+	}
+
+	wr := &Writer{enc: dbf.upd.enc}
+	for i := range dbf.fields {
+		f := dbf.fields[i]
+		if f.System() || !set[i] && !appending {
+			continue
+		}
+		if err := checkWritable(f); err != nil {
+			return err
+		}
+		if f.isMemo() && wr.memo == nil {
+			if err := dbf.prepareMemoWrite(wr); err != nil {
+				return err
+			}
+		}
+		l := dbf.layout[i]
+		if err := wr.encodeField(data[l.start:l.end], &f, values[i]); err != nil {
+			return fmt.Errorf("field %s: %s", f.FieldName(), err)
+		}
+		if l.null >= 0 && dbf.nullflags >= 0 {
+			nl := dbf.layout[dbf.nullflags]
+			setNullBit(data[nl.start:nl.end], l.null, values[i] == nil)
+		}
+	}
+	if wr.memo != nil {
+		if err := wr.memo.close(); err != nil {
+			return err
+		}
+		dbf.fptheader.NextFree = wr.memo.next
+	}
+	if !appending {
+		if err := dbf.writeRecordData(recno, 0, data); err != nil {
+			return err
+		}
+		return dbf.setModified()
+	}
+	if err := dbf.writeRecordData(recno, 0, append(data, 0x1A)); err != nil {
+		return err
+	}
+	dbf.header.NumRec++
+	return dbf.writeRecordCount()
+}
+
+// lockAppend locks the header for appending a record and reads the record count of the file again,
+// it returns the function which releases the lock. Without locking support the count is only read again.
+func (dbf *DBF) lockAppend() (func(), error) {
+	unlock := func() {}
+	l := NewLocker(dbf.f)
+	switch err := l.LockHeader(); err {
+	case nil:
+		unlock = func() { l.UnlockHeader() }
+	case ErrLockingNotSupported:
+	default:
+		return nil, err
+	}
+	h, err := readHeaderAt(dbf.f)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	if h.NumRec > dbf.header.NumRec {
+		dbf.header.NumRec = h.NumRec
+	}
+	return unlock, nil
+}
+
+// checkWritable returns an error when the Writer can not encode values of field f,
+// the stored length must be the length the Writer uses for the type
+func checkWritable(f FieldHeader) error {
+	check := f
+	if err := prepareField(&check); err != nil {
+		return err
+	}
+	if check.Len != f.Len {
+		return fmt.Errorf("field %s: invalid length %d for a %s field", f.FieldName(), f.Len, f.FieldType())
+	}
+	return nil
+}
+
+// prepareMemoWrite sets a memoWriter for wr which writes new memos after the last block of the memo file
+func (dbf *DBF) prepareMemoWrite(wr *Writer) error {
+	if dbf.fptf == nil {
+		return ErrNoFPTFile
+	}
+	if dbf.smt {
+		return errors.New("memos of tables with an SMT memo file can not be changed")
+	}
+	next, err := memoFileEnd(dbf.fptf)
+	if err != nil {
+		return err
+	}
+	wr.memo = &memoWriter{w: dbf.fptf, blockSize: uint32(dbf.fptheader.BlockSize), next: next}
+	return nil
+}
+
+// MarkDeleted sets the deleted flag of the record at recno, like DELETE in FoxPro.
+// The record stays in the table until it is removed by a pack.
+func (dbf *DBF) MarkDeleted(recno uint32) error {
+	return dbf.writeDeleted(recno, 0x2A)
+}
+
+// Recall clears the deleted flag of the record at recno, like RECALL in FoxPro
+func (dbf *DBF) Recall(recno uint32) error {
+	return dbf.writeDeleted(recno, 0x20)
+}
+
+// writeDeleted writes flag as deleted flag of the record at recno
+func (dbf *DBF) writeDeleted(recno uint32, flag byte) error {
+	if dbf.upd == nil {
+		return ErrReadOnly
+	}
+	if recno >= dbf.header.NumRec {
+		return ErrEOF
+	}
+	if dbf.decrypter != nil {
+		return errors.New("records of encrypted tables can not be changed")
+	}
+	if err := dbf.writeRecordData(recno, 0, []byte{flag}); err != nil {
+		return err
+	}
+	return dbf.setModified()
+}
+
+// Pack removes the deleted records from the table and their memos from the memo file, like PACK in FoxPro.
+// The packed table is written with PackTo to new files, which replace the files of the table with a Rewrite,
+// so the table is never half-packed. The table is reopened afterwards and the record pointer is reset.
+// Staged tables are packed to new copies, the files are replaced by Flush or Close.
+// Index files are not updated, tables with a structural index return ErrStructuralIndex, other index files
+// must be rebuilt.
+func (dbf *DBF) Pack() (*PackReport, error) {
+	if dbf.upd == nil {
+		return nil, ErrReadOnly
+	}
+	if dbf.header.HasCDX() {
+		return nil, ErrStructuralIndex
+	}
+	if dbf.upd.stage != nil {
+		return dbf.packStaged()
+	}
+	rw := NewRewrite()
+	dbfout, err := rw.Create(dbf.f.Name())
+	if err != nil {
+		return nil, err
+	}
+	var fptout io.WriteSeeker
+	if dbf.fptf != nil {
+		f, err := rw.Create(dbf.fptf.Name())
+		if err != nil {
+			rw.Abort()
+			return nil, err
+		}
+		fptout = f
+	}
+	report, err := dbf.PackTo(dbfout, fptout)
+	if err != nil {
+		rw.Abort()
+		return nil, err
+	}
+	// open files can not be replaced on Windows
+	dbf.Close()
+	err = rw.Commit()
+	if reopenErr := dbf.Reopen(); err == nil {
+		err = reopenErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Zap removes all records from the table and all memos from the memo file, like ZAP in FoxPro.
+// The structure of the table is kept. The record count is set to 0 first, then the files are truncated.
+// Index files are not updated, tables with a structural index return ErrStructuralIndex, other index files
+// must be rebuilt.
+func (dbf *DBF) Zap() error {
+	if dbf.upd == nil {
+		return ErrReadOnly
+	}
+	if dbf.header.HasCDX() {
+		return ErrStructuralIndex
+	}
+	if dbf.smt {
+		return errors.New("tables with an SMT memo file can not be zapped")
+	}
+	dbf.header.NumRec = 0
+	if err := dbf.writeRecordCount(); err != nil {
+		return err
+	}
+	if err := dbf.f.Truncate(int64(dbf.header.FirstRec)); err != nil {
+		return err
+	}
+	if _, err := dbf.f.WriteAt([]byte{0x1A}, int64(dbf.header.FirstRec)); err != nil {
+		return err
+	}
+	dbf.recpointer = 0
+
+	if dbf.fptf != nil {
+		blockSize := uint32(dbf.fptheader.BlockSize)
+		if blockSize == 0 {
+			return errors.New("invalid memo block size 0")
+		}
+		// the first block after the header is the next free block
+		next := (fptHeaderSize + blockSize - 1) / blockSize
+		buf := make([]byte, 4)
+		binary.BigEndian.PutUint32(buf, next)
+		if _, err := dbf.fptf.WriteAt(buf, 0); err != nil {
+			return err
+		}
+		dbf.fptheader.NextFree = next
+		return dbf.fptf.Truncate(int64(next) * int64(blockSize))
+	}
+	return nil
+}
+
+// writeNullFlag sets or clears bit in the _NullFlags field of the record at recno
+func (dbf *DBF) writeNullFlag(recno uint32, bit int, null bool) error {
+	flags, err := dbf.readField(recno, dbf.nullflags)
+	if err != nil {
+		return err
+	}
+	setNullBit(flags, bit, null)
+	return dbf.writeRecordData(recno, dbf.layout[dbf.nullflags].start, flags)
+}
+
+// setNullBit sets or clears bit in the _NullFlags field data flags
+func setNullBit(flags []byte, bit int, null bool) {
+	if bit/8 >= len(flags) {
+		return
+	}
+	if null {
+		flags[bit/8] |= 1 << uint(bit%8)
+	} else {
+		flags[bit/8] &^= 1 << uint(bit%8)
+	}
+}
+
+// writeRecordData writes data at offset in the record at recno
+func (dbf *DBF) writeRecordData(recno uint32, offset int, data []byte) error {
+	pos := int64(dbf.header.FirstRec) + int64(recno)*int64(dbf.header.RecLen) + int64(offset)
+	_, err := dbf.f.WriteAt(data, pos)
+	return err
+}
+
+// writeRecordCount writes the record count of the header and the current date as last update date
+func (dbf *DBF) writeRecordCount() error {
+	setHeaderModified(dbf.header, time.Now())
+	buf := []byte{dbf.header.ModYear, dbf.header.ModMonth, dbf.header.ModDay, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(buf[3:], dbf.header.NumRec)
+	if _, err := dbf.f.WriteAt(buf, 1); err != nil {
+		return err
+	}
+	dbf.upd.modified = true
+	return nil
+}
+
+// setModified writes the current date as last update date to the header, once for every table which is opened
+func (dbf *DBF) setModified() error {
+	if dbf.upd.modified {
+		return nil
+	}
+	setHeaderModified(dbf.header, time.Now())
+	if _, err := dbf.f.WriteAt([]byte{dbf.header.ModYear, dbf.header.ModMonth, dbf.header.ModDay}, 1); err != nil {
+		return err
+	}
+	dbf.upd.modified = true
+	return nil
+}
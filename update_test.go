@@ -0,0 +1,474 @@
+package dbf
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// updateTestTable copies TEST.DBF and TEST.FPT to dir and returns the name of the copied table
+func updateTestTable(t *testing.T, dir string) string {
+	dbfdata, fptdata := readTestFiles(t)
+	if err := ioutil.WriteFile(filepath.Join(dir, "TEST.DBF"), dbfdata, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "TEST.FPT"), fptdata, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "TEST.DBF")
+}
+
+func TestWriteFieldAt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfupdate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+
+	dbf, err := OpenFileForUpdate(name, new(Win1250Decoder), new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2021, 6, 7, 0, 0, 0, 0, time.UTC)
+	values := map[string]interface{}{
+		"ID":        int32(42),
+		"DATUM":     date,
+		"COMP_NAME": "Čestmír",
+		"NUMBER":    12.345,
+		"BOOL":      true,
+		"COMP_OS":   nil,
+	}
+	for field, val := range values {
+		if err := dbf.WriteFieldAt(2, dbf.FieldPos(field), val); err != nil {
+			t.Fatalf("%s: %v", field, err)
+		}
+	}
+	if err := dbf.WriteFieldAt(2, dbf.FieldPos("COMP_OS"), "This value is much too long"); err == nil {
+		t.Error("Want an error for a too long value")
+	}
+	if err := dbf.WriteFieldAt(4, 0, 1); err != ErrEOF {
+		t.Errorf("Want ErrEOF, have %v", err)
+	}
+	if err := dbf.WriteFieldAt(0, 13, 1); err != ErrInvalidField {
+		t.Errorf("Want ErrInvalidField, have %v", err)
+	}
+	if err := dbf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dbf, err = OpenFile(name, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	rec, err := dbf.RecordAt(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"ID":        int32(42),
+		"DATUM":     date,
+		"COMP_NAME": "Čestmír                                 ",
+		"NUMBER":    12.35,
+		"BOOL":      true,
+		"COMP_OS":   "                    ",
+	}
+	for field, val := range want {
+		if have := rec.FieldSlice()[dbf.FieldPos(field)]; !equalValue(have, val) {
+			t.Errorf("%s: want %#v, have %#v", field, val, have)
+		}
+	}
+	// the other records and fields are not changed
+	original, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer original.Close()
+	for recno := uint32(0); recno < 4; recno++ {
+		a, _ := original.RecordAt(recno)
+		b, _ := dbf.RecordAt(recno)
+		ignore := []string{"MELDING"}
+		if recno == 2 {
+			ignore = append(ignore, "ID", "DATUM", "COMP_NAME", "NUMBER", "BOOL", "COMP_OS")
+		}
+		if diffs := DiffRecords(a, b, ignore...); len(diffs) != 0 {
+			t.Errorf("Record %d: want no other changes, have %+v", recno, diffs)
+		}
+	}
+	if modified := dbf.Header().Modified(); modified.Year() != time.Now().Year() {
+		t.Errorf("Want the last update date set, have %v", modified)
+	}
+
+	if err := dbf.WriteFieldAt(0, 0, 1); err != ErrReadOnly {
+		t.Errorf("Want ErrReadOnly for a table opened with OpenFile, have %v", err)
+	}
+
+	// without encoder the code page of the table is used, Windows-1252
+	cp, err := OpenFileForUpdate(name, new(Win1250Decoder), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+	if err := cp.WriteFieldAt(0, cp.FieldPos("COMP_OS"), "Café"); err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := cp.readField(0, cp.FieldPos("COMP_OS")); err != nil || string(raw[:5]) != "Caf\xe9 " {
+		t.Errorf("Want Café in Windows-1252, have % x (%v)", raw, err)
+	}
+}
+
+func TestWriteFieldAtNull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfupdate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stream := nullTable(t)
+	size, err := stream.r.Seek(0, io.SeekEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, size)
+	if _, err := stream.r.ReadAt(data, 0); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "NULL.DBF")
+	if err := ioutil.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dbf, err := OpenFileForUpdate(name, new(UTF8Decoder), new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	if err := dbf.WriteFieldAt(0, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.WriteFieldAt(1, 1, "Piet"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.WriteFieldAt(1, 3, "\x00"); err != ErrInvalidField {
+		t.Errorf("Want ErrInvalidField for _NullFlags, have %v", err)
+	}
+	for recno, want := range []bool{true, false} {
+		rec, err := dbf.RecordAt(uint32(recno))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.IsNull(1) != want {
+			t.Errorf("Record %d: want null %v", recno, want)
+		}
+		// BORN of the second record stays null
+		if recno == 1 && !rec.IsNull(2) {
+			t.Error("Want the other null value kept")
+		}
+	}
+}
+
+func TestMarkDeleted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfupdate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+
+	dbf, err := OpenFileForUpdate(name, new(Win1250Decoder), new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	if err := dbf.MarkDeleted(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.Recall(1); err != nil {
+		t.Fatal(err)
+	}
+	// deleting twice is not an error
+	if err := dbf.MarkDeleted(0); err != nil {
+		t.Fatal(err)
+	}
+	for recno, want := range []bool{true, false, false, false} {
+		deleted, err := dbf.DeletedAt(uint32(recno))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted != want {
+			t.Errorf("Record %d: want deleted %v", recno, want)
+		}
+	}
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Deleted || rec.FieldSlice()[0] != int32(1) {
+		t.Errorf("Want the values of a deleted record kept, have %v", rec.FieldSlice())
+	}
+	if err := dbf.MarkDeleted(4); err != ErrEOF {
+		t.Errorf("Want ErrEOF, have %v", err)
+	}
+
+	readonly, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readonly.Close()
+	if err := readonly.Recall(1); err != ErrReadOnly {
+		t.Errorf("Want ErrReadOnly, have %v", err)
+	}
+}
+
+func TestPackInPlace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfupdate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+
+	dbf, err := OpenFileForUpdate(name, new(Win1250Decoder), new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	if err := dbf.MarkDeleted(2); err != nil {
+		t.Fatal(err)
+	}
+	report, err := dbf.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Kept != 2 || report.Removed != 2 || dbf.NumRecords() != 2 {
+		t.Errorf("Want 2 records kept and 2 removed, have %+v and %d records", report, dbf.NumRecords())
+	}
+
+	original, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer original.Close()
+	for recno, origno := range []uint32{0, 3} {
+		a, err := original.RecordAt(origno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := dbf.RecordAt(uint32(recno))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diffs := DiffRecords(a, b); len(diffs) != 0 || b.Deleted {
+			t.Errorf("Record %d: want record %d of the original table, have %+v", recno, origno, diffs)
+		}
+	}
+	if rec, err := dbf.RecordAt(1); err != nil || rec.FieldSlice()[dbf.FieldPos("MELDING")] != "" {
+		t.Errorf("Want an empty memo, have %v (%v)", rec, err)
+	}
+	if files, err := ioutil.ReadDir(dir); err != nil || len(files) != 2 {
+		t.Errorf("Want only the table and its memo file, have %d files (%v)", len(files), err)
+	}
+
+	// the packed table can still be changed
+	if err := dbf.WriteFieldAt(1, 0, 7); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := dbf.Int64At(1, 0); err != nil || v != 7 {
+		t.Errorf("Want 7, have %d (%v)", v, err)
+	}
+}
+
+func TestZap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfupdate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+
+	dbf, err := OpenFileForUpdate(name, new(Win1250Decoder), new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.Zap(); err != nil {
+		t.Fatal(err)
+	}
+	if dbf.NumRecords() != 0 || !dbf.EOF() {
+		t.Errorf("Want no records, have %d", dbf.NumRecords())
+	}
+	if err := dbf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dbf, err = OpenFile(name, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	if dbf.NumRecords() != 0 || dbf.NumFields() != 13 {
+		t.Errorf("Want an empty table with 13 fields, have %d records and %d fields", dbf.NumRecords(), dbf.NumFields())
+	}
+	if info, err := os.Stat(name); err != nil {
+		t.Fatal(err)
+	} else if info.Size() != int64(dbf.Header().FirstRec)+1 {
+		t.Errorf("Want the header and the EOF marker, have %d bytes", info.Size())
+	}
+	blocks := (fptHeaderSize + uint32(dbf.fptheader.BlockSize) - 1) / uint32(dbf.fptheader.BlockSize)
+	if dbf.fptheader.NextFree != blocks {
+		t.Errorf("Want next free block %d, have %d", blocks, dbf.fptheader.NextFree)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "TEST.FPT")); err != nil {
+		t.Fatal(err)
+	} else if info.Size() != int64(blocks)*int64(dbf.fptheader.BlockSize) {
+		t.Errorf("Want only the memo file header, have %d bytes", info.Size())
+	}
+}
+
+func TestPackStructuralIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfupdate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+	// set the structural index flag
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte{0x03}, 28); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	dbf, err := OpenFileForUpdate(name, new(Win1250Decoder), new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	if _, err := dbf.Pack(); err != ErrStructuralIndex {
+		t.Errorf("Want ErrStructuralIndex from Pack, have %v", err)
+	}
+	if err := dbf.Zap(); err != ErrStructuralIndex {
+		t.Errorf("Want ErrStructuralIndex from Zap, have %v", err)
+	}
+	if dbf.NumRecords() != 4 {
+		t.Errorf("Want the 4 records unchanged, have %d", dbf.NumRecords())
+	}
+}
+
+func TestWriteFieldAtMemo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfupdate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := updateTestTable(t, dir)
+
+	dbf, err := OpenFileForUpdate(name, new(Win1250Decoder), new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	pos := dbf.FieldPos("MELDING")
+	before := dbf.fptheader.NextFree
+	long := strings.Repeat("Dlouhá poznámka ", 20)
+	if err := dbf.WriteFieldAt(0, pos, long); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.WriteFieldAt(2, pos, "Krátká"); err != nil {
+		t.Fatal(err)
+	}
+	// a memo which is cleared reads as empty
+	if err := dbf.WriteFieldAt(3, pos, "Smazat"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbf.WriteFieldAt(3, pos, nil); err != nil {
+		t.Fatal(err)
+	}
+	if dbf.fptheader.NextFree <= before {
+		t.Errorf("Want the next free block after %d, have %d", before, dbf.fptheader.NextFree)
+	}
+
+	reopened, err := OpenFile(name, new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if err := reopened.GoTo(3); err != nil {
+		t.Fatal(err)
+	}
+	if memo, err := reopened.Field(pos); err != nil || memo != "" {
+		t.Errorf("Want an empty memo, have %q (%v)", memo, err)
+	}
+	for recno, want := range map[uint32]string{0: long, 2: "Krátká", 3: ""} {
+		rec, err := reopened.RecordAt(recno)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := rec.FieldSlice()[pos]; have != want {
+			t.Errorf("Record %d: want memo %q, have %q", recno, want, have)
+		}
+	}
+	if reopened.fptheader.NextFree != dbf.fptheader.NextFree {
+		t.Errorf("Want next free block %d in the memo file, have %d", dbf.fptheader.NextFree, reopened.fptheader.NextFree)
+	}
+	audit, err := reopened.AuditMemos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.Problems) != 0 {
+		t.Errorf("Want no memo problems, have %+v", audit.Problems)
+	}
+}
+
+func TestOpenCreatedTableForUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfupdate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		enc  Encoder
+		dec  Decoder
+		mark byte
+	}{
+		{new(Win1250Encoder), new(Win1250Decoder), 0xC8},
+		{new(UTF8Encoder), new(UTF8Decoder), 0},
+	}
+	for i, test := range tests {
+		name := filepath.Join(dir, fmt.Sprintf("T%d.DBF", i))
+		wr, err := Create(name, []FieldHeader{newField("NAME", 'C', 10, 0)}, test.enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := wr.Append("Zoë"); err != nil {
+			t.Fatal(err)
+		}
+		if err := wr.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// without Encoder the table is written in its own charset
+		dbf, err := OpenFileForUpdate(name, test.dec, nil)
+		if err != nil {
+			t.Fatalf("%T: %s", test.enc, err)
+		}
+		if dbf.Header().CodePage != test.mark {
+			t.Errorf("%T: want code page 0x%02X, have 0x%02X", test.enc, test.mark, dbf.Header().CodePage)
+		}
+		if err := dbf.WriteFieldAt(0, 0, "Zoë Ő"); err != nil {
+			t.Fatal(err)
+		}
+		if rec, err := dbf.RecordAt(0); err != nil || strings.TrimRight(rec.FieldSlice()[0].(string), " ") != "Zoë Ő" {
+			t.Errorf("%T: want the written name, have %v (%v)", test.enc, rec, err)
+		}
+		dbf.Close()
+	}
+}
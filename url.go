@@ -0,0 +1,119 @@
+package dbf
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Opener opens the file a URL points to, for example an object in a bucket or a file on a web server.
+// It returns a ReaderAt for the file and its size. When the ReaderAt is an io.Closer it is closed by DBF.Close.
+// An Opener should return an error for which os.IsNotExist is true when the file does not exist,
+// this is used to look for an SMT memo file when there is no FPT file.
+type Opener func(u *url.URL) (io.ReaderAt, int64, error)
+
+// openers are the registered openers by URL scheme
+var openers = map[string]Opener{}
+
+// RegisterOpener registers the Opener used by OpenURL for a URL scheme, like "s3" or "https", nil removes it.
+// It should be called before opening tables.
+func RegisterOpener(scheme string, o Opener) {
+	scheme = strings.ToLower(scheme)
+	if o == nil {
+		delete(openers, scheme)
+		return
+	}
+	openers[scheme] = o
+}
+
+// OpenURL opens a table with the Opener registered for the scheme of the URL, for example s3://bucket/key.dbf.
+// The memo file is opened with the same Opener, its URL is the URL of the table with the extension of the path replaced.
+// URLs with the file scheme and paths without a scheme are opened from disk with OpenFile.
+// After a successful call the caller should call DBF.Close to close the readers.
+func OpenURL(rawurl string, dec Decoder) (*DBF, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil || len(u.Scheme) <= 1 {
+		// no scheme or a Windows drive letter
+		return OpenFile(rawurl, dec)
+	}
+	if u.Scheme == "file" {
+		path := u.Path
+		if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+			// file:///C:/data/table.dbf
+			path = path[1:]
+		}
+		return OpenFile(filepath.FromSlash(path), dec)
+	}
+	opener, ok := openers[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no opener registered for URL scheme %s", u.Scheme)
+	}
+
+	dbffile, err := openURLReader(opener, u)
+	if err != nil {
+		return nil, err
+	}
+	dbf, err := prepareDBF(dbffile, dec, nil)
+	if err != nil {
+		closeReader(dbffile)
+		return nil, err
+	}
+	dbf.closers = append(dbf.closers, dbffile)
+
+	// the memo file is looked up like OpenFile does
+	if dbf.header.HasMemo() {
+		memoURL := *u
+		memoURL.Path = memoFileName(u.Path)
+		if dbf.smt {
+			memoURL.Path = smtFileName(u.Path)
+		}
+		fptfile, err := openURLReader(opener, &memoURL)
+		if os.IsNotExist(err) && !dbf.smt {
+			memoURL.Path = smtFileName(u.Path)
+			if smtfile, smterr := openURLReader(opener, &memoURL); smterr == nil {
+				fptfile, err, dbf.smt = smtfile, nil, true
+			}
+		}
+		if err != nil {
+			dbf.Close()
+			return nil, err
+		}
+		dbf.closers = append(dbf.closers, fptfile)
+		if err := dbf.prepareFPT(fptfile); err != nil {
+			dbf.Close()
+			return nil, err
+		}
+	}
+	return dbf, nil
+}
+
+// urlReader is a file opened by an Opener
+type urlReader struct {
+	*io.SectionReader
+	r io.ReaderAt
+}
+
+// Close closes the ReaderAt returned by the Opener, if it is an io.Closer
+func (u *urlReader) Close() error {
+	return closeReader(u.r)
+}
+
+// openURLReader opens u with an Opener
+func openURLReader(opener Opener, u *url.URL) (*urlReader, error) {
+	r, size, err := opener(u)
+	if err != nil {
+		return nil, err
+	}
+	return &urlReader{SectionReader: io.NewSectionReader(r, 0, size), r: r}, nil
+}
+
+// closeReader closes r if it is an io.Closer
+func closeReader(r io.ReaderAt) error {
+	if c, ok := r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
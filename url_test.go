@@ -0,0 +1,92 @@
+package dbf
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// memFile is an in memory file served by a test Opener
+type memFile struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (f *memFile) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestOpenURL(t *testing.T) {
+	files := map[string]*memFile{}
+	for _, name := range []string{"TEST.DBF", "TEST.FPT"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files["/tables/"+name] = &memFile{Reader: bytes.NewReader(data)}
+	}
+	RegisterOpener("MEM", func(u *url.URL) (io.ReaderAt, int64, error) {
+		if u.Host != "bucket" {
+			return nil, 0, os.ErrNotExist
+		}
+		f, ok := files[u.Path]
+		if !ok {
+			return nil, 0, os.ErrNotExist
+		}
+		return f, f.Size(), nil
+	})
+	defer RegisterOpener("mem", nil)
+
+	dbf, err := OpenURL("mem://bucket/tables/TEST.DBF", new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := dbf.RecordAt(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compOS := rec.FieldSlice()[dbf.FieldPos("COMP_OS")]; compOS != "Windows 7 SP1       " {
+		t.Errorf("Want Windows 7 SP1, have %q", compOS)
+	}
+	if memo := rec.FieldSlice()[dbf.FieldPos("MELDING")]; memo == "" {
+		t.Error("Want the memo to be read with the opener")
+	}
+	if err := dbf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, f := range files {
+		if !f.closed {
+			t.Errorf("Want %s to be closed", name)
+		}
+	}
+
+	delete(files, "/tables/TEST.FPT")
+	if _, err := OpenURL("mem://bucket/tables/TEST.DBF", new(Win1250Decoder)); !os.IsNotExist(err) {
+		t.Errorf("Want a not exist error without memo file, have %v", err)
+	}
+	if _, err := OpenURL("unknown://bucket/tables/TEST.DBF", new(Win1250Decoder)); err == nil {
+		t.Error("Want an error for a scheme without opener")
+	}
+
+	abs, err := filepath.Abs(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileURL := "file://" + filepath.ToSlash(abs)
+	if !strings.HasPrefix(abs, "/") {
+		fileURL = "file:///" + filepath.ToSlash(abs) // C:\ on Windows
+	}
+	for _, name := range []string{filepath.Join("testdata", "TEST.DBF"), fileURL} {
+		dbf, err := OpenURL(name, new(Win1250Decoder))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		dbf.Close()
+	}
+}
@@ -0,0 +1,57 @@
+package dbf
+
+import "fmt"
+
+// FileVersionInfo describes the file type flag (version byte) in the DBF header and the features to expect
+type FileVersionInfo struct {
+	Version     byte   // File type flag as stored in the header
+	Description string // For example Visual FoxPro with autoincrement
+	Known       bool   // The version byte is known
+
+	// MemoFile is the memo file format, FPT, DBT or SMT, or empty when the version has no memo file.
+	// Visual FoxPro tables store the presence of a memo file in the table flags instead.
+	MemoFile string
+
+	VisualFoxPro  bool // Visual FoxPro table: field flags, null fields, DBC backlink after the field descriptors
+	AutoIncrement bool // Fields can have autoincrement values
+	Varchar       bool // Fields can be Varchar or Varbinary
+}
+
+// fileVersions are the known file type flags
+// from https://docs.microsoft.com/en-us/previous-versions/visualstudio/foxpro/st4a0s68(v=vs.80)
+var fileVersions = map[byte]FileVersionInfo{
+	0x02: {Description: "FoxBASE"},
+	0x03: {Description: "FoxBASE+/dBASE III PLUS without memo"},
+	0x30: {Description: "Visual FoxPro", MemoFile: "FPT", VisualFoxPro: true},
+	0x31: {Description: "Visual FoxPro with autoincrement", MemoFile: "FPT", VisualFoxPro: true, AutoIncrement: true},
+	0x32: {Description: "Visual FoxPro with Varchar or Varbinary", MemoFile: "FPT", VisualFoxPro: true, AutoIncrement: true, Varchar: true},
+	0x43: {Description: "dBASE IV SQL table without memo"},
+	0x63: {Description: "dBASE IV SQL system file without memo"},
+	0x83: {Description: "FoxBASE+/dBASE III PLUS with memo", MemoFile: "DBT"},
+	0x8B: {Description: "dBASE IV with memo", MemoFile: "DBT"},
+	0xCB: {Description: "dBASE IV SQL table with memo", MemoFile: "DBT"},
+	0xE5: {Description: "HiPer-Six (SIx driver) with SMT memo", MemoFile: "SMT"},
+	0xF5: {Description: "FoxPro 2.x (or earlier) with memo", MemoFile: "FPT"},
+	0xFB: {Description: "FoxBASE"},
+}
+
+// LookupFileVersion returns the description and features of a file type flag
+func LookupFileVersion(version byte) FileVersionInfo {
+	info, ok := fileVersions[version]
+	if !ok {
+		info.Description = fmt.Sprintf("Unknown version 0x%02X", version)
+	}
+	info.Version = version
+	info.Known = ok
+	return info
+}
+
+// VersionInfo returns the description and features of the file version of the table
+func (dbf *DBF) VersionInfo() FileVersionInfo {
+	return LookupFileVersion(dbf.header.FileVersion)
+}
+
+// VersionDescription returns a description of the file version of the table, for example Visual FoxPro
+func (dbf *DBF) VersionDescription() string {
+	return dbf.VersionInfo().Description
+}
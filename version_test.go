@@ -0,0 +1,32 @@
+package dbf
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestVersionDescription(t *testing.T) {
+	d, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	if desc := d.VersionDescription(); desc != "Visual FoxPro" {
+		t.Errorf("Want Visual FoxPro, have %s", desc)
+	}
+	if info := d.VersionInfo(); !info.Known || !info.VisualFoxPro || info.AutoIncrement || info.MemoFile != "FPT" {
+		t.Errorf("Unexpected version info %+v", info)
+	}
+
+	if info := LookupFileVersion(0x31); !info.AutoIncrement || info.Description != "Visual FoxPro with autoincrement" {
+		t.Errorf("Unexpected version info for 0x31 %+v", info)
+	}
+	if info := LookupFileVersion(0x8B); info.MemoFile != "DBT" || info.VisualFoxPro {
+		t.Errorf("Unexpected version info for 0x8B %+v", info)
+	}
+	info := LookupFileVersion(0x99)
+	if info.Known || info.Version != 0x99 || info.Description != "Unknown version 0x99" {
+		t.Errorf("Unexpected version info for 0x99 %+v", info)
+	}
+}
@@ -0,0 +1,733 @@
+package dbf
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrViewNotFound is returned by Database.OpenView for names which are not a view of the database
+var ErrViewNotFound = errors.New("view not found in database")
+
+// DatabaseView is a view defined in a database container
+type DatabaseView struct {
+	Name    string
+	SQL     string // SELECT statement of the view
+	Comment string
+	Remote  bool // Remote views select from an ODBC connection and cannot be executed
+}
+
+// Views returns the views of the database
+func (db *Database) Views() []DatabaseView {
+	return db.views
+}
+
+// OpenView executes the SELECT statement of a local view and returns the result, see Query.
+// params contains the values of view parameters (?name).
+func (db *Database) OpenView(name string, params map[string]interface{}) (*Cursor, error) {
+	for _, v := range db.views {
+		if !strings.EqualFold(v.Name, name) {
+			continue
+		}
+		if v.Remote {
+			return nil, fmt.Errorf("view %s is a remote view", v.Name)
+		}
+		cur, err := db.Query(v.SQL, params)
+		if err != nil {
+			return nil, fmt.Errorf("view %s: %s", v.Name, err)
+		}
+		return cur, nil
+	}
+	return nil, ErrViewNotFound
+}
+
+// Query executes a SELECT statement over the tables of the database and returns the result in memory.
+// The supported SQL is the subset used by FoxPro local views:
+//
+//	SELECT [DISTINCT] [TOP n] *|alias.*|expr [AS name], ...
+//	FROM table [alias] [[INNER|LEFT [OUTER]] JOIN table [alias] ON condition] ...
+//	[WHERE condition]
+//	[ORDER BY expr|name|position [ASC|DESC], ...]
+//
+// Tables are referenced by their long name, optionally prefixed with the database name (database!table).
+// Expressions can use field references, view parameters (?name), FoxPro operators and common functions.
+// Deleted records are ignored. GROUP BY, HAVING, UNION and subqueries are not supported.
+func (db *Database) Query(sql string, params map[string]interface{}) (*Cursor, error) {
+	stmt, err := parseSelect(sql)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.execute(db.Table, params)
+}
+
+// Cursor is the result of a query
+type Cursor struct {
+	columns []string
+	rows    [][]interface{}
+	pos     int // number of rows read by Next
+}
+
+// Columns returns the column names of the result
+func (c *Cursor) Columns() []string {
+	return c.columns
+}
+
+// Len returns the number of rows
+func (c *Cursor) Len() int {
+	return len(c.rows)
+}
+
+// Next moves to the next row, it returns false after the last row.
+// Next has to be called before reading the first row.
+func (c *Cursor) Next() bool {
+	if c.pos >= len(c.rows) {
+		return false
+	}
+	c.pos++
+	return true
+}
+
+// Values returns the values of the current row
+func (c *Cursor) Values() []interface{} {
+	if c.pos == 0 {
+		return nil
+	}
+	return c.rows[c.pos-1]
+}
+
+// Row returns the values of row i (zero based)
+func (c *Cursor) Row(i int) []interface{} {
+	return c.rows[i]
+}
+
+// sqlSelect is a parsed SELECT statement
+type sqlSelect struct {
+	distinct bool
+	top      int
+	items    []sqlItem
+	from     []sqlFrom
+	where    exprNode
+	orderBy  []sqlOrder
+}
+
+// sqlItem is an item of the select list
+type sqlItem struct {
+	expr exprNode
+	name string // column name, AS name or field name
+	star string // set for * (empty qualifier is "*") and alias.*
+}
+
+// sqlFrom is a table of the FROM clause
+type sqlFrom struct {
+	table string
+	alias string
+	left  bool     // LEFT JOIN
+	on    exprNode // join condition, nil for the first table and comma separated tables
+}
+
+// sqlOrder is an item of the ORDER BY clause
+type sqlOrder struct {
+	expr     exprNode
+	column   int // item of the select list with the same name (zero based), -1 if none
+	position int // result column for ORDER BY n (one based), 0 if not used
+	desc     bool
+}
+
+// sqlKeywords end an expression or alias in the SQL parser
+var sqlKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "ORDER": true, "GROUP": true, "HAVING": true, "BY": true,
+	"JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "OUTER": true, "ON": true,
+	"AS": true, "UNION": true, "INTO": true, "ASC": true, "DESC": true, "DISTINCT": true, "TOP": true,
+}
+
+// parseSelect parses a SELECT statement
+func parseSelect(sql string) (*sqlSelect, error) {
+	toks, err := tokenizeExpr(strings.Replace(sql, ";", " ", -1))
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	stmt := new(sqlSelect)
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	if p.isKeyword("DISTINCT") {
+		p.next()
+		stmt.distinct = true
+	}
+	if p.isKeyword("TOP") {
+		p.next()
+		t := p.next()
+		if t.kind != tokNumber {
+			return nil, errors.New("expected a number after TOP")
+		}
+		stmt.top = int(t.val.(float64))
+	}
+
+	for {
+		item, err := p.selectItem(len(stmt.items))
+		if err != nil {
+			return nil, err
+		}
+		stmt.items = append(stmt.items, item)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	for {
+		from, err := p.tableRef()
+		if err != nil {
+			return nil, err
+		}
+		stmt.from = append(stmt.from, from)
+		if err := p.joins(stmt); err != nil {
+			return nil, err
+		}
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+
+	if p.isKeyword("WHERE") {
+		p.next()
+		if stmt.where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.isKeyword("GROUP") || p.isKeyword("HAVING") || p.isKeyword("UNION") {
+		return nil, fmt.Errorf("%s is not supported", strings.ToUpper(p.peek().text))
+	}
+	if p.isKeyword("ORDER") {
+		p.next()
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			order, err := p.orderItem(stmt.items)
+			if err != nil {
+				return nil, err
+			}
+			stmt.orderBy = append(stmt.orderBy, order)
+			if !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return stmt, nil
+}
+
+// selectItem parses an item of the select list, n is the number of items before it
+func (p *exprParser) selectItem(n int) (sqlItem, error) {
+	if p.isOp("*") {
+		p.next()
+		return sqlItem{star: "*"}, nil
+	}
+	if t := p.peek(); t.kind == tokIdent && p.pos+2 < len(p.toks) &&
+		p.toks[p.pos+1].kind == tokOp && p.toks[p.pos+1].text == "." &&
+		p.toks[p.pos+2].kind == tokOp && p.toks[p.pos+2].text == "*" {
+		// alias.*
+		p.pos += 3
+		return sqlItem{star: t.text}, nil
+	}
+	expr, err := p.expr()
+	if err != nil {
+		return sqlItem{}, err
+	}
+	item := sqlItem{expr: expr, name: fmt.Sprintf("EXP_%d", n+1)}
+	if col, ok := expr.(*columnNode); ok {
+		item.name = col.name[strings.LastIndex(col.name, ".")+1:]
+	}
+	if p.isKeyword("AS") {
+		p.next()
+		t := p.next()
+		if t.kind != tokIdent && t.kind != tokString {
+			return sqlItem{}, fmt.Errorf("expected a column name after AS, found %q", t.text)
+		}
+		item.name = t.text
+		if t.kind == tokString {
+			item.name = t.val.(string)
+		}
+	} else if t := p.peek(); t.kind == tokIdent && !sqlKeywords[strings.ToUpper(t.text)] {
+		p.next()
+		item.name = t.text
+	}
+	return item, nil
+}
+
+// joins parses the JOIN clauses following a table
+func (p *exprParser) joins(stmt *sqlSelect) error {
+	for {
+		left := false
+		switch {
+		case p.isKeyword("INNER"):
+			p.next()
+		case p.isKeyword("LEFT"):
+			p.next()
+			left = true
+			if p.isKeyword("OUTER") {
+				p.next()
+			}
+		case p.isKeyword("RIGHT"), p.isKeyword("FULL"):
+			return fmt.Errorf("%s JOIN is not supported", strings.ToUpper(p.peek().text))
+		}
+		if !p.isKeyword("JOIN") {
+			if left {
+				return errors.New("expected JOIN")
+			}
+			return nil
+		}
+		p.next()
+		join, err := p.tableRef()
+		if err != nil {
+			return err
+		}
+		if err := p.expect("ON"); err != nil {
+			return err
+		}
+		if join.on, err = p.expr(); err != nil {
+			return err
+		}
+		join.left = left
+		stmt.from = append(stmt.from, join)
+	}
+}
+
+// tableRef parses a table reference: [database!]table [[AS] alias]
+func (p *exprParser) tableRef() (sqlFrom, error) {
+	t := p.next()
+	// logical and null literals are string tokens without string value
+	quoted, isQuoted := t.val.(string)
+	if t.kind != tokIdent && (t.kind != tokString || !isQuoted) {
+		return sqlFrom{}, fmt.Errorf("expected a table name, found %q", t.text)
+	}
+	name := t.text
+	if t.kind == tokString {
+		name = quoted
+	}
+	if p.isOp("!") {
+		p.next()
+		t = p.next()
+		if t.kind != tokIdent {
+			return sqlFrom{}, fmt.Errorf("expected a table name after !, found %q", t.text)
+		}
+		name = t.text
+	}
+	from := sqlFrom{table: name, alias: name}
+	if p.isKeyword("AS") {
+		p.next()
+	}
+	if t := p.peek(); t.kind == tokIdent && !sqlKeywords[strings.ToUpper(t.text)] {
+		p.next()
+		from.alias = t.text
+	}
+	return from, nil
+}
+
+// orderItem parses an item of the ORDER BY clause
+func (p *exprParser) orderItem(items []sqlItem) (sqlOrder, error) {
+	order := sqlOrder{column: -1}
+	if t := p.peek(); t.kind == tokNumber {
+		p.next()
+		order.position = int(t.val.(float64))
+	} else {
+		expr, err := p.expr()
+		if err != nil {
+			return order, err
+		}
+		order.expr = expr
+		if col, ok := expr.(*columnNode); ok && !strings.Contains(col.name, ".") {
+			// a result column name takes precedence over a field name
+			for i, item := range items {
+				if item.star == "" && strings.EqualFold(item.name, col.name) {
+					order.column = i
+					break
+				}
+			}
+		}
+	}
+	if p.isKeyword("ASC") {
+		p.next()
+	} else if p.isKeyword("DESC") {
+		p.next()
+		order.desc = true
+	}
+	return order, nil
+}
+
+// sqlTable is a table of a query with its not deleted records
+type sqlTable struct {
+	alias   string
+	dbf     *DBF
+	records []*Record
+}
+
+// sqlRow is a combination of records of the tables of a query, records of unmatched LEFT JOIN tables are nil
+type sqlRow struct {
+	tables  []*sqlTable
+	records []*Record
+	params  map[string]interface{}
+}
+
+func (r *sqlRow) column(name string) (interface{}, error) {
+	qualifier := ""
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		qualifier, name = name[:i], name[i+1:]
+	}
+	for i, t := range r.tables {
+		if qualifier != "" && !strings.EqualFold(qualifier, t.alias) {
+			continue
+		}
+		pos := t.dbf.FieldPos(strings.ToUpper(name))
+		if pos < 0 {
+			continue
+		}
+		if i >= len(r.records) || r.records[i] == nil {
+			return nil, nil
+		}
+		return r.records[i].data[pos], nil
+	}
+	if qualifier != "" {
+		name = qualifier + "." + name
+	}
+	return nil, fmt.Errorf("unknown field %s", name)
+}
+
+func (r *sqlRow) param(name string) (interface{}, error) {
+	for key, val := range r.params {
+		if strings.EqualFold(key, name) {
+			return val, nil
+		}
+	}
+	return nil, fmt.Errorf("missing value for parameter ?%s", name)
+}
+
+// execute runs the statement, open returns the table for a name of the FROM clause
+func (stmt *sqlSelect) execute(open func(name string) (*DBF, error), params map[string]interface{}) (*Cursor, error) {
+	tables := make([]*sqlTable, len(stmt.from))
+	for i, from := range stmt.from {
+		dbf, err := open(from.table)
+		if err != nil {
+			return nil, err
+		}
+		t := &sqlTable{alias: from.alias, dbf: dbf}
+		for recno := uint32(0); recno < dbf.NumRecords(); recno++ {
+			rec, err := dbf.RecordAt(recno)
+			if err != nil {
+				return nil, fmt.Errorf("%s record %d: %s", from.table, recno, err)
+			}
+			if !rec.Deleted {
+				t.records = append(t.records, rec)
+			}
+		}
+		tables[i] = t
+	}
+
+	// nested loop joins, joins on an equal condition use a hash of the records of the joined table
+	rows := [][]*Record{{}}
+	for i, from := range stmt.from {
+		env := &sqlRow{tables: tables[:i+1], params: params}
+		hash, lookup := stmt.joinHash(env, i)
+		var joined [][]*Record
+		for _, row := range rows {
+			candidates := tables[i].records
+			if hash != nil {
+				env.records = append(row, nil)
+				key, err := lookup.eval(env)
+				if err != nil {
+					return nil, err
+				}
+				candidates = hash[sqlKey(key)]
+			}
+			matched := false
+			for _, rec := range candidates {
+				combined := append(append(make([]*Record, 0, i+1), row...), rec)
+				if from.on != nil {
+					env.records = combined
+					ok, err := from.on.eval(env)
+					if err != nil {
+						return nil, err
+					}
+					if !exprTrue(ok) {
+						continue
+					}
+				}
+				matched = true
+				joined = append(joined, combined)
+			}
+			if !matched && from.left {
+				joined = append(joined, append(append(make([]*Record, 0, i+1), row...), nil))
+			}
+		}
+		rows = joined
+	}
+
+	env := &sqlRow{tables: tables, params: params}
+	if stmt.where != nil {
+		filtered := rows[:0]
+		for _, row := range rows {
+			env.records = row
+			ok, err := stmt.where.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			if exprTrue(ok) {
+				filtered = append(filtered, row)
+			}
+		}
+		rows = filtered
+	}
+
+	cur := new(Cursor)
+	type resultRow struct {
+		values []interface{}
+		order  []interface{}
+	}
+	var results []resultRow
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		env.records = row
+		var values []interface{}
+		for _, item := range stmt.items {
+			if item.star != "" {
+				for i, t := range tables {
+					if item.star != "*" && !strings.EqualFold(item.star, t.alias) {
+						continue
+					}
+					for pos := range t.dbf.fields {
+						if row[i] == nil {
+							values = append(values, nil)
+						} else {
+							values = append(values, row[i].data[pos])
+						}
+					}
+				}
+				continue
+			}
+			val, err := item.expr.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, val)
+		}
+		if stmt.distinct {
+			key := sqlRowKey(values)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		result := resultRow{values: values}
+		for _, order := range stmt.orderBy {
+			if order.column >= 0 || order.position > 0 {
+				result.order = append(result.order, nil)
+				continue
+			}
+			val, err := order.expr.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			result.order = append(result.order, val)
+		}
+		results = append(results, result)
+	}
+
+	// result column positions of the items, star items expand to multiple columns
+	columnPos := make([]int, len(stmt.items))
+	for i, item := range stmt.items {
+		if item.star == "" {
+			columnPos[i] = len(cur.columns)
+			cur.columns = append(cur.columns, item.name)
+			continue
+		}
+		columnPos[i] = len(cur.columns)
+		for _, t := range tables {
+			if item.star == "*" || strings.EqualFold(item.star, t.alias) {
+				cur.columns = append(cur.columns, t.dbf.FieldNames()...)
+			}
+		}
+	}
+
+	for _, order := range stmt.orderBy {
+		if order.position < 0 || order.position > len(cur.columns) {
+			return nil, fmt.Errorf("ORDER BY column %d does not exist", order.position)
+		}
+	}
+	if len(stmt.orderBy) > 0 {
+		var sortErr error
+		sort.SliceStable(results, func(a, b int) bool {
+			for i, order := range stmt.orderBy {
+				var va, vb interface{}
+				if order.position > 0 {
+					va, vb = results[a].values[order.position-1], results[b].values[order.position-1]
+				} else if order.column >= 0 {
+					va, vb = results[a].values[columnPos[order.column]], results[b].values[columnPos[order.column]]
+				} else {
+					va, vb = results[a].order[i], results[b].order[i]
+				}
+				c, err := sqlCompare(va, vb)
+				if err != nil && sortErr == nil {
+					sortErr = err
+				}
+				if c == 0 {
+					continue
+				}
+				if order.desc {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+		if sortErr != nil {
+			return nil, sortErr
+		}
+	}
+	if stmt.top > 0 && len(results) > stmt.top {
+		results = results[:stmt.top]
+	}
+	cur.rows = make([][]interface{}, len(results))
+	for i, result := range results {
+		cur.rows[i] = result.values
+	}
+	return cur, nil
+}
+
+// joinHash returns a hash of the records of table i by the value of the field compared with = in its ON
+// condition, and the expression giving the key from the other tables. It returns nil when the condition
+// has no such comparison.
+func (stmt *sqlSelect) joinHash(env *sqlRow, i int) (map[string][]*Record, exprNode) {
+	on := stmt.from[i].on
+	if on == nil {
+		return nil, nil
+	}
+	// find an equal comparison between a field of table i and an expression of the other tables in the
+	// conditions combined with AND
+	conditions := []exprNode{on}
+	for len(conditions) > 0 {
+		cond := conditions[0]
+		conditions = conditions[1:]
+		bin, ok := cond.(*binaryNode)
+		if !ok {
+			continue
+		}
+		if bin.op == "AND" {
+			conditions = append(conditions, bin.l, bin.r)
+			continue
+		}
+		if bin.op != "=" && bin.op != "==" {
+			continue
+		}
+		for _, sides := range [][2]exprNode{{bin.l, bin.r}, {bin.r, bin.l}} {
+			col, ok := sides[0].(*columnNode)
+			if !ok || sqlTableOf(env, col.name) != i || sqlRefersTo(env, sides[1], i) {
+				continue
+			}
+			hash := make(map[string][]*Record)
+			t := env.tables[i]
+			records := make([]*Record, i+1)
+			for _, rec := range t.records {
+				records[i] = rec
+				env.records = records
+				val, err := col.eval(env)
+				if err != nil {
+					return nil, nil
+				}
+				key := sqlKey(val)
+				hash[key] = append(hash[key], rec)
+			}
+			return hash, sides[1]
+		}
+	}
+	return nil, nil
+}
+
+// sqlTableOf returns the index of the table of a field reference, or -1
+func sqlTableOf(env *sqlRow, name string) int {
+	qualifier := ""
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		qualifier, name = name[:i], name[i+1:]
+	}
+	for i, t := range env.tables {
+		if qualifier != "" && !strings.EqualFold(qualifier, t.alias) {
+			continue
+		}
+		if t.dbf.FieldPos(strings.ToUpper(name)) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// sqlRefersTo returns if expression x contains a field of table i
+func sqlRefersTo(env *sqlRow, x exprNode, i int) bool {
+	switch n := x.(type) {
+	case *columnNode:
+		return sqlTableOf(env, n.name) == i
+	case *unaryNode:
+		return sqlRefersTo(env, n.x, i)
+	case *binaryNode:
+		return sqlRefersTo(env, n.l, i) || sqlRefersTo(env, n.r, i)
+	case *callNode:
+		for _, arg := range n.args {
+			if sqlRefersTo(env, arg, i) {
+				return true
+			}
+		}
+		return false
+	case *literalNode, *paramNode:
+		return false
+	}
+	// other nodes are not used as join keys
+	return true
+}
+
+// sqlKey returns a string key of a value for hashing, equal values according to exprCompare have the same key
+func sqlKey(val interface{}) string {
+	if f, ok := exprNumber(val); ok {
+		return "n" + strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	switch v := val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "s" + strings.TrimRight(v, " ")
+	case time.Time:
+		return "t" + v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%T%v", val, val)
+}
+
+// sqlRowKey returns a key of a result row for DISTINCT
+func sqlRowKey(values []interface{}) string {
+	keys := make([]string, len(values))
+	for i, val := range values {
+		keys[i] = sqlKey(val)
+	}
+	return strings.Join(keys, "\x00")
+}
+
+// sqlCompare compares values for ORDER BY, NULL sorts first
+func sqlCompare(a, b interface{}) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return -1, nil
+	case b == nil:
+		return 1, nil
+	}
+	return exprCompare(a, b)
+}
@@ -0,0 +1,137 @@
+package dbf
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestOpenView(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := OpenDatabase(writeTestDatabase(t, dir), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	views := db.Views()
+	if len(views) != 2 || views[0].SQL != testViewSQL || views[0].Remote || !views[1].Remote {
+		t.Fatalf("Unexpected views %+v", views)
+	}
+	if _, err := db.OpenView("remote_users", nil); err == nil {
+		t.Error("Want an error opening a remote view")
+	}
+	if _, err := db.OpenView("notexist", nil); err != ErrViewNotFound {
+		t.Errorf("Want ErrViewNotFound, have %v", err)
+	}
+	if _, err := db.OpenView("results_by_user", nil); err == nil {
+		t.Error("Want an error for a missing parameter")
+	}
+
+	cur, err := db.OpenView("RESULTS_BY_USER", map[string]interface{}{"minid": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"id", "company", "name"}; !reflect.DeepEqual(cur.Columns(), want) {
+		t.Errorf("Want columns %v, have %v", want, cur.Columns())
+	}
+	var rows [][]interface{}
+	for cur.Next() {
+		rows = append(rows, cur.Values())
+	}
+	want := [][]interface{}{
+		{int32(4), "                                        ", nil},
+		{int32(3), "TEST2                                   ", "Piet                "},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Want rows %q, have %q", want, rows)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfquery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := OpenDatabase(writeTestDatabase(t, dir), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		sql     string
+		columns []string
+		rows    [][]interface{}
+	}{
+		{
+			"SELECT * FROM users",
+			[]string{"USERNR", "NAME"},
+			[][]interface{}{{int32(1), "Jan                 "}, {int32(2), "Piet                "}},
+		},
+		{
+			"SELECT u.*, r.id FROM users u, test_results r WHERE r.usernr = u.usernr ORDER BY 3",
+			[]string{"USERNR", "NAME", "id"},
+			[][]interface{}{{int32(1), "Jan                 ", int32(1)}, {int32(2), "Piet                ", int32(3)}},
+		},
+		{
+			"SELECT DISTINCT niveau FROM test_results ORDER BY niveau DESC",
+			[]string{"niveau"},
+			[][]interface{}{{int64(1)}, {int64(0)}},
+		},
+		{
+			"SELECT TOP 1 id, UPPER(ALLTRIM(comp_os)) + '!' os FROM test_results WHERE comp_os LIKE 'Windows%' AND NOT bool ORDER BY id DESC",
+			[]string{"id", "os"},
+			[][]interface{}{{int32(3), "WINDOWS 7 SP1!"}},
+		},
+		{
+			"SELECT id, number * 2 FROM test_results WHERE datum BETWEEN {^2015-01-01} AND {^2015-01-31} OR id IN (4)",
+			[]string{"id", "EXP_2"},
+			[][]interface{}{{int32(1), 3.32}, {int32(4), float64(0)}},
+		},
+		{
+			"SELECT r.id, u.name FROM test_results r INNER JOIN users u ON u.usernr = r.usernr AND u.name = 'Jan'",
+			[]string{"id", "name"},
+			[][]interface{}{{int32(1), "Jan                 "}},
+		},
+	}
+	for _, test := range tests {
+		cur, err := db.Query(test.sql, nil)
+		if err != nil {
+			t.Errorf("%s: %s", test.sql, err)
+			continue
+		}
+		if !reflect.DeepEqual(cur.Columns(), test.columns) {
+			t.Errorf("%s: want columns %v, have %v", test.sql, test.columns, cur.Columns())
+		}
+		var rows [][]interface{}
+		for i := 0; i < cur.Len(); i++ {
+			rows = append(rows, cur.Row(i))
+		}
+		if !reflect.DeepEqual(rows, test.rows) {
+			t.Errorf("%s: want rows %v, have %v", test.sql, test.rows, rows)
+		}
+	}
+
+	for _, sql := range []string{
+		"SELECT id FROM test_results GROUP BY id",
+		"SELECT id FROM test_results RIGHT JOIN users ON 1 = 1",
+		"SELECT id FROM notexist",
+		"SELECT notexist FROM users",
+		"SELECT id FROM",
+		"SELECT x FROM .T.",
+		"SELECT x FROM .NULL.",
+	} {
+		if _, err := db.Query(sql, nil); err == nil {
+			t.Errorf("%s: want an error", sql)
+		}
+	}
+}
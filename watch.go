@@ -0,0 +1,189 @@
+package dbf
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+var (
+	// ErrNotOnDisk is returned by Watch for tables which were not opened with OpenFile
+	ErrNotOnDisk = errors.New("table was not opened from disk")
+
+	// ErrStructureChanged is returned when the header of a changed table has a different record layout
+	ErrStructureChanged = errors.New("table structure has changed, the table must be reopened")
+
+	// ErrRecordCountDecreased is returned by ReadNewSince when the table has less records than the bookmark,
+	// which happens when the table was packed or zapped
+	ErrRecordCountDecreased = errors.New("record count has decreased, the table was packed or zapped")
+)
+
+// WatchEvent is sent by Watch when the table files have changed
+type WatchEvent struct {
+	Header   DBFHeader // Header as read from disk after the change
+	Size     int64     // Size of the DBF file
+	MemoSize int64     // Size of the FPT file, 0 for tables without memo file
+	ModTime  time.Time // Modification time of the DBF file
+	Err      error     // Set if the files could not be read, or ErrStructureChanged
+}
+
+// Watch checks the DBF and FPT files every interval (10 seconds when interval is 0) and sends an event when their size, modification time or the
+// header (record count or last update date) has changed, so new records appended by another application can be read.
+// The channel is closed when ctx is done. Watch only reads the files, call RefreshHeader after an event to make
+// the new records available in the DBF.
+//
+// Watch polls instead of using file system notifications (fsnotify) because FoxPro tables are often shared on
+// network drives, where notifications are not reliable, and polling needs no dependency. A header is only reported when it is consistent with the file size,
+// FoxPro writes new records before the header is updated.
+func (dbf *DBF) Watch(ctx context.Context, interval time.Duration) (<-chan WatchEvent, error) {
+	if dbf.f == nil {
+		return nil, ErrNotOnDisk
+	}
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	dbfname := dbf.f.Name()
+	fptname := ""
+	if dbf.fptf != nil {
+		fptname = dbf.fptf.Name()
+	}
+
+	last, err := watchState(dbfname, fptname)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			state, err := watchState(dbfname, fptname)
+			if err == errInconsistentHeader {
+				// the header is not updated yet, check again on the next tick
+				continue
+			}
+			if err == nil && state.equal(last) {
+				continue
+			}
+			event := WatchEvent{Err: err}
+			if err == nil {
+				event = state
+				if state.Header.FirstRec != dbf.header.FirstRec || state.Header.RecLen != dbf.header.RecLen {
+					event.Err = ErrStructureChanged
+				}
+				last = state
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// RefreshHeader reads the header from disk again, so records added by another application after the table
+// was opened can be read. It returns ErrStructureChanged when the record layout has changed.
+// Like all other DBF methods, RefreshHeader must not be called concurrently with reading records.
+func (dbf *DBF) RefreshHeader() error {
+	header, err := readHeaderAt(dbf.r)
+	if err != nil {
+		return err
+	}
+	if header.FirstRec != dbf.header.FirstRec || header.RecLen != dbf.header.RecLen {
+		return ErrStructureChanged
+	}
+	dbf.header = header
+	return nil
+}
+
+// ReadNewSince re-reads the record count from the header and returns the records appended after the first
+// lastCount records, together with the new record count to use as bookmark for the next call.
+// This makes it possible to tail append-only tables:
+//
+//	records, last, err = d.ReadNewSince(last)
+//
+// Deleted records are returned as well, check Record.Deleted.
+func (dbf *DBF) ReadNewSince(lastCount uint32) ([]*Record, uint32, error) {
+	if err := dbf.RefreshHeader(); err != nil {
+		return nil, lastCount, err
+	}
+	count := dbf.header.NumRec
+	if count < lastCount {
+		return nil, lastCount, ErrRecordCountDecreased
+	}
+	records := make([]*Record, 0, count-lastCount)
+	for recno := lastCount; recno < count; recno++ {
+		rec, err := dbf.RecordAt(recno)
+		if err != nil {
+			return records, recno, err
+		}
+		records = append(records, rec)
+	}
+	return records, count, nil
+}
+
+// errInconsistentHeader is returned by watchState when the header does not match the file size
+var errInconsistentHeader = errors.New("header is not consistent with the file size")
+
+// watchState reads the current state of the table files, it opens the files separately so it can run
+// concurrently with reads from the DBF
+func watchState(dbfname, fptname string) (WatchEvent, error) {
+	var state WatchEvent
+	f, err := os.Open(longPath(dbfname))
+	if err != nil {
+		return state, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return state, err
+	}
+	header, err := readHeaderAt(f)
+	if err != nil {
+		return state, err
+	}
+	if info.Size() < int64(header.FirstRec)+int64(header.NumRec)*int64(header.RecLen) {
+		return state, errInconsistentHeader
+	}
+	state.Header = *header
+	state.Size = info.Size()
+	state.ModTime = info.ModTime()
+	if fptname != "" {
+		info, err := os.Stat(longPath(fptname))
+		if err != nil {
+			return state, err
+		}
+		state.MemoSize = info.Size()
+	}
+	return state, nil
+}
+
+// equal returns if nothing was changed between two states
+func (e WatchEvent) equal(other WatchEvent) bool {
+	return e.Header == other.Header && e.Size == other.Size && e.MemoSize == other.MemoSize && e.ModTime.Equal(other.ModTime)
+}
+
+// readHeaderAt reads the DBF header without moving the read position of r
+func readHeaderAt(r io.ReaderAt) (*DBFHeader, error) {
+	buf := make([]byte, 32)
+	if _, err := r.ReadAt(buf, 0); err != nil {
+		return nil, err
+	}
+	h := new(DBFHeader)
+	if err := binary.Read(bytes.NewReader(buf), binary.LittleEndian, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
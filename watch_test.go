@@ -0,0 +1,205 @@
+package dbf
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfwatch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"TEST.DBF", "TEST.FPT"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dbf, err := OpenFile(filepath.Join(dir, "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := dbf.Watch(ctx, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// append a copy of the first record like FoxPro does: records first, header last
+	f, err := os.OpenFile(filepath.Join(dir, "TEST.DBF"), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	h := dbf.Header()
+	rec := make([]byte, h.RecLen)
+	if _, err := f.ReadAt(rec, int64(h.FirstRec)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt(append(rec, 0x1A), int64(h.FirstRec)+int64(h.NumRec)*int64(h.RecLen)); err != nil {
+		t.Fatal(err)
+	}
+	numRec := make([]byte, 4)
+	binary.LittleEndian.PutUint32(numRec, h.NumRec+1)
+	if _, err := f.WriteAt(numRec, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	for event := range events {
+		if event.Err != nil {
+			t.Fatal(event.Err)
+		}
+		if event.Header.NumRec == 5 {
+			cancel()
+			break
+		}
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		t.Fatal("No event received for the new record")
+	}
+
+	if dbf.NumRecords() != 4 {
+		t.Errorf("Want 4 records before refresh, have %d", dbf.NumRecords())
+	}
+	if err := dbf.RefreshHeader(); err != nil {
+		t.Fatal(err)
+	}
+	if dbf.NumRecords() != 5 {
+		t.Fatalf("Want 5 records after refresh, have %d", dbf.NumRecords())
+	}
+	last, err := dbf.RecordAt(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.FieldSlice()[0] != int32(1) {
+		t.Errorf("Want ID 1 in the new record, have %v", last.FieldSlice()[0])
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpt, err := ioutil.ReadFile(filepath.Join(dir, "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := OpenStream(bytes.NewReader(data), bytes.NewReader(fpt), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Watch(context.Background(), time.Second); err != ErrNotOnDisk {
+		t.Errorf("Want ErrNotOnDisk, have %v", err)
+	}
+}
+
+func TestWatchDefaultInterval(t *testing.T) {
+	dbf, err := OpenFile(filepath.Join("testdata", "TEST.DBF"), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := dbf.Watch(ctx, interval)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		// the channel is closed after cancel, no event is sent before the first poll
+		if _, ok := <-events; ok {
+			t.Errorf("Interval %v: want no events", interval)
+		}
+	}
+}
+
+func TestReadNewSince(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpt, err := ioutil.ReadFile(filepath.Join("testdata", "TEST.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := &growingReader{data: data}
+	dbf, err := OpenStream(stream, bytes.NewReader(fpt), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, last, err := dbf.ReadNewSince(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || last != 4 {
+		t.Fatalf("Want 2 records and bookmark 4, have %d and %d", len(records), last)
+	}
+	if records[0].FieldSlice()[0] != int32(3) {
+		t.Errorf("Want ID 3, have %v", records[0].FieldSlice()[0])
+	}
+
+	// nothing new
+	records, last, err = dbf.ReadNewSince(last)
+	if err != nil || len(records) != 0 || last != 4 {
+		t.Fatalf("Want no records and bookmark 4, have %d, %d and %v", len(records), last, err)
+	}
+
+	// append a copy of the first record
+	h := dbf.Header()
+	end := int(h.FirstRec) + int(h.NumRec)*int(h.RecLen)
+	rec := append([]byte{}, data[h.FirstRec:int(h.FirstRec)+int(h.RecLen)]...)
+	stream.data = append(append(stream.data[:end:end], rec...), 0x1A)
+	binary.LittleEndian.PutUint32(stream.data[4:], h.NumRec+1)
+
+	records, last, err = dbf.ReadNewSince(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || last != 5 || records[0].FieldSlice()[0] != int32(1) {
+		t.Errorf("Want the appended record and bookmark 5, have %d records and %d", len(records), last)
+	}
+
+	if _, _, err := dbf.ReadNewSince(10); err != ErrRecordCountDecreased {
+		t.Errorf("Want ErrRecordCountDecreased, have %v", err)
+	}
+}
+
+// growingReader is a ReaderAtSeeker over a byte slice which can be changed between reads
+type growingReader struct {
+	data []byte
+	pos  int64
+}
+
+func (g *growingReader) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(g.data).ReadAt(p, off)
+}
+
+func (g *growingReader) Read(p []byte) (int, error) {
+	n, err := g.ReadAt(p, g.pos)
+	g.pos += int64(n)
+	return n, err
+}
+
+func (g *growingReader) Seek(offset int64, whence int) (int64, error) {
+	r := bytes.NewReader(g.data)
+	r.Seek(g.pos, 0)
+	pos, err := r.Seek(offset, whence)
+	g.pos = pos
+	return pos, err
+}
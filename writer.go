@@ -0,0 +1,612 @@
+package dbf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrWriterClosed is returned when writing to a Writer after Close
+	ErrWriterClosed = errors.New("writer is closed")
+
+	// ErrNumFields is returned when the number of values does not match the number of fields
+	ErrNumFields = errors.New("number of values does not match number of fields")
+)
+
+// memoBlockSize is the block size used for new memo files, the FoxPro default
+const memoBlockSize = 64
+
+// backlinkSize is the size of the database container backlink area between the field descriptors and the first record
+const backlinkSize = 263
+
+// Writer writes a new Visual FoxPro table (file version 0x30) and its memo file.
+// Records are appended in order, the header is completed when Close is called.
+type Writer struct {
+	header *DBFHeader
+	fields []FieldHeader
+
+	w    io.WriteSeeker
+	bw   *bufio.Writer
+	memo *memoWriter
+
+	enc Encoder
+
+	buf     []byte
+	closed  bool
+	structs map[reflect.Type][][]int // struct field mapping cache of AppendStruct
+
+	defaults []exprNode // default value expressions by field position, see defaults.go
+
+	files []*os.File // files created by Create, closed by Close
+}
+
+// NewWriter creates a Writer for a new table with the given fields and writes the header to dbffile.
+// The Pos of the fields is calculated by NewWriter, only Name, Type, Len and Decimals have to be set.
+// Names must be accepted by FoxPro and unique without regard to case, see SchemaBuilder.
+// For field types with a fixed size (D, T, I, B, Y, L, M, G, P, W) the length is set automatically.
+// fptfile is required when there are memo fields, otherwise it is ignored and can be nil.
+// The Encoder is used to convert UTF8 strings to the charset of the table, see encoder.go, the code page mark
+// of the header is set for the charset of the Encoder.
+// The caller is responsible for calling Close to finish the table.
+func NewWriter(dbffile, fptfile io.WriteSeeker, fields []FieldHeader, enc Encoder) (*Writer, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("a table needs at least one field")
+	}
+
+	wr := &Writer{
+		header: &DBFHeader{FileVersion: 0x30, CodePage: encoderCodePage(enc)},
+		fields: make([]FieldHeader, len(fields)),
+		w:      dbffile,
+		enc:    enc,
+	}
+
+	if len(fields) > DefaultMaxFields {
+		return nil, &LimitError{Limit: "number of fields", Value: uint64(len(fields)), Max: DefaultMaxFields}
+	}
+	names := make(map[string]bool)
+	pos := uint32(1) // deleted flag
+	hasMemo := false
+	for i, f := range fields {
+		if err := prepareNewField(&f, names); err != nil {
+			return nil, err
+		}
+		if f.isMemo() {
+			hasMemo = true
+		}
+		f.Pos = pos
+		pos += uint32(f.Len)
+		wr.fields[i] = f
+	}
+	if pos > MaxRecordLength {
+		return nil, &LimitError{Limit: "record length", Value: uint64(pos), Max: MaxRecordLength}
+	}
+	if headerLen := 32 + 32*len(fields) + 1 + backlinkSize; headerLen > MaxHeaderLength {
+		return nil, &LimitError{Limit: "header length", Value: uint64(headerLen), Max: MaxHeaderLength}
+	}
+
+	wr.header.RecLen = uint16(pos)
+	wr.header.FirstRec = uint16(32 + 32*len(fields) + 1 + backlinkSize)
+	if hasMemo {
+		if fptfile == nil {
+			return nil, ErrNoFPTFile
+		}
+		wr.header.TableFlags |= 0x02
+		memo, err := newMemoWriter(fptfile, memoBlockSize)
+		if err != nil {
+			return nil, err
+		}
+		wr.memo = memo
+	}
+
+	if _, err := dbffile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if _, err := dbffile.Write(wr.headerBytes()); err != nil {
+		return nil, err
+	}
+	wr.bw = bufio.NewWriter(dbffile)
+	wr.buf = make([]byte, wr.header.RecLen)
+	return wr, nil
+}
+
+// Create creates the table filename and, when there are memo fields, its memo file with the name of the table and
+// the extension FPT, and returns a Writer for them like NewWriter. Existing files are truncated.
+// Close completes the table and closes the files. When the Writer can not be created the files are removed.
+func Create(filename string, fields []FieldHeader, enc Encoder) (*Writer, error) {
+	dbffile, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	files := []*os.File{dbffile}
+	var fptfile io.WriteSeeker
+	for _, f := range fields {
+		if f.isMemo() {
+			memo, err := os.Create(memoFileName(filename))
+			if err != nil {
+				dbffile.Close()
+				os.Remove(filename)
+				return nil, err
+			}
+			files = append(files, memo)
+			fptfile = memo
+			break
+		}
+	}
+	wr, err := NewWriter(dbffile, fptfile, fields, enc)
+	if err != nil {
+		for _, f := range files {
+			f.Close()
+			os.Remove(f.Name())
+		}
+		return nil, err
+	}
+	wr.files = files
+	return wr, nil
+}
+
+// prepareNewField validates a field of a new table with prepareField, its name must be accepted by FoxPro
+// and not be in names without regard to case. The name is added to names.
+func prepareNewField(f *FieldHeader, names map[string]bool) error {
+	name := f.FieldName()
+	if detail := checkFieldName(name); detail != "" {
+		return errors.New(detail)
+	}
+	upper := strings.ToUpper(name)
+	if names[upper] {
+		return fmt.Errorf("duplicate field name %s", upper)
+	}
+	if err := prepareField(f); err != nil {
+		return err
+	}
+	names[upper] = true
+	return nil
+}
+
+// checkFieldName returns why FoxPro does not accept name as field name, or an empty string when it does
+func checkFieldName(name string) string {
+	if name == "" || len(name) > 10 {
+		return fmt.Sprintf("a field name has 1 to 10 characters, %q has %d", name, len(name))
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		case c >= '0' && c <= '9':
+			return fmt.Sprintf("field name %s starts with a digit", name)
+		default:
+			return fmt.Sprintf("field name %s contains %q, only letters, digits and underscores are allowed", name, c)
+		}
+	}
+	return ""
+}
+
+// prepareField validates a field definition and sets the length of fixed size types
+func prepareField(f *FieldHeader) error {
+	name := f.FieldName()
+	if name == "" || len(name) > 10 {
+		return fmt.Errorf("invalid field name %q, a name must have 1 to 10 characters", name)
+	}
+	switch f.Type {
+	case 'C':
+		if f.Len == 0 || f.Len > 254 {
+			return fmt.Errorf("field %s: length of C field must be 1 to 254", name)
+		}
+		f.Decimals = 0
+	case 'N', 'F':
+		if f.Len == 0 || f.Len > 20 {
+			return fmt.Errorf("field %s: length of %c field must be 1 to 20", name, f.Type)
+		}
+		if f.Decimals > 0 && int(f.Decimals) > int(f.Len)-2 {
+			return fmt.Errorf("field %s: %d decimals do not fit in length %d", name, f.Decimals, f.Len)
+		}
+	case 'D', 'T', 'B':
+		f.Len = 8
+	case 'Y':
+		f.Len, f.Decimals = 8, 4
+	case 'I', 'M', 'G', 'P', 'W':
+		f.Len = 4
+	case 'L':
+		f.Len = 1
+	default:
+		return fmt.Errorf("field %s: unsupported field type %q for writing", name, string(f.Type))
+	}
+	return nil
+}
+
+// Header returns the header of the table being written.
+// Changes to the header, like the CodePage, are written when the Writer is closed.
+func (wr *Writer) Header() *DBFHeader {
+	return wr.header
+}
+
+// Fields returns the fields of the table being written, with their calculated positions
+func (wr *Writer) Fields() []FieldHeader {
+	return wr.fields
+}
+
+// NumRecords returns the number of records written so far
+func (wr *Writer) NumRecords() uint32 {
+	return wr.header.NumRec
+}
+
+// Append writes a record with the values in field order.
+// Accepted Go types per field type:
+//
+//	C          string, []byte
+//	N, F       all int and float types, numeric strings
+//	I          all int types
+//	B, Y       all int and float types
+//	D, T       time.Time
+//	L          bool
+//	M          string (text memo), []byte (binary memo)
+//	G, P, W    []byte
+//
+// A nil value writes the default value set with SetDefaultValues, or an empty field.
+func (wr *Writer) Append(values ...interface{}) error {
+	if wr.closed {
+		return ErrWriterClosed
+	}
+	if len(values) != len(wr.fields) {
+		return ErrNumFields
+	}
+	if wr.header.NumRec == MaxRecords {
+		return &LimitError{Limit: "number of records", Value: uint64(MaxRecords) + 1, Max: MaxRecords}
+	}
+	if err := wr.encodeRecord(values); err != nil {
+		return err
+	}
+	if _, err := wr.bw.Write(wr.buf); err != nil {
+		return err
+	}
+	wr.header.NumRec++
+	return nil
+}
+
+// encodeRecord converts the values to the raw record data in wr.buf
+func (wr *Writer) encodeRecord(values []interface{}) error {
+	wr.buf[0] = 0x20
+	offset := 1
+	for i, val := range values {
+		f := &wr.fields[i]
+		if val == nil && wr.defaults != nil && wr.defaults[i] != nil {
+			var err error
+			if val, err = wr.defaultValue(i); err != nil {
+				return fmt.Errorf("field %s: default value: %s", f.FieldName(), err)
+			}
+		}
+		if err := wr.encodeField(wr.buf[offset:offset+int(f.Len)], f, val); err != nil {
+			return fmt.Errorf("field %s: %s", f.FieldName(), err)
+		}
+		offset += int(f.Len)
+	}
+	return nil
+}
+
+// encodeField converts val to the raw field representation of f and stores it in dst
+func (wr *Writer) encodeField(dst []byte, f *FieldHeader, val interface{}) error {
+	switch f.Type {
+	case 'M', 'G', 'P', 'W':
+		return wr.encodeMemo(dst, f, val)
+	case 'C':
+		if val != nil {
+			data, err := wr.encodeText(val)
+			if err != nil {
+				return err
+			}
+			if len(data) > len(dst) {
+				return fmt.Errorf("value of %d bytes does not fit in length %d", len(data), len(dst))
+			}
+			copy(dst, data)
+			fillSpaces(dst[len(data):])
+			return nil
+		}
+	}
+	return encodeValue(dst, f, val)
+}
+
+// encodeValue converts a non-character, non-memo value to the raw field representation of f
+func encodeValue(dst []byte, f *FieldHeader, val interface{}) error {
+	if val == nil {
+		switch f.Type {
+		case 'C', 'N', 'F', 'D', 'L':
+			fillSpaces(dst)
+		default:
+			for i := range dst {
+				dst[i] = 0
+			}
+		}
+		return nil
+	}
+
+	switch f.Type {
+	case 'N', 'F':
+		str, err := formatNumeric(val, int(f.Len), int(f.Decimals))
+		if err != nil {
+			return err
+		}
+		copy(dst, str)
+	case 'I':
+		i, ok := toInt64(val)
+		if !ok {
+			return fmt.Errorf("cannot write %T to I field", val)
+		}
+		if i < math.MinInt32 || i > math.MaxInt32 {
+			return fmt.Errorf("value %d does not fit in an I field", i)
+		}
+		binary.LittleEndian.PutUint32(dst, uint32(int32(i)))
+	case 'B':
+		fl, ok := toFloat64(val)
+		if !ok {
+			return fmt.Errorf("cannot write %T to B field", val)
+		}
+		binary.LittleEndian.PutUint64(dst, math.Float64bits(fl))
+	case 'Y':
+		fl, ok := toFloat64(val)
+		if !ok {
+			return fmt.Errorf("cannot write %T to Y field", val)
+		}
+		// currency is stored as an integer of 1/10000 units
+		scaled := math.Round(fl * 10000)
+		if math.IsNaN(scaled) || scaled < math.MinInt64 || scaled >= -math.MinInt64 {
+			return fmt.Errorf("value %v does not fit in a Y field", fl)
+		}
+		binary.LittleEndian.PutUint64(dst, uint64(int64(scaled)))
+	case 'D':
+		t, ok := val.(time.Time)
+		if !ok {
+			return fmt.Errorf("cannot write %T to D field", val)
+		}
+		if t.IsZero() {
+			fillSpaces(dst)
+			return nil
+		}
+		if err := checkYear(t, f); err != nil {
+			return err
+		}
+		copy(dst, t.Format("20060102"))
+	case 'T':
+		t, ok := val.(time.Time)
+		if !ok {
+			return fmt.Errorf("cannot write %T to T field", val)
+		}
+		if err := checkYear(t, f); err != nil {
+			return err
+		}
+		julian, msec := TimeToJulian(t)
+		binary.LittleEndian.PutUint32(dst[:4], julian)
+		binary.LittleEndian.PutUint32(dst[4:], msec)
+	case 'L':
+		b, ok := val.(bool)
+		if !ok {
+			return fmt.Errorf("cannot write %T to L field", val)
+		}
+		dst[0] = 'F'
+		if b {
+			dst[0] = 'T'
+		}
+	default:
+		return fmt.Errorf("unsupported field type %s for writing", f.FieldType())
+	}
+	return nil
+}
+
+// checkYear returns an error if the year of t is outside 0 to 9999, which D and T fields can store
+func checkYear(t time.Time, f *FieldHeader) error {
+	if y := t.Year(); y < 0 || y > 9999 {
+		return fmt.Errorf("year %d does not fit in a %s field", y, f.FieldType())
+	}
+	return nil
+}
+
+// encodeText converts a string or byte slice to the table charset
+func (wr *Writer) encodeText(val interface{}) ([]byte, error) {
+	switch v := val.(type) {
+	case string:
+		return wr.enc.Encode([]byte(v))
+	case []byte:
+		return wr.enc.Encode(v)
+	default:
+		return nil, fmt.Errorf("cannot write %T to a character field", val)
+	}
+}
+
+// encodeMemo writes val to the memo file and stores the block number in dst
+func (wr *Writer) encodeMemo(dst []byte, f *FieldHeader, val interface{}) error {
+	var data []byte
+	sign := uint32(0) // binary
+	switch v := val.(type) {
+	case nil:
+	case string:
+		if f.Type != 'M' {
+			return fmt.Errorf("cannot write string to %s field, use []byte", f.FieldType())
+		}
+		enc, err := wr.enc.Encode([]byte(v))
+		if err != nil {
+			return err
+		}
+		data, sign = enc, 1
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot write %T to %s field", val, f.FieldType())
+	}
+	if len(data) == 0 {
+		// empty memos are stored as block 0
+		binary.LittleEndian.PutUint32(dst, 0)
+		return nil
+	}
+	block, err := wr.memo.write(sign, data)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(dst, block)
+	return nil
+}
+
+// Close writes the EOF marker and completes the DBF and FPT headers.
+// It does not close the underlying files, except the files created by Create.
+func (wr *Writer) Close() error {
+	if wr.closed {
+		return ErrWriterClosed
+	}
+	wr.closed = true
+	err := wr.complete()
+	for _, f := range wr.files {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// complete writes the EOF marker and the DBF and FPT headers
+func (wr *Writer) complete() error {
+	if err := wr.bw.WriteByte(0x1A); err != nil {
+		return err
+	}
+	if err := wr.bw.Flush(); err != nil {
+		return err
+	}
+	setHeaderModified(wr.header, time.Now())
+	if _, err := wr.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := wr.w.Write(wr.headerBytes()); err != nil {
+		return err
+	}
+	if wr.memo != nil {
+		return wr.memo.close()
+	}
+	return nil
+}
+
+// headerBytes returns the complete raw header including field descriptors, terminator and backlink area
+func (wr *Writer) headerBytes() []byte {
+	buf := make([]byte, wr.header.FirstRec)
+	h := wr.header
+	buf[0] = h.FileVersion
+	buf[1], buf[2], buf[3] = h.ModYear, h.ModMonth, h.ModDay
+	binary.LittleEndian.PutUint32(buf[4:], h.NumRec)
+	binary.LittleEndian.PutUint16(buf[8:], h.FirstRec)
+	binary.LittleEndian.PutUint16(buf[10:], h.RecLen)
+	buf[28] = h.TableFlags
+	buf[29] = h.CodePage
+	for i, f := range wr.fields {
+		putFieldDescriptor(buf[32+32*i:], &f)
+	}
+	buf[32+32*len(wr.fields)] = 0x0D
+	return buf
+}
+
+// putFieldDescriptor writes the 32 byte field descriptor of f to dst
+func putFieldDescriptor(dst []byte, f *FieldHeader) {
+	copy(dst[0:11], f.Name[:])
+	dst[11] = f.Type
+	binary.LittleEndian.PutUint32(dst[12:], f.Pos)
+	dst[16] = f.Len
+	dst[17] = f.Decimals
+	dst[18] = f.Flags
+	binary.LittleEndian.PutUint32(dst[19:], f.Next)
+	dst[23] = byte(f.Step)
+}
+
+// setHeaderModified sets the last update date in the header
+func setHeaderModified(h *DBFHeader, t time.Time) {
+	h.ModYear = uint8(t.Year() % 100)
+	h.ModMonth = uint8(t.Month())
+	h.ModDay = uint8(t.Day())
+}
+
+// formatNumeric formats val right aligned in a field of length width with the given decimals
+func formatNumeric(val interface{}, width, decimals int) (string, error) {
+	var str string
+	switch v := val.(type) {
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if trimmed == "" {
+			return strings.Repeat(" ", width), nil
+		}
+		fl, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid numeric value %q", v)
+		}
+		str = strconv.FormatFloat(fl, 'f', decimals, 64)
+	default:
+		if i, ok := toInt64(val); ok && decimals == 0 {
+			str = strconv.FormatInt(i, 10)
+			break
+		}
+		fl, ok := toFloat64(val)
+		if !ok {
+			return "", fmt.Errorf("cannot write %T to a numeric field", val)
+		}
+		if math.IsNaN(fl) || math.IsInf(fl, 0) {
+			return "", fmt.Errorf("cannot write %v to a numeric field", fl)
+		}
+		str = strconv.FormatFloat(fl, 'f', decimals, 64)
+	}
+	if len(str) > width {
+		return "", fmt.Errorf("value %s does not fit in length %d", str, width)
+	}
+	return strings.Repeat(" ", width-len(str)) + str, nil
+}
+
+// toInt64 converts all Go int types to int64
+func toInt64(val interface{}) (int64, bool) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// toFloat64 converts all Go int and float types to float64
+func toFloat64(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	if i, ok := toInt64(val); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+// fillSpaces sets all bytes in b to a space
+func fillSpaces(b []byte) {
+	for i := range b {
+		b[i] = 0x20
+	}
+}
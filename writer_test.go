@@ -0,0 +1,299 @@
+package dbf
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newField(name string, typ byte, length, decimals uint8) FieldHeader {
+	f := FieldHeader{Type: typ, Len: length, Decimals: decimals}
+	copy(f.Name[:], name)
+	return f
+}
+
+func TestWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dbffile, err := os.Create(filepath.Join(dir, "NEW.DBF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbffile.Close()
+	fptfile, err := os.Create(filepath.Join(dir, "NEW.FPT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fptfile.Close()
+
+	fields := []FieldHeader{
+		newField("NAME", 'C', 20, 0),
+		newField("AMOUNT", 'N', 10, 2),
+		newField("COUNT", 'I', 0, 0),
+		newField("BORN", 'D', 0, 0),
+		newField("CHANGED", 'T', 0, 0),
+		newField("ACTIVE", 'L', 0, 0),
+		newField("PRICE", 'Y', 0, 0),
+		newField("NOTES", 'M', 0, 0),
+	}
+	wr, err := NewWriter(dbffile, fptfile, fields, new(Win1250Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wr.Header().CodePage = 0xC8
+
+	born := time.Date(1985, 4, 12, 0, 0, 0, 0, time.UTC)
+	changed := time.Date(2019, 11, 3, 14, 5, 6, 0, time.UTC)
+	rows := [][]interface{}{
+		{"Čestmír", 1234.5, 42, born, changed, true, 12.3456, "Poznámka"},
+		{"Empty", nil, nil, nil, nil, nil, nil, nil},
+	}
+	for _, row := range rows {
+		if err := wr.Append(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wr.Append("Too few"); err != ErrNumFields {
+		t.Errorf("Want ErrNumFields, have %v", err)
+	}
+	if err := wr.Append("This name is much too long", nil, nil, nil, nil, nil, nil, nil); err == nil {
+		t.Error("Want an error for a too long value")
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dbfbytes, err := ioutil.ReadFile(dbffile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fptbytes, err := ioutil.ReadFile(fptfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(dbfbytes), bytes.NewReader(fptbytes), new(Win1250Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dbf.NumRecords() != 2 {
+		t.Fatalf("Want 2 records, have %d", dbf.NumRecords())
+	}
+	if dbf.Header().CodePage != 0xC8 {
+		t.Errorf("Want code page 0xC8, have 0x%X", dbf.Header().CodePage)
+	}
+	if int64(len(dbfbytes)) != dbf.Header().FileSize()+1 {
+		t.Errorf("Want file size %d, have %d", dbf.Header().FileSize()+1, len(dbfbytes))
+	}
+
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// C fields are read with their padding
+	want := []interface{}{"Čestmír             ", 1234.5, int32(42), born, changed, true, 12.3456, "Poznámka"}
+	for i, val := range want {
+		if have := rec.FieldSlice()[i]; !equalValue(val, have) {
+			t.Errorf("Field %s: want %v, have %v", fields[i].FieldName(), val, have)
+		}
+	}
+
+	rec, err = dbf.RecordAt(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []interface{}{"Empty               ", float64(0), int32(0), time.Time{}, time.Time{}, false, float64(0)}
+	for i, val := range want {
+		if have := rec.FieldSlice()[i]; !equalValue(val, have) {
+			t.Errorf("Empty field %s: want %v, have %v", fields[i].FieldName(), val, have)
+		}
+	}
+}
+
+func TestWriterInvalidFields(t *testing.T) {
+	tests := [][]FieldHeader{
+		nil,
+		{newField("TOOLONGNAME", 'C', 10, 0)},
+		{newField("NAME", 'C', 0, 0)},
+		{newField("NAME", 'X', 10, 0)},
+		{newField("AMOUNT", 'N', 5, 4)},
+		{newField("NAME", 'C', 10, 0), newField("NAME", 'C', 10, 0)},
+		{newField("NOTES", 'M', 0, 0)}, // no memo file
+		{newField("NAME", 'C', 255, 0)},
+		{newField("1ST", 'C', 10, 0)},
+		{newField("FULL NAME", 'C', 10, 0)},
+		{newField("Name", 'C', 10, 0), newField("NAME", 'D', 0, 0)},
+	}
+	var many []FieldHeader
+	for i := 0; i <= DefaultMaxFields; i++ {
+		many = append(many, newField(fmt.Sprintf("F%d", i), 'L', 0, 0))
+	}
+	tests = append(tests, many)
+	for i, fields := range tests {
+		if _, err := NewWriter(new(memWriteSeeker), nil, fields, new(UTF8Encoder)); err == nil {
+			t.Errorf("Test %d: want an error", i)
+		}
+	}
+}
+
+func TestWriterInvalidValues(t *testing.T) {
+	tests := []struct {
+		field FieldHeader
+		val   interface{}
+	}{
+		{newField("PRICE", 'Y', 8, 4), math.NaN()},
+		{newField("PRICE", 'Y', 8, 4), math.Inf(1)},
+		{newField("PRICE", 'Y', 8, 4), math.Inf(-1)},
+		{newField("PRICE", 'Y', 8, 4), 1e15},
+		{newField("PRICE", 'Y', 8, 4), -1e15},
+		{newField("BORN", 'D', 8, 0), time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{newField("BORN", 'D', 8, 0), time.Date(-1, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{newField("CHANGED", 'T', 8, 0), time.Date(12345, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{newField("CHANGED", 'T', 8, 0), time.Date(-5000, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		wr, err := NewWriter(new(memWriteSeeker), nil, []FieldHeader{test.field}, new(UTF8Encoder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := wr.Append(test.val); err == nil {
+			t.Errorf("Field %s: want an error for %v", test.field.FieldType(), test.val)
+		}
+	}
+
+	// the limits of the field types can be written
+	fields := []FieldHeader{newField("PRICE", 'Y', 8, 4), newField("BORN", 'D', 8, 0)}
+	dbffile := new(memWriteSeeker)
+	wr, err := NewWriter(dbffile, nil, fields, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	born := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	if err := wr.Append(-922337203685477.0, born); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(dbffile.buf), nil, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price, date := rec.FieldSlice()[0], rec.FieldSlice()[1]; price != -922337203685477.0 || date != born {
+		t.Errorf("Want the limits, have %v and %v", price, date)
+	}
+}
+
+func TestWriterEmptyMemo(t *testing.T) {
+	dbffile, fptfile := new(memWriteSeeker), new(memWriteSeeker)
+	wr, err := NewWriter(dbffile, fptfile, []FieldHeader{newField("NOTES", 'M', 0, 0)}, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, memo := range []interface{}{nil, "", "text"} {
+		if err := wr.Append(memo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dbf, err := OpenStream(bytes.NewReader(dbffile.buf), bytes.NewReader(fptfile.buf), new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for recno, want := range []string{"", "", "text"} {
+		rec, err := dbf.RecordAt(uint32(recno))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := rec.FieldSlice()[0]; have != want {
+			t.Errorf("Record %d: want memo %q, have %#v", recno, want, have)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbfcreate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "NEW.DBF")
+	wr, err := Create(name, []FieldHeader{newField("NAME", 'C', 10, 0), newField("NOTES", 'M', 0, 0)}, new(UTF8Encoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Append("Jan", "A memo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.files[0].Close(); err == nil {
+		t.Error("Want the created files closed")
+	}
+
+	dbf, err := OpenFile(name, new(UTF8Decoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbf.Close()
+	rec, err := dbf.RecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := rec.FieldSlice(); dbf.NumRecords() != 1 || v[0] != "Jan       " || v[1] != "A memo" {
+		t.Errorf("Want the appended record, have %v", v)
+	}
+
+	// invalid fields leave no files behind
+	other := filepath.Join(dir, "OTHER.DBF")
+	if _, err := Create(other, []FieldHeader{newField("NOTES", 'M', 0, 0), newField("NOTES", 'M', 0, 0)}, new(UTF8Encoder)); err == nil {
+		t.Error("Want an error for duplicate fields")
+	}
+	for _, file := range []string{other, filepath.Join(dir, "OTHER.FPT")} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("Want %s removed, have %v", file, err)
+		}
+	}
+}
+
+// memWriteSeeker is an in memory io.WriteSeeker for tests
+type memWriteSeeker struct {
+	buf []byte
+	pos int
+}
+
+func (m *memWriteSeeker) Write(p []byte) (int, error) {
+	if end := m.pos + len(p); end > len(m.buf) {
+		m.buf = append(m.buf, make([]byte, end-len(m.buf))...)
+	}
+	n := copy(m.buf[m.pos:], p)
+	m.pos += n
+	return n, nil
+}
+
+func (m *memWriteSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case 1:
+		offset += int64(m.pos)
+	case 2:
+		offset += int64(len(m.buf))
+	}
+	m.pos = int(offset)
+	return offset, nil
+}